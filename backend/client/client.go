@@ -0,0 +1,237 @@
+// Package client calls the task REST API from Go. Client has the methods of
+// service.TaskRepository, so the tasks of a remote server can stand in for
+// the local ones.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/models"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+
+	defaultTimeout    = 30 * time.Second
+	defaultRetries    = 3
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+	defaultPageSize   = 100
+	// maxErrorMessage bounds the error bodies read into APIError.Message.
+	maxErrorMessage = 4096
+)
+
+// Client is a client of the task REST API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	userAgent  string
+	httpClient *http.Client
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	pageSize   int
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAPIKey authenticates the requests with key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithHTTPClient sends the requests with httpClient instead of a client with
+// a 30 seconds timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times the idempotent calls are retried after a
+// network error or a 429, 502, 503 or 504 response, 3 by default and 0 to
+// never retry.
+func WithRetries(retries int) Option {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+// WithBackoff bounds the wait before a retry, which doubles from min up to
+// max with every attempt. The actual wait is drawn at random below it, so
+// the clients that failed together don't retry together.
+func WithBackoff(min, max time.Duration) Option {
+	return func(c *Client) {
+		c.minBackoff = min
+		c.maxBackoff = max
+	}
+}
+
+// WithPageSize sets the number of items the iterators fetch per request, 100
+// by default.
+func WithPageSize(size int) Option {
+	return func(c *Client) {
+		c.pageSize = size
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New returns a client of the API served at baseURL, such as
+// http://localhost:8080.
+func New(baseURL string, options ...Option) (*Client, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base URL: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("client: invalid base URL %q, expected http(s)://host", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    strings.TrimRight(parsed.String(), "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retries:    defaultRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+		pageSize:   defaultPageSize,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c, nil
+}
+
+// Create creates task and sets its ID and creation time. It isn't retried
+// since a retry could create the task twice.
+func (c *Client) Create(ctx context.Context, task *models.Task) error {
+	_, err := c.do(ctx, http.MethodPost, "/tasks", nil, task, task)
+	return err
+}
+
+// GetByID returns the task with id. Its error matches ErrNotFound, and
+// sql.ErrNoRows like TaskManager.GetByID, when there is none.
+func (c *Client) GetByID(ctx context.Context, id string) (*models.Task, error) {
+	var task models.Task
+	if _, err := c.do(ctx, http.MethodGet, taskPath(id), nil, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Update replaces the task with the ID of task.
+func (c *Client) Update(ctx context.Context, task *models.Task) error {
+	_, err := c.do(ctx, http.MethodPut, taskPath(task.ID), nil, task, task)
+	return err
+}
+
+// Delete deletes the task with id. A retried deletion whose first attempt
+// went through reports ErrNotFound.
+func (c *Client) Delete(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, taskPath(id), nil, nil, nil)
+	return err
+}
+
+// GetAll returns all the tasks in one response, see Tasks to page through
+// them instead.
+func (c *Client) GetAll(ctx context.Context) ([]models.Task, error) {
+	var tasks []models.Task
+	if _, err := c.do(ctx, http.MethodGet, "/tasks", nil, nil, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func taskPath(id string) string {
+	return "/tasks/" + url.PathEscape(id)
+}
+
+// do sends body as JSON to path and decodes the response into result, when
+// they are not nil, and returns the response headers. The idempotent methods
+// are retried.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, result any) (http.Header, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	idempotent := method != http.MethodPost
+
+	for attempt := 0; ; attempt++ {
+		header, err := c.send(ctx, method, target, payload, result)
+		if err == nil {
+			return header, nil
+		}
+		if !idempotent || attempt >= c.retries || !retryable(ctx, err) {
+			return nil, err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt, err))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send makes one attempt of a request.
+func (c *Client) send(ctx context.Context, method, target string, payload []byte, result any) (http.Header, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &networkError{err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, newAPIError(resp)
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return nil, fmt.Errorf("client: invalid response to %s %s: %w", method, target, err)
+		}
+	}
+	return resp.Header, nil
+}
@@ -0,0 +1,330 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/handler"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"github.com/saarzur123/task-management/backend/utils"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Client Suite")
+}
+
+var _ service.TaskRepository = (*Client)(nil)
+
+var (
+	errMock = errors.New("mock error")
+)
+
+var _ = Describe("Client", func() {
+	var (
+		mockTasks  *serviceMock.MockTaskRepository
+		mockPages  *serviceMock.MockTaskPageReader
+		mockOutbox *serviceMock.MockOutboxReader
+		mockKeys   *serviceMock.MockAPIKeyRepository
+		// failures answers the next requests with failStatus, or drops their
+		// connection when it is zero.
+		failures   atomic.Int32
+		failStatus int
+		requests   atomic.Int32
+		server     *httptest.Server
+		client     *Client
+		now        = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		tasks      = []models.Task{
+			{ID: "1", Title: "Task 1", Status: "todo", CreatedAt: now},
+			{ID: "2", Title: "Task 2", Status: "done", CreatedAt: now},
+			{ID: "3", Title: "Task 3", Status: "todo", CreatedAt: now},
+		}
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockTasks = serviceMock.NewMockTaskRepository(mockCtrl)
+		mockPages = serviceMock.NewMockTaskPageReader(mockCtrl)
+		mockOutbox = serviceMock.NewMockOutboxReader(mockCtrl)
+		mockKeys = serviceMock.NewMockAPIKeyRepository(mockCtrl)
+		mockKeys.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		mockAudit := serviceMock.NewMockAuditRepository(mockCtrl)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		failures.Store(0)
+		failStatus = http.StatusServiceUnavailable
+		requests.Store(0)
+
+		router := utils.SetupRoutes(mockTasks,
+			utils.WithAPIKeys(mockKeys, mockAudit, false),
+			utils.WithTaskPages(mockPages),
+			utils.WithEventFeed(&handler.EventFeedHandler{Outbox: mockOutbox}))
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			if failures.Add(-1) >= 0 {
+				if failStatus == 0 {
+					conn, _, err := w.(http.Hijacker).Hijack()
+					Expect(err).To(Succeed())
+					conn.Close()
+					return
+				}
+				http.Error(w, http.StatusText(failStatus), failStatus)
+				return
+			}
+			router.ServeHTTP(w, r)
+		}))
+		DeferCleanup(server.Close)
+
+		var err error
+		client, err = New(server.URL+"/", WithBackoff(time.Millisecond, 4*time.Millisecond), WithPageSize(2))
+		Expect(err).To(Succeed())
+	})
+
+	It("rejects invalid base URLs", func() {
+		for _, baseURL := range []string{"localhost:8080", "ftp://example.com", "http://", "://"} {
+			_, err := New(baseURL)
+			Expect(err).To(HaveOccurred(), baseURL)
+		}
+	})
+
+	Describe("TaskRepository", func() {
+		It("creates tasks", func() {
+			mockTasks.EXPECT().Create(gomock.Any(), &models.Task{Title: "New", Status: "todo"}).DoAndReturn(func(_ context.Context, task *models.Task) error {
+				task.ID, task.CreatedAt = "4", now
+				return nil
+			})
+
+			task := &models.Task{Title: "New", Status: "todo"}
+			Expect(client.Create(context.Background(), task)).To(Succeed())
+			Expect(*task).To(Equal(models.Task{ID: "4", Title: "New", Status: "todo", CreatedAt: now}))
+		})
+
+		It("gets tasks", func() {
+			mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(&tasks[0], nil)
+
+			task, err := client.GetByID(context.Background(), "1")
+			Expect(err).To(Succeed())
+			Expect(*task).To(Equal(tasks[0]))
+		})
+
+		It("reports missing tasks like the services", func() {
+			mockTasks.EXPECT().GetByID(gomock.Any(), "9").Return(nil, sql.ErrNoRows)
+			mockTasks.EXPECT().Update(gomock.Any(), gomock.Any()).Return(service.ErrNotFound)
+			mockTasks.EXPECT().Delete(gomock.Any(), "9").Return(service.ErrNotFound)
+
+			_, err := client.GetByID(context.Background(), "9")
+			Expect(err).To(MatchError(service.ErrNotFound))
+			Expect(err).To(MatchError(sql.ErrNoRows))
+			var apiErr *APIError
+			Expect(errors.As(err, &apiErr)).To(BeTrue())
+			Expect(apiErr.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(apiErr.Message).To(Equal("Task not found"))
+
+			Expect(client.Update(context.Background(), &models.Task{ID: "9", Title: "Gone"})).To(MatchError(ErrNotFound))
+			Expect(client.Delete(context.Background(), "9")).To(MatchError(ErrNotFound))
+		})
+
+		It("updates tasks", func() {
+			mockTasks.EXPECT().Update(gomock.Any(), &models.Task{ID: "1", Title: "Task 1", Status: "done", CreatedAt: now}).Return(nil)
+
+			task := tasks[0]
+			task.Status = "done"
+			Expect(client.Update(context.Background(), &task)).To(Succeed())
+			Expect(task.Status).To(Equal("done"))
+		})
+
+		It("deletes tasks", func() {
+			mockTasks.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+			Expect(client.Delete(context.Background(), "1")).To(Succeed())
+		})
+
+		It("gets all the tasks", func() {
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return(tasks, nil)
+
+			all, err := client.GetAll(context.Background())
+			Expect(err).To(Succeed())
+			Expect(all).To(Equal(tasks))
+		})
+
+		It("authenticates with the API key", func() {
+			client, err := New(server.URL, WithAPIKey("tm_secret"), WithRetries(0))
+			Expect(err).To(Succeed())
+			mockKeys.EXPECT().Authenticate(gomock.Any(), "tm_secret").Return(&models.APIKey{Name: "sdk", Scopes: []string{models.ScopeTasksRead}}, nil).Times(2)
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return(tasks, nil)
+
+			_, err = client.GetAll(context.Background())
+			Expect(err).To(Succeed())
+			err = client.Delete(context.Background(), "1")
+			Expect(err).To(MatchError(ErrForbidden))
+			Expect(err).To(MatchError("server returned 403: Missing scope tasks:write"))
+		})
+	})
+
+	Describe("retries", func() {
+		It("retries the idempotent calls when the server is unavailable", func() {
+			failures.Store(2)
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return(tasks, nil)
+
+			_, err := client.GetAll(context.Background())
+			Expect(err).To(Succeed())
+			Expect(requests.Load()).To(BeEquivalentTo(3))
+		})
+
+		It("retries dropped connections", func() {
+			failures.Store(1)
+			failStatus = 0
+			mockTasks.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+
+			Expect(client.Delete(context.Background(), "1")).To(Succeed())
+			Expect(requests.Load()).To(BeEquivalentTo(2))
+		})
+
+		It("retries rate limited calls", func() {
+			failures.Store(1)
+			failStatus = http.StatusTooManyRequests
+			mockTasks.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+			Expect(client.Update(context.Background(), &models.Task{ID: "1", Title: "Task 1"})).To(Succeed())
+			Expect(requests.Load()).To(BeEquivalentTo(2))
+		})
+
+		It("gives up after the configured retries", func() {
+			failures.Store(10)
+
+			_, err := client.GetByID(context.Background(), "1")
+			var apiErr *APIError
+			Expect(errors.As(err, &apiErr)).To(BeTrue())
+			Expect(apiErr.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(requests.Load()).To(BeEquivalentTo(1 + defaultRetries))
+		})
+
+		It("doesn't retry creations", func() {
+			failures.Store(1)
+
+			err := client.Create(context.Background(), &models.Task{Title: "New"})
+			Expect(err).To(HaveOccurred())
+			Expect(requests.Load()).To(BeEquivalentTo(1))
+		})
+
+		It("doesn't retry server errors", func() {
+			failures.Store(1)
+			failStatus = http.StatusInternalServerError
+
+			_, err := client.GetAll(context.Background())
+			Expect(err).To(MatchError("server returned 500: Internal Server Error"))
+			Expect(requests.Load()).To(BeEquivalentTo(1))
+		})
+
+		It("stops when the context is done", func() {
+			failures.Store(10)
+			client, err := New(server.URL, WithBackoff(time.Hour, time.Hour))
+			Expect(err).To(Succeed())
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			_, err = client.GetAll(ctx)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(requests.Load()).To(BeEquivalentTo(1))
+		})
+
+		It("waits a random part of the exponential backoff", func() {
+			client, err := New(server.URL, WithBackoff(10*time.Millisecond, 50*time.Millisecond))
+			Expect(err).To(Succeed())
+			unavailable := &APIError{StatusCode: http.StatusServiceUnavailable}
+			for range 100 {
+				Expect(client.backoff(0, unavailable)).To(BeNumerically("<=", 10*time.Millisecond))
+				Expect(client.backoff(2, unavailable)).To(BeNumerically("<=", 40*time.Millisecond))
+				Expect(client.backoff(40, unavailable)).To(BeNumerically("<=", 50*time.Millisecond))
+			}
+			Expect(client.backoff(0, &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second})).To(Equal(2 * time.Second))
+		})
+
+		It("reads Retry-After in seconds or as a date", func() {
+			Expect(parseRetryAfter("3")).To(Equal(3 * time.Second))
+			Expect(parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))).To(BeNumerically("~", time.Minute, 2*time.Second))
+			Expect(parseRetryAfter("soon")).To(BeZero())
+		})
+	})
+
+	Describe("Tasks", func() {
+		It("fetches the tasks a page at a time", func() {
+			gomock.InOrder(
+				mockPages.EXPECT().GetPage(gomock.Any(), "", 2).Return(tasks[:2], nil),
+				mockPages.EXPECT().GetPage(gomock.Any(), "2", 2).Return(tasks[2:], nil),
+			)
+
+			var listed []models.Task
+			for task, err := range client.Tasks(context.Background()) {
+				Expect(err).To(Succeed())
+				listed = append(listed, task)
+			}
+			Expect(listed).To(Equal(tasks))
+		})
+
+		It("fetches no more pages once the loop stops", func() {
+			mockPages.EXPECT().GetPage(gomock.Any(), "", 2).Return(tasks[:2], nil)
+
+			for task := range client.Tasks(context.Background()) {
+				Expect(task.ID).To(Equal("1"))
+				break
+			}
+		})
+
+		It("yields the errors", func() {
+			mockPages.EXPECT().GetPage(gomock.Any(), "", 2).Return(nil, errMock)
+
+			var errs []error
+			for _, err := range client.Tasks(context.Background()) {
+				errs = append(errs, err)
+			}
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0]).To(MatchError("server returned 500: " + errMock.Error()))
+		})
+
+		It("takes all the tasks from servers that don't page", func() {
+			server := httptest.NewServer(utils.SetupRoutes(mockTasks))
+			DeferCleanup(server.Close)
+			client, err := New(server.URL, WithPageSize(2))
+			Expect(err).To(Succeed())
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return(tasks, nil)
+
+			var listed []models.Task
+			for task, err := range client.Tasks(context.Background()) {
+				Expect(err).To(Succeed())
+				listed = append(listed, task)
+			}
+			Expect(listed).To(Equal(tasks))
+		})
+	})
+
+	Describe("Events", func() {
+		It("follows the feed until it caught up", func() {
+			events := []models.TaskEvent{
+				{ID: 4, Type: models.EventTaskCreated, TaskID: "1", OccurredAt: now},
+				{ID: 5, Type: models.EventTaskUpdated, TaskID: "1", OccurredAt: now},
+				{ID: 7, Type: models.EventTaskDeleted, TaskID: "1", OccurredAt: now},
+			}
+			gomock.InOrder(
+				mockOutbox.EXPECT().After(gomock.Any(), uint64(3), 2).Return(events[:2], nil),
+				mockOutbox.EXPECT().After(gomock.Any(), uint64(5), 2).Return(events[2:], nil),
+				mockOutbox.EXPECT().After(gomock.Any(), uint64(7), 2).Return([]models.TaskEvent{}, nil),
+			)
+
+			var received []models.TaskEvent
+			for event, err := range client.Events(context.Background(), 3) {
+				Expect(err).To(Succeed())
+				received = append(received, event)
+			}
+			Expect(received).To(Equal(events))
+		})
+	})
+})
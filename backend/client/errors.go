@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/models"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is the error of the services for missing tasks, which the
+	// 404 responses match.
+	ErrNotFound     = models.ErrNotFound
+	ErrInvalidInput = errors.New("invalid input")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// APIError is a response with an error status. Use errors.Is with the Err
+// variables to check for the common ones.
+type APIError struct {
+	StatusCode int
+	// Message is the error written by the server.
+	Message string
+	// RetryAfter is the wait asked for by the Retry-After header, if any.
+	RetryAfter time.Duration
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorMessage))
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(message)),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("server returned %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("server returned %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound, sql.ErrNoRows:
+		return e.StatusCode == http.StatusNotFound
+	case ErrInvalidInput:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as a date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+// retryable reports whether a request that failed with err may succeed if
+// sent again: the server was overloaded or unreachable.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var netErr *networkError
+	return errors.As(err, &netErr)
+}
+
+// networkError is a request that got no response.
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string {
+	return e.err.Error()
+}
+
+func (e *networkError) Unwrap() error {
+	return e.err
+}
+
+// backoff returns the wait before retrying after attempt failed with err: a
+// random duration below the exponential backoff, or the Retry-After of the
+// response when it is longer.
+func (c *Client) backoff(attempt int, err error) time.Duration {
+	ceiling := c.minBackoff
+	for i := 0; i < attempt && ceiling < c.maxBackoff; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, c.maxBackoff)
+	var wait time.Duration
+	if ceiling > 0 {
+		wait = rand.N(ceiling + 1)
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > wait {
+		wait = apiErr.RetryAfter
+	}
+	return wait
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"github.com/saarzur123/task-management/backend/models"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Tasks iterates over the tasks ordered by ID, fetching them a page at a
+// time. A server that doesn't page returns them all at once. The iteration
+// stops after yielding an error.
+func (c *Client) Tasks(ctx context.Context) iter.Seq2[models.Task, error] {
+	return func(yield func(models.Task, error) bool) {
+		query := url.Values{"limit": {strconv.Itoa(c.pageSize)}}
+		for {
+			var page []models.Task
+			header, err := c.do(ctx, http.MethodGet, "/tasks", query, nil, &page)
+			if err != nil {
+				yield(models.Task{}, err)
+				return
+			}
+			for _, task := range page {
+				if !yield(task, nil) {
+					return
+				}
+			}
+
+			next, ok := nextPage(header)
+			if !ok {
+				return
+			}
+			query = next
+		}
+	}
+}
+
+// eventFeed is a page of GET /events.
+type eventFeed struct {
+	Events []models.TaskEvent `json:"events"`
+	Next   uint64             `json:"next"`
+}
+
+// Events iterates over the task events with a sequence number greater than
+// after until it caught up with the feed. Pass the ID of the last event as
+// after to continue later. The iteration stops after yielding an error.
+func (c *Client) Events(ctx context.Context, after uint64) iter.Seq2[models.TaskEvent, error] {
+	return func(yield func(models.TaskEvent, error) bool) {
+		for {
+			query := url.Values{
+				"after": {strconv.FormatUint(after, 10)},
+				"limit": {strconv.Itoa(c.pageSize)},
+				"wait":  {"0"},
+			}
+			var feed eventFeed
+			if _, err := c.do(ctx, http.MethodGet, "/events", query, nil, &feed); err != nil {
+				yield(models.TaskEvent{}, err)
+				return
+			}
+			if len(feed.Events) == 0 {
+				return
+			}
+			for _, event := range feed.Events {
+				if !yield(event, nil) {
+					return
+				}
+			}
+			after = feed.Next
+		}
+	}
+}
+
+// nextPage returns the query of the rel="next" link of header. Only the
+// query is kept, the link is relative to the root of the server which may
+// not be the base URL of the client.
+func nextPage(header http.Header) (url.Values, bool) {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok || !strings.Contains(params, `rel="next"`) {
+				continue
+			}
+			parsed, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+			if err != nil {
+				return nil, false
+			}
+			return parsed.Query(), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,52 @@
+package clock
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Clock Suite")
+}
+
+var _ = Describe("Fake", func() {
+	var (
+		start = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+		fake  *Fake
+	)
+
+	BeforeEach(func() {
+		fake = NewFake(start)
+	})
+
+	It("only moves when advanced", func() {
+		Expect(fake.Now()).To(Equal(start))
+		fake.Advance(time.Hour)
+		Expect(fake.Now()).To(Equal(start.Add(time.Hour)))
+	})
+
+	It("fires After once its time came", func() {
+		soon := fake.After(time.Minute)
+		later := fake.After(time.Hour)
+		Expect(fake.Waiters()).To(Equal(2))
+
+		fake.Advance(30 * time.Second)
+		Consistently(soon).ShouldNot(Receive())
+
+		fake.Advance(30 * time.Second)
+		Expect(soon).To(Receive(Equal(start.Add(time.Minute))))
+		Expect(later).NotTo(Receive())
+		Expect(fake.Waiters()).To(Equal(1))
+
+		fake.Advance(2 * time.Hour)
+		Expect(later).To(Receive(Equal(start.Add(2*time.Hour + time.Minute))))
+		Expect(fake.Waiters()).To(BeZero())
+	})
+
+	It("fires After right away without a delay", func() {
+		Expect(fake.After(0)).To(Receive(Equal(start)))
+	})
+})
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/client"
+	"io"
+	"os"
+)
+
+const (
+	userAgent = "taskctl"
+
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const usage = `Usage: taskctl [flags] <command> [arguments]
+
+Manages the tasks of the task management REST API.
+
+Commands:
+  list [--status s1,s2] [--search text] [--limit n]  list the tasks
+  get <id>                                           show a task
+  create --title t [--description d] [--status s]    create a task, recurring with an
+         [--due time [--recurrence rule]]            RRULE when due at an RFC 3339 time
+         [--assignee user]                           and reminded to the assignee
+  edit <id>                                          edit a task as YAML in $EDITOR
+  delete <id>                                        delete a task
+  status <id> <status>                               set the status of a task
+  config list                                        list the profiles
+  config set <name> [--server url] [--token key]     add or change a profile
+  config use <name>                                  make a profile the current one
+  config delete <name>                               delete a profile
+
+Flags, also accepted after the command:
+  -o, --output table|json|yaml  output format (default table)
+  --profile name                profile to use instead of the current one ($TASKCTL_PROFILE)
+  --server url                  server to use instead of the profile's ($TASKCTL_SERVER)
+  --token key                   API key to use instead of the profile's ($TASKCTL_TOKEN)
+  --config path                 profiles file ($TASKCTL_CONFIG)
+`
+
+var (
+	// errHelp reports that the usage was asked for and printed.
+	errHelp = errors.New("help requested")
+)
+
+// usageError is a command line that can't run. An empty message means the
+// flag package already reported it.
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// cli runs the commands with its streams and environment, which the tests
+// replace.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+}
+
+func newCLI() *cli {
+	return &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+}
+
+// session holds the flags shared by the commands.
+type session struct {
+	*cli
+	configPath string
+	profile    string
+	server     string
+	token      string
+	output     string
+}
+
+var commands = map[string]func(s *session, ctx context.Context, args []string) error{
+	"list":   (*session).list,
+	"get":    (*session).get,
+	"create": (*session).create,
+	"edit":   (*session).edit,
+	"delete": (*session).delete,
+	"status": (*session).status,
+	"config": (*session).configCommand,
+}
+
+// run runs the command of args and returns the exit code.
+func (c *cli) run(ctx context.Context, args []string) int {
+	s := &session{
+		cli:        c,
+		configPath: c.getenv("TASKCTL_CONFIG"),
+		profile:    c.getenv("TASKCTL_PROFILE"),
+		server:     c.getenv("TASKCTL_SERVER"),
+		token:      c.getenv("TASKCTL_TOKEN"),
+		output:     formatTable,
+	}
+
+	flags := s.flags("taskctl")
+	flags.Usage = func() { fmt.Fprint(c.stderr, usage) }
+	err := flags.Parse(args)
+	if err == nil && flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+	if err == nil {
+		name := flags.Arg(0)
+		if name == "help" {
+			fmt.Fprint(c.stdout, usage)
+			return exitOK
+		}
+		command, ok := commands[name]
+		if !ok {
+			err = usageErrorf("unknown command %q", name)
+		} else {
+			err = command(s, ctx, flags.Args()[1:])
+		}
+	}
+
+	var usageErr *usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp), errors.Is(err, errHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		if usageErr.message != "" {
+			fmt.Fprintf(c.stderr, "taskctl: %s\nRun 'taskctl help' for usage.\n", usageErr.message)
+		}
+		return exitUsage
+	default:
+		fmt.Fprintf(c.stderr, "taskctl: %s\n", err)
+		return exitError
+	}
+}
+
+// flags returns a flag set with the shared flags, so they can follow the
+// command too.
+func (s *session) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(s.stderr)
+	fs.StringVar(&s.output, "o", s.output, "output format: table, json or yaml")
+	fs.StringVar(&s.output, "output", s.output, "output format: table, json or yaml")
+	fs.StringVar(&s.profile, "profile", s.profile, "profile to use instead of the current one")
+	fs.StringVar(&s.server, "server", s.server, "server to use instead of the profile's")
+	fs.StringVar(&s.token, "token", s.token, "API key to use instead of the profile's")
+	fs.StringVar(&s.configPath, "config", s.configPath, "profiles file")
+	return fs
+}
+
+// parse parses the flags of fs wherever they are among args and returns the
+// other arguments, of which there must be want.
+func (s *session) parse(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, errHelp
+			}
+			return nil, &usageError{}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if len(positional) != want {
+		return nil, usageErrorf("%s takes %d argument(s), got %d", fs.Name(), want, len(positional))
+	}
+	if !validFormat(s.output) {
+		return nil, usageErrorf("unknown output format %q", s.output)
+	}
+	return positional, nil
+}
+
+func (s *session) loadConfig() (*config, string, error) {
+	path := s.configPath
+	if path == "" {
+		var err error
+		if path, err = defaultConfigPath(); err != nil {
+			return nil, "", err
+		}
+	}
+	cfg, err := loadConfig(path)
+	return cfg, path, err
+}
+
+// apiClient returns a client for the selected profile, with the server and
+// token overridden by the flags.
+func (s *session) apiClient() (*client.Client, error) {
+	cfg, _, err := s.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	p, err := cfg.resolve(s.profile)
+	if err != nil {
+		return nil, err
+	}
+	if s.server != "" {
+		p.Server = s.server
+	}
+	if s.token != "" {
+		p.Token = s.token
+	}
+	return client.New(p.Server, client.WithAPIKey(p.Token), client.WithUserAgent(userAgent))
+}
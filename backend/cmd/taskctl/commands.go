@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/models"
+	"strings"
+	"time"
+)
+
+func (s *session) list(ctx context.Context, args []string) error {
+	fs := s.flags("list")
+	status := fs.String("status", "", "only list the tasks with one of these comma-separated statuses")
+	search := fs.String("search", "", "only list the tasks whose title or description contain this text")
+	limit := fs.Int("limit", 0, "list at most this many tasks")
+	if _, err := s.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	api, err := s.apiClient()
+	if err != nil {
+		return err
+	}
+
+	match := taskFilter(*status, *search)
+	tasks := make([]models.Task, 0)
+	for task, err := range api.Tasks(ctx) {
+		if err != nil {
+			return err
+		}
+		if !match(task) {
+			continue
+		}
+		tasks = append(tasks, task)
+		if len(tasks) == *limit {
+			break
+		}
+	}
+	return printTasks(s.stdout, s.output, tasks)
+}
+
+// taskFilter matches the tasks with one of the comma-separated statuses
+// whose title or description contain search, ignoring case. Empty filters
+// match every task.
+func taskFilter(statuses, search string) func(task models.Task) bool {
+	wanted := map[string]bool{}
+	for _, status := range strings.Split(statuses, ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			wanted[status] = true
+		}
+	}
+	search = strings.ToLower(search)
+
+	return func(task models.Task) bool {
+		if len(wanted) > 0 && !wanted[task.Status] {
+			return false
+		}
+		return search == "" || strings.Contains(strings.ToLower(task.Title), search) ||
+			strings.Contains(strings.ToLower(task.Description), search)
+	}
+}
+
+func (s *session) get(ctx context.Context, args []string) error {
+	fs := s.flags("get")
+	positional, err := s.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	api, err := s.apiClient()
+	if err != nil {
+		return err
+	}
+	task, err := api.GetByID(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return printTask(s.stdout, s.output, task)
+}
+
+func (s *session) create(ctx context.Context, args []string) error {
+	fs := s.flags("create")
+	var task models.Task
+	fs.StringVar(&task.Title, "title", "", "title of the task, required")
+	fs.StringVar(&task.Description, "description", "", "description of the task")
+	fs.StringVar(&task.Status, "status", "", "status of the task")
+	due := fs.String("due", "", "when the task is due, as an RFC 3339 time")
+	fs.StringVar(&task.Recurrence, "recurrence", "", "RRULE repeating the task from --due, such as FREQ=WEEKLY;BYDAY=MO")
+	fs.StringVar(&task.Assignee, "assignee", "", "user reminded of the due date, the name of their API key")
+	if _, err := s.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if task.Title == "" {
+		return usageErrorf("create needs a --title")
+	}
+	if *due != "" {
+		dueAt, err := time.Parse(time.RFC3339, *due)
+		if err != nil {
+			return usageErrorf("invalid --due %q, expected an RFC 3339 time such as 2026-01-05T09:00:00Z", *due)
+		}
+		task.DueAt = &dueAt
+	}
+	if task.Recurrence != "" && task.DueAt == nil {
+		return usageErrorf("--recurrence needs a --due")
+	}
+
+	api, err := s.apiClient()
+	if err != nil {
+		return err
+	}
+	if err := api.Create(ctx, &task); err != nil {
+		return err
+	}
+	return printTask(s.stdout, s.output, &task)
+}
+
+func (s *session) delete(ctx context.Context, args []string) error {
+	fs := s.flags("delete")
+	positional, err := s.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	api, err := s.apiClient()
+	if err != nil {
+		return err
+	}
+	if err := api.Delete(ctx, positional[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.stdout, "Deleted task %s\n", positional[0])
+	return nil
+}
+
+// status replaces the status of a task. The API has no partial updates, so
+// the task is read first.
+func (s *session) status(ctx context.Context, args []string) error {
+	fs := s.flags("status")
+	positional, err := s.parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	api, err := s.apiClient()
+	if err != nil {
+		return err
+	}
+	task, err := api.GetByID(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	task.Status = positional[1]
+	if err := api.Update(ctx, task); err != nil {
+		return err
+	}
+	return printTask(s.stdout, s.output, task)
+}
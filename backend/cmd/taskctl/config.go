@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	defaultServer = "http://localhost:8080"
+	// The profiles hold API keys, keep them private to the user.
+	configFileMode = 0o600
+	configDirMode  = 0o700
+)
+
+// config is the file of the profiles. Current names the profile used when
+// none is given.
+type config struct {
+	Current  string             `yaml:"current,omitempty"`
+	Profiles map[string]profile `yaml:"profiles,omitempty"`
+}
+
+// profile is a server and the API key to authenticate to it with.
+type profile struct {
+	Server string `yaml:"server"`
+	Token  string `yaml:"token,omitempty"`
+}
+
+// defaultConfigPath returns taskctl/config.yaml in the user configuration
+// directory.
+func defaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "taskctl", "config.yaml"), nil
+}
+
+// loadConfig reads the config at path, which may not exist yet.
+func loadConfig(path string) (*config, error) {
+	cfg := &config{Profiles: map[string]profile{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]profile{}
+	}
+	return cfg, nil
+}
+
+func (c *config) save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), configDirMode); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, configFileMode)
+}
+
+// resolve returns the profile named name, or the current one when name is
+// empty. Without profiles it returns the local server.
+func (c *config) resolve(name string) (profile, error) {
+	if name == "" {
+		name = c.Current
+	}
+	if name == "" {
+		return profile{Server: defaultServer}, nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return profile{}, fmt.Errorf("profile %q not found", name)
+	}
+	if p.Server == "" {
+		p.Server = defaultServer
+	}
+	return p, nil
+}
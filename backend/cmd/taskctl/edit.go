@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/models"
+	"gopkg.in/yaml.v3"
+	"os"
+	"os/exec"
+)
+
+const (
+	defaultEditor = "vi"
+	editHeader    = "# Edit task %s and save to update it, leave it unchanged to cancel.\n" +
+		"# Changes to id, created_at, series_id and occurrence are ignored.\n"
+)
+
+// edit opens the task as YAML in the editor of $VISUAL or $EDITOR and
+// updates it with the saved file.
+func (s *session) edit(ctx context.Context, args []string) error {
+	fs := s.flags("edit")
+	positional, err := s.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	api, err := s.apiClient()
+	if err != nil {
+		return err
+	}
+	task, err := api.GetByID(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+
+	original, err := yaml.Marshal(task)
+	if err != nil {
+		return err
+	}
+	original = append([]byte(fmt.Sprintf(editHeader, task.ID)), original...)
+
+	edited, err := s.editFile("taskctl-"+task.ID+"-*.yaml", original)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(edited, original) {
+		fmt.Fprintln(s.stderr, "Edit cancelled, no changes made.")
+		return nil
+	}
+
+	var updated models.Task
+	if err := yaml.Unmarshal(edited, &updated); err != nil {
+		return fmt.Errorf("invalid task YAML: %w", err)
+	}
+	updated.ID = task.ID
+	updated.CreatedAt = task.CreatedAt
+	if err := api.Update(ctx, &updated); err != nil {
+		return err
+	}
+	return printTask(s.stdout, s.output, &updated)
+}
+
+// editFile writes content to a temporary file, waits for the editor to
+// close it and returns what was saved.
+func (s *session) editFile(pattern string, content []byte) ([]byte, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	editor := s.getenv("VISUAL")
+	if editor == "" {
+		editor = s.getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = defaultEditor
+	}
+	// Through the shell, so editors given with arguments like "code --wait"
+	// work.
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", file.Name())
+	cmd.Stdin = s.stdin
+	cmd.Stdout = s.stdout
+	cmd.Stderr = s.stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return os.ReadFile(file.Name())
+}
@@ -0,0 +1,19 @@
+// Command taskctl manages the tasks of the REST API from the terminal.
+//
+// The servers and API keys it talks to are kept as named profiles in
+// $TASKCTL_CONFIG, by default taskctl/config.yaml in the user configuration
+// directory.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := newCLI().run(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/models"
+	"gopkg.in/yaml.v3"
+	"io"
+	"text/tabwriter"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+
+	createdLayout = "2006-01-02 15:04"
+)
+
+func validFormat(format string) bool {
+	return format == formatTable || format == formatJSON || format == formatYAML
+}
+
+func printTasks(w io.Writer, format string, tasks []models.Task) error {
+	switch format {
+	case formatJSON:
+		return printJSON(w, tasks)
+	case formatYAML:
+		return yaml.NewEncoder(w).Encode(tasks)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tTITLE\tCREATED")
+	for _, task := range tasks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", task.ID, task.Status, task.Title, task.CreatedAt.Local().Format(createdLayout))
+	}
+	return tw.Flush()
+}
+
+// printTask prints task alone, as a table with its description and schedule.
+func printTask(w io.Writer, format string, task *models.Task) error {
+	switch format {
+	case formatJSON:
+		return printJSON(w, task)
+	case formatYAML:
+		return yaml.NewEncoder(w).Encode(task)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID:\t%s\n", task.ID)
+	fmt.Fprintf(tw, "Title:\t%s\n", task.Title)
+	fmt.Fprintf(tw, "Status:\t%s\n", task.Status)
+	fmt.Fprintf(tw, "Description:\t%s\n", task.Description)
+	fmt.Fprintf(tw, "Created:\t%s\n", task.CreatedAt.Local().Format(createdLayout))
+	if task.DueAt != nil {
+		fmt.Fprintf(tw, "Due:\t%s\n", task.DueAt.Local().Format(createdLayout))
+	}
+	if task.Assignee != "" {
+		fmt.Fprintf(tw, "Assignee:\t%s\n", task.Assignee)
+	}
+	if task.Recurrence != "" {
+		fmt.Fprintf(tw, "Recurrence:\t%s (occurrence %d)\n", task.Recurrence, task.Occurrence)
+	}
+	return tw.Flush()
+}
+
+func printJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+)
+
+// configCommand manages the profiles: list, set, use and delete.
+func (s *session) configCommand(_ context.Context, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("config needs a subcommand: list, set, use or delete")
+	}
+
+	switch args[0] {
+	case "list":
+		return s.configList(args[1:])
+	case "set":
+		return s.configSet(args[1:])
+	case "use":
+		return s.configUse(args[1:])
+	case "delete":
+		return s.configDelete(args[1:])
+	}
+	return usageErrorf("unknown config subcommand %q", args[0])
+}
+
+func (s *session) configList(args []string) error {
+	if _, err := s.parse(s.flags("config list"), args, 0); err != nil {
+		return err
+	}
+	cfg, _, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(s.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CURRENT\tNAME\tSERVER\tTOKEN")
+	for _, name := range names {
+		current, token := "", ""
+		if name == cfg.Current {
+			current = "*"
+		}
+		if cfg.Profiles[name].Token != "" {
+			token = "set"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", current, name, cfg.Profiles[name].Server, token)
+	}
+	return tw.Flush()
+}
+
+// configSet adds or changes the server and token of a profile. The first
+// profile becomes the current one.
+func (s *session) configSet(args []string) error {
+	// The server and token of the environment are not meant for the profile,
+	// only the given flags are saved.
+	s.server, s.token = "", ""
+	fs := s.flags("config set")
+	positional, err := s.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	name := positional[0]
+
+	cfg, path, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+	p, exists := cfg.Profiles[name]
+	if s.server != "" {
+		p.Server = s.server
+	}
+	if s.token != "" {
+		p.Token = s.token
+	}
+	if !exists && p.Server == "" {
+		return usageErrorf("new profile %q needs a --server", name)
+	}
+
+	cfg.Profiles[name] = p
+	if cfg.Current == "" {
+		cfg.Current = name
+	}
+	if err := cfg.save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.stdout, "Saved profile %s\n", name)
+	return nil
+}
+
+func (s *session) configUse(args []string) error {
+	positional, err := s.parse(s.flags("config use"), args, 1)
+	if err != nil {
+		return err
+	}
+	name := positional[0]
+
+	cfg, path, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+	cfg.Current = name
+	if err := cfg.save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.stdout, "Using profile %s\n", name)
+	return nil
+}
+
+func (s *session) configDelete(args []string) error {
+	positional, err := s.parse(s.flags("config delete"), args, 1)
+	if err != nil {
+		return err
+	}
+	name := positional[0]
+
+	cfg, path, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+	delete(cfg.Profiles, name)
+	if cfg.Current == name {
+		cfg.Current = ""
+	}
+	if err := cfg.save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.stdout, "Deleted profile %s\n", name)
+	return nil
+}
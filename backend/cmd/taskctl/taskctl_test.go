@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/utils"
+	"gopkg.in/yaml.v3"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskctl(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Taskctl Suite")
+}
+
+var _ = Describe("taskctl", func() {
+	var (
+		mockTasks  *serviceMock.MockTaskRepository
+		mockKeys   *serviceMock.MockAPIKeyRepository
+		mockAudit  *serviceMock.MockAuditRepository
+		server     *httptest.Server
+		configPath string
+		env        map[string]string
+		stdout     *bytes.Buffer
+		stderr     *bytes.Buffer
+		now        = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		tasks      = []models.Task{
+			{ID: "1", Title: "Write docs", Description: "For the CLI", Status: "todo", CreatedAt: now},
+			{ID: "2", Title: "Ship release", Status: "done", CreatedAt: now},
+			{ID: "3", Title: "Fix login", Description: "Write a regression test", Status: "in progress", CreatedAt: now},
+		}
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockTasks = serviceMock.NewMockTaskRepository(mockCtrl)
+		mockKeys = serviceMock.NewMockAPIKeyRepository(mockCtrl)
+		mockKeys.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		mockAudit = serviceMock.NewMockAuditRepository(mockCtrl)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		server = httptest.NewServer(utils.SetupRoutes(mockTasks, utils.WithAPIKeys(mockKeys, mockAudit, false)))
+		DeferCleanup(server.Close)
+
+		configPath = filepath.Join(GinkgoT().TempDir(), "taskctl", "config.yaml")
+		env = map[string]string{"TASKCTL_CONFIG": configPath, "TASKCTL_SERVER": server.URL}
+	})
+
+	run := func(args ...string) int {
+		stdout, stderr = &bytes.Buffer{}, &bytes.Buffer{}
+		c := &cli{
+			stdin:  strings.NewReader(""),
+			stdout: stdout,
+			stderr: stderr,
+			getenv: func(key string) string { return env[key] },
+		}
+		return c.run(context.Background(), args)
+	}
+
+	Describe("list", func() {
+		BeforeEach(func() {
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return(tasks, nil)
+		})
+
+		It("prints the tasks as a table", func() {
+			Expect(run("list")).To(Equal(exitOK))
+			lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+			Expect(lines).To(HaveLen(4))
+			Expect(lines[0]).To(MatchRegexp(`^ID\s+STATUS\s+TITLE\s+CREATED$`))
+			Expect(lines[1]).To(MatchRegexp(`^1\s+todo\s+Write docs\s+`))
+			Expect(lines[3]).To(MatchRegexp(`^3\s+in progress\s+Fix login\s+`))
+		})
+
+		It("filters by status and text", func() {
+			Expect(run("list", "--status", "todo,in progress", "--search", "WRITE", "-o", "json")).To(Equal(exitOK))
+			var listed []models.Task
+			Expect(json.Unmarshal(stdout.Bytes(), &listed)).To(Succeed())
+			Expect(listed).To(Equal([]models.Task{tasks[0], tasks[2]}))
+		})
+
+		It("limits the tasks", func() {
+			Expect(run("list", "--limit", "1", "-o", "json")).To(Equal(exitOK))
+			var listed []models.Task
+			Expect(json.Unmarshal(stdout.Bytes(), &listed)).To(Succeed())
+			Expect(listed).To(Equal(tasks[:1]))
+		})
+
+		It("prints an empty JSON list when nothing matches", func() {
+			Expect(run("-o", "json", "list", "--status", "blocked")).To(Equal(exitOK))
+			Expect(strings.TrimSpace(stdout.String())).To(Equal("[]"))
+		})
+
+		It("prints the tasks as YAML", func() {
+			Expect(run("list", "--output", "yaml")).To(Equal(exitOK))
+			var listed []models.Task
+			Expect(yaml.Unmarshal(stdout.Bytes(), &listed)).To(Succeed())
+			Expect(listed).To(Equal(tasks))
+			Expect(stdout.String()).To(ContainSubstring("created_at: 2024-01-02T03:04:05Z"))
+		})
+	})
+
+	It("lists the pages of servers that page", func() {
+		mockPages := serviceMock.NewMockTaskPageReader(gomock.NewController(GinkgoT()))
+		paged := httptest.NewServer(utils.SetupRoutes(mockTasks, utils.WithTaskPages(mockPages)))
+		DeferCleanup(paged.Close)
+		env["TASKCTL_SERVER"] = paged.URL
+		mockPages.EXPECT().GetPage(gomock.Any(), "", 100).Return(tasks, nil)
+
+		Expect(run("list", "--limit", "2", "-o", "json")).To(Equal(exitOK))
+		var listed []models.Task
+		Expect(json.Unmarshal(stdout.Bytes(), &listed)).To(Succeed())
+		Expect(listed).To(Equal(tasks[:2]))
+	})
+
+	It("rejects unknown output formats", func() {
+		Expect(run("list", "-o", "xml")).To(Equal(exitUsage))
+		Expect(stderr.String()).To(ContainSubstring(`unknown output format "xml"`))
+	})
+
+	Describe("get", func() {
+		It("prints the task", func() {
+			mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(&tasks[0], nil)
+			Expect(run("get", "1")).To(Equal(exitOK))
+			Expect(stdout.String()).To(MatchRegexp(`Title:\s+Write docs`))
+			Expect(stdout.String()).To(MatchRegexp(`Description:\s+For the CLI`))
+		})
+
+		It("reports the error of the server", func() {
+			mockTasks.EXPECT().GetByID(gomock.Any(), "9").Return(nil, sql.ErrNoRows)
+			Expect(run("get", "9")).To(Equal(exitError))
+			Expect(stderr.String()).To(Equal("taskctl: server returned 404: Task not found\n"))
+		})
+
+		It("needs an ID", func() {
+			Expect(run("get")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("get takes 1 argument(s), got 0"))
+		})
+	})
+
+	Describe("create", func() {
+		It("creates the task", func() {
+			mockTasks.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *models.Task) error {
+				Expect(*task).To(Equal(models.Task{Title: "New task", Description: "Details", Status: "todo"}))
+				task.ID = "4"
+				task.CreatedAt = now
+				return nil
+			})
+			Expect(run("create", "--title", "New task", "--description", "Details", "--status", "todo", "-o", "json")).To(Equal(exitOK))
+			var created models.Task
+			Expect(json.Unmarshal(stdout.Bytes(), &created)).To(Succeed())
+			Expect(created.ID).To(Equal("4"))
+		})
+
+		It("needs a title", func() {
+			Expect(run("create", "--status", "todo")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("create needs a --title"))
+		})
+
+		It("creates recurring tasks", func() {
+			dueAt := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+			mockTasks.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *models.Task) error {
+				Expect(task.DueAt).To(Equal(&dueAt))
+				Expect(task.Recurrence).To(Equal("FREQ=WEEKLY;BYDAY=MO"))
+				Expect(task.Assignee).To(Equal("ci"))
+				task.ID, task.SeriesID, task.Occurrence = "4", "4", 1
+				return nil
+			})
+			Expect(run("create", "--title", "Standup", "--due", "2026-01-05T09:00:00Z", "--recurrence", "FREQ=WEEKLY;BYDAY=MO", "--assignee", "ci")).To(Equal(exitOK))
+			Expect(stdout.String()).To(ContainSubstring("Assignee:     ci"))
+			Expect(stdout.String()).To(ContainSubstring("Recurrence:   FREQ=WEEKLY;BYDAY=MO (occurrence 1)"))
+		})
+
+		It("needs a valid due time to recur", func() {
+			Expect(run("create", "--title", "Standup", "--due", "monday")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring(`invalid --due "monday"`))
+			Expect(run("create", "--title", "Standup", "--recurrence", "FREQ=DAILY")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("--recurrence needs a --due"))
+		})
+	})
+
+	It("deletes tasks", func() {
+		mockTasks.EXPECT().Delete(gomock.Any(), "2").Return(nil)
+		Expect(run("delete", "2")).To(Equal(exitOK))
+		Expect(stdout.String()).To(Equal("Deleted task 2\n"))
+	})
+
+	It("sets the status of tasks", func() {
+		task := tasks[0]
+		mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(&task, nil)
+		mockTasks.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, updated *models.Task) error {
+			Expect(updated.Status).To(Equal("done"))
+			Expect(updated.Title).To(Equal("Write docs"))
+			Expect(updated.Description).To(Equal("For the CLI"))
+			return nil
+		})
+		Expect(run("status", "1", "done")).To(Equal(exitOK))
+		Expect(stdout.String()).To(MatchRegexp(`Status:\s+done`))
+	})
+
+	Describe("edit", func() {
+		BeforeEach(func() {
+			task := tasks[0]
+			mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(&task, nil)
+		})
+
+		It("updates the task with the saved YAML", func() {
+			env["EDITOR"] = `sed -i -e 's/^status: .*/status: done/' -e 's/^id: .*/id: "7"/'`
+			mockTasks.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, updated *models.Task) error {
+				Expect(updated.ID).To(Equal("1"))
+				Expect(updated.Status).To(Equal("done"))
+				Expect(updated.Title).To(Equal("Write docs"))
+				Expect(updated.CreatedAt).To(BeTemporally("==", now))
+				return nil
+			})
+			Expect(run("edit", "1")).To(Equal(exitOK))
+		})
+
+		It("prefers $VISUAL", func() {
+			env["EDITOR"] = "false"
+			env["VISUAL"] = `sed -i -e 's/^title: .*/title: Write better docs/'`
+			mockTasks.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, updated *models.Task) error {
+				Expect(updated.Title).To(Equal("Write better docs"))
+				return nil
+			})
+			Expect(run("edit", "1")).To(Equal(exitOK))
+		})
+
+		It("cancels when the file is unchanged", func() {
+			env["EDITOR"] = "true"
+			Expect(run("edit", "1")).To(Equal(exitOK))
+			Expect(stderr.String()).To(ContainSubstring("Edit cancelled"))
+		})
+
+		It("reports invalid YAML", func() {
+			env["EDITOR"] = `sh -c 'echo "title: [" > "$1"' sh`
+			Expect(run("edit", "1")).To(Equal(exitError))
+			Expect(stderr.String()).To(ContainSubstring("invalid task YAML"))
+		})
+
+		It("reports editor failures", func() {
+			env["EDITOR"] = "false"
+			Expect(run("edit", "1")).To(Equal(exitError))
+			Expect(stderr.String()).To(ContainSubstring(`editor "false" failed`))
+		})
+	})
+
+	Describe("profiles", func() {
+		BeforeEach(func() {
+			delete(env, "TASKCTL_SERVER")
+		})
+
+		It("saves the profiles privately and uses the current one", func() {
+			Expect(run("config", "set", "staging", "--server", server.URL, "--token", "secret")).To(Equal(exitOK))
+			Expect(run("config", "set", "prod", "--server", "http://prod.invalid")).To(Equal(exitOK))
+
+			info, err := os.Stat(configPath)
+			Expect(err).To(Succeed())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(configFileMode)))
+
+			Expect(run("config", "list")).To(Equal(exitOK))
+			Expect(stdout.String()).To(MatchRegexp(`\*\s+staging\s+` + server.URL + `\s+set`))
+			Expect(stdout.String()).To(MatchRegexp(`\n\s+prod\s+http://prod.invalid\s*\n`))
+
+			mockKeys.EXPECT().Authenticate(gomock.Any(), "secret").Return(&models.APIKey{Name: "cli", Scopes: []string{models.ScopeTasksRead}}, nil)
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return(tasks, nil)
+			Expect(run("list")).To(Equal(exitOK))
+		})
+
+		It("switches between the profiles", func() {
+			Expect(run("config", "set", "staging", "--server", "http://staging.invalid")).To(Equal(exitOK))
+			Expect(run("config", "set", "local", "--server", server.URL)).To(Equal(exitOK))
+			Expect(run("config", "use", "local")).To(Equal(exitOK))
+
+			mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(&tasks[0], nil)
+			Expect(run("get", "1")).To(Equal(exitOK))
+
+			Expect(run("config", "delete", "local")).To(Equal(exitOK))
+			Expect(run("config", "list")).To(Equal(exitOK))
+			Expect(stdout.String()).NotTo(ContainSubstring("*"))
+		})
+
+		It("takes the profile and token from the flags", func() {
+			Expect(run("config", "set", "local", "--server", server.URL)).To(Equal(exitOK))
+			Expect(run("config", "set", "other", "--server", "http://other.invalid")).To(Equal(exitOK))
+
+			mockKeys.EXPECT().Authenticate(gomock.Any(), "read-only").Return(&models.APIKey{Name: "cli", Scopes: []string{models.ScopeTasksRead}}, nil)
+			Expect(run("delete", "1", "--token", "read-only")).To(Equal(exitError))
+			Expect(stderr.String()).To(ContainSubstring("server returned 403: Missing scope tasks:write"))
+
+			Expect(run("--profile", "other", "config", "use", "other")).To(Equal(exitOK))
+			Expect(run("--profile", "missing", "list")).To(Equal(exitError))
+			Expect(stderr.String()).To(ContainSubstring(`profile "missing" not found`))
+		})
+
+		It("needs a server for new profiles", func() {
+			env["TASKCTL_SERVER"] = server.URL
+			Expect(run("config", "set", "staging", "--token", "secret")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring(`new profile "staging" needs a --server`))
+		})
+	})
+
+	It("rejects unknown commands", func() {
+		Expect(run("frobnicate")).To(Equal(exitUsage))
+		Expect(stderr.String()).To(ContainSubstring(`unknown command "frobnicate"`))
+	})
+
+	It("prints the usage", func() {
+		Expect(run("help")).To(Equal(exitOK))
+		Expect(stdout.String()).To(HavePrefix("Usage: taskctl"))
+	})
+})
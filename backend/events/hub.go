@@ -0,0 +1,158 @@
+// Package events fans task changes out to in-process subscribers such as the
+// Server-Sent Events stream.
+package events
+
+import (
+	"github.com/saarzur123/task-management/backend/models"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultReplaySize       = 1000
+	DefaultSubscriberBuffer = 64
+)
+
+// Subscription receives the events published after it was created. C is
+// closed when the subscriber falls behind by more than its buffer or is
+// unsubscribed, so a slow consumer never blocks publishers.
+type Subscription struct {
+	events chan models.TaskEvent
+	C      <-chan models.TaskEvent
+	hub    *Hub
+	// Lagged is set when the subscription was dropped for being too slow.
+	Lagged bool
+}
+
+// Hub keeps the last published events in a bounded replay buffer so
+// subscribers can resume after a reconnect.
+type Hub struct {
+	subscribers      map[*Subscription]struct{}
+	now              func() time.Time
+	replay           []models.TaskEvent
+	nextID           uint64
+	replaySize       int
+	subscriberBuffer int
+	closed           bool
+	mu               sync.Mutex
+}
+
+func NewHub(replaySize, subscriberBuffer int) *Hub {
+	return &Hub{
+		subscribers:      make(map[*Subscription]struct{}),
+		now:              time.Now,
+		replay:           make([]models.TaskEvent, 0, replaySize),
+		nextID:           1,
+		replaySize:       replaySize,
+		subscriberBuffer: subscriberBuffer,
+	}
+}
+
+// StartIDsAt numbers the events published next from id. Starting from the
+// time the process started, in microseconds, keeps the IDs growing across
+// restarts, so Subscribe tells the IDs of an earlier process apart.
+func (h *Hub) StartIDsAt(id uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextID = id
+}
+
+// Publish assigns the event the next sequence ID, stores it for replay and
+// delivers it to every subscriber without blocking.
+func (h *Hub) Publish(event models.TaskEvent) models.TaskEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	event.ID = h.nextID
+	h.nextID++
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = h.now()
+	}
+
+	if len(h.replay) == h.replaySize && h.replaySize > 0 {
+		copy(h.replay, h.replay[1:])
+		h.replay = h.replay[:len(h.replay)-1]
+	}
+	if h.replaySize > 0 {
+		h.replay = append(h.replay, event)
+	}
+
+	for sub := range h.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			sub.Lagged = true
+			h.remove(sub)
+		}
+	}
+
+	return event
+}
+
+// Subscribe registers a new subscriber. When afterID is not zero, the events
+// published after it that are still buffered are returned for replay; complete
+// is false when some of them were already evicted from the buffer, or when
+// afterID wasn't assigned by this hub, e.g. before a restart.
+func (h *Hub) Subscribe(afterID uint64) (sub *Subscription, missed []models.TaskEvent, complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	events := make(chan models.TaskEvent, h.subscriberBuffer)
+	sub = &Subscription{events: events, C: events, hub: h}
+	if h.closed {
+		close(events)
+	} else {
+		h.subscribers[sub] = struct{}{}
+	}
+
+	complete = true
+	if afterID == 0 {
+		return sub, nil, complete
+	}
+
+	missed = make([]models.TaskEvent, 0)
+	for _, event := range h.replay {
+		if event.ID > afterID {
+			missed = append(missed, event)
+		}
+	}
+	oldest := h.nextID
+	if len(h.replay) > 0 {
+		oldest = h.replay[0].ID
+	}
+	complete = afterID < h.nextID && afterID+1 >= oldest
+	return sub, missed, complete
+}
+
+// Unsubscribe stops delivering events to sub and closes its channel.
+func (s *Subscription) Unsubscribe() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subscribers[sub]; !ok {
+		return
+	}
+	delete(h.subscribers, sub)
+	close(sub.events)
+}
+
+// Close ends every subscription so long-lived streams finish during a
+// graceful shutdown. Later subscriptions are closed right away.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subscribers {
+		h.remove(sub)
+	}
+}
+
+// SubscriberCount returns the number of connected subscribers.
+func (h *Hub) SubscriberCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
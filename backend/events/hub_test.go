@@ -0,0 +1,115 @@
+package events
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Events Suite")
+}
+
+func publish(hub *Hub, count int) {
+	for i := 0; i < count; i++ {
+		hub.Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: "1"})
+	}
+}
+
+var _ = Describe("Hub", func() {
+	var hub *Hub
+
+	BeforeEach(func() {
+		hub = NewHub(3, 2)
+	})
+
+	It("assigns increasing IDs and delivers events to subscribers", func() {
+		sub, missed, complete := hub.Subscribe(0)
+		Expect(missed).To(BeEmpty())
+		Expect(complete).To(BeTrue())
+
+		first := hub.Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: "1"})
+		second := hub.Publish(models.TaskEvent{Type: models.EventTaskDeleted, TaskID: "1"})
+		Expect(first.ID).To(Equal(uint64(1)))
+		Expect(second.ID).To(Equal(uint64(2)))
+		Expect(first.OccurredAt).NotTo(BeZero())
+
+		Expect(<-sub.C).To(Equal(first))
+		Expect(<-sub.C).To(Equal(second))
+	})
+
+	It("replays the buffered events after the given ID", func() {
+		publish(hub, 3)
+
+		_, missed, complete := hub.Subscribe(1)
+		Expect(complete).To(BeTrue())
+		Expect(missed).To(HaveLen(2))
+		Expect(missed[0].ID).To(Equal(uint64(2)))
+	})
+
+	It("reports an incomplete replay once events were evicted", func() {
+		publish(hub, 5)
+
+		_, missed, complete := hub.Subscribe(1)
+		Expect(complete).To(BeFalse())
+		Expect(missed).To(HaveLen(3))
+		Expect(missed[0].ID).To(Equal(uint64(3)))
+	})
+
+	It("reports an incomplete replay after an ID the hub didn't assign", func() {
+		publish(hub, 2)
+
+		_, missed, complete := hub.Subscribe(5)
+		Expect(complete).To(BeFalse())
+		Expect(missed).To(BeEmpty())
+	})
+
+	It("reports an incomplete replay after an ID of an earlier process", func() {
+		hub.StartIDsAt(100)
+		event := hub.Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: "1"})
+		Expect(event.ID).To(Equal(uint64(100)))
+
+		_, missed, complete := hub.Subscribe(7)
+		Expect(complete).To(BeFalse())
+		Expect(missed).To(HaveLen(1))
+	})
+
+	It("drops subscribers that fall behind without blocking", func() {
+		slow, _, _ := hub.Subscribe(0)
+		fast, _, _ := hub.Subscribe(0)
+
+		publish(hub, 2)
+		<-fast.C
+		<-fast.C
+		publish(hub, 1)
+
+		Expect(slow.Lagged).To(BeTrue())
+		Expect(fast.Lagged).To(BeFalse())
+		Expect(hub.SubscriberCount()).To(Equal(1))
+		Eventually(slow.C).Should(Receive())
+		Eventually(slow.C).Should(Receive())
+		Eventually(slow.C).Should(BeClosed())
+	})
+
+	It("closes the channel on unsubscribe", func() {
+		sub, _, _ := hub.Subscribe(0)
+		sub.Unsubscribe()
+		sub.Unsubscribe()
+
+		Expect(sub.C).To(BeClosed())
+		Expect(hub.SubscriberCount()).To(BeZero())
+	})
+
+	It("closes every subscription", func() {
+		sub, _, _ := hub.Subscribe(0)
+		hub.Close()
+		late, _, _ := hub.Subscribe(0)
+
+		Expect(sub.C).To(BeClosed())
+		Expect(late.C).To(BeClosed())
+		Expect(hub.SubscriberCount()).To(BeZero())
+		late.Unsubscribe()
+	})
+})
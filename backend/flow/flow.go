@@ -0,0 +1,223 @@
+// Package flow replays the outbox events of the tasks into their history and
+// computes the flow reports from it: the burndown of a sprint, the
+// cumulative flow of the statuses and the percentiles of the time tasks take
+// to move through them. Days are UTC days, a day's value being the state at
+// its end.
+package flow
+
+import (
+	"github.com/saarzur123/task-management/backend/models"
+	"math"
+	"slices"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// State is what a task looks like from At until its next state.
+type State struct {
+	At       time.Time
+	Status   string
+	Points   int
+	SprintID string
+	Deleted  bool
+}
+
+// History is the states of a task, oldest first. The first state stands for
+// the creation of the task.
+type History struct {
+	TaskID string
+	States []State
+}
+
+// Histories replays events, oldest first, into the history of each task, in
+// the order the tasks first appear. Events that don't change the status,
+// points or sprint of a task add no state.
+func Histories(events []models.TaskEvent) []History {
+	histories := make([]History, 0)
+	index := map[string]int{}
+	for _, event := range events {
+		i, ok := index[event.TaskID]
+		var last *State
+		if ok {
+			states := histories[i].States
+			last = &states[len(states)-1]
+			if last.Deleted {
+				continue
+			}
+		}
+
+		var state State
+		switch {
+		case event.Type == models.EventTaskDeleted:
+			if last == nil {
+				continue
+			}
+			state = *last
+			state.Deleted = true
+		case event.Task != nil:
+			state = State{Status: event.Task.Status, Points: event.Task.StoryPoints, SprintID: event.Task.SprintID}
+		default:
+			continue
+		}
+		state.At = event.OccurredAt.UTC()
+
+		if !ok {
+			index[event.TaskID] = len(histories)
+			histories = append(histories, History{TaskID: event.TaskID, States: []State{state}})
+			continue
+		}
+		if last.Status == state.Status && last.Points == state.Points && last.SprintID == state.SprintID && !state.Deleted {
+			continue
+		}
+		histories[i].States = append(histories[i].States, state)
+	}
+	return histories
+}
+
+// Before returns the state of the task just before t, false when it didn't
+// exist yet or was deleted.
+func (h History) Before(t time.Time) (State, bool) {
+	i, _ := slices.BinarySearchFunc(h.States, t, func(state State, t time.Time) int {
+		return state.At.Compare(t)
+	})
+	if i == 0 || h.States[i-1].Deleted {
+		return State{}, false
+	}
+	return h.States[i-1], true
+}
+
+// Burndown returns the days of sprint up to the day of now, or an empty
+// slice when it hasn't started. A task is remaining until its status is
+// done.
+func Burndown(histories []History, sprint models.Sprint, now time.Time) ([]models.BurndownDay, error) {
+	start, err := time.Parse(time.DateOnly, sprint.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse(time.DateOnly, sprint.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	length := int(end.Sub(start)/day) + 1
+	today := now.UTC().Truncate(day)
+
+	days := make([]models.BurndownDay, 0, length)
+	var planned int
+	for i := 0; i < length; i++ {
+		date := start.AddDate(0, 0, i)
+		if date.After(today) {
+			break
+		}
+		burndownDay := models.BurndownDay{Day: date.Format(time.DateOnly)}
+		for _, history := range histories {
+			state, ok := history.Before(date.Add(day))
+			if !ok || state.SprintID != sprint.ID {
+				continue
+			}
+			burndownDay.Committed += state.Points
+			if state.Status != models.StatusDone {
+				burndownDay.Remaining += state.Points
+				burndownDay.RemainingTasks++
+			}
+		}
+		if i == 0 {
+			planned = burndownDay.Committed
+		}
+		if length > 1 {
+			ideal := float64(planned) * float64(length-1-i) / float64(length-1)
+			burndownDay.Ideal = math.Round(ideal*100) / 100
+		}
+		days = append(days, burndownDay)
+	}
+	return days, nil
+}
+
+// CumulativeFlow returns the statuses of the tasks from the day of from to
+// that of to, and the tasks counted per status each day.
+func CumulativeFlow(histories []History, from, to time.Time) ([]string, []models.FlowDay) {
+	statuses := make([]string, 0)
+	days := make([]models.FlowDay, 0)
+	for date := from.UTC().Truncate(day); !date.After(to.UTC()); date = date.Add(day) {
+		flowDay := models.FlowDay{Day: date.Format(time.DateOnly), Counts: map[string]int{}}
+		for _, history := range histories {
+			if state, ok := history.Before(date.Add(day)); ok {
+				flowDay.Counts[state.Status]++
+				if !slices.Contains(statuses, state.Status) {
+					statuses = append(statuses, state.Status)
+				}
+			}
+		}
+		days = append(days, flowDay)
+	}
+
+	slices.SortFunc(statuses, models.CompareStatuses)
+	for _, flowDay := range days {
+		for _, status := range statuses {
+			if _, ok := flowDay.Counts[status]; !ok {
+				flowDay.Counts[status] = 0
+			}
+		}
+	}
+	return statuses, days
+}
+
+// Times measures the status changes made in [from, to). A task done,
+// reopened and done again counts twice.
+func Times(histories []History, from, to time.Time) models.FlowTimes {
+	var lead, cycle []time.Duration
+	type transition struct{ from, to string }
+	transitions := map[transition][]time.Duration{}
+
+	for _, history := range histories {
+		created := history.States[0]
+		entered, status := created.At, created.Status
+		var started time.Time
+		for _, state := range history.States[1:] {
+			if state.Deleted {
+				break
+			}
+			if state.Status == status {
+				continue
+			}
+			if started.IsZero() {
+				started = state.At
+			}
+			if !state.At.Before(from) && state.At.Before(to) {
+				key := transition{status, state.Status}
+				transitions[key] = append(transitions[key], state.At.Sub(entered))
+				if state.Status == models.StatusDone {
+					lead = append(lead, state.At.Sub(created.At))
+					cycle = append(cycle, state.At.Sub(started))
+				}
+			}
+			entered, status = state.At, state.Status
+		}
+	}
+
+	times := models.FlowTimes{LeadTime: percentiles(lead), CycleTime: percentiles(cycle), Transitions: make([]models.TransitionTimes, 0, len(transitions))}
+	for key, durations := range transitions {
+		times.Transitions = append(times.Transitions, models.TransitionTimes{From: key.from, To: key.to, Percentiles: percentiles(durations)})
+	}
+	slices.SortFunc(times.Transitions, func(a, b models.TransitionTimes) int {
+		if c := models.CompareStatuses(a.From, b.From); c != 0 {
+			return c
+		}
+		return models.CompareStatuses(a.To, b.To)
+	})
+	return times
+}
+
+// percentiles uses the nearest rank method: the pth percentile is the
+// smallest duration at least p percent of the durations don't exceed.
+func percentiles(durations []time.Duration) models.Percentiles {
+	if len(durations) == 0 {
+		return models.Percentiles{}
+	}
+	slices.Sort(durations)
+	rank := func(p int) int64 {
+		i := (p*len(durations)+99)/100 - 1
+		return int64(durations[i] / time.Second)
+	}
+	return models.Percentiles{Count: len(durations), P50: rank(50), P85: rank(85), P95: rank(95)}
+}
@@ -0,0 +1,253 @@
+package flow
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"testing"
+	"time"
+)
+
+func TestFlow(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Flow Suite")
+}
+
+// at returns the time of the day of January 2026 and hour given.
+func at(day, hour int) time.Time {
+	return time.Date(2026, time.January, day, hour, 0, 0, 0, time.UTC)
+}
+
+// event returns an event of task id leaving it with status, points and
+// sprint, an empty status standing for its deletion.
+func event(eventType, id string, occurred time.Time, status string, points int, sprint string) models.TaskEvent {
+	e := models.TaskEvent{Type: eventType, TaskID: id, OccurredAt: occurred}
+	if eventType != models.EventTaskDeleted {
+		e.Task = &models.Task{ID: id, Status: status, StoryPoints: points, SprintID: sprint}
+	}
+	return e
+}
+
+func created(id string, occurred time.Time, status string, points int, sprint string) models.TaskEvent {
+	return event(models.EventTaskCreated, id, occurred, status, points, sprint)
+}
+
+func updated(id string, occurred time.Time, status string, points int, sprint string) models.TaskEvent {
+	return event(models.EventTaskUpdated, id, occurred, status, points, sprint)
+}
+
+var _ = Describe("flow", func() {
+	Describe("Histories", func() {
+		It("keeps the states changing the status, points or sprint of each task", func() {
+			histories := Histories([]models.TaskEvent{
+				created("1", at(5, 9), "pending", 3, ""),
+				created("2", at(5, 10), "pending", 0, ""),
+				updated("1", at(5, 11), "pending", 3, ""),
+				updated("1", at(6, 9), "in_progress", 3, ""),
+				{Type: models.EventTaskStatusChanged, TaskID: "1", OccurredAt: at(6, 9), PreviousStatus: "pending",
+					Task: &models.Task{ID: "1", Status: "in_progress", StoryPoints: 3}},
+				updated("1", at(7, 9), "in_progress", 5, "1"),
+				event(models.EventTaskDeleted, "2", at(7, 10), "", 0, ""),
+				updated("2", at(7, 11), "done", 0, ""),
+			})
+
+			Expect(histories).To(Equal([]History{
+				{TaskID: "1", States: []State{
+					{At: at(5, 9), Status: "pending", Points: 3},
+					{At: at(6, 9), Status: "in_progress", Points: 3},
+					{At: at(7, 9), Status: "in_progress", Points: 5, SprintID: "1"},
+				}},
+				{TaskID: "2", States: []State{
+					{At: at(5, 10), Status: "pending"},
+					{At: at(7, 10), Status: "pending", Deleted: true},
+				}},
+			}))
+		})
+
+		It("starts the history of a task created before the outbox at its first event", func() {
+			histories := Histories([]models.TaskEvent{
+				event(models.EventTaskDeleted, "1", at(5, 9), "", 0, ""),
+				updated("2", at(5, 10), "in_progress", 2, ""),
+			})
+
+			Expect(histories).To(Equal([]History{
+				{TaskID: "2", States: []State{{At: at(5, 10), Status: "in_progress", Points: 2}}},
+			}))
+		})
+	})
+
+	Describe("Before", func() {
+		history := History{TaskID: "1", States: []State{
+			{At: at(5, 9), Status: "pending"},
+			{At: at(6, 9), Status: "done"},
+			{At: at(7, 9), Status: "done", Deleted: true},
+		}}
+
+		DescribeTable("returns the state just before the time",
+			func(t time.Time, status string, exists bool) {
+				state, ok := history.Before(t)
+				Expect(ok).To(Equal(exists))
+				Expect(state.Status).To(Equal(status))
+			},
+			Entry("before the creation", at(5, 8), "", false),
+			Entry("at the creation", at(5, 9), "", false),
+			Entry("after the creation", at(5, 10), "pending", true),
+			Entry("at a change", at(6, 9), "pending", true),
+			Entry("after a change", at(6, 10), "done", true),
+			Entry("after the deletion", at(8, 0), "", false),
+		)
+	})
+
+	Describe("Burndown", func() {
+		sprint := models.Sprint{ID: "1", StartDate: "2026-01-05", EndDate: "2026-01-09"}
+		histories := Histories([]models.TaskEvent{
+			created("1", at(4, 9), "pending", 5, "1"),
+			created("2", at(4, 10), "pending", 3, "1"),
+			created("3", at(5, 9), "pending", 2, ""),
+			updated("3", at(5, 12), "pending", 2, "1"),
+			updated("1", at(6, 15), "done", 5, "1"),
+			// Task 4 joins the sprint on its third day and leaves it the day after.
+			created("4", at(7, 9), "pending", 8, "1"),
+			updated("4", at(8, 9), "pending", 8, ""),
+			updated("2", at(8, 17), "done", 3, "1"),
+			// Task 3 is reopened before the end of the day it was done.
+			updated("3", at(9, 10), "done", 2, "1"),
+			updated("3", at(9, 16), "in_progress", 2, "1"),
+			// Tasks of other sprints don't count.
+			created("5", at(5, 9), "pending", 13, "2"),
+		})
+
+		It("follows the points left each day, the ideal burning the first day's", func() {
+			days, err := Burndown(histories, sprint, at(20, 0))
+			Expect(err).To(Succeed())
+			Expect(days).To(Equal([]models.BurndownDay{
+				{Day: "2026-01-05", Committed: 10, Remaining: 10, RemainingTasks: 3, Ideal: 10},
+				{Day: "2026-01-06", Committed: 10, Remaining: 5, RemainingTasks: 2, Ideal: 7.5},
+				{Day: "2026-01-07", Committed: 18, Remaining: 13, RemainingTasks: 3, Ideal: 5},
+				{Day: "2026-01-08", Committed: 10, Remaining: 2, RemainingTasks: 1, Ideal: 2.5},
+				{Day: "2026-01-09", Committed: 10, Remaining: 2, RemainingTasks: 1, Ideal: 0},
+			}))
+		})
+
+		It("stops at today", func() {
+			days, err := Burndown(histories, sprint, at(6, 12))
+			Expect(err).To(Succeed())
+			Expect(days).To(HaveLen(2))
+			Expect(days[1].Day).To(Equal("2026-01-06"))
+			Expect(days[1].Ideal).To(Equal(7.5))
+		})
+
+		It("is empty before the sprint starts", func() {
+			days, err := Burndown(histories, sprint, at(4, 23))
+			Expect(err).To(Succeed())
+			Expect(days).To(BeEmpty())
+		})
+
+		It("rounds the ideal and ends a sprint of one day at zero", func() {
+			days, err := Burndown(histories, models.Sprint{ID: "1", StartDate: "2026-01-05", EndDate: "2026-01-07"}, at(20, 0))
+			Expect(err).To(Succeed())
+			Expect(days[1].Ideal).To(Equal(5.0))
+
+			days, err = Burndown(histories, models.Sprint{ID: "1", StartDate: "2026-01-05", EndDate: "2026-01-05"}, at(20, 0))
+			Expect(err).To(Succeed())
+			Expect(days).To(Equal([]models.BurndownDay{{Day: "2026-01-05", Committed: 10, Remaining: 10, RemainingTasks: 3}}))
+
+			days, err = Burndown([]History{{TaskID: "1", States: []State{{At: at(4, 0), Status: "pending", Points: 10, SprintID: "1"}}}},
+				models.Sprint{ID: "1", StartDate: "2026-01-05", EndDate: "2026-01-08"}, at(20, 0))
+			Expect(err).To(Succeed())
+			Expect(days[1].Ideal).To(Equal(6.67))
+		})
+
+		It("refuses invalid sprint dates", func() {
+			_, err := Burndown(histories, models.Sprint{ID: "1", StartDate: "05/01/2026", EndDate: "2026-01-09"}, at(20, 0))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("CumulativeFlow", func() {
+		It("counts the tasks per status at the end of each day", func() {
+			histories := Histories([]models.TaskEvent{
+				created("1", at(4, 9), "pending", 0, ""),
+				created("2", at(5, 9), "pending", 0, ""),
+				updated("1", at(5, 12), "review", 0, ""),
+				updated("2", at(6, 9), "in_progress", 0, ""),
+				updated("1", at(6, 10), "done", 0, ""),
+				created("3", at(6, 11), "pending", 0, ""),
+				event(models.EventTaskDeleted, "3", at(7, 9), "", 0, ""),
+			})
+
+			statuses, days := CumulativeFlow(histories, at(4, 0), at(7, 0))
+			Expect(statuses).To(Equal([]string{"pending", "in_progress", "review", "done"}))
+			Expect(days).To(Equal([]models.FlowDay{
+				{Day: "2026-01-04", Counts: map[string]int{"pending": 1, "in_progress": 0, "review": 0, "done": 0}},
+				{Day: "2026-01-05", Counts: map[string]int{"pending": 1, "in_progress": 0, "review": 1, "done": 0}},
+				{Day: "2026-01-06", Counts: map[string]int{"pending": 1, "in_progress": 1, "review": 0, "done": 1}},
+				{Day: "2026-01-07", Counts: map[string]int{"pending": 0, "in_progress": 1, "review": 0, "done": 1}},
+			}))
+		})
+
+		It("has a day without counts when there are no tasks", func() {
+			statuses, days := CumulativeFlow(nil, at(4, 0), at(4, 12))
+			Expect(statuses).To(BeEmpty())
+			Expect(days).To(Equal([]models.FlowDay{{Day: "2026-01-04", Counts: map[string]int{}}}))
+		})
+	})
+
+	Describe("Times", func() {
+		// Each of the tasks waits i days, works i hours and is done.
+		var events []models.TaskEvent
+		for i := 1; i <= 20; i++ {
+			id := string(rune('A' + i))
+			created := at(1, 0)
+			events = append(events,
+				event(models.EventTaskCreated, id, created, "pending", 0, ""),
+				updated(id, created.AddDate(0, 0, i), "in_progress", 0, ""),
+				updated(id, created.AddDate(0, 0, i).Add(time.Duration(i)*time.Hour), "done", 0, ""))
+		}
+		histories := Histories(events)
+
+		It("returns the percentiles of the lead, cycle and transition times", func() {
+			times := Times(histories, at(1, 0), at(31, 0))
+
+			hour, hours := int64(3600), func(n int64) int64 { return n * 3600 }
+			Expect(times.LeadTime).To(Equal(models.Percentiles{Count: 20, P50: hours(10*24 + 10), P85: hours(17*24 + 17), P95: hours(19*24 + 19)}))
+			Expect(times.CycleTime).To(Equal(models.Percentiles{Count: 20, P50: 10 * hour, P85: 17 * hour, P95: 19 * hour}))
+			Expect(times.Transitions).To(Equal([]models.TransitionTimes{
+				{From: "pending", To: "in_progress", Percentiles: models.Percentiles{Count: 20, P50: hours(10 * 24), P85: hours(17 * 24), P95: hours(19 * 24)}},
+				{From: "in_progress", To: "done", Percentiles: models.Percentiles{Count: 20, P50: 10 * hour, P85: 17 * hour, P95: 19 * hour}},
+			}))
+		})
+
+		It("only measures the changes made in the range", func() {
+			// The first three tasks start by the 4th, the first two being done
+			// before the 4th at 3:00.
+			times := Times(histories, at(2, 0), at(4, 3))
+			Expect(times.LeadTime).To(Equal(models.Percentiles{Count: 2, P50: 3600 * (24 + 1), P85: 3600 * (48 + 2), P95: 3600 * (48 + 2)}))
+			Expect(times.Transitions[0].Count).To(Equal(3))
+			Expect(times.Transitions[1].Count).To(Equal(2))
+		})
+
+		It("counts each time a task is done and stops at its deletion", func() {
+			times := Times(Histories([]models.TaskEvent{
+				created("1", at(1, 0), "pending", 0, ""),
+				updated("1", at(1, 1), "done", 0, ""),
+				updated("1", at(1, 3), "in_progress", 0, ""),
+				updated("1", at(1, 6), "done", 0, ""),
+				event(models.EventTaskDeleted, "1", at(1, 7), "", 0, ""),
+				created("2", at(1, 0), "done", 0, ""),
+			}), at(1, 0), at(2, 0))
+
+			Expect(times.LeadTime).To(Equal(models.Percentiles{Count: 2, P50: 3600, P85: 6 * 3600, P95: 6 * 3600}))
+			Expect(times.CycleTime).To(Equal(models.Percentiles{Count: 2, P50: 0, P85: 5 * 3600, P95: 5 * 3600}))
+			Expect(times.Transitions).To(Equal([]models.TransitionTimes{
+				{From: "pending", To: "done", Percentiles: models.Percentiles{Count: 1, P50: 3600, P85: 3600, P95: 3600}},
+				{From: "in_progress", To: "done", Percentiles: models.Percentiles{Count: 1, P50: 3 * 3600, P85: 3 * 3600, P95: 3 * 3600}},
+				{From: "done", To: "in_progress", Percentiles: models.Percentiles{Count: 1, P50: 2 * 3600, P85: 2 * 3600, P95: 2 * 3600}},
+			}))
+		})
+
+		It("is empty without status changes", func() {
+			Expect(Times(nil, at(1, 0), at(2, 0))).To(Equal(models.FlowTimes{Transitions: []models.TransitionTimes{}}))
+		})
+	})
+})
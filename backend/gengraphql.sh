@@ -0,0 +1,5 @@
+set -e
+
+# Generates the GraphQL executor and resolver stubs of graph from
+# graph/schema.graphqls. Keep the version in sync with go.mod.
+go run github.com/99designs/gqlgen@v0.17.57 generate
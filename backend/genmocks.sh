@@ -4,4 +4,60 @@ set -e
 
 mockgen -package serviceMock \
 -destination mocks/serviceMock/mocks.go \
--source service/service.go
\ No newline at end of file
+-source service/service.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/apikey_mocks.go \
+-source service/apikey.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/audit_mocks.go \
+-source service/audit.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/migrations_mocks.go \
+-source service/migrations.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/events_mocks.go \
+-source service/events.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/outbox_mocks.go \
+-source service/outbox.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/webhook_mocks.go \
+-source service/webhook.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/recurrence_mocks.go \
+-source service/recurrence.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/notification_mocks.go \
+-source service/notification.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/watcher_mocks.go \
+-source service/watcher.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/board_mocks.go \
+-source service/board.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/worklog_mocks.go \
+-source service/worklog.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/sprint_mocks.go \
+-source service/sprint.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/flow_mocks.go \
+-source service/flow.go
+
+mockgen -package serviceMock \
+-destination mocks/serviceMock/stats_mocks.go \
+-source service/stats.go
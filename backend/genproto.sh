@@ -0,0 +1,5 @@
+set -e
+
+# Generates taskspb from taskspb/tasks.proto. Requires buf, protoc-gen-go and
+# protoc-gen-go-grpc in PATH.
+buf generate
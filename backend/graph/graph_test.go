@@ -0,0 +1,302 @@
+package graph
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/events"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGraph(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GraphQL Suite")
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+var _ = Describe("GraphQL handler", func() {
+	var (
+		mockTasks   *serviceMock.MockTaskRepository
+		mockBatch   *serviceMock.MockTaskBatchReader
+		mockHistory *serviceMock.MockTaskHistoryReader
+		mockOutbox  *serviceMock.MockOutboxReader
+		hub         *events.Hub
+		apiKey      *models.APIKey
+		server      *httptest.Server
+		now         = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockTasks = serviceMock.NewMockTaskRepository(mockCtrl)
+		mockBatch = serviceMock.NewMockTaskBatchReader(mockCtrl)
+		mockHistory = serviceMock.NewMockTaskHistoryReader(mockCtrl)
+		mockOutbox = serviceMock.NewMockOutboxReader(mockCtrl)
+		hub = events.NewHub(2, 4)
+		apiKey = nil
+
+		graphql := NewHandler(&Resolver{Tasks: mockTasks, TaskBatch: mockBatch, History: mockHistory, Outbox: mockOutbox, Events: hub},
+			func(*http.Request) bool { return true })
+		// Stands in for the API key middleware.
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if apiKey != nil {
+				r = r.WithContext(service.ContextWithAPIKey(r.Context(), apiKey))
+			}
+			graphql.ServeHTTP(w, r)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	post := func(query string) (int, graphQLResponse) {
+		body, err := json.Marshal(map[string]string{"query": query})
+		Expect(err).To(Succeed())
+		response, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+		Expect(err).To(Succeed())
+		defer response.Body.Close()
+
+		var result graphQLResponse
+		Expect(json.NewDecoder(response.Body).Decode(&result)).To(Succeed())
+		return response.StatusCode, result
+	}
+
+	Describe("queries", func() {
+		It("lists the tasks with the given status", func() {
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{
+				{ID: "1", Title: "Write tests", Status: "done", CreatedAt: now},
+				{ID: "2", Title: "Ship", Status: "pending", CreatedAt: now},
+			}, nil)
+
+			status, response := post(`{ tasks(status: "pending") { id title createdAt } }`)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(response.Errors).To(BeEmpty())
+			Expect(response.Data).To(MatchJSON(`{"tasks": [{"id": "2", "title": "Ship", "createdAt": "2024-01-02T03:04:05Z"}]}`))
+		})
+
+		It("batches the tasks looked up in one response", func() {
+			mockBatch.EXPECT().GetByIDs(gomock.Any(), gomock.InAnyOrder([]string{"1", "2"})).Return([]models.Task{{ID: "1", Title: "Write tests"}}, nil)
+
+			_, response := post(`{ a: task(id: "1") { title } b: task(id: "2") { title } }`)
+			Expect(response.Errors).To(BeEmpty())
+			Expect(response.Data).To(MatchJSON(`{"a": {"title": "Write tests"}, "b": null}`))
+		})
+
+		It("loads the history of every listed task in one query", func() {
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{{ID: "1"}, {ID: "2"}, {ID: "3"}}, nil)
+			mockHistory.EXPECT().History(gomock.Any(), gomock.InAnyOrder([]string{"1", "2", "3"}), historyLimit).Return([]models.TaskEvent{
+				{ID: 1, Type: models.EventTaskCreated, TaskID: "1", OccurredAt: now},
+				{ID: 2, Type: models.EventTaskCreated, TaskID: "2", OccurredAt: now},
+				{ID: 3, Type: models.EventTaskStatusChanged, TaskID: "1", PreviousStatus: "pending", OccurredAt: now},
+			}, nil)
+
+			_, response := post(`{ tasks { id history { id type previousStatus } } }`)
+			Expect(response.Errors).To(BeEmpty())
+			Expect(response.Data).To(MatchJSON(`{"tasks": [
+				{"id": "1", "history": [{"id": "1", "type": "CREATED", "previousStatus": null}, {"id": "3", "type": "STATUS_CHANGED", "previousStatus": "pending"}]},
+				{"id": "2", "history": [{"id": "2", "type": "CREATED", "previousStatus": null}]},
+				{"id": "3", "history": []}
+			]}`))
+		})
+
+		It("resolves the current task of the events in one query", func() {
+			snapshot := models.Task{ID: "1", Title: "Old title"}
+			mockOutbox.EXPECT().After(gomock.Any(), uint64(5), 2).Return([]models.TaskEvent{
+				{ID: 6, Type: models.EventTaskUpdated, TaskID: "1", Task: &snapshot, OccurredAt: now},
+				{ID: 7, Type: models.EventTaskDeleted, TaskID: "2", OccurredAt: now},
+			}, nil)
+			mockBatch.EXPECT().GetByIDs(gomock.Any(), gomock.InAnyOrder([]string{"1", "2"})).Return([]models.Task{{ID: "1", Title: "New title"}}, nil)
+
+			_, response := post(`{ events(after: "5", limit: 2) { id snapshot { title } task { title } } }`)
+			Expect(response.Errors).To(BeEmpty())
+			Expect(response.Data).To(MatchJSON(`{"events": [
+				{"id": "6", "snapshot": {"title": "Old title"}, "task": {"title": "New title"}},
+				{"id": "7", "snapshot": null, "task": null}
+			]}`))
+		})
+
+		It("rejects an invalid events limit", func() {
+			_, response := post(`{ events(limit: 0) { id } }`)
+			Expect(response.Errors).To(HaveLen(1))
+			Expect(response.Errors[0].Message).To(ContainSubstring("limit must be between 1 and 1000"))
+		})
+
+		It("answers the introspection query of the tools", func() {
+			status, response := post(introspectionQuery)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(response.Errors).To(BeEmpty())
+		})
+	})
+
+	Describe("limits", func() {
+		It("rejects operations nested too deep", func() {
+			status, response := post(`{ tasks { history { task { history { task { history { task { history { id } } } } } } } } }`)
+			Expect(status).To(Equal(http.StatusUnprocessableEntity))
+			Expect(response.Errors[0].Message).To(Equal("operation has depth 9, which exceeds the limit of 8"))
+		})
+
+		It("rejects operations that are too complex", func() {
+			status, response := post(`{ tasks { history { task { history { id } } } } }`)
+			Expect(status).To(Equal(http.StatusUnprocessableEntity))
+			Expect(response.Errors[0].Message).To(ContainSubstring("exceeds the limit of 2000"))
+		})
+	})
+
+	Describe("mutations", func() {
+		It("creates a task", func() {
+			mockTasks.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *models.Task) error {
+				Expect(task.Title).To(Equal("Write tests"))
+				Expect(task.Description).To(BeEmpty())
+				task.ID = "1"
+				return nil
+			})
+
+			_, response := post(`mutation { createTask(input: {title: "Write tests", status: "pending"}) { id status } }`)
+			Expect(response.Errors).To(BeEmpty())
+			Expect(response.Data).To(MatchJSON(`{"createTask": {"id": "1", "status": "pending"}}`))
+		})
+
+		It("updates a task, keeping its schedule", func() {
+			dueAt := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+			mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(&models.Task{ID: "1", DueAt: &dueAt, Recurrence: "FREQ=DAILY"}, nil)
+			mockTasks.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *models.Task) error {
+				Expect(task.Title).To(Equal("Write tests"))
+				Expect(task.DueAt).To(Equal(&dueAt))
+				Expect(task.Recurrence).To(Equal("FREQ=DAILY"))
+				return nil
+			})
+
+			_, response := post(`mutation { updateTask(id: "1", input: {title: "Write tests"}) { id title } }`)
+			Expect(response.Errors).To(BeEmpty())
+			Expect(response.Data).To(MatchJSON(`{"updateTask": {"id": "1", "title": "Write tests"}}`))
+		})
+
+		It("reports updating a missing task", func() {
+			mockTasks.EXPECT().GetByID(gomock.Any(), "9").Return(nil, sql.ErrNoRows)
+
+			_, response := post(`mutation { updateTask(id: "9", input: {title: "Write tests"}) { id } }`)
+			Expect(response.Errors).To(HaveLen(1))
+			Expect(response.Errors[0].Message).To(Equal("task not found"))
+		})
+
+		It("deletes a task", func() {
+			mockTasks.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+
+			_, response := post(`mutation { deleteTask(id: "1") }`)
+			Expect(response.Errors).To(BeEmpty())
+			Expect(response.Data).To(MatchJSON(`{"deleteTask": "1"}`))
+		})
+
+		It("needs tasks:write when called with an API key", func() {
+			apiKey = &models.APIKey{Name: "ci", Scopes: []string{models.ScopeTasksRead}}
+
+			_, response := post(`mutation { deleteTask(id: "1") }`)
+			Expect(response.Errors).To(HaveLen(1))
+			Expect(response.Errors[0].Message).To(Equal("missing scope tasks:write"))
+		})
+
+		It("lets tasks:read keys query", func() {
+			apiKey = &models.APIKey{Name: "ci", Scopes: []string{models.ScopeTasksRead}}
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{}, nil)
+
+			_, response := post(`{ tasks { id } }`)
+			Expect(response.Errors).To(BeEmpty())
+		})
+	})
+
+	Describe("subscriptions", func() {
+		It("streams the task changes as Server-Sent Events", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			DeferCleanup(cancel)
+			request, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL,
+				strings.NewReader(`{"query": "subscription { taskChanged { id type taskId task { title } } }"}`))
+			Expect(err).To(Succeed())
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Accept", "text/event-stream")
+			response, err := http.DefaultClient.Do(request)
+			Expect(err).To(Succeed())
+			defer response.Body.Close()
+			Expect(response.Header.Get("Content-Type")).To(HavePrefix("text/event-stream"))
+			Eventually(hub.SubscriberCount).Should(Equal(1))
+
+			hub.Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: "1", Task: &models.Task{ID: "1", Title: "Write tests"}})
+			reader := bufio.NewReader(response.Body)
+			readData := func() string {
+				for {
+					line, err := reader.ReadString('\n')
+					Expect(err).To(Succeed())
+					if data, ok := strings.CutPrefix(line, "data: "); ok {
+						return data
+					}
+				}
+			}
+			Expect(readData()).To(MatchJSON(`{"data": {"taskChanged": {"id": "1", "type": "CREATED", "taskId": "1", "task": {"title": "Write tests"}}}}`))
+
+			hub.Close()
+			for {
+				line, err := reader.ReadString('\n')
+				Expect(err).To(Succeed())
+				if line == "event: complete\n" {
+					break
+				}
+			}
+		})
+
+		It("unsubscribes when the client disconnects", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			request, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL,
+				strings.NewReader(`{"query": "subscription { taskChanged { id } }"}`))
+			Expect(err).To(Succeed())
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Accept", "text/event-stream")
+			response, err := http.DefaultClient.Do(request)
+			Expect(err).To(Succeed())
+			defer response.Body.Close()
+			Eventually(hub.SubscriberCount).Should(Equal(1))
+
+			cancel()
+			Eventually(hub.SubscriberCount).Should(BeZero())
+		})
+	})
+})
+
+// introspectionQuery is the query GraphiQL and the code generators send.
+const introspectionQuery = `
+query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives { name description locations args { ...InputValue } }
+  }
+}
+fragment FullType on __Type {
+  kind name description
+  fields(includeDeprecated: true) { name description args { ...InputValue } type { ...TypeRef } isDeprecated deprecationReason }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) { name description isDeprecated deprecationReason }
+  possibleTypes { ...TypeRef }
+}
+fragment InputValue on __InputValue { name description type { ...TypeRef } defaultValue }
+fragment TypeRef on __Type {
+  kind name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
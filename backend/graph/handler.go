@@ -0,0 +1,141 @@
+package graph
+
+import (
+	"context"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gorilla/websocket"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxDepth is the deepest selection an operation may have.
+	MaxDepth = 8
+	// MaxComplexity bounds the estimated cost of an operation. Lists count
+	// as many times their items as they may hold: tasks ten, history 20 and
+	// events their limit.
+	MaxComplexity = 2000
+
+	// errComplexityLimit is the code of the errors of extension.ComplexityLimit.
+	errComplexityLimit = "COMPLEXITY_LIMIT_EXCEEDED"
+	errDepthLimit      = "DEPTH_LIMIT_EXCEEDED"
+
+	tasksComplexity       = 10
+	websocketPingInterval = 30 * time.Second
+	queryCacheSize        = 1000
+)
+
+// Operations over the limits are rejected before running, answer them with
+// 422 like invalid ones rather than 200.
+func init() {
+	errcode.RegisterErrorType(errComplexityLimit, errcode.KindProtocol)
+	errcode.RegisterErrorType(errDepthLimit, errcode.KindProtocol)
+}
+
+// NewHandler serves the queries and mutations over GET and POST, and the
+// subscriptions over Server-Sent Events and WebSockets. checkOrigin accepts
+// the origins allowed to open a WebSocket.
+func NewHandler(resolver *Resolver, checkOrigin func(r *http.Request) bool) http.Handler {
+	config := Config{Resolvers: resolver}
+	config.Complexity.Query.Tasks = func(childComplexity int, _ *string) int {
+		return tasksComplexity * childComplexity
+	}
+	config.Complexity.Query.Events = func(childComplexity int, _ string, limit int) int {
+		return limit * childComplexity
+	}
+	config.Complexity.Task.History = func(childComplexity int) int {
+		return historyLimit * childComplexity
+	}
+
+	server := handler.New(NewExecutableSchema(config))
+	server.AddTransport(transport.Websocket{
+		Upgrader:              websocket.Upgrader{CheckOrigin: checkOrigin},
+		KeepAlivePingInterval: websocketPingInterval,
+	})
+	// SSE only takes POST requests accepting text/event-stream, so it goes
+	// before POST.
+	server.AddTransport(transport.SSE{})
+	server.AddTransport(transport.GET{})
+	server.AddTransport(transport.POST{})
+	server.SetQueryCache(lru.New[*ast.QueryDocument](queryCacheSize))
+
+	server.Use(extension.Introspection{})
+	// The depth is cheaper to check, reject the deep operations first.
+	server.Use(depthLimit(MaxDepth))
+	server.Use(extension.FixedComplexityLimit(MaxComplexity))
+	server.AroundOperations(requireWriteScope)
+	server.AroundResponses(func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+		return next(withLoaders(ctx, resolver))
+	})
+	return server
+}
+
+// requireWriteScope rejects the mutations of API keys without tasks:write.
+// The API key middleware lets tasks:read keys in since queries and mutations
+// share the route.
+func requireWriteScope(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	if graphql.GetOperationContext(ctx).Operation.Operation == ast.Mutation {
+		if key, ok := service.APIKeyFromContext(ctx); ok && !key.HasScope(models.ScopeTasksWrite) {
+			return graphql.OneShot(graphql.ErrorResponse(ctx, "missing scope %s", models.ScopeTasksWrite))
+		}
+	}
+	return next(ctx)
+}
+
+// depthLimit rejects operations nested deeper than its value. Introspection
+// fields are not counted, the introspection query of the tools is deep.
+type depthLimit int
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = depthLimit(0)
+
+func (d depthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (d depthLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d depthLimit) MutateOperationContext(_ context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	if depth := selectionDepth(opCtx.Operation.SelectionSet); depth > int(d) {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+	return nil
+}
+
+// selectionDepth returns the number of nested fields of selections. The
+// fragments were already checked for cycles by the validation.
+func selectionDepth(selections ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range selections {
+		var nested int
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			nested = 1 + selectionDepth(s.SelectionSet)
+		case *ast.InlineFragment:
+			nested = selectionDepth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			nested = selectionDepth(s.Definition.SelectionSet)
+		}
+		depth = max(depth, nested)
+	}
+	return depth
+}
@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"context"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/vikstrous/dataloadgen"
+	"time"
+)
+
+// loaderWait is how long the loaders collect keys before running a batch.
+const loaderWait = time.Millisecond
+
+type loadersKey struct{}
+
+// loaders batch the lookups of one response, so resolving a relation for
+// every item of a list runs a single query instead of one per item.
+type loaders struct {
+	tasks   *dataloadgen.Loader[string, *models.Task]
+	history *dataloadgen.Loader[string, []models.TaskEvent]
+}
+
+func newLoaders(r *Resolver) *loaders {
+	return &loaders{
+		tasks:   dataloadgen.NewLoader(r.fetchTasks, dataloadgen.WithWait(loaderWait)),
+		history: dataloadgen.NewLoader(r.fetchHistory, dataloadgen.WithWait(loaderWait)),
+	}
+}
+
+// withLoaders returns ctx with new loaders. They cache what they load, so
+// each response gets its own to never serve stale tasks.
+func withLoaders(ctx context.Context, r *Resolver) context.Context {
+	return context.WithValue(ctx, loadersKey{}, newLoaders(r))
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// fetchTasks returns the tasks of ids in order, nil for the missing ones.
+func (r *Resolver) fetchTasks(ctx context.Context, ids []string) ([]*models.Task, []error) {
+	tasks, err := r.TaskBatch.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	byID := make(map[string]*models.Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+	results := make([]*models.Task, len(ids))
+	for i, id := range ids {
+		results[i] = byID[id]
+	}
+	return results, nil
+}
+
+// fetchHistory returns the latest historyLimit events of each of taskIDs.
+func (r *Resolver) fetchHistory(ctx context.Context, taskIDs []string) ([][]models.TaskEvent, []error) {
+	history, err := r.History.History(ctx, taskIDs, historyLimit)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	byTask := make(map[string][]models.TaskEvent, len(taskIDs))
+	for _, event := range history {
+		byTask[event.TaskID] = append(byTask[event.TaskID], event)
+	}
+	results := make([][]models.TaskEvent, len(taskIDs))
+	for i, id := range taskIDs {
+		results[i] = byTask[id]
+		if results[i] == nil {
+			results[i] = []models.TaskEvent{}
+		}
+	}
+	return results, nil
+}
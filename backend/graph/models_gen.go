@@ -0,0 +1,69 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type Mutation struct {
+}
+
+type Query struct {
+}
+
+type Subscription struct {
+}
+
+type TaskInput struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+}
+
+type TaskEventType string
+
+const (
+	TaskEventTypeCreated       TaskEventType = "CREATED"
+	TaskEventTypeUpdated       TaskEventType = "UPDATED"
+	TaskEventTypeStatusChanged TaskEventType = "STATUS_CHANGED"
+	TaskEventTypeDeleted       TaskEventType = "DELETED"
+)
+
+var AllTaskEventType = []TaskEventType{
+	TaskEventTypeCreated,
+	TaskEventTypeUpdated,
+	TaskEventTypeStatusChanged,
+	TaskEventTypeDeleted,
+}
+
+func (e TaskEventType) IsValid() bool {
+	switch e {
+	case TaskEventTypeCreated, TaskEventTypeUpdated, TaskEventTypeStatusChanged, TaskEventTypeDeleted:
+		return true
+	}
+	return false
+}
+
+func (e TaskEventType) String() string {
+	return string(e)
+}
+
+func (e *TaskEventType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TaskEventType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TaskEventType", str)
+	}
+	return nil
+}
+
+func (e TaskEventType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,44 @@
+// Package graph serves the tasks over GraphQL. generated.go and the resolver
+// stubs of schema.resolvers.go are generated from schema.graphqls by
+// gengraphql.sh.
+package graph
+
+import (
+	"errors"
+	"github.com/saarzur123/task-management/backend/events"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"strconv"
+)
+
+const (
+	// historyLimit is the number of events of Task.history.
+	historyLimit = 20
+	maxEvents    = 1000
+)
+
+var (
+	errTaskNotFound = errors.New("task not found")
+)
+
+// Resolver resolves the schema on top of the services of the REST API.
+type Resolver struct {
+	// Tasks runs the mutations, which it publishes to Events.
+	Tasks service.TaskRepository
+	// TaskBatch and History back the dataloaders of the task relations.
+	TaskBatch service.TaskBatchReader
+	History   service.TaskHistoryReader
+	Outbox    service.OutboxReader
+	Events    *events.Hub
+}
+
+var eventTypes = map[string]TaskEventType{
+	models.EventTaskCreated:       TaskEventTypeCreated,
+	models.EventTaskUpdated:       TaskEventTypeUpdated,
+	models.EventTaskStatusChanged: TaskEventTypeStatusChanged,
+	models.EventTaskDeleted:       TaskEventTypeDeleted,
+}
+
+func eventID(event *models.TaskEvent) string {
+	return strconv.FormatUint(event.ID, 10)
+}
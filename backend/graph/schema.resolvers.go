@@ -0,0 +1,188 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+)
+
+// CreateTask is the resolver for the createTask field.
+func (r *mutationResolver) CreateTask(ctx context.Context, input TaskInput) (*models.Task, error) {
+	task := models.Task{Title: input.Title, Description: input.Description, Status: input.Status}
+	if err := r.Tasks.Create(ctx, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdateTask is the resolver for the updateTask field.
+func (r *mutationResolver) UpdateTask(ctx context.Context, id string, input TaskInput) (*models.Task, error) {
+	// The schedule of the task isn't part of the input, keep it.
+	task, err := r.Tasks.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errTaskNotFound
+		}
+		return nil, err
+	}
+	task.Title, task.Description, task.Status = input.Title, input.Description, input.Status
+	if err := r.Tasks.Update(ctx, task); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return nil, errTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// DeleteTask is the resolver for the deleteTask field.
+func (r *mutationResolver) DeleteTask(ctx context.Context, id string) (string, error) {
+	if err := r.Tasks.Delete(ctx, id); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return "", errTaskNotFound
+		}
+		return "", err
+	}
+	return id, nil
+}
+
+// Tasks is the resolver for the tasks field.
+func (r *queryResolver) Tasks(ctx context.Context, status *string) ([]models.Task, error) {
+	tasks, err := r.Resolver.Tasks.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return tasks, nil
+	}
+
+	filtered := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Status == *status {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered, nil
+}
+
+// Task is the resolver for the task field.
+func (r *queryResolver) Task(ctx context.Context, id string) (*models.Task, error) {
+	return loadersFrom(ctx).tasks.Load(ctx, id)
+}
+
+// Events is the resolver for the events field.
+func (r *queryResolver) Events(ctx context.Context, after string, limit int) ([]models.TaskEvent, error) {
+	afterID, err := strconv.ParseUint(after, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid after %q", after)
+	}
+	if limit < 1 || limit > maxEvents {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxEvents)
+	}
+	return r.Outbox.After(ctx, afterID, limit)
+}
+
+// TaskChanged is the resolver for the taskChanged field.
+func (r *subscriptionResolver) TaskChanged(ctx context.Context) (<-chan *models.TaskEvent, error) {
+	sub, _, _ := r.Events.Subscribe(0)
+	changes := make(chan *models.TaskEvent)
+	go func() {
+		defer close(changes)
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-sub.C:
+				// The subscription is closed on shutdown or when the client
+				// fell behind, either way the client subscribes again.
+				if !ok {
+					return
+				}
+				select {
+				case changes <- &event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// History is the resolver for the history field.
+func (r *taskResolver) History(ctx context.Context, obj *models.Task) ([]models.TaskEvent, error) {
+	return loadersFrom(ctx).history.Load(ctx, obj.ID)
+}
+
+// ID is the resolver for the id field.
+func (r *taskChangeResolver) ID(ctx context.Context, obj *models.TaskEvent) (string, error) {
+	return eventID(obj), nil
+}
+
+// Type is the resolver for the type field.
+func (r *taskChangeResolver) Type(ctx context.Context, obj *models.TaskEvent) (TaskEventType, error) {
+	return eventTypes[obj.Type], nil
+}
+
+// ID is the resolver for the id field.
+func (r *taskEventResolver) ID(ctx context.Context, obj *models.TaskEvent) (string, error) {
+	return eventID(obj), nil
+}
+
+// Type is the resolver for the type field.
+func (r *taskEventResolver) Type(ctx context.Context, obj *models.TaskEvent) (TaskEventType, error) {
+	return eventTypes[obj.Type], nil
+}
+
+// Snapshot is the resolver for the snapshot field.
+func (r *taskEventResolver) Snapshot(ctx context.Context, obj *models.TaskEvent) (*models.Task, error) {
+	return obj.Task, nil
+}
+
+// Task is the resolver for the task field.
+func (r *taskEventResolver) Task(ctx context.Context, obj *models.TaskEvent) (*models.Task, error) {
+	return loadersFrom(ctx).tasks.Load(ctx, obj.TaskID)
+}
+
+// PreviousStatus is the resolver for the previousStatus field.
+func (r *taskEventResolver) PreviousStatus(ctx context.Context, obj *models.TaskEvent) (*string, error) {
+	if obj.PreviousStatus == "" {
+		return nil, nil
+	}
+	return &obj.PreviousStatus, nil
+}
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+// Task returns TaskResolver implementation.
+func (r *Resolver) Task() TaskResolver { return &taskResolver{r} }
+
+// TaskChange returns TaskChangeResolver implementation.
+func (r *Resolver) TaskChange() TaskChangeResolver { return &taskChangeResolver{r} }
+
+// TaskEvent returns TaskEventResolver implementation.
+func (r *Resolver) TaskEvent() TaskEventResolver { return &taskEventResolver{r} }
+
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
+type taskResolver struct{ *Resolver }
+type taskChangeResolver struct{ *Resolver }
+type taskEventResolver struct{ *Resolver }
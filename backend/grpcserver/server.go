@@ -0,0 +1,206 @@
+// Package grpcserver serves the tasks over gRPC, on top of the same
+// service.TaskRepository and events.Hub as the REST API.
+package grpcserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saarzur123/task-management/backend/events"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"github.com/saarzur123/task-management/backend/taskspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strconv"
+)
+
+type serverConfig struct {
+	apiKeys        service.APIKeyRepository
+	audit          service.AuditRepository
+	apiKeyRequired bool
+}
+
+// Option configures the optional parts of the server built by New.
+type Option func(*serverConfig)
+
+// WithAPIKeys authenticates the API key sent in the x-api-key metadata and
+// enforces the scopes of the REST routes. When required is set, every call
+// has to carry a valid key.
+func WithAPIKeys(apiKeys service.APIKeyRepository, audit service.AuditRepository, required bool) Option {
+	return func(c *serverConfig) {
+		c.apiKeys = apiKeys
+		c.audit = audit
+		c.apiKeyRequired = required
+	}
+}
+
+// New returns a gRPC server with the TaskService registered. WatchTasks
+// streams the events published to hub.
+func New(tasks service.TaskRepository, hub *events.Hub, options ...Option) *grpc.Server {
+	var config serverConfig
+	for _, option := range options {
+		option(&config)
+	}
+
+	var serverOptions []grpc.ServerOption
+	if config.apiKeys != nil {
+		auth := apiKeyAuth{apiKeys: config.apiKeys, audit: config.audit, required: config.apiKeyRequired}
+		serverOptions = append(serverOptions, grpc.ChainUnaryInterceptor(auth.unary), grpc.ChainStreamInterceptor(auth.stream))
+	}
+
+	server := grpc.NewServer(serverOptions...)
+	taskspb.RegisterTaskServiceServer(server, &TaskServer{Tasks: tasks, Events: hub})
+	return server
+}
+
+type TaskServer struct {
+	taskspb.UnimplementedTaskServiceServer
+	Tasks  service.TaskRepository
+	Events *events.Hub
+}
+
+func (s *TaskServer) CreateTask(ctx context.Context, request *taskspb.CreateTaskRequest) (*taskspb.Task, error) {
+	task := models.Task{Title: request.GetTitle(), Description: request.GetDescription(), Status: request.GetStatus()}
+	if err := s.Tasks.Create(ctx, &task); err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(&task), nil
+}
+
+func (s *TaskServer) GetTask(ctx context.Context, request *taskspb.GetTaskRequest) (*taskspb.Task, error) {
+	if err := validateID(request.GetId()); err != nil {
+		return nil, err
+	}
+	task, err := s.Tasks.GetByID(ctx, request.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(task), nil
+}
+
+func (s *TaskServer) UpdateTask(ctx context.Context, request *taskspb.UpdateTaskRequest) (*taskspb.Task, error) {
+	if err := validateID(request.GetId()); err != nil {
+		return nil, err
+	}
+	// The schedule of the task isn't part of the request, keep it.
+	task, err := s.Tasks.GetByID(ctx, request.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	task.Title, task.Description, task.Status = request.GetTitle(), request.GetDescription(), request.GetStatus()
+	if err := s.Tasks.Update(ctx, task); err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(task), nil
+}
+
+func (s *TaskServer) DeleteTask(ctx context.Context, request *taskspb.DeleteTaskRequest) (*taskspb.DeleteTaskResponse, error) {
+	if err := validateID(request.GetId()); err != nil {
+		return nil, err
+	}
+	if err := s.Tasks.Delete(ctx, request.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &taskspb.DeleteTaskResponse{}, nil
+}
+
+func (s *TaskServer) ListTasks(_ *taskspb.ListTasksRequest, stream grpc.ServerStreamingServer[taskspb.Task]) error {
+	tasks, err := s.Tasks.GetAll(stream.Context())
+	if err != nil {
+		return toStatus(err)
+	}
+	for i := range tasks {
+		if err = stream.Send(toProto(&tasks[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchTasks streams the task changes, first replaying the buffered events
+// after after_id, like the Server-Sent Events of /tasks/events.
+func (s *TaskServer) WatchTasks(request *taskspb.WatchTasksRequest, stream grpc.ServerStreamingServer[taskspb.TaskEvent]) error {
+	sub, missed, complete := s.Events.Subscribe(request.GetAfterId())
+	defer sub.Unsubscribe()
+
+	if !complete {
+		if err := stream.Send(&taskspb.TaskEvent{Type: taskspb.TaskEvent_TYPE_RESYNC}); err != nil {
+			return err
+		}
+	}
+	for _, event := range missed {
+		if err := stream.Send(eventToProto(event)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case event, ok := <-sub.C:
+			if !ok {
+				if sub.Lagged {
+					return status.Error(codes.ResourceExhausted, "fell behind the task events, watch again from the last event")
+				}
+				return status.Error(codes.Unavailable, "server is shutting down")
+			}
+			if err := stream.Send(eventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func validateID(id string) error {
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return status.Error(codes.InvalidArgument, "invalid task id")
+	}
+	return nil
+}
+
+// toStatus maps the errors of the TaskRepository to gRPC status codes.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, "task not found")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func toProto(task *models.Task) *taskspb.Task {
+	return &taskspb.Task{
+		Id:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      task.Status,
+		CreatedAt:   timestamppb.New(task.CreatedAt),
+	}
+}
+
+var eventTypes = map[string]taskspb.TaskEvent_Type{
+	models.EventTaskCreated: taskspb.TaskEvent_TYPE_CREATED,
+	models.EventTaskUpdated: taskspb.TaskEvent_TYPE_UPDATED,
+	models.EventTaskDeleted: taskspb.TaskEvent_TYPE_DELETED,
+}
+
+func eventToProto(event models.TaskEvent) *taskspb.TaskEvent {
+	message := &taskspb.TaskEvent{
+		Id:         event.ID,
+		Type:       eventTypes[event.Type],
+		TaskId:     event.TaskID,
+		OccurredAt: timestamppb.New(event.OccurredAt),
+	}
+	if event.Task != nil {
+		message.Task = toProto(event.Task)
+	}
+	return message
+}
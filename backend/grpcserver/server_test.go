@@ -0,0 +1,328 @@
+package grpcserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/events"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"github.com/saarzur123/task-management/backend/taskspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+var errMock = errors.New("mock error")
+
+func TestGRPCServer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "gRPC Suite")
+}
+
+// dial serves server over an in-memory listener and returns a client for it.
+func dial(server *grpc.Server) taskspb.TaskServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener)
+	DeferCleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	Expect(err).To(Succeed())
+	DeferCleanup(conn.Close)
+	return taskspb.NewTaskServiceClient(conn)
+}
+
+func expectCode(err error, code codes.Code) {
+	ExpectWithOffset(1, err).To(HaveOccurred())
+	ExpectWithOffset(1, status.Code(err)).To(Equal(code))
+}
+
+var _ = Describe("TaskServer", func() {
+	var (
+		mockTasks *serviceMock.MockTaskRepository
+		hub       *events.Hub
+		client    taskspb.TaskServiceClient
+		ctx       context.Context
+		createdAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockTasks = serviceMock.NewMockTaskRepository(mockCtrl)
+		hub = events.NewHub(2, 4)
+		client = dial(New(mockTasks, hub))
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	Describe("CreateTask", func() {
+		It("creates the task", func() {
+			mockTasks.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *models.Task) error {
+				Expect(task.Title).To(Equal("Write tests"))
+				Expect(task.Status).To(Equal("pending"))
+				task.ID = "1"
+				task.CreatedAt = createdAt
+				return nil
+			})
+
+			task, err := client.CreateTask(ctx, &taskspb.CreateTaskRequest{Title: "Write tests", Status: "pending"})
+			Expect(err).To(Succeed())
+			Expect(task.GetId()).To(Equal("1"))
+			Expect(task.GetCreatedAt().AsTime()).To(Equal(createdAt))
+		})
+
+		It("fails with Internal on a database error", func() {
+			mockTasks.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errMock)
+
+			_, err := client.CreateTask(ctx, &taskspb.CreateTaskRequest{Title: "Write tests"})
+			expectCode(err, codes.Internal)
+		})
+	})
+
+	Describe("GetTask", func() {
+		It("returns the task", func() {
+			mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(&models.Task{ID: "1", Title: "Write tests", CreatedAt: createdAt}, nil)
+
+			task, err := client.GetTask(ctx, &taskspb.GetTaskRequest{Id: "1"})
+			Expect(err).To(Succeed())
+			Expect(task.GetTitle()).To(Equal("Write tests"))
+		})
+
+		It("fails with NotFound when the task doesn't exist", func() {
+			mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(nil, service.ErrNotFound)
+
+			_, err := client.GetTask(ctx, &taskspb.GetTaskRequest{Id: "1"})
+			expectCode(err, codes.NotFound)
+		})
+
+		It("fails with NotFound on sql.ErrNoRows", func() {
+			mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(nil, sql.ErrNoRows)
+
+			_, err := client.GetTask(ctx, &taskspb.GetTaskRequest{Id: "1"})
+			expectCode(err, codes.NotFound)
+		})
+
+		It("fails with InvalidArgument on a malformed ID", func() {
+			_, err := client.GetTask(ctx, &taskspb.GetTaskRequest{Id: "abc"})
+			expectCode(err, codes.InvalidArgument)
+		})
+	})
+
+	Describe("UpdateTask", func() {
+		It("updates the task, keeping its schedule", func() {
+			dueAt := createdAt.Add(time.Hour)
+			mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(&models.Task{ID: "1", DueAt: &dueAt, Recurrence: "FREQ=DAILY"}, nil)
+			mockTasks.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *models.Task) error {
+				Expect(task.ID).To(Equal("1"))
+				Expect(task.Status).To(Equal("done"))
+				Expect(task.DueAt).To(Equal(&dueAt))
+				Expect(task.Recurrence).To(Equal("FREQ=DAILY"))
+				task.CreatedAt = createdAt
+				return nil
+			})
+
+			task, err := client.UpdateTask(ctx, &taskspb.UpdateTaskRequest{Id: "1", Title: "Write tests", Status: "done"})
+			Expect(err).To(Succeed())
+			Expect(task.GetStatus()).To(Equal("done"))
+		})
+
+		It("fails with NotFound when the task doesn't exist", func() {
+			mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(nil, sql.ErrNoRows)
+
+			_, err := client.UpdateTask(ctx, &taskspb.UpdateTaskRequest{Id: "1"})
+			expectCode(err, codes.NotFound)
+		})
+	})
+
+	Describe("DeleteTask", func() {
+		It("deletes the task", func() {
+			mockTasks.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+
+			_, err := client.DeleteTask(ctx, &taskspb.DeleteTaskRequest{Id: "1"})
+			Expect(err).To(Succeed())
+		})
+
+		It("fails with NotFound when the task doesn't exist", func() {
+			mockTasks.EXPECT().Delete(gomock.Any(), "1").Return(sql.ErrNoRows)
+
+			_, err := client.DeleteTask(ctx, &taskspb.DeleteTaskRequest{Id: "1"})
+			expectCode(err, codes.NotFound)
+		})
+	})
+
+	Describe("ListTasks", func() {
+		It("streams every task", func() {
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{{ID: "1"}, {ID: "2"}}, nil)
+
+			stream, err := client.ListTasks(ctx, &taskspb.ListTasksRequest{})
+			Expect(err).To(Succeed())
+			var ids []string
+			for {
+				task, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).To(Succeed())
+				ids = append(ids, task.GetId())
+			}
+			Expect(ids).To(Equal([]string{"1", "2"}))
+		})
+
+		It("fails with Internal on a database error", func() {
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return(nil, errMock)
+
+			stream, err := client.ListTasks(ctx, &taskspb.ListTasksRequest{})
+			Expect(err).To(Succeed())
+			_, err = stream.Recv()
+			expectCode(err, codes.Internal)
+		})
+	})
+
+	Describe("WatchTasks", func() {
+		It("streams published events", func() {
+			stream, err := client.WatchTasks(ctx, &taskspb.WatchTasksRequest{})
+			Expect(err).To(Succeed())
+			Eventually(hub.SubscriberCount).Should(Equal(1))
+
+			hub.Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: "1", Task: &models.Task{ID: "1", Title: "Write tests"}})
+			event, err := stream.Recv()
+			Expect(err).To(Succeed())
+			Expect(event.GetId()).To(Equal(uint64(1)))
+			Expect(event.GetType()).To(Equal(taskspb.TaskEvent_TYPE_CREATED))
+			Expect(event.GetTask().GetTitle()).To(Equal("Write tests"))
+		})
+
+		It("replays the events after after_id", func() {
+			hub.Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: "1"})
+			hub.Publish(models.TaskEvent{Type: models.EventTaskDeleted, TaskID: "1"})
+
+			stream, err := client.WatchTasks(ctx, &taskspb.WatchTasksRequest{AfterId: 1})
+			Expect(err).To(Succeed())
+			event, err := stream.Recv()
+			Expect(err).To(Succeed())
+			Expect(event.GetId()).To(Equal(uint64(2)))
+			Expect(event.GetType()).To(Equal(taskspb.TaskEvent_TYPE_DELETED))
+			Expect(event.GetTask()).To(BeNil())
+		})
+
+		It("asks for a resync when the missed events are no longer buffered", func() {
+			for range 4 {
+				hub.Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: "1"})
+			}
+
+			stream, err := client.WatchTasks(ctx, &taskspb.WatchTasksRequest{AfterId: 1})
+			Expect(err).To(Succeed())
+			event, err := stream.Recv()
+			Expect(err).To(Succeed())
+			Expect(event.GetType()).To(Equal(taskspb.TaskEvent_TYPE_RESYNC))
+			event, err = stream.Recv()
+			Expect(err).To(Succeed())
+			Expect(event.GetId()).To(Equal(uint64(3)))
+		})
+
+		It("ends the stream when the server shuts down", func() {
+			stream, err := client.WatchTasks(ctx, &taskspb.WatchTasksRequest{})
+			Expect(err).To(Succeed())
+			Eventually(hub.SubscriberCount).Should(Equal(1))
+
+			hub.Close()
+			_, err = stream.Recv()
+			expectCode(err, codes.Unavailable)
+		})
+	})
+})
+
+var _ = Describe("API key authentication", func() {
+	var (
+		mockTasks *serviceMock.MockTaskRepository
+		mockKeys  *serviceMock.MockAPIKeyRepository
+		mockAudit *serviceMock.MockAuditRepository
+		ctx       context.Context
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockTasks = serviceMock.NewMockTaskRepository(mockCtrl)
+		mockKeys = serviceMock.NewMockAPIKeyRepository(mockCtrl)
+		mockKeys.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		mockAudit = serviceMock.NewMockAuditRepository(mockCtrl)
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	withKey := func(key string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, apiKeyMetadata, key)
+	}
+
+	It("lets calls without a key through unless a key is required", func() {
+		client := dial(New(mockTasks, events.NewHub(2, 4), WithAPIKeys(mockKeys, mockAudit, false)))
+		mockTasks.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+
+		_, err := client.DeleteTask(ctx, &taskspb.DeleteTaskRequest{Id: "1"})
+		Expect(err).To(Succeed())
+	})
+
+	It("rejects calls without a key when a key is required", func() {
+		client := dial(New(mockTasks, events.NewHub(2, 4), WithAPIKeys(mockKeys, mockAudit, true)))
+
+		_, err := client.GetTask(ctx, &taskspb.GetTaskRequest{Id: "1"})
+		expectCode(err, codes.Unauthenticated)
+	})
+
+	It("rejects invalid keys", func() {
+		client := dial(New(mockTasks, events.NewHub(2, 4), WithAPIKeys(mockKeys, mockAudit, false)))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), "tm_bad").Return(nil, service.ErrInvalidAPIKey)
+
+		_, err := client.GetTask(withKey("tm_bad"), &taskspb.GetTaskRequest{Id: "1"})
+		expectCode(err, codes.Unauthenticated)
+	})
+
+	It("rejects keys without the scope of the method", func() {
+		client := dial(New(mockTasks, events.NewHub(2, 4), WithAPIKeys(mockKeys, mockAudit, false)))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), "tm_read").Return(&models.APIKey{Name: "ci", Scopes: []string{models.ScopeTasksRead}}, nil)
+
+		_, err := client.DeleteTask(withKey("tm_read"), &taskspb.DeleteTaskRequest{Id: "1"})
+		expectCode(err, codes.PermissionDenied)
+	})
+
+	It("records the usage and passes the key to the repository", func() {
+		client := dial(New(mockTasks, events.NewHub(2, 4), WithAPIKeys(mockKeys, mockAudit, true)))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), "tm_read").Return(&models.APIKey{Name: "ci", Scopes: []string{models.ScopeTasksRead}}, nil)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry *models.AuditEntry) error {
+			Expect(entry.Actor).To(Equal("apikey:ci"))
+			Expect(entry.Resource).To(Equal(taskspb.TaskService_ListTasks_FullMethodName))
+			return nil
+		})
+		mockTasks.EXPECT().GetAll(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]models.Task, error) {
+			key, ok := service.APIKeyFromContext(ctx)
+			Expect(ok).To(BeTrue())
+			Expect(key.Name).To(Equal("ci"))
+			return nil, nil
+		})
+
+		stream, err := client.ListTasks(withKey("tm_read"), &taskspb.ListTasksRequest{})
+		Expect(err).To(Succeed())
+		_, err = stream.Recv()
+		Expect(err).To(Equal(io.EOF))
+	})
+})
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/saarzur123/task-management/backend/logging"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"log/slog"
+	"net/http"
+)
+
+type APIKeyHandler struct {
+	DB    service.APIKeyRepository
+	Audit service.AuditRepository
+}
+
+const (
+	anonymousActor = "anonymous"
+)
+
+var knownScopes = map[string]bool{
+	models.ScopeTasksRead:   true,
+	models.ScopeTasksWrite:  true,
+	models.ScopeAPIKeyAdmin: true,
+}
+
+// Actor returns the name recorded in the audit trail for the caller of r.
+func Actor(r *http.Request) string {
+	if key, ok := service.APIKeyFromContext(r.Context()); ok {
+		return "apikey:" + key.Name
+	}
+	return anonymousActor
+}
+
+func (h *APIKeyHandler) record(r *http.Request, action, resource string) {
+	recordAudit(h.Audit, r, action, resource)
+}
+
+// recordAudit adds an entry for the caller of r to the audit trail. Failures
+// are logged rather than failing the request.
+func recordAudit(audit service.AuditRepository, r *http.Request, action, resource string) {
+	err := audit.Record(r.Context(), &models.AuditEntry{Actor: Actor(r), Action: action, Resource: resource})
+	if err != nil {
+		logging.FromContext(r.Context()).ErrorContext(r.Context(), "failed to record audit entry",
+			slog.String("action", action), slog.String("resource", resource), slog.Any("error", err))
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var key models.APIKey
+	if err := json.NewDecoder(r.Body).Decode(&key); err != nil || key.Name == "" || len(key.Scopes) == 0 {
+		http.Error(w, invalidInput, http.StatusBadRequest)
+		return
+	}
+	for _, scope := range key.Scopes {
+		if !knownScopes[scope] {
+			http.Error(w, "Unknown scope "+scope, http.StatusBadRequest)
+			return
+		}
+	}
+	err := h.DB.Create(r.Context(), &key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.record(r, "apikey.create", "apikeys/"+key.ID)
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *APIKeyHandler) GetAllAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.DB.GetAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(keys)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	err := h.DB.Revoke(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			http.Error(w, "API key not found", http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.record(r, "apikey.revoke", "apikeys/"+id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIKeyHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.Audit.GetAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(entries)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
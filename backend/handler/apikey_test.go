@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("APIKeyHandler", func() {
+	var (
+		mockKeys         *serviceMock.MockAPIKeyRepository
+		mockAudit        *serviceMock.MockAuditRepository
+		handler          *APIKeyHandler
+		responseRecorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockKeys = serviceMock.NewMockAPIKeyRepository(mockCtrl)
+		mockAudit = serviceMock.NewMockAuditRepository(mockCtrl)
+		handler = &APIKeyHandler{DB: mockKeys, Audit: mockAudit}
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	Describe("CreateAPIKey", func() {
+		newRequest := func(key models.APIKey) *http.Request {
+			body, err := json.Marshal(key)
+			Expect(err).To(Succeed())
+			request, err := http.NewRequest("POST", "/apikeys", bytes.NewBuffer(body))
+			Expect(err).To(Succeed())
+			return request
+		}
+
+		It("creates the key, returns it once and records the creation", func() {
+			mockKeys.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key *models.APIKey) error {
+				key.ID = "1"
+				key.Key = "tm_secret"
+				return nil
+			})
+			mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry *models.AuditEntry) error {
+				Expect(entry.Actor).To(Equal("anonymous"))
+				Expect(entry.Action).To(Equal("apikey.create"))
+				Expect(entry.Resource).To(Equal("apikeys/1"))
+				return nil
+			})
+
+			handler.CreateAPIKey(responseRecorder, newRequest(models.APIKey{Name: "ci", Scopes: []string{models.ScopeTasksWrite}}))
+			Expect(responseRecorder.Code).To(Equal(http.StatusCreated))
+			var responseKey models.APIKey
+			json.NewDecoder(responseRecorder.Body).Decode(&responseKey)
+			Expect(responseKey.Key).To(Equal("tm_secret"))
+		})
+
+		It("returns 400 when the name is missing", func() {
+			handler.CreateAPIKey(responseRecorder, newRequest(models.APIKey{Scopes: []string{models.ScopeTasksRead}}))
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns 400 on unknown scopes", func() {
+			handler.CreateAPIKey(responseRecorder, newRequest(models.APIKey{Name: "ci", Scopes: []string{"tasks:all"}}))
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring("Unknown scope tasks:all"))
+		})
+
+		It("returns 500 when database error occurred", func() {
+			mockKeys.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errMock)
+
+			handler.CreateAPIKey(responseRecorder, newRequest(models.APIKey{Name: "ci", Scopes: []string{models.ScopeTasksRead}}))
+			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Describe("RevokeAPIKey", func() {
+		var request *http.Request
+
+		BeforeEach(func() {
+			var err error
+			request, err = http.NewRequest("DELETE", "/apikeys/1", nil)
+			Expect(err).To(Succeed())
+			request = mux.SetURLVars(request, map[string]string{"id": "1"})
+		})
+
+		It("revokes the key and records the caller", func() {
+			request = request.WithContext(service.ContextWithAPIKey(request.Context(), &models.APIKey{Name: "admin"}))
+			mockKeys.EXPECT().Revoke(gomock.Any(), "1").Return(nil)
+			mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry *models.AuditEntry) error {
+				Expect(entry.Actor).To(Equal("apikey:admin"))
+				return nil
+			})
+
+			handler.RevokeAPIKey(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("returns 404 when the key doesn't exist", func() {
+			mockKeys.EXPECT().Revoke(gomock.Any(), "1").Return(service.ErrNotFound)
+
+			handler.RevokeAPIKey(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("GetAllAPIKeys", func() {
+		It("returns all keys", func() {
+			mockKeys.EXPECT().GetAll(gomock.Any()).Return([]models.APIKey{{ID: "1", Name: "ci"}}, nil)
+			request, err := http.NewRequest("GET", "/apikeys", nil)
+			Expect(err).To(Succeed())
+
+			handler.GetAllAPIKeys(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			var keys []models.APIKey
+			json.NewDecoder(responseRecorder.Body).Decode(&keys)
+			Expect(keys).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+)
+
+type BoardHandler struct {
+	DB service.TaskBoard
+}
+
+func (h *BoardHandler) GetBoard(w http.ResponseWriter, r *http.Request) {
+	board, err := h.DB.Board(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(board)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// MoveTask moves the task to a column of the board, between the cards around
+// the drop position. It answers 409 when the board changed since the caller
+// read it and the cards aren't next to each other anymore.
+func (h *BoardHandler) MoveTask(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var move models.TaskMove
+	if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+		http.Error(w, invalidInput, http.StatusBadRequest)
+		return
+	}
+	if move.Status == "" {
+		http.Error(w, "Invalid move, expected a status", http.StatusBadRequest)
+		return
+	}
+	if move.After == id || move.Before == id {
+		http.Error(w, "Invalid move, a task can't be moved next to itself", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.DB.Move(r.Context(), id, move)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			http.Error(w, "Task not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidMove) {
+			http.Error(w, "The neighbors of the task moved, reload the board", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(task)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
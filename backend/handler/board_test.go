@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+var _ = Describe("BoardHandler", func() {
+	var (
+		mockBoard        *serviceMock.MockTaskBoard
+		handler          *BoardHandler
+		responseRecorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockBoard = serviceMock.NewMockTaskBoard(mockCtrl)
+		handler = &BoardHandler{DB: mockBoard}
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	newMoveRequest := func(body string) *http.Request {
+		request, err := http.NewRequest("POST", "/tasks/1/move", strings.NewReader(body))
+		Expect(err).To(Succeed())
+		return mux.SetURLVars(request, map[string]string{"id": "1"})
+	}
+
+	It("returns the board", func() {
+		mockBoard.EXPECT().Board(gomock.Any()).Return(&models.Board{Columns: []models.BoardColumn{
+			{Status: "pending", Tasks: []models.Task{{ID: "2", Title: "Task 2", Status: "pending", Rank: "V"}}},
+			{Status: "done", Tasks: []models.Task{}},
+		}}, nil)
+
+		request, err := http.NewRequest("GET", "/board", nil)
+		Expect(err).To(Succeed())
+		handler.GetBoard(responseRecorder, request)
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(responseRecorder.Body.String()).To(MatchJSON(`{"columns": [
+			{"status": "pending", "tasks": [{"id":"2","title":"Task 2","description":"","status":"pending","created_at":"0001-01-01T00:00:00Z","rank":"V"}]},
+			{"status": "done", "tasks": []}
+		]}`))
+	})
+
+	It("returns 500 when the board can't be read", func() {
+		mockBoard.EXPECT().Board(gomock.Any()).Return(nil, errMock)
+
+		request, err := http.NewRequest("GET", "/board", nil)
+		Expect(err).To(Succeed())
+		handler.GetBoard(responseRecorder, request)
+		Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("moves the task", func() {
+		move := models.TaskMove{Status: "done", After: "2", Before: "3"}
+		mockBoard.EXPECT().Move(gomock.Any(), "1", move).
+			Return(&models.Task{ID: "1", Title: "Task 1", Status: "done", Rank: "N"}, nil)
+
+		handler.MoveTask(responseRecorder, newMoveRequest(`{"status": "done", "after": "2", "before": "3"}`))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(responseRecorder.Body.String()).To(MatchJSON(
+			`{"id":"1","title":"Task 1","description":"","status":"done","created_at":"0001-01-01T00:00:00Z","rank":"N"}`))
+	})
+
+	DescribeTable("rejects invalid moves",
+		func(body, expected string) {
+			handler.MoveTask(responseRecorder, newMoveRequest(body))
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring(expected))
+		},
+		Entry("malformed body", `{`, invalidInput),
+		Entry("missing status", `{"after": "2"}`, "Invalid move, expected a status"),
+		Entry("after itself", `{"status": "done", "after": "1"}`, "can't be moved next to itself"),
+		Entry("before itself", `{"status": "done", "before": "1"}`, "can't be moved next to itself"),
+	)
+
+	DescribeTable("maps the errors of the move",
+		func(err error, expected int) {
+			mockBoard.EXPECT().Move(gomock.Any(), "1", models.TaskMove{Status: "done"}).Return(nil, err)
+
+			handler.MoveTask(responseRecorder, newMoveRequest(`{"status": "done"}`))
+			Expect(responseRecorder.Code).To(Equal(expected))
+		},
+		Entry("missing task", service.ErrNotFound, http.StatusNotFound),
+		Entry("stale neighbors", service.ErrInvalidMove, http.StatusConflict),
+		Entry("storage error", errMock, http.StatusInternalServerError),
+	)
+})
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/events"
+	"github.com/saarzur123/task-management/backend/logging"
+	"github.com/saarzur123/task-management/backend/models"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type EventsHandler struct {
+	Hub *events.Hub
+	// Heartbeat is the interval of the comments keeping idle connections
+	// open through proxies, defaultHeartbeat when zero.
+	Heartbeat time.Duration
+}
+
+const (
+	defaultHeartbeat = 15 * time.Second
+	// eventResync tells clients that events were missed and they have to
+	// fetch the tasks again.
+	eventResync     = "resync"
+	reconnectMillis = 3000
+)
+
+// StreamTaskEvents streams task changes as Server-Sent Events. Clients
+// resuming with Last-Event-ID receive the buffered events they missed first.
+func (h *EventsHandler) StreamTaskEvents(w http.ResponseWriter, r *http.Request) {
+	controller := http.NewResponseController(w)
+
+	var afterID uint64
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		afterID = id
+	}
+
+	sub, missed, complete := h.Hub.Subscribe(afterID)
+	defer sub.Unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", reconnectMillis); err != nil {
+		return
+	}
+	if !complete {
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: {}\n\n", eventResync); err != nil {
+			return
+		}
+	}
+	for _, event := range missed {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		logging.FromContext(r.Context()).ErrorContext(r.Context(), "streaming unsupported", slog.Any("error", err))
+		return
+	}
+
+	heartbeat := h.Heartbeat
+	if heartbeat == 0 {
+		heartbeat = defaultHeartbeat
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind, the client reconnects with
+				// Last-Event-ID and catches up from the replay buffer.
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event models.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
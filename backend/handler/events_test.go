@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/events"
+	"github.com/saarzur123/task-management/backend/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+var _ = Describe("EventsHandler", func() {
+	var (
+		hub    *events.Hub
+		server *httptest.Server
+		cancel context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		hub = events.NewHub(2, 4)
+		server = httptest.NewServer(http.HandlerFunc((&EventsHandler{Hub: hub, Heartbeat: 20 * time.Millisecond}).StreamTaskEvents))
+	})
+
+	AfterEach(func() {
+		if cancel != nil {
+			cancel()
+		}
+		server.Close()
+	})
+
+	stream := func(lastEventID string) (*http.Response, *bufio.Reader) {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		Expect(err).To(Succeed())
+		if lastEventID != "" {
+			request.Header.Set("Last-Event-ID", lastEventID)
+		}
+		response, err := http.DefaultClient.Do(request)
+		Expect(err).To(Succeed())
+		return response, bufio.NewReader(response.Body)
+	}
+
+	// readUntil returns the stream up to and including the line containing
+	// substring.
+	readUntil := func(reader *bufio.Reader, substring string) string {
+		var read strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			Expect(err).To(Succeed())
+			read.WriteString(line)
+			if strings.Contains(line, substring) {
+				return read.String()
+			}
+		}
+	}
+
+	It("streams published events", func() {
+		response, reader := stream("")
+		Expect(response.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+		readUntil(reader, "retry:")
+		Eventually(hub.SubscriberCount).Should(Equal(1))
+
+		hub.Publish(models.TaskEvent{Type: models.EventTaskDeleted, TaskID: "42"})
+		Expect(readUntil(reader, "data:")).To(And(
+			ContainSubstring("id: 1\n"),
+			ContainSubstring("event: task.deleted\n"),
+			ContainSubstring(`"task_id":"42"`),
+		))
+	})
+
+	It("sends heartbeats", func() {
+		_, reader := stream("")
+		readUntil(reader, ": heartbeat")
+	})
+
+	It("replays missed events after Last-Event-ID", func() {
+		hub.Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: "1"})
+		hub.Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: "1"})
+
+		_, reader := stream("1")
+		Expect(readUntil(reader, "data:")).To(ContainSubstring("id: 2\n"))
+	})
+
+	It("asks the client to resync when the replay buffer can't cover the gap", func() {
+		for i := 0; i < 4; i++ {
+			hub.Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: "1"})
+		}
+
+		_, reader := stream("1")
+		Expect(readUntil(reader, "data:")).To(ContainSubstring("event: resync\n"))
+		Expect(readUntil(reader, "data:")).To(ContainSubstring("id: 3\n"))
+	})
+
+	It("rejects an invalid Last-Event-ID", func() {
+		response, _ := stream("abc")
+		Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	It("unsubscribes when the client disconnects", func() {
+		stream("")
+		Eventually(hub.SubscriberCount).Should(Equal(1))
+
+		cancel()
+		Eventually(hub.SubscriberCount).Should(BeZero())
+	})
+})
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type EventFeedHandler struct {
+	Outbox service.OutboxReader
+	// PollInterval is how often a waiting request checks the outbox for new
+	// events, defaultFeedPollInterval when zero.
+	PollInterval time.Duration
+
+	initOnce  sync.Once
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+const (
+	defaultFeedPollInterval = 500 * time.Millisecond
+	defaultFeedLimit        = 100
+	maxFeedLimit            = 1000
+	defaultFeedWait         = 30 * time.Second
+	maxFeedWait             = 60 * time.Second
+)
+
+func (h *EventFeedHandler) closing() chan struct{} {
+	h.initOnce.Do(func() { h.closed = make(chan struct{}) })
+	return h.closed
+}
+
+// Close answers the waiting requests with an empty page, so a shutdown
+// doesn't wait for them.
+func (h *EventFeedHandler) Close() {
+	closed := h.closing()
+	h.closeOnce.Do(func() { close(closed) })
+}
+
+// EventFeed is a page of the event feed. Next is the sequence number to pass
+// as after to get the following events.
+type EventFeed struct {
+	Events []models.TaskEvent `json:"events"`
+	Next   uint64             `json:"next"`
+}
+
+// GetEvents returns the task events with a sequence number greater than the
+// after query parameter. When there are none yet it waits for up to wait
+// seconds (30 by default, 0 to answer right away) and returns an empty page
+// if nothing happened, so consumers can call it in a loop.
+func (h *EventFeedHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var after uint64
+	if value := query.Get("after"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid after", http.StatusBadRequest)
+			return
+		}
+		after = parsed
+	}
+
+	limit := defaultFeedLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxFeedLimit {
+			http.Error(w, "Invalid limit, expected 1 to "+strconv.Itoa(maxFeedLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	wait := defaultFeedWait
+	if value := query.Get("wait"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || time.Duration(parsed)*time.Second > maxFeedWait {
+			http.Error(w, "Invalid wait, expected 0 to "+strconv.Itoa(int(maxFeedWait.Seconds()))+" seconds", http.StatusBadRequest)
+			return
+		}
+		wait = time.Duration(parsed) * time.Second
+	}
+
+	pollInterval := h.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultFeedPollInterval
+	}
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		events, err := h.Outbox.After(r.Context(), after, limit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if len(events) > 0 {
+			writeEventFeed(w, EventFeed{Events: events, Next: events[len(events)-1].ID})
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-deadline.C:
+			writeEventFeed(w, EventFeed{Events: events, Next: after})
+			return
+		case <-h.closing():
+			writeEventFeed(w, EventFeed{Events: events, Next: after})
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func writeEventFeed(w http.ResponseWriter, feed EventFeed) {
+	w.Header().Set("Content-Type", jsonContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	err := json.NewEncoder(w).Encode(feed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+var _ = Describe("EventFeedHandler", func() {
+	var (
+		mockOutbox       *serviceMock.MockOutboxReader
+		handler          *EventFeedHandler
+		responseRecorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockOutbox = serviceMock.NewMockOutboxReader(mockCtrl)
+		handler = &EventFeedHandler{Outbox: mockOutbox, PollInterval: 10 * time.Millisecond}
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	decodeFeed := func() EventFeed {
+		var feed EventFeed
+		Expect(json.NewDecoder(responseRecorder.Body).Decode(&feed)).To(Succeed())
+		return feed
+	}
+
+	It("returns the events after the given sequence number right away", func() {
+		events := []models.TaskEvent{
+			{ID: 8, Type: models.EventTaskUpdated, TaskID: "1"},
+			{ID: 9, Type: models.EventTaskStatusChanged, TaskID: "1", PreviousStatus: "pending"},
+		}
+		mockOutbox.EXPECT().After(gomock.Any(), uint64(7), 2).Return(events, nil)
+
+		handler.GetEvents(responseRecorder, httptest.NewRequest("GET", "/events?after=7&limit=2", nil))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(decodeFeed()).To(Equal(EventFeed{Events: events, Next: 9}))
+	})
+
+	It("waits for the next event", func() {
+		gomock.InOrder(
+			mockOutbox.EXPECT().After(gomock.Any(), uint64(0), defaultFeedLimit).Return([]models.TaskEvent{}, nil).Times(2),
+			mockOutbox.EXPECT().After(gomock.Any(), uint64(0), defaultFeedLimit).
+				Return([]models.TaskEvent{{ID: 1, Type: models.EventTaskCreated, TaskID: "1"}}, nil),
+		)
+
+		handler.GetEvents(responseRecorder, httptest.NewRequest("GET", "/events", nil))
+		Expect(decodeFeed().Next).To(Equal(uint64(1)))
+	})
+
+	It("returns an empty page when nothing happens before the wait ends", func() {
+		mockOutbox.EXPECT().After(gomock.Any(), uint64(5), defaultFeedLimit).Return([]models.TaskEvent{}, nil).MinTimes(1)
+
+		handler.GetEvents(responseRecorder, httptest.NewRequest("GET", "/events?after=5&wait=0", nil))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(decodeFeed()).To(Equal(EventFeed{Events: []models.TaskEvent{}, Next: 5}))
+	})
+
+	It("stops waiting when the client goes away", func() {
+		mockOutbox.EXPECT().After(gomock.Any(), uint64(0), defaultFeedLimit).Return([]models.TaskEvent{}, nil).AnyTimes()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		handler.GetEvents(responseRecorder, httptest.NewRequest("GET", "/events", nil).WithContext(ctx))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(responseRecorder.Body.Len()).To(BeZero())
+	})
+
+	It("returns an empty page when closed", func() {
+		mockOutbox.EXPECT().After(gomock.Any(), uint64(0), defaultFeedLimit).Return([]models.TaskEvent{}, nil).AnyTimes()
+		time.AfterFunc(20*time.Millisecond, handler.Close)
+
+		handler.GetEvents(responseRecorder, httptest.NewRequest("GET", "/events", nil))
+		Expect(decodeFeed()).To(Equal(EventFeed{Events: []models.TaskEvent{}, Next: 0}))
+	})
+
+	It("returns an error when the outbox can't be read", func() {
+		mockOutbox.EXPECT().After(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errMock)
+
+		handler.GetEvents(responseRecorder, httptest.NewRequest("GET", "/events", nil))
+		Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+	})
+
+	DescribeTable("rejects invalid parameters",
+		func(query string) {
+			handler.GetEvents(responseRecorder, httptest.NewRequest("GET", "/events?"+query, nil))
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+		},
+		Entry("negative after", "after=-1"),
+		Entry("non-numeric after", "after=abc"),
+		Entry("zero limit", "limit=0"),
+		Entry("too large limit", "limit=5000"),
+		Entry("too long wait", "wait=120"),
+	)
+})
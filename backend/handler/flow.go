@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultFlowDays is the number of days of a cumulative flow without
+	// from, up to to.
+	defaultFlowDays = 30
+	maxFlowDays     = 366
+)
+
+type FlowReportHandler struct {
+	DB service.FlowReporter
+}
+
+// GetBurndown returns the burndown of the sprint of the sprint parameter.
+func (h *FlowReportHandler) GetBurndown(w http.ResponseWriter, r *http.Request) {
+	sprintID := r.URL.Query().Get("sprint")
+	if sprintID == "" {
+		http.Error(w, "Invalid sprint, expected the ID of a sprint", http.StatusBadRequest)
+		return
+	}
+
+	burndown, err := h.DB.Burndown(r.Context(), sprintID)
+	if errors.Is(err, service.ErrNotFound) {
+		http.Error(w, sprintNotFound, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeFlowJSON(w, burndown)
+}
+
+// GetCumulativeFlow returns the cumulative flow from the from date to the to
+// date, both included. to defaults to today and from to 30 days up to to.
+func (h *FlowReportHandler) GetCumulativeFlow(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := query.Get("to"); value != "" {
+		var err error
+		if to, err = time.Parse(time.DateOnly, value); err != nil {
+			http.Error(w, "Invalid to, expected a YYYY-MM-DD date", http.StatusBadRequest)
+			return
+		}
+	}
+	from := to.AddDate(0, 0, 1-defaultFlowDays)
+	if value := query.Get("from"); value != "" {
+		var err error
+		if from, err = time.Parse(time.DateOnly, value); err != nil {
+			http.Error(w, "Invalid from, expected a YYYY-MM-DD date", http.StatusBadRequest)
+			return
+		}
+	}
+	if from.After(to) {
+		http.Error(w, "Invalid from, expected to or before", http.StatusBadRequest)
+		return
+	}
+	if to.Sub(from) >= maxFlowDays*24*time.Hour {
+		http.Error(w, "Invalid from, expected at most 366 days up to to", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.DB.CumulativeFlow(r.Context(), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeFlowJSON(w, report)
+}
+
+func writeFlowJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", jsonContentType)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+var _ = Describe("FlowReportHandler", func() {
+	var (
+		mockReporter     *serviceMock.MockFlowReporter
+		handler          *FlowReportHandler
+		responseRecorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockReporter = serviceMock.NewMockFlowReporter(mockCtrl)
+		handler = &FlowReportHandler{DB: mockReporter}
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	newRequest := func(url string) *http.Request {
+		request, err := http.NewRequest("GET", url, nil)
+		Expect(err).To(Succeed())
+		return request
+	}
+
+	Describe("GetBurndown", func() {
+		It("returns the burndown of the sprint", func() {
+			mockReporter.EXPECT().Burndown(gomock.Any(), "1").Return(&models.Burndown{
+				SprintID: "1",
+				Days:     []models.BurndownDay{{Day: "2026-01-05", Committed: 8, Remaining: 8, RemainingTasks: 2, Ideal: 8}},
+				Times:    models.FlowTimes{Transitions: []models.TransitionTimes{}},
+			}, nil)
+
+			handler.GetBurndown(responseRecorder, newRequest("/reports/burndown?sprint=1"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Body.String()).To(MatchJSON(`{"sprint_id":"1",
+				"days":[{"day":"2026-01-05","committed":8,"remaining":8,"remaining_tasks":2,"ideal":8}],
+				"times":{"lead_time":{"count":0,"p50":0,"p85":0,"p95":0},"cycle_time":{"count":0,"p50":0,"p85":0,"p95":0},"transitions":[]}}`))
+		})
+
+		It("requires a sprint", func() {
+			handler.GetBurndown(responseRecorder, newRequest("/reports/burndown"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		DescribeTable("maps the errors",
+			func(err error, expected int) {
+				mockReporter.EXPECT().Burndown(gomock.Any(), "1").Return(nil, err)
+
+				handler.GetBurndown(responseRecorder, newRequest("/reports/burndown?sprint=1"))
+				Expect(responseRecorder.Code).To(Equal(expected))
+			},
+			Entry("missing sprint", service.ErrNotFound, http.StatusNotFound),
+			Entry("failure", errors.New("db error"), http.StatusInternalServerError),
+		)
+	})
+
+	Describe("GetCumulativeFlow", func() {
+		date := func(day int) time.Time {
+			return time.Date(2026, time.January, day, 0, 0, 0, 0, time.UTC)
+		}
+
+		It("returns the cumulative flow of the dates", func() {
+			mockReporter.EXPECT().CumulativeFlow(gomock.Any(), date(5), date(6)).Return(&models.CumulativeFlow{
+				From: "2026-01-05", To: "2026-01-06", Statuses: []string{"pending"},
+				Days: []models.FlowDay{{Day: "2026-01-05", Counts: map[string]int{"pending": 2}}, {Day: "2026-01-06", Counts: map[string]int{"pending": 1}}},
+				Times: models.FlowTimes{Transitions: []models.TransitionTimes{
+					{From: "pending", To: "done", Percentiles: models.Percentiles{Count: 1, P50: 60, P85: 60, P95: 60}},
+				}},
+			}, nil)
+
+			handler.GetCumulativeFlow(responseRecorder, newRequest("/reports/cfd?from=2026-01-05&to=2026-01-06"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring(`"days":[{"day":"2026-01-05","counts":{"pending":2}}`))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring(`"transitions":[{"from":"pending","to":"done","count":1,"p50":60,"p85":60,"p95":60}]`))
+		})
+
+		It("defaults to the 30 days up to today", func() {
+			today := time.Now().UTC().Truncate(24 * time.Hour)
+			mockReporter.EXPECT().CumulativeFlow(gomock.Any(), today.AddDate(0, 0, -29), today).Return(&models.CumulativeFlow{}, nil)
+
+			handler.GetCumulativeFlow(responseRecorder, newRequest("/reports/cfd"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		})
+
+		It("defaults from to the 30 days up to to", func() {
+			mockReporter.EXPECT().CumulativeFlow(gomock.Any(), date(1).AddDate(0, 0, -29), date(1)).Return(&models.CumulativeFlow{}, nil)
+
+			handler.GetCumulativeFlow(responseRecorder, newRequest("/reports/cfd?to=2026-01-01"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		})
+
+		DescribeTable("refuses invalid ranges",
+			func(query, message string) {
+				handler.GetCumulativeFlow(responseRecorder, newRequest("/reports/cfd?"+query))
+				Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(responseRecorder.Body.String()).To(ContainSubstring(message))
+			},
+			Entry("bad from", "from=2026-01-05T00:00:00Z", "Invalid from"),
+			Entry("bad to", "to=01/05/2026", "Invalid to"),
+			Entry("from after to", "from=2026-01-06&to=2026-01-05", "expected to or before"),
+			Entry("more than a year", "from=2024-12-31&to=2026-01-01", "at most 366 days"),
+		)
+
+		It("accepts 366 days", func() {
+			mockReporter.EXPECT().CumulativeFlow(gomock.Any(), gomock.Any(), gomock.Any()).Return(&models.CumulativeFlow{}, nil)
+
+			handler.GetCumulativeFlow(responseRecorder, newRequest("/reports/cfd?from=2025-01-01&to=2026-01-01"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		})
+
+		It("returns the errors of the reporter", func() {
+			mockReporter.EXPECT().CumulativeFlow(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+
+			handler.GetCumulativeFlow(responseRecorder, newRequest("/reports/cfd"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -6,16 +6,33 @@ import (
 	"errors"
 	"github.com/gorilla/mux"
 	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/rrule"
 	"github.com/saarzur123/task-management/backend/service"
 	"net/http"
+	"net/url"
+	"strconv"
 )
 
 type TaskHandler struct {
 	DB service.TaskRepository
+	// Pages serves GET /tasks a page at a time when the after or limit query
+	// parameters are set. Without it all the tasks are returned.
+	Pages service.TaskPageReader
+	// Time sets the time logged on the task returned by GET /tasks/{id}.
+	Time service.TaskTimeReader
 }
 
 const (
-	invalidInput = "Invalid input"
+	invalidInput      = "Invalid input"
+	invalidRecurrence = "Invalid recurrence, expected an RRULE with FREQ=DAILY, WEEKLY or MONTHLY " +
+		"and optionally INTERVAL, BYDAY, COUNT or UNTIL"
+	missingDueAt    = "Invalid recurrence, a recurring task needs a due_at"
+	invalidPoints   = "Invalid story_points, expected 0 or more"
+	unknownSprint   = "Invalid sprint_id, no such sprint"
+	jsonContentType = "application/json"
+
+	defaultTaskPageLimit = 100
+	maxTaskPageLimit     = 1000
 )
 
 func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
@@ -24,11 +41,20 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, invalidInput, http.StatusBadRequest)
 		return
 	}
-	err := h.DB.Create(&task)
+	if message := checkTask(&task); message != "" {
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+	err := h.DB.Create(r.Context(), &task)
 	if err != nil {
+		if errors.Is(err, service.ErrSprintNotFound) {
+			http.Error(w, unknownSprint, http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	w.Header().Set("Content-Type", jsonContentType)
 	w.WriteHeader(http.StatusCreated)
 	err = json.NewEncoder(w).Encode(task)
 	if err != nil {
@@ -37,12 +63,86 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, _ *http.Request) {
-	tasks, err := h.DB.GetAll()
+// checkTask returns why task is invalid, or an empty string, see
+// checkRecurrence.
+func checkTask(task *models.Task) string {
+	if task.StoryPoints < 0 {
+		return invalidPoints
+	}
+	return checkRecurrence(task)
+}
+
+// checkRecurrence puts the recurrence of task in its canonical form, or
+// returns why it is invalid.
+func checkRecurrence(task *models.Task) string {
+	if task.Recurrence == "" {
+		return ""
+	}
+	if task.DueAt == nil {
+		return missingDueAt
+	}
+	rule, err := rrule.Parse(task.Recurrence)
+	if err != nil {
+		return invalidRecurrence
+	}
+	task.Recurrence = rule.String()
+	return ""
+}
+
+func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if h.Pages != nil && (query.Has("after") || query.Has("limit")) {
+		h.getTaskPage(w, r)
+		return
+	}
+
+	tasks, err := h.DB.GetAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(tasks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// getTaskPage returns up to limit tasks (100 by default) with an ID greater
+// than after. A full page links to the next one with a Link header.
+func (h *TaskHandler) getTaskPage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	after := query.Get("after")
+	if after != "" {
+		if _, err := strconv.ParseUint(after, 10, 63); err != nil {
+			http.Error(w, "Invalid after", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := defaultTaskPageLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxTaskPageLimit {
+			http.Error(w, "Invalid limit, expected 1 to "+strconv.Itoa(maxTaskPageLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	tasks, err := h.Pages.GetPage(r.Context(), after, limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	if len(tasks) == limit {
+		next := url.Values{"after": {tasks[len(tasks)-1].ID}, "limit": {strconv.Itoa(limit)}}
+		w.Header().Set("Link", "</tasks?"+next.Encode()+`>; rel="next"`)
+	}
+	w.Header().Set("Content-Type", jsonContentType)
 	err = json.NewEncoder(w).Encode(tasks)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -53,7 +153,7 @@ func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, _ *http.Request) {
 func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	task, err := h.DB.GetByID(id)
+	task, err := h.DB.GetByID(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			http.Error(w, "Task not found", http.StatusNotFound)
@@ -62,6 +162,13 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if h.Time != nil {
+		if task.TimeSpent, err = h.Time.TaskTime(r.Context(), id); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", jsonContentType)
 	err = json.NewEncoder(w).Encode(task)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -78,15 +185,24 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	task.ID = id
-	err := h.DB.Update(&task)
+	if message := checkTask(&task); message != "" {
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+	err := h.DB.Update(r.Context(), &task)
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
 			http.Error(w, "Task not found", http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, service.ErrSprintNotFound) {
+			http.Error(w, unknownSprint, http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	w.Header().Set("Content-Type", jsonContentType)
 	w.WriteHeader(http.StatusOK)
 	err = json.NewEncoder(w).Encode(task)
 	if err != nil {
@@ -98,7 +214,7 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	err := h.DB.Delete(id)
+	err := h.DB.Delete(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
 			http.Error(w, "Task not found", http.StatusNotFound)
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -14,6 +15,7 @@ import (
 	"github.com/saarzur123/task-management/backend/service"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -60,7 +62,7 @@ var _ = Describe("TaskHandler", func() {
 		})
 
 		It("should create a task successfully", func() {
-			mockDB.EXPECT().Create(&task).Return(nil)
+			mockDB.EXPECT().Create(gomock.Any(), &task).Return(nil)
 
 			handler.CreateTask(responseRecorder, request)
 			Expect(responseRecorder.Code).To(Equal(http.StatusCreated))
@@ -78,8 +80,52 @@ var _ = Describe("TaskHandler", func() {
 			Expect(responseRecorder.Body.String()).To(ContainSubstring("Invalid input"))
 		})
 
+		It("creates a recurring task with its rule in canonical form", func() {
+			mockDB.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *models.Task) error {
+				Expect(task.Recurrence).To(Equal("FREQ=WEEKLY;BYDAY=MO,TH"))
+				Expect(task.DueAt).NotTo(BeNil())
+				return nil
+			})
+			request, err := http.NewRequest("POST", "/tasks", strings.NewReader(
+				`{"title":"Standup","due_at":"2026-01-05T09:00:00Z","recurrence":"RRULE:freq=weekly;interval=1;byday=MO,TH"}`))
+			Expect(err).To(Succeed())
+
+			handler.CreateTask(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusCreated))
+		})
+
+		DescribeTable("returns 400 for an invalid recurrence",
+			func(body, message string) {
+				request, err := http.NewRequest("POST", "/tasks", strings.NewReader(body))
+				Expect(err).To(Succeed())
+
+				handler.CreateTask(responseRecorder, request)
+				Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(responseRecorder.Body.String()).To(ContainSubstring(message))
+			},
+			Entry("unsupported rule", `{"title":"Standup","due_at":"2026-01-05T09:00:00Z","recurrence":"FREQ=YEARLY"}`, "Invalid recurrence, expected an RRULE"),
+			Entry("without due_at", `{"title":"Standup","recurrence":"FREQ=DAILY"}`, "a recurring task needs a due_at"),
+		)
+
+		It("returns 400 for negative story points", func() {
+			request, err := http.NewRequest("POST", "/tasks", strings.NewReader(`{"title":"Task","story_points":-3}`))
+			Expect(err).To(Succeed())
+
+			handler.CreateTask(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring("Invalid story_points"))
+		})
+
+		It("returns 400 for a missing sprint", func() {
+			mockDB.EXPECT().Create(gomock.Any(), &task).Return(service.ErrSprintNotFound)
+
+			handler.CreateTask(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring("Invalid sprint_id"))
+		})
+
 		It("returns 500 when database error occurred", func() {
-			mockDB.EXPECT().Create(&task).Return(errMock)
+			mockDB.EXPECT().Create(gomock.Any(), &task).Return(errMock)
 
 			handler.CreateTask(responseRecorder, request)
 			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
@@ -87,7 +133,7 @@ var _ = Describe("TaskHandler", func() {
 		})
 
 		It("returns 500 when encoding tasks to JSON fails", func() {
-			mockDB.EXPECT().Create(&task).Return(nil)
+			mockDB.EXPECT().Create(gomock.Any(), &task).Return(nil)
 
 			faultyResponseRecorder := &FaultyResponseWriter{Body: failedEncodeBody}
 			handler.CreateTask(faultyResponseRecorder, request)
@@ -105,7 +151,7 @@ var _ = Describe("TaskHandler", func() {
 		})
 
 		It("succeeds to return all tasks", func() {
-			mockDB.EXPECT().GetAll().Return(multipleTasks, nil)
+			mockDB.EXPECT().GetAll(gomock.Any()).Return(multipleTasks, nil)
 
 			handler.GetAllTasks(responseRecorder, request)
 			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
@@ -116,7 +162,7 @@ var _ = Describe("TaskHandler", func() {
 		})
 
 		It("returns 500 when database error occurred", func() {
-			mockDB.EXPECT().GetAll().Return(nil, errMock)
+			mockDB.EXPECT().GetAll(gomock.Any()).Return(nil, errMock)
 			handler.GetAllTasks(responseRecorder, request)
 
 			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
@@ -124,7 +170,7 @@ var _ = Describe("TaskHandler", func() {
 		})
 
 		It("doesn't return error when no tasks were found", func() {
-			mockDB.EXPECT().GetAll().Return([]models.Task{}, nil)
+			mockDB.EXPECT().GetAll(gomock.Any()).Return([]models.Task{}, nil)
 			handler.GetAllTasks(responseRecorder, request)
 
 			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
@@ -134,7 +180,7 @@ var _ = Describe("TaskHandler", func() {
 		})
 
 		It("returns 500 when encoding tasks to JSON fails", func() {
-			mockDB.EXPECT().GetAll().Return(multipleTasks, nil)
+			mockDB.EXPECT().GetAll(gomock.Any()).Return(multipleTasks, nil)
 
 			faultyResponseRecorder := &FaultyResponseWriter{Body: failedEncodeBody}
 			handler.GetAllTasks(faultyResponseRecorder, request)
@@ -144,6 +190,69 @@ var _ = Describe("TaskHandler", func() {
 		})
 	})
 
+	Describe("GetAllTasks pages", func() {
+		var (
+			mockPages *serviceMock.MockTaskPageReader
+			page      = []models.Task{{ID: "3", Title: "Task 3"}, {ID: "4", Title: "Task 4"}}
+		)
+
+		BeforeEach(func() {
+			mockPages = serviceMock.NewMockTaskPageReader(gomock.NewController(GinkgoT()))
+			handler.Pages = mockPages
+		})
+
+		get := func(target string) {
+			request = httptest.NewRequest(http.MethodGet, target, nil)
+			handler.GetAllTasks(responseRecorder, request)
+		}
+
+		It("links a full page to the next one", func() {
+			mockPages.EXPECT().GetPage(gomock.Any(), "2", 2).Return(page, nil)
+
+			get("/tasks?after=2&limit=2")
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Header().Get("Link")).To(Equal(`</tasks?after=4&limit=2>; rel="next"`))
+			var responseTasks []models.Task
+			Expect(json.NewDecoder(responseRecorder.Body).Decode(&responseTasks)).To(Succeed())
+			Expect(responseTasks).To(Equal(page))
+		})
+
+		It("has no next page after a partial one", func() {
+			mockPages.EXPECT().GetPage(gomock.Any(), "", 100).Return(page, nil)
+
+			get("/tasks?after=")
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Header().Get("Link")).To(BeEmpty())
+		})
+
+		It("returns all the tasks without page parameters", func() {
+			mockDB.EXPECT().GetAll(gomock.Any()).Return(multipleTasks, nil)
+
+			get("/tasks")
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		})
+
+		DescribeTable("rejects invalid parameters",
+			func(target, message string) {
+				get(target)
+				Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(responseRecorder.Body.String()).To(ContainSubstring(message))
+			},
+			Entry("malformed after", "/tasks?after=abc", "Invalid after"),
+			Entry("negative after", "/tasks?after=-1", "Invalid after"),
+			Entry("zero limit", "/tasks?limit=0", "Invalid limit"),
+			Entry("limit above the maximum", "/tasks?limit=1001", "Invalid limit"),
+		)
+
+		It("returns 500 when database error occurred", func() {
+			mockPages.EXPECT().GetPage(gomock.Any(), "", 10).Return(nil, errMock)
+
+			get("/tasks?limit=10")
+			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring(errMock.Error()))
+		})
+	})
+
 	Describe("GetTask", func() {
 
 		BeforeEach(func() {
@@ -154,7 +263,7 @@ var _ = Describe("TaskHandler", func() {
 
 		It("returns the task by ID", func() {
 			task := models.Task{ID: "1", Title: "Task 1"}
-			mockDB.EXPECT().GetByID("1").Return(&task, nil)
+			mockDB.EXPECT().GetByID(gomock.Any(), "1").Return(&task, nil)
 
 			handler.GetTask(responseRecorder, request)
 			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
@@ -163,8 +272,30 @@ var _ = Describe("TaskHandler", func() {
 			Expect(responseTask).To(Equal(task))
 		})
 
+		It("adds the time logged on the task", func() {
+			mockTime := serviceMock.NewMockTaskTimeReader(gomock.NewController(GinkgoT()))
+			handler.Time = mockTime
+			mockDB.EXPECT().GetByID(gomock.Any(), "1").Return(&models.Task{ID: "1", Title: "Task 1"}, nil)
+			mockTime.EXPECT().TaskTime(gomock.Any(), "1").Return(int64(5400), nil)
+
+			handler.GetTask(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Body.String()).To(MatchJSON(
+				`{"id":"1","title":"Task 1","description":"","status":"","created_at":"0001-01-01T00:00:00Z","time_spent":5400}`))
+		})
+
+		It("returns 500 when the time logged can't be read", func() {
+			mockTime := serviceMock.NewMockTaskTimeReader(gomock.NewController(GinkgoT()))
+			handler.Time = mockTime
+			mockDB.EXPECT().GetByID(gomock.Any(), "1").Return(&models.Task{ID: "1", Title: "Task 1"}, nil)
+			mockTime.EXPECT().TaskTime(gomock.Any(), "1").Return(int64(0), errMock)
+
+			handler.GetTask(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+
 		It("returns 404 if task not found", func() {
-			mockDB.EXPECT().GetByID("1").Return(nil, sql.ErrNoRows)
+			mockDB.EXPECT().GetByID(gomock.Any(), "1").Return(nil, sql.ErrNoRows)
 
 			handler.GetTask(responseRecorder, request)
 			Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
@@ -172,7 +303,7 @@ var _ = Describe("TaskHandler", func() {
 		})
 
 		It("returns 500 when database error occurred", func() {
-			mockDB.EXPECT().GetByID("1").Return(nil, errMock)
+			mockDB.EXPECT().GetByID(gomock.Any(), "1").Return(nil, errMock)
 			handler.GetTask(responseRecorder, request)
 
 			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
@@ -181,7 +312,7 @@ var _ = Describe("TaskHandler", func() {
 
 		It("returns 500 when encoding tasks to JSON fails", func() {
 			task := models.Task{ID: "1", Title: "Task 1"}
-			mockDB.EXPECT().GetByID("1").Return(&task, nil)
+			mockDB.EXPECT().GetByID(gomock.Any(), "1").Return(&task, nil)
 
 			faultyResponseRecorder := &FaultyResponseWriter{Body: failedEncodeBody}
 			handler.GetTask(faultyResponseRecorder, request)
@@ -205,7 +336,7 @@ var _ = Describe("TaskHandler", func() {
 		})
 
 		It("succeeds to update task", func() {
-			mockDB.EXPECT().Update(&task).Return(nil)
+			mockDB.EXPECT().Update(gomock.Any(), &task).Return(nil)
 			handler.UpdateTask(responseRecorder, request)
 
 			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
@@ -223,8 +354,17 @@ var _ = Describe("TaskHandler", func() {
 			Expect(responseRecorder.Body.String()).To(ContainSubstring("Invalid input"))
 		})
 
+		It("returns 400 for an invalid recurrence", func() {
+			request, err := http.NewRequest("PUT", "/tasks/1", strings.NewReader(`{"title":"Standup","recurrence":"FREQ=DAILY;COUNT=0"}`))
+			Expect(err).To(Succeed())
+			handler.UpdateTask(responseRecorder, request)
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring("Invalid recurrence"))
+		})
+
 		It("should return 500 when database error occurred", func() {
-			mockDB.EXPECT().Update(&task).Return(errMock)
+			mockDB.EXPECT().Update(gomock.Any(), &task).Return(errMock)
 
 			handler.UpdateTask(responseRecorder, request)
 			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
@@ -232,15 +372,23 @@ var _ = Describe("TaskHandler", func() {
 		})
 
 		It("should return 404 when didn't find row to update", func() {
-			mockDB.EXPECT().Update(&task).Return(service.ErrNotFound)
+			mockDB.EXPECT().Update(gomock.Any(), &task).Return(service.ErrNotFound)
 
 			handler.UpdateTask(responseRecorder, request)
 			Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
 			Expect(responseRecorder.Body.String()).To(ContainSubstring("Task not found"))
 		})
 
+		It("returns 400 for a missing sprint", func() {
+			mockDB.EXPECT().Update(gomock.Any(), &task).Return(service.ErrSprintNotFound)
+
+			handler.UpdateTask(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring("Invalid sprint_id"))
+		})
+
 		It("returns 500 when encoding tasks to JSON fails", func() {
-			mockDB.EXPECT().Update(&task).Return(nil)
+			mockDB.EXPECT().Update(gomock.Any(), &task).Return(nil)
 
 			faultyResponseRecorder := &FaultyResponseWriter{Body: failedEncodeBody}
 			handler.UpdateTask(faultyResponseRecorder, request)
@@ -259,14 +407,14 @@ var _ = Describe("TaskHandler", func() {
 		})
 
 		It("succeeds to delete the task", func() {
-			mockDB.EXPECT().Delete("1").Return(nil)
+			mockDB.EXPECT().Delete(gomock.Any(), "1").Return(nil)
 			handler.DeleteTask(responseRecorder, request)
 
 			Expect(responseRecorder.Code).To(Equal(http.StatusNoContent))
 		})
 
 		It("returns 500 when database error occurred", func() {
-			mockDB.EXPECT().Delete("1").Return(errMock)
+			mockDB.EXPECT().Delete(gomock.Any(), "1").Return(errMock)
 			handler.DeleteTask(responseRecorder, request)
 
 			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
@@ -274,7 +422,7 @@ var _ = Describe("TaskHandler", func() {
 		})
 
 		It("should return 404 when didn't find row to delete", func() {
-			mockDB.EXPECT().Delete("1").Return(service.ErrNotFound)
+			mockDB.EXPECT().Delete(gomock.Any(), "1").Return(service.ErrNotFound)
 
 			handler.DeleteTask(responseRecorder, request)
 			Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/saarzur123/task-management/backend/logging"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+type HealthHandler struct {
+	DB           service.ReadinessChecker
+	BuildInfo    models.BuildInfo
+	shuttingDown atomic.Bool
+}
+
+const (
+	readinessTimeout = 2 * time.Second
+)
+
+// SetShuttingDown makes the readiness probe fail so no new traffic is routed
+// to the instance while it drains.
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Healthz reports that the process is alive.
+func (h *HealthHandler) Healthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n")) // nolint: errcheck
+}
+
+// Readyz reports whether the instance can serve traffic: the database answers,
+// its migrations are applied and the server isn't shutting down.
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if h.shuttingDown.Load() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	if err := h.DB.Ready(ctx); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "not ready", slog.Any("error", err))
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n")) // nolint: errcheck
+}
+
+func (h *HealthHandler) Version(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", jsonContentType)
+	err := json.NewEncoder(w).Encode(h.BuildInfo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("HealthHandler", func() {
+	var (
+		mockDB           *serviceMock.MockReadinessChecker
+		handler          *HealthHandler
+		responseRecorder *httptest.ResponseRecorder
+		request          = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	)
+
+	BeforeEach(func() {
+		mockDB = serviceMock.NewMockReadinessChecker(gomock.NewController(GinkgoT()))
+		handler = &HealthHandler{DB: mockDB, BuildInfo: models.BuildInfo{GitSHA: "abc123", BuildTime: "2024-11-01T10:00:00Z", GoVersion: "go1.23.2"}}
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	It("reports liveness without touching the database", func() {
+		handler.Healthz(responseRecorder, request)
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("is ready when the database is", func() {
+		mockDB.EXPECT().Ready(gomock.Any()).Return(nil)
+
+		handler.Readyz(responseRecorder, request)
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("isn't ready when the database check fails", func() {
+		mockDB.EXPECT().Ready(gomock.Any()).Return(errMock)
+
+		handler.Readyz(responseRecorder, request)
+		Expect(responseRecorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(responseRecorder.Body.String()).To(Equal("not ready\n"))
+		Expect(responseRecorder.Body.String()).ToNot(ContainSubstring(errMock.Error()))
+	})
+
+	It("isn't ready once shutting down", func() {
+		handler.SetShuttingDown()
+
+		handler.Readyz(responseRecorder, request)
+		Expect(responseRecorder.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("returns the build info", func() {
+		handler.Version(responseRecorder, request)
+		var info models.BuildInfo
+		Expect(json.NewDecoder(responseRecorder.Body).Decode(&info)).To(Succeed())
+		Expect(info).To(Equal(handler.BuildInfo))
+	})
+})
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/notify"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultInboxLimit    = 50
+	maxInboxLimit        = 100
+	notificationsNeedKey = "Notifications need an API key"
+)
+
+type NotificationHandler struct {
+	Preferences service.NotificationPreferencesRepository
+	Inbox       service.NotificationInbox
+}
+
+// UserID returns the user the caller of r acts as: the name of its API key,
+// which is what tasks are assigned to.
+func UserID(r *http.Request) string {
+	return service.UserFromContext(r.Context())
+}
+
+// requireUser returns the user the caller of r acts as, or answers 401 with
+// message when r has no API key: the callers without one would all share the
+// same inbox, preferences and followed tasks.
+func requireUser(w http.ResponseWriter, r *http.Request, message string) (string, bool) {
+	if _, ok := service.APIKeyFromContext(r.Context()); !ok {
+		http.Error(w, message, http.StatusUnauthorized)
+		return "", false
+	}
+	return UserID(r), true
+}
+
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r, notificationsNeedKey)
+	if !ok {
+		return
+	}
+	prefs, err := h.Preferences.GetPreferences(r.Context(), user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(prefs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdatePreferences replaces the preferences of the caller.
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r, notificationsNeedKey)
+	if !ok {
+		return
+	}
+	var prefs models.NotificationPreferences
+	if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil || prefs.Channels == nil {
+		http.Error(w, invalidInput, http.StatusBadRequest)
+		return
+	}
+	if err := notify.ValidatePreferences(&prefs); err != nil {
+		_, reason, _ := strings.Cut(err.Error(), ": ")
+		http.Error(w, "Invalid notification preferences, "+reason, http.StatusBadRequest)
+		return
+	}
+	prefs.UserID = user
+	err := h.Preferences.SavePreferences(r.Context(), &prefs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(prefs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetNotifications returns the newest notifications of the caller, up to limit
+// (50 by default) with an ID lower than before, and how many are unread. The
+// unread query parameter leaves out the read ones. A full page links to the
+// next one with a Link header.
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r, notificationsNeedKey)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	before := query.Get("before")
+	if before != "" {
+		if _, err := strconv.ParseUint(before, 10, 63); err != nil {
+			http.Error(w, "Invalid before", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := defaultInboxLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxInboxLimit {
+			http.Error(w, "Invalid limit, expected 1 to "+strconv.Itoa(maxInboxLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	unreadOnly := false
+	if value := query.Get("unread"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid unread, expected true or false", http.StatusBadRequest)
+			return
+		}
+		unreadOnly = parsed
+	}
+
+	inbox, err := h.Inbox.Inbox(r.Context(), user, before, limit, unreadOnly)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(inbox.Notifications) == limit {
+		next := url.Values{"before": {inbox.Notifications[len(inbox.Notifications)-1].ID}, "limit": {strconv.Itoa(limit)}}
+		if unreadOnly {
+			next.Set("unread", "true")
+		}
+		w.Header().Set("Link", "</users/me/notifications?"+next.Encode()+`>; rel="next"`)
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(inbox)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *NotificationHandler) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r, notificationsNeedKey)
+	if !ok {
+		return
+	}
+	err := h.Inbox.MarkRead(r.Context(), user, mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			http.Error(w, "Notification not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r, notificationsNeedKey)
+	if !ok {
+		return
+	}
+	if err := h.Inbox.MarkAllRead(r.Context(), user); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("NotificationHandler", func() {
+	var (
+		mockPreferences  *serviceMock.MockNotificationPreferencesRepository
+		mockInbox        *serviceMock.MockNotificationInbox
+		handler          *NotificationHandler
+		responseRecorder *httptest.ResponseRecorder
+		key              = &models.APIKey{Name: "ci"}
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockPreferences = serviceMock.NewMockNotificationPreferencesRepository(mockCtrl)
+		mockInbox = serviceMock.NewMockNotificationInbox(mockCtrl)
+		handler = &NotificationHandler{Preferences: mockPreferences, Inbox: mockInbox}
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	newRequest := func(method, body string) *http.Request {
+		request, err := http.NewRequest(method, "/users/me/notification-preferences", bytes.NewBufferString(body))
+		Expect(err).To(Succeed())
+		return request.WithContext(service.ContextWithAPIKey(request.Context(), key))
+	}
+
+	It("returns the preferences of the caller", func() {
+		mockPreferences.EXPECT().GetPreferences(gomock.Any(), "ci").
+			Return(&models.NotificationPreferences{UserID: "ci", Channels: []string{models.ChannelInApp}}, nil)
+
+		handler.GetPreferences(responseRecorder, newRequest("GET", ""))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(responseRecorder.Body.String()).To(MatchJSON(`{"user_id":"ci","channels":["in_app"]}`))
+	})
+
+	It("returns 500 when the preferences can't be read", func() {
+		mockPreferences.EXPECT().GetPreferences(gomock.Any(), "ci").Return(nil, errMock)
+
+		handler.GetPreferences(responseRecorder, newRequest("GET", ""))
+		Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("saves the preferences of the caller", func() {
+		mockPreferences.EXPECT().SavePreferences(gomock.Any(), &models.NotificationPreferences{
+			UserID: "ci", Channels: []string{models.ChannelEmail}, Email: "ci@example.com",
+			QuietStart: "22:00", QuietEnd: "07:00", TimeZone: "Europe/Paris",
+		}).Return(nil)
+
+		handler.UpdatePreferences(responseRecorder, newRequest("PUT",
+			`{"user_id":"someone-else","channels":["email"],"email":"ci@example.com","quiet_start":"22:00","quiet_end":"07:00","time_zone":"Europe/Paris"}`))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		var prefs models.NotificationPreferences
+		Expect(json.NewDecoder(responseRecorder.Body).Decode(&prefs)).To(Succeed())
+		Expect(prefs.UserID).To(Equal("ci"))
+	})
+
+	DescribeTable("rejects invalid preferences",
+		func(body, expected string) {
+			handler.UpdatePreferences(responseRecorder, newRequest("PUT", body))
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring(expected))
+		},
+		Entry("malformed", `{`, invalidInput),
+		Entry("no channels", `{}`, invalidInput),
+		Entry("unknown channel", `{"channels":["sms"]}`, `Invalid notification preferences, unknown channel "sms"`),
+		Entry("email without an address", `{"channels":["email"]}`, "Invalid notification preferences, the email channel needs a valid email"),
+		Entry("invalid quiet hours", `{"channels":[],"quiet_start":"25:00","quiet_end":"07:00"}`, "Invalid notification preferences, quiet hours are HH:MM"),
+	)
+
+	It("returns 500 when the preferences can't be saved", func() {
+		mockPreferences.EXPECT().SavePreferences(gomock.Any(), gomock.Any()).Return(errMock)
+
+		handler.UpdatePreferences(responseRecorder, newRequest("PUT", `{"channels":["in_app"]}`))
+		Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("acts as anonymous without an API key", func() {
+		request, err := http.NewRequest("GET", "/users/me/notification-preferences", nil)
+		Expect(err).To(Succeed())
+		Expect(UserID(request)).To(Equal("anonymous"))
+	})
+
+	DescribeTable("refuses the callers without an API key, who would share one inbox",
+		func(serve func(http.ResponseWriter, *http.Request), method string) {
+			request, err := http.NewRequest(method, "/users/me/notifications", bytes.NewBufferString(`{"channels":["in_app"]}`))
+			Expect(err).To(Succeed())
+
+			serve(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring("Notifications need an API key"))
+		},
+		Entry("reading the preferences", func(w http.ResponseWriter, r *http.Request) { handler.GetPreferences(w, r) }, "GET"),
+		Entry("saving the preferences", func(w http.ResponseWriter, r *http.Request) { handler.UpdatePreferences(w, r) }, "PUT"),
+		Entry("reading the inbox", func(w http.ResponseWriter, r *http.Request) { handler.GetNotifications(w, r) }, "GET"),
+		Entry("marking a notification read", func(w http.ResponseWriter, r *http.Request) { handler.MarkNotificationRead(w, r) }, "POST"),
+		Entry("marking the inbox read", func(w http.ResponseWriter, r *http.Request) { handler.MarkAllNotificationsRead(w, r) }, "POST"),
+	)
+
+	Describe("inbox", func() {
+		notifications := func(ids ...string) []models.Notification {
+			page := make([]models.Notification, 0, len(ids))
+			for _, id := range ids {
+				page = append(page, models.Notification{ID: id, UserID: "ci", TaskID: "1", Kind: models.EventTaskUpdated})
+			}
+			return page
+		}
+
+		newInboxRequest := func(method, url string) *http.Request {
+			request, err := http.NewRequest(method, url, nil)
+			Expect(err).To(Succeed())
+			return request.WithContext(service.ContextWithAPIKey(request.Context(), key))
+		}
+
+		It("returns the notifications of the caller", func() {
+			mockInbox.EXPECT().Inbox(gomock.Any(), "ci", "", 50, false).
+				Return(&models.Inbox{Notifications: notifications("3"), Unread: 1}, nil)
+
+			handler.GetNotifications(responseRecorder, newInboxRequest("GET", "/users/me/notifications"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Header().Get("Link")).To(BeEmpty())
+			Expect(responseRecorder.Body.String()).To(MatchJSON(
+				`{"notifications":[{"id":"3","user_id":"ci","task_id":"1","kind":"task.updated","message":"","created_at":"0001-01-01T00:00:00Z"}],"unread":1}`))
+		})
+
+		It("links a full page to the next one", func() {
+			mockInbox.EXPECT().Inbox(gomock.Any(), "ci", "9", 2, true).
+				Return(&models.Inbox{Notifications: notifications("8", "5"), Unread: 4}, nil)
+
+			handler.GetNotifications(responseRecorder, newInboxRequest("GET", "/users/me/notifications?before=9&limit=2&unread=true"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Header().Get("Link")).To(Equal(`</users/me/notifications?before=5&limit=2&unread=true>; rel="next"`))
+		})
+
+		DescribeTable("rejects invalid queries",
+			func(query, expected string) {
+				handler.GetNotifications(responseRecorder, newInboxRequest("GET", "/users/me/notifications?"+query))
+				Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(responseRecorder.Body.String()).To(ContainSubstring(expected))
+			},
+			Entry("before", "before=x", "Invalid before"),
+			Entry("limit too low", "limit=0", "Invalid limit, expected 1 to 100"),
+			Entry("limit too high", "limit=101", "Invalid limit, expected 1 to 100"),
+			Entry("unread", "unread=maybe", "Invalid unread, expected true or false"),
+		)
+
+		It("returns 500 when the notifications can't be read", func() {
+			mockInbox.EXPECT().Inbox(gomock.Any(), "ci", "", 50, false).Return(nil, errMock)
+
+			handler.GetNotifications(responseRecorder, newInboxRequest("GET", "/users/me/notifications"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+
+		DescribeTable("marks a notification read",
+			func(err error, expected int) {
+				mockInbox.EXPECT().MarkRead(gomock.Any(), "ci", "3").Return(err)
+
+				request := mux.SetURLVars(newInboxRequest("POST", "/users/me/notifications/3/read"), map[string]string{"id": "3"})
+				handler.MarkNotificationRead(responseRecorder, request)
+				Expect(responseRecorder.Code).To(Equal(expected))
+			},
+			Entry("read", nil, http.StatusNoContent),
+			Entry("not the caller's", service.ErrNotFound, http.StatusNotFound),
+			Entry("storage error", errMock, http.StatusInternalServerError),
+		)
+
+		DescribeTable("marks all the notifications read",
+			func(err error, expected int) {
+				mockInbox.EXPECT().MarkAllRead(gomock.Any(), "ci").Return(err)
+
+				handler.MarkAllNotificationsRead(responseRecorder, newInboxRequest("POST", "/users/me/notifications/read-all"))
+				Expect(responseRecorder.Code).To(Equal(expected))
+			},
+			Entry("read", nil, http.StatusNoContent),
+			Entry("storage error", errMock, http.StatusInternalServerError),
+		)
+	})
+})
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/presence"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+)
+
+type PresenceHandler struct {
+	Hub      *presence.Hub
+	Upgrader websocket.Upgrader
+}
+
+// Connect upgrades the request to a presence WebSocket. The handshake went
+// through the API key middleware, so the viewer is named after the key and
+// relaying edits needs the tasks:write scope.
+func (h *PresenceHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	canEdit := true
+	if key, ok := service.APIKeyFromContext(r.Context()); ok {
+		canEdit = key.HasScope(models.ScopeTasksWrite)
+	}
+
+	conn, err := h.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade already replied with the error.
+		return
+	}
+	h.Hub.Serve(r.Context(), conn, Actor(r), canEdit)
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"io"
+	"net/http"
+	"time"
+)
+
+const sprintNotFound = "Sprint not found"
+
+type SprintHandler struct {
+	DB service.SprintPlanner
+}
+
+func (h *SprintHandler) CreateSprint(w http.ResponseWriter, r *http.Request) {
+	var sprint models.Sprint
+	if err := json.NewDecoder(r.Body).Decode(&sprint); err != nil {
+		http.Error(w, invalidInput, http.StatusBadRequest)
+		return
+	}
+	if message := checkSprint(&sprint); message != "" {
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.CreateSprint(r.Context(), &sprint); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeSprintJSON(w, http.StatusCreated, sprint)
+}
+
+func (h *SprintHandler) GetAllSprints(w http.ResponseWriter, r *http.Request) {
+	sprints, err := h.DB.ListSprints(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeSprintJSON(w, http.StatusOK, sprints)
+}
+
+func (h *SprintHandler) GetSprint(w http.ResponseWriter, r *http.Request) {
+	sprint, err := h.DB.GetSprint(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeSprintError(w, err)
+		return
+	}
+	writeSprintJSON(w, http.StatusOK, sprint)
+}
+
+func (h *SprintHandler) UpdateSprint(w http.ResponseWriter, r *http.Request) {
+	var sprint models.Sprint
+	if err := json.NewDecoder(r.Body).Decode(&sprint); err != nil {
+		http.Error(w, invalidInput, http.StatusBadRequest)
+		return
+	}
+	if message := checkSprint(&sprint); message != "" {
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+	sprint.ID = mux.Vars(r)["id"]
+
+	if err := h.DB.UpdateSprint(r.Context(), &sprint); err != nil {
+		writeSprintError(w, err)
+		return
+	}
+	writeSprintJSON(w, http.StatusOK, sprint)
+}
+
+// DeleteSprint removes the sprint, its tasks going back to the backlog.
+func (h *SprintHandler) DeleteSprint(w http.ResponseWriter, r *http.Request) {
+	if err := h.DB.DeleteSprint(r.Context(), mux.Vars(r)["id"]); err != nil {
+		writeSprintError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SprintHandler) GetSprintTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := h.DB.SprintTasks(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeSprintError(w, err)
+		return
+	}
+	writeSprintJSON(w, http.StatusOK, tasks)
+}
+
+func (h *SprintHandler) GetSprintProgress(w http.ResponseWriter, r *http.Request) {
+	progress, err := h.DB.SprintProgress(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeSprintError(w, err)
+		return
+	}
+	writeSprintJSON(w, http.StatusOK, progress)
+}
+
+// CarryOver moves the unfinished tasks of the sprint to the sprint of the
+// optional to field, the sprint starting next by default.
+func (h *SprintHandler) CarryOver(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		To string `json:"to"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, invalidInput, http.StatusBadRequest)
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if body.To == id {
+		http.Error(w, "Invalid to, a sprint can't carry over to itself", http.StatusBadRequest)
+		return
+	}
+
+	carryOver, err := h.DB.CarryOver(r.Context(), id, body.To)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSprintNotFound):
+			http.Error(w, "Invalid to, no such sprint", http.StatusBadRequest)
+		case errors.Is(err, service.ErrNoNextSprint):
+			http.Error(w, "No sprint starts after this one, create it first", http.StatusConflict)
+		default:
+			writeSprintError(w, err)
+		}
+		return
+	}
+	writeSprintJSON(w, http.StatusOK, carryOver)
+}
+
+// checkSprint returns why sprint is invalid, or an empty string.
+func checkSprint(sprint *models.Sprint) string {
+	if sprint.Name == "" {
+		return "Invalid sprint, expected a name"
+	}
+	start, err := time.Parse(time.DateOnly, sprint.StartDate)
+	if err != nil {
+		return "Invalid start_date, expected a YYYY-MM-DD date"
+	}
+	end, err := time.Parse(time.DateOnly, sprint.EndDate)
+	if err != nil {
+		return "Invalid end_date, expected a YYYY-MM-DD date"
+	}
+	if end.Before(start) {
+		return "Invalid end_date, expected start_date or later"
+	}
+	if sprint.Capacity < 0 {
+		return "Invalid capacity, expected 0 or more"
+	}
+	return ""
+}
+
+func writeSprintError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrNotFound) {
+		http.Error(w, sprintNotFound, http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func writeSprintJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+var _ = Describe("SprintHandler", func() {
+	var (
+		mockPlanner      *serviceMock.MockSprintPlanner
+		handler          *SprintHandler
+		responseRecorder *httptest.ResponseRecorder
+		sprint           = models.Sprint{ID: "1", Name: "Sprint 1", StartDate: "2026-01-05", EndDate: "2026-01-16", Capacity: 20}
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockPlanner = serviceMock.NewMockSprintPlanner(mockCtrl)
+		handler = &SprintHandler{DB: mockPlanner}
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	newRequest := func(method, url, body string) *http.Request {
+		request, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).To(Succeed())
+		return mux.SetURLVars(request, map[string]string{"id": "1"})
+	}
+
+	Describe("CreateSprint", func() {
+		It("creates the sprint", func() {
+			mockPlanner.EXPECT().CreateSprint(gomock.Any(), &models.Sprint{Name: "Sprint 1", StartDate: "2026-01-05", EndDate: "2026-01-16", Capacity: 20}).
+				DoAndReturn(func(_ any, created *models.Sprint) error {
+					created.ID = "1"
+					return nil
+				})
+
+			handler.CreateSprint(responseRecorder, newRequest("POST", "/sprints",
+				`{"name": "Sprint 1", "start_date": "2026-01-05", "end_date": "2026-01-16", "capacity": 20}`))
+			Expect(responseRecorder.Code).To(Equal(http.StatusCreated))
+			Expect(responseRecorder.Body.String()).To(MatchJSON(
+				`{"id":"1","name":"Sprint 1","start_date":"2026-01-05","end_date":"2026-01-16","capacity":20}`))
+		})
+
+		DescribeTable("refuses invalid sprints",
+			func(body, message string) {
+				handler.CreateSprint(responseRecorder, newRequest("POST", "/sprints", body))
+				Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(responseRecorder.Body.String()).To(ContainSubstring(message))
+			},
+			Entry("no name", `{"start_date": "2026-01-05", "end_date": "2026-01-16"}`, "expected a name"),
+			Entry("bad start", `{"name": "S", "start_date": "05/01/2026", "end_date": "2026-01-16"}`, "Invalid start_date"),
+			Entry("no end", `{"name": "S", "start_date": "2026-01-05"}`, "Invalid end_date"),
+			Entry("end before start", `{"name": "S", "start_date": "2026-01-16", "end_date": "2026-01-05"}`, "start_date or later"),
+			Entry("negative capacity", `{"name": "S", "start_date": "2026-01-05", "end_date": "2026-01-05", "capacity": -1}`, "Invalid capacity"),
+			Entry("not JSON", `{`, invalidInput),
+		)
+	})
+
+	It("lists the sprints", func() {
+		mockPlanner.EXPECT().ListSprints(gomock.Any()).Return([]models.Sprint{sprint}, nil)
+
+		handler.GetAllSprints(responseRecorder, newRequest("GET", "/sprints", ""))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(responseRecorder.Body.String()).To(MatchJSON(
+			`[{"id":"1","name":"Sprint 1","start_date":"2026-01-05","end_date":"2026-01-16","capacity":20}]`))
+	})
+
+	It("returns 404 for a missing sprint", func() {
+		mockPlanner.EXPECT().GetSprint(gomock.Any(), "1").Return(nil, service.ErrNotFound)
+
+		handler.GetSprint(responseRecorder, newRequest("GET", "/sprints/1", ""))
+		Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("updates the sprint of the path", func() {
+		mockPlanner.EXPECT().UpdateSprint(gomock.Any(), &sprint).Return(nil)
+
+		handler.UpdateSprint(responseRecorder, newRequest("PUT", "/sprints/1",
+			`{"id": "9", "name": "Sprint 1", "start_date": "2026-01-05", "end_date": "2026-01-16", "capacity": 20}`))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("deletes the sprint", func() {
+		mockPlanner.EXPECT().DeleteSprint(gomock.Any(), "1").Return(nil)
+
+		handler.DeleteSprint(responseRecorder, newRequest("DELETE", "/sprints/1", ""))
+		Expect(responseRecorder.Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("returns the tasks of the sprint", func() {
+		mockPlanner.EXPECT().SprintTasks(gomock.Any(), "1").Return([]models.Task{{ID: "4", Title: "Task 4", StoryPoints: 3, SprintID: "1"}}, nil)
+
+		handler.GetSprintTasks(responseRecorder, newRequest("GET", "/sprints/1/tasks", ""))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(responseRecorder.Body.String()).To(ContainSubstring(`"story_points":3,"sprint_id":"1"`))
+	})
+
+	It("returns the progress of the sprint", func() {
+		mockPlanner.EXPECT().SprintProgress(gomock.Any(), "1").
+			Return(&models.SprintProgress{SprintID: "1", Capacity: 20, Committed: 13, Completed: 5, Remaining: 8, Tasks: 4, DoneTasks: 1}, nil)
+
+		handler.GetSprintProgress(responseRecorder, newRequest("GET", "/sprints/1/progress", ""))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(responseRecorder.Body.String()).To(MatchJSON(
+			`{"sprint_id":"1","capacity":20,"committed":13,"completed":5,"remaining":8,"tasks":4,"done_tasks":1,"over_capacity":false}`))
+	})
+
+	Describe("CarryOver", func() {
+		It("carries the unfinished tasks over to the next sprint", func() {
+			mockPlanner.EXPECT().CarryOver(gomock.Any(), "1", "").
+				Return(&models.CarryOver{From: "1", To: "2", Points: 3, Tasks: []models.Task{{ID: "4", StoryPoints: 3, SprintID: "2"}}}, nil)
+
+			handler.CarryOver(responseRecorder, newRequest("POST", "/sprints/1/carry-over", ""))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring(`"from":"1","to":"2","points":3`))
+		})
+
+		It("carries the unfinished tasks over to the given sprint", func() {
+			mockPlanner.EXPECT().CarryOver(gomock.Any(), "1", "7").Return(&models.CarryOver{From: "1", To: "7", Tasks: []models.Task{}}, nil)
+
+			handler.CarryOver(responseRecorder, newRequest("POST", "/sprints/1/carry-over", `{"to": "7"}`))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		})
+
+		It("refuses to carry over to the same sprint", func() {
+			handler.CarryOver(responseRecorder, newRequest("POST", "/sprints/1/carry-over", `{"to": "1"}`))
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		DescribeTable("maps the errors",
+			func(err error, expected int) {
+				mockPlanner.EXPECT().CarryOver(gomock.Any(), "1", "").Return(nil, err)
+
+				handler.CarryOver(responseRecorder, newRequest("POST", "/sprints/1/carry-over", ""))
+				Expect(responseRecorder.Code).To(Equal(expected))
+			},
+			Entry("missing sprint", service.ErrNotFound, http.StatusNotFound),
+			Entry("missing target", service.ErrSprintNotFound, http.StatusBadRequest),
+			Entry("last sprint", service.ErrNoNextSprint, http.StatusConflict),
+			Entry("failure", errors.New("db error"), http.StatusInternalServerError),
+		)
+	})
+})
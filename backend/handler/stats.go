@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+)
+
+type StatsHandler struct {
+	DB service.TaskStatsReader
+}
+
+// GetStats returns the statistics of the dashboard, computed at most a few
+// seconds ago.
+func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.DB.Stats(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(stats)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+var _ = Describe("StatsHandler", func() {
+	var (
+		mockStats        *serviceMock.MockTaskStatsReader
+		handler          *StatsHandler
+		responseRecorder *httptest.ResponseRecorder
+		request          *http.Request
+	)
+
+	BeforeEach(func() {
+		mockStats = serviceMock.NewMockTaskStatsReader(gomock.NewController(GinkgoT()))
+		handler = &StatsHandler{DB: mockStats}
+		responseRecorder = httptest.NewRecorder()
+		var err error
+		request, err = http.NewRequest("GET", "/stats", nil)
+		Expect(err).To(Succeed())
+	})
+
+	It("returns the statistics", func() {
+		generatedAt := time.Date(2026, time.January, 7, 10, 0, 0, 0, time.UTC)
+		mockStats.EXPECT().Stats(gomock.Any()).Return(&models.TaskStats{
+			ByStatus:    map[string]int{"pending": 2, "done": 1},
+			Total:       3,
+			Week:        models.WeekStats{Start: "2026-01-05", Created: 2, Completed: 1},
+			OldestOpen:  []models.Task{},
+			Assignees:   []models.AssigneeLoad{{Assignee: "ann", Open: 2, Points: 5}},
+			GeneratedAt: generatedAt,
+		}, nil)
+
+		handler.GetStats(responseRecorder, request)
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(responseRecorder.Header().Get("Content-Type")).To(Equal(jsonContentType))
+		Expect(responseRecorder.Body.String()).To(MatchJSON(`{"by_status":{"pending":2,"done":1},"total":3,
+			"week":{"start":"2026-01-05","created":2,"completed":1},"oldest_open":[],
+			"assignees":[{"assignee":"ann","open":2,"points":5}],"generated_at":"2026-01-07T10:00:00Z"}`))
+	})
+
+	It("returns 500 when the statistics fail", func() {
+		mockStats.EXPECT().Stats(gomock.Any()).Return(nil, errors.New("db error"))
+
+		handler.GetStats(responseRecorder, request)
+		Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+	})
+})
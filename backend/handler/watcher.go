@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultWatchingLimit = 50
+	maxWatchingLimit     = 100
+	watchingNeedsKey     = "Following a task needs an API key"
+)
+
+type WatcherHandler struct {
+	DB service.WatcherRepository
+}
+
+// WatchTask makes the caller follow the task. Callers without an API key
+// can't follow tasks.
+func (h *WatcherHandler) WatchTask(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r, watchingNeedsKey)
+	if !ok {
+		return
+	}
+	err := h.DB.Watch(r.Context(), mux.Vars(r)["id"], user)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			http.Error(w, "Task not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrNotWatchable) {
+			http.Error(w, watchingNeedsKey, http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnwatchTask stops the caller following the task, whether they followed it
+// or not.
+func (h *WatcherHandler) UnwatchTask(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r, watchingNeedsKey)
+	if !ok {
+		return
+	}
+	if err := h.DB.Unwatch(r.Context(), mux.Vars(r)["id"], user); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WatcherHandler) GetWatchers(w http.ResponseWriter, r *http.Request) {
+	watchers, err := h.DB.Watchers(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			http.Error(w, "Task not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(watchers)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetWatching returns up to limit tasks (50 by default) followed by the
+// caller with an ID greater than after. A full page links to the next one
+// with a Link header.
+func (h *WatcherHandler) GetWatching(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r, watchingNeedsKey)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	after := query.Get("after")
+	if after != "" {
+		if _, err := strconv.ParseUint(after, 10, 63); err != nil {
+			http.Error(w, "Invalid after", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := defaultWatchingLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxWatchingLimit {
+			http.Error(w, "Invalid limit, expected 1 to "+strconv.Itoa(maxWatchingLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	tasks, err := h.DB.Watching(r.Context(), user, after, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(tasks) == limit {
+		next := url.Values{"after": {tasks[len(tasks)-1].ID}, "limit": {strconv.Itoa(limit)}}
+		w.Header().Set("Link", "</users/me/watching?"+next.Encode()+`>; rel="next"`)
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(tasks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
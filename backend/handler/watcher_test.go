@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("WatcherHandler", func() {
+	var (
+		mockWatchers     *serviceMock.MockWatcherRepository
+		handler          *WatcherHandler
+		responseRecorder *httptest.ResponseRecorder
+		key              = &models.APIKey{Name: "ci"}
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockWatchers = serviceMock.NewMockWatcherRepository(mockCtrl)
+		handler = &WatcherHandler{DB: mockWatchers}
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	newRequest := func(method, url string) *http.Request {
+		request, err := http.NewRequest(method, url, nil)
+		Expect(err).To(Succeed())
+		request = request.WithContext(service.ContextWithAPIKey(request.Context(), key))
+		return mux.SetURLVars(request, map[string]string{"id": "1"})
+	}
+
+	DescribeTable("follows a task",
+		func(err error, expected int) {
+			mockWatchers.EXPECT().Watch(gomock.Any(), "1", "ci").Return(err)
+
+			handler.WatchTask(responseRecorder, newRequest("POST", "/tasks/1/watchers"))
+			Expect(responseRecorder.Code).To(Equal(expected))
+		},
+		Entry("followed", nil, http.StatusNoContent),
+		Entry("missing task", service.ErrNotFound, http.StatusNotFound),
+		Entry("anonymous caller", service.ErrNotWatchable, http.StatusUnauthorized),
+		Entry("storage error", errMock, http.StatusInternalServerError),
+	)
+
+	DescribeTable("refuses the callers without an API key",
+		func(serve func(http.ResponseWriter, *http.Request), method string) {
+			request, err := http.NewRequest(method, "/tasks/1/watchers", nil)
+			Expect(err).To(Succeed())
+
+			serve(responseRecorder, mux.SetURLVars(request, map[string]string{"id": "1"}))
+			Expect(responseRecorder.Code).To(Equal(http.StatusUnauthorized))
+		},
+		Entry("following", func(w http.ResponseWriter, r *http.Request) { handler.WatchTask(w, r) }, "POST"),
+		Entry("unfollowing", func(w http.ResponseWriter, r *http.Request) { handler.UnwatchTask(w, r) }, "DELETE"),
+		Entry("listing the followed tasks", func(w http.ResponseWriter, r *http.Request) { handler.GetWatching(w, r) }, "GET"),
+	)
+
+	DescribeTable("stops following a task",
+		func(err error, expected int) {
+			mockWatchers.EXPECT().Unwatch(gomock.Any(), "1", "ci").Return(err)
+
+			handler.UnwatchTask(responseRecorder, newRequest("DELETE", "/tasks/1/watchers"))
+			Expect(responseRecorder.Code).To(Equal(expected))
+		},
+		Entry("unfollowed", nil, http.StatusNoContent),
+		Entry("storage error", errMock, http.StatusInternalServerError),
+	)
+
+	It("returns the watchers of a task", func() {
+		mockWatchers.EXPECT().Watchers(gomock.Any(), "1").Return([]string{"ci", "qa"}, nil)
+
+		handler.GetWatchers(responseRecorder, newRequest("GET", "/tasks/1/watchers"))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(responseRecorder.Body.String()).To(MatchJSON(`["ci", "qa"]`))
+	})
+
+	It("returns 404 for the watchers of a missing task", func() {
+		mockWatchers.EXPECT().Watchers(gomock.Any(), "1").Return(nil, service.ErrNotFound)
+
+		handler.GetWatchers(responseRecorder, newRequest("GET", "/tasks/1/watchers"))
+		Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
+	})
+
+	Describe("watching", func() {
+		tasks := func(ids ...string) []models.Task {
+			page := make([]models.Task, 0, len(ids))
+			for _, id := range ids {
+				page = append(page, models.Task{ID: id, Title: "Task " + id})
+			}
+			return page
+		}
+
+		It("returns the tasks followed by the caller", func() {
+			mockWatchers.EXPECT().Watching(gomock.Any(), "ci", "", 50).Return(tasks("3"), nil)
+
+			handler.GetWatching(responseRecorder, newRequest("GET", "/users/me/watching"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Header().Get("Link")).To(BeEmpty())
+			Expect(responseRecorder.Body.String()).To(MatchJSON(
+				`[{"id":"3","title":"Task 3","description":"","status":"","created_at":"0001-01-01T00:00:00Z"}]`))
+		})
+
+		It("links a full page to the next one", func() {
+			mockWatchers.EXPECT().Watching(gomock.Any(), "ci", "2", 2).Return(tasks("3", "7"), nil)
+
+			handler.GetWatching(responseRecorder, newRequest("GET", "/users/me/watching?after=2&limit=2"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Header().Get("Link")).To(Equal(`</users/me/watching?after=7&limit=2>; rel="next"`))
+		})
+
+		DescribeTable("rejects invalid queries",
+			func(query, expected string) {
+				handler.GetWatching(responseRecorder, newRequest("GET", "/users/me/watching?"+query))
+				Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(responseRecorder.Body.String()).To(ContainSubstring(expected))
+			},
+			Entry("after", "after=x", "Invalid after"),
+			Entry("limit too low", "limit=0", "Invalid limit, expected 1 to 100"),
+			Entry("limit too high", "limit=101", "Invalid limit, expected 1 to 100"),
+		)
+
+		It("returns 500 when the tasks can't be read", func() {
+			mockWatchers.EXPECT().Watching(gomock.Any(), "ci", "", 50).Return(nil, errMock)
+
+			handler.GetWatching(responseRecorder, newRequest("GET", "/users/me/watching"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"github.com/saarzur123/task-management/backend/webhooks"
+	"net/http"
+	"net/url"
+)
+
+type WebhookHandler struct {
+	DB    service.WebhookRepository
+	Audit service.AuditRepository
+}
+
+var knownEventTypes = map[string]bool{
+	models.EventTaskCreated:       true,
+	models.EventTaskUpdated:       true,
+	models.EventTaskDeleted:       true,
+	models.EventTaskStatusChanged: true,
+}
+
+const (
+	minWebhookSecretLen = 16
+)
+
+// validWebhookURL refuses the URLs of hosts that aren't public, which would
+// let callers make the server send requests to its own network.
+func validWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && webhooks.PublicHost(u.Hostname())
+}
+
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var hook models.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil || len(hook.EventTypes) == 0 {
+		http.Error(w, invalidInput, http.StatusBadRequest)
+		return
+	}
+	if !validWebhookURL(hook.URL) {
+		http.Error(w, "Invalid webhook URL", http.StatusBadRequest)
+		return
+	}
+	for _, eventType := range hook.EventTypes {
+		if !knownEventTypes[eventType] {
+			http.Error(w, "Unknown event type "+eventType, http.StatusBadRequest)
+			return
+		}
+	}
+	if hook.Secret != "" && len(hook.Secret) < minWebhookSecretLen {
+		http.Error(w, "Secret is too short", http.StatusBadRequest)
+		return
+	}
+	err := h.DB.Create(r.Context(), &hook)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	recordAudit(h.Audit, r, "webhook.create", "webhooks/"+hook.ID)
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(hook)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *WebhookHandler) GetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.DB.GetAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(hooks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, err := h.DB.GetByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeWebhookError(w, err, "Webhook not found")
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(hook)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := h.DB.Delete(r.Context(), id); err != nil {
+		writeWebhookError(w, err, "Webhook not found")
+		return
+	}
+	recordAudit(h.Audit, r, "webhook.delete", "webhooks/"+id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EnableWebhook turns a webhook disabled after repeated failures back on.
+func (h *WebhookHandler) EnableWebhook(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := h.DB.Enable(r.Context(), id); err != nil {
+		writeWebhookError(w, err, "Webhook not found")
+		return
+	}
+	recordAudit(h.Audit, r, "webhook.enable", "webhooks/"+id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := h.DB.Deliveries(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeWebhookError(w, err, "Webhook not found")
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(deliveries)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// ReplayDelivery queues the payload of an earlier delivery again.
+func (h *WebhookHandler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	delivery, err := h.DB.Replay(r.Context(), vars["id"], vars["deliveryID"])
+	if err != nil {
+		writeWebhookError(w, err, "Delivery not found")
+		return
+	}
+	recordAudit(h.Audit, r, "webhook.replay", "webhooks/"+vars["id"]+"/deliveries/"+vars["deliveryID"])
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(http.StatusAccepted)
+	err = json.NewEncoder(w).Encode(delivery)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeWebhookError(w http.ResponseWriter, err error, notFound string) {
+	if errors.Is(err, service.ErrNotFound) {
+		http.Error(w, notFound, http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
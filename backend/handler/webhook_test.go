@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("WebhookHandler", func() {
+	var (
+		mockWebhooks     *serviceMock.MockWebhookRepository
+		mockAudit        *serviceMock.MockAuditRepository
+		handler          *WebhookHandler
+		responseRecorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockWebhooks = serviceMock.NewMockWebhookRepository(mockCtrl)
+		mockAudit = serviceMock.NewMockAuditRepository(mockCtrl)
+		handler = &WebhookHandler{DB: mockWebhooks, Audit: mockAudit}
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	Describe("CreateWebhook", func() {
+		newRequest := func(hook models.Webhook) *http.Request {
+			body, err := json.Marshal(hook)
+			Expect(err).To(Succeed())
+			request, err := http.NewRequest("POST", "/webhooks", bytes.NewBuffer(body))
+			Expect(err).To(Succeed())
+			return request
+		}
+
+		It("creates the webhook, returns its secret and records the creation", func() {
+			mockWebhooks.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, hook *models.Webhook) error {
+				hook.ID = "1"
+				hook.Secret = "whsec_secret"
+				return nil
+			})
+			mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry *models.AuditEntry) error {
+				Expect(entry.Action).To(Equal("webhook.create"))
+				Expect(entry.Resource).To(Equal("webhooks/1"))
+				return nil
+			})
+
+			handler.CreateWebhook(responseRecorder, newRequest(models.Webhook{URL: "https://ci.example.com/hook", EventTypes: []string{models.EventTaskCreated}}))
+			Expect(responseRecorder.Code).To(Equal(http.StatusCreated))
+			var hook models.Webhook
+			Expect(json.NewDecoder(responseRecorder.Body).Decode(&hook)).To(Succeed())
+			Expect(hook.Secret).To(Equal("whsec_secret"))
+		})
+
+		DescribeTable("rejects invalid webhooks",
+			func(hook models.Webhook, expected string) {
+				handler.CreateWebhook(responseRecorder, newRequest(hook))
+				Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(responseRecorder.Body.String()).To(ContainSubstring(expected))
+			},
+			Entry("no event types", models.Webhook{URL: "https://ci.example.com/hook"}, invalidInput),
+			Entry("relative URL", models.Webhook{URL: "/hook", EventTypes: []string{models.EventTaskCreated}}, "Invalid webhook URL"),
+			Entry("unsupported scheme", models.Webhook{URL: "ftp://ci.example.com", EventTypes: []string{models.EventTaskCreated}}, "Invalid webhook URL"),
+			Entry("cloud metadata", models.Webhook{URL: "http://169.254.169.254/latest/meta-data", EventTypes: []string{models.EventTaskCreated}}, "Invalid webhook URL"),
+			Entry("loopback", models.Webhook{URL: "http://127.0.0.1:8080/hook", EventTypes: []string{models.EventTaskCreated}}, "Invalid webhook URL"),
+			Entry("IPv6 loopback", models.Webhook{URL: "http://[::1]/hook", EventTypes: []string{models.EventTaskCreated}}, "Invalid webhook URL"),
+			Entry("private", models.Webhook{URL: "https://10.0.0.5/hook", EventTypes: []string{models.EventTaskCreated}}, "Invalid webhook URL"),
+			Entry("unspecified", models.Webhook{URL: "http://0.0.0.0/hook", EventTypes: []string{models.EventTaskCreated}}, "Invalid webhook URL"),
+			Entry("localhost", models.Webhook{URL: "http://localhost/hook", EventTypes: []string{models.EventTaskCreated}}, "Invalid webhook URL"),
+			Entry("unknown event type", models.Webhook{URL: "https://ci.example.com/hook", EventTypes: []string{"task.moved"}}, "Unknown event type task.moved"),
+			Entry("short secret", models.Webhook{URL: "https://ci.example.com/hook", EventTypes: []string{models.EventTaskCreated}, Secret: "short"}, "Secret is too short"),
+		)
+
+		It("returns 500 when database error occurred", func() {
+			mockWebhooks.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errMock)
+
+			handler.CreateWebhook(responseRecorder, newRequest(models.Webhook{URL: "https://ci.example.com/hook", EventTypes: []string{models.EventTaskDeleted}}))
+			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Describe("DeleteWebhook", func() {
+		request := mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/webhooks/1", nil), map[string]string{"id": "1"})
+
+		It("deletes the webhook", func() {
+			mockWebhooks.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+			mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+
+			handler.DeleteWebhook(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("returns 404 when the webhook doesn't exist", func() {
+			mockWebhooks.EXPECT().Delete(gomock.Any(), "1").Return(service.ErrNotFound)
+
+			handler.DeleteWebhook(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("EnableWebhook", func() {
+		It("enables the webhook", func() {
+			mockWebhooks.EXPECT().Enable(gomock.Any(), "1").Return(nil)
+			mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+
+			handler.EnableWebhook(responseRecorder, mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/webhooks/1/enable", nil), map[string]string{"id": "1"}))
+			Expect(responseRecorder.Code).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	Describe("GetDeliveries", func() {
+		It("returns the delivery log", func() {
+			mockWebhooks.EXPECT().Deliveries(gomock.Any(), "1").Return([]models.WebhookDelivery{
+				{ID: "2", WebhookID: "1", Status: models.WebhookDeliveryFailed, Payload: json.RawMessage(`{"id":1}`)},
+			}, nil)
+
+			handler.GetDeliveries(responseRecorder, mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/webhooks/1/deliveries", nil), map[string]string{"id": "1"}))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring(`"payload":{"id":1}`))
+		})
+	})
+
+	Describe("ReplayDelivery", func() {
+		request := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/webhooks/1/deliveries/2/replay", nil), map[string]string{"id": "1", "deliveryID": "2"})
+
+		It("queues the delivery again", func() {
+			mockWebhooks.EXPECT().Replay(gomock.Any(), "1", "2").Return(&models.WebhookDelivery{ID: "3", Status: models.WebhookDeliveryPending}, nil)
+			mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry *models.AuditEntry) error {
+				Expect(entry.Resource).To(Equal("webhooks/1/deliveries/2"))
+				return nil
+			})
+
+			handler.ReplayDelivery(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusAccepted))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring(`"id":"3"`))
+		})
+
+		It("returns 404 when the delivery doesn't exist", func() {
+			mockWebhooks.EXPECT().Replay(gomock.Any(), "1", "2").Return(nil, service.ErrNotFound)
+
+			handler.ReplayDelivery(responseRecorder, request)
+			Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring("Delivery not found"))
+		})
+	})
+})
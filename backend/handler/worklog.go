@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxWorklogDuration bounds the time logged by hand at once, to catch
+	// hours typed as seconds.
+	maxWorklogDuration = 24 * 60 * 60
+	csvContentType     = "text/csv"
+	// timeGroupTag is refused with its own message: the tasks have no tags
+	// to group the time by.
+	timeGroupTag = "tag"
+)
+
+var defaultTimeGroups = []string{models.TimeGroupUser, models.TimeGroupTask}
+
+type WorklogHandler struct {
+	DB service.TimeTracker
+}
+
+// StartTimer starts a timer of the caller on the task, with an optional
+// note. Callers have one running timer at most.
+func (h *WorklogHandler) StartTimer(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, invalidInput, http.StatusBadRequest)
+		return
+	}
+
+	worklog, err := h.DB.StartTimer(r.Context(), mux.Vars(r)["id"], UserID(r), body.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			http.Error(w, "Task not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrNotTrackable):
+			http.Error(w, "Tracking time needs an API key", http.StatusUnauthorized)
+		case errors.Is(err, service.ErrTimerRunning):
+			http.Error(w, "A timer is running already, stop it first", http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeWorklog(w, http.StatusCreated, worklog)
+}
+
+func (h *WorklogHandler) StopTimer(w http.ResponseWriter, r *http.Request) {
+	worklog, err := h.DB.StopTimer(r.Context(), mux.Vars(r)["id"], UserID(r))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			http.Error(w, "No running timer on the task", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeWorklog(w, http.StatusOK, worklog)
+}
+
+// LogWork records time spent by the caller on the task, ending now unless
+// started_at is set.
+func (h *WorklogHandler) LogWork(w http.ResponseWriter, r *http.Request) {
+	var worklog models.Worklog
+	if err := json.NewDecoder(r.Body).Decode(&worklog); err != nil {
+		http.Error(w, invalidInput, http.StatusBadRequest)
+		return
+	}
+	if worklog.Duration < 1 || worklog.Duration > maxWorklogDuration {
+		http.Error(w, "Invalid duration, expected 1 to "+strconv.Itoa(maxWorklogDuration)+" seconds", http.StatusBadRequest)
+		return
+	}
+	worklog.TaskID = mux.Vars(r)["id"]
+	worklog.UserID = UserID(r)
+	if worklog.UserID == service.AnonymousUser {
+		http.Error(w, "Tracking time needs an API key", http.StatusUnauthorized)
+		return
+	}
+	if worklog.StartedAt.IsZero() {
+		worklog.StartedAt = time.Now().Add(-time.Duration(worklog.Duration) * time.Second)
+	}
+
+	if err := h.DB.LogWork(r.Context(), &worklog); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			http.Error(w, "Task not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeWorklog(w, http.StatusCreated, &worklog)
+}
+
+func (h *WorklogHandler) GetWorklogs(w http.ResponseWriter, r *http.Request) {
+	worklogs, err := h.DB.Worklogs(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			http.Error(w, "Task not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(worklogs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetTimeReport sums the time logged between from and to, dates or RFC 3339
+// times, by the comma separated group_by fields (user and task by default),
+// optionally for a single user or task. format=csv returns the report as CSV
+// with a row per group.
+func (h *WorklogHandler) GetTimeReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var (
+		reportQuery = models.TimeReportQuery{UserID: query.Get("user"), TaskID: query.Get("task"), GroupBy: defaultTimeGroups}
+		err         error
+	)
+	if reportQuery.From, err = parseReportTime(query.Get("from")); err != nil {
+		http.Error(w, "Invalid from, expected a date or an RFC 3339 time", http.StatusBadRequest)
+		return
+	}
+	if reportQuery.To, err = parseReportTime(query.Get("to")); err != nil {
+		http.Error(w, "Invalid to, expected a date or an RFC 3339 time", http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("group_by"); value != "" {
+		reportQuery.GroupBy = strings.Split(value, ",")
+		for i, field := range reportQuery.GroupBy {
+			if field == timeGroupTag {
+				http.Error(w, "Invalid group_by, tasks have no tags yet: expected user, task or day", http.StatusBadRequest)
+				return
+			}
+			if !slices.Contains([]string{models.TimeGroupUser, models.TimeGroupTask, models.TimeGroupDay}, field) ||
+				slices.Contains(reportQuery.GroupBy[:i], field) {
+				http.Error(w, "Invalid group_by, expected user, task or day", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		http.Error(w, "Invalid format, expected json or csv", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.DB.TimeReport(r.Context(), reportQuery)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", csvContentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="time-report.csv"`)
+		writeTimeReportCSV(w, report)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	err = json.NewEncoder(w).Encode(report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func parseReportTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		return day, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// writeTimeReportCSV writes a column per group_by field, then the duration in
+// seconds and in hours.
+func writeTimeReportCSV(w io.Writer, report *models.TimeReport) {
+	out := csv.NewWriter(w)
+	out.Write(append(slices.Clone(report.GroupBy), "seconds", "hours")) // nolint: errcheck
+	for _, row := range report.Rows {
+		record := make([]string, 0, len(report.GroupBy)+2)
+		for _, field := range report.GroupBy {
+			switch field {
+			case models.TimeGroupUser:
+				record = append(record, row.UserID)
+			case models.TimeGroupTask:
+				record = append(record, row.TaskID)
+			case models.TimeGroupDay:
+				record = append(record, row.Day)
+			}
+		}
+		record = append(record, strconv.FormatInt(row.Duration, 10), strconv.FormatFloat(float64(row.Duration)/3600, 'f', 2, 64))
+		out.Write(record) // nolint: errcheck
+	}
+	out.Flush()
+}
+
+func writeWorklog(w http.ResponseWriter, status int, worklog *models.Worklog) {
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(worklog)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+var _ = Describe("WorklogHandler", func() {
+	var (
+		mockTracker      *serviceMock.MockTimeTracker
+		handler          *WorklogHandler
+		responseRecorder *httptest.ResponseRecorder
+		key              = &models.APIKey{Name: "ci"}
+		startedAt        = time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockTracker = serviceMock.NewMockTimeTracker(mockCtrl)
+		handler = &WorklogHandler{DB: mockTracker}
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	newRequest := func(method, url, body string) *http.Request {
+		request, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).To(Succeed())
+		request = request.WithContext(service.ContextWithAPIKey(request.Context(), key))
+		return mux.SetURLVars(request, map[string]string{"id": "1"})
+	}
+
+	Describe("timers", func() {
+		It("starts a timer with a note", func() {
+			mockTracker.EXPECT().StartTimer(gomock.Any(), "1", "ci", "Review").
+				Return(&models.Worklog{ID: "4", TaskID: "1", UserID: "ci", StartedAt: startedAt, Note: "Review", Running: true}, nil)
+
+			handler.StartTimer(responseRecorder, newRequest("POST", "/tasks/1/timer", `{"note": "Review"}`))
+			Expect(responseRecorder.Code).To(Equal(http.StatusCreated))
+			Expect(responseRecorder.Body.String()).To(MatchJSON(
+				`{"id":"4","task_id":"1","user_id":"ci","started_at":"2026-01-05T09:00:00Z","duration":0,"note":"Review","running":true}`))
+		})
+
+		It("starts a timer without a body", func() {
+			mockTracker.EXPECT().StartTimer(gomock.Any(), "1", "ci", "").Return(&models.Worklog{ID: "4"}, nil)
+
+			handler.StartTimer(responseRecorder, newRequest("POST", "/tasks/1/timer", ""))
+			Expect(responseRecorder.Code).To(Equal(http.StatusCreated))
+		})
+
+		DescribeTable("maps the errors of starting a timer",
+			func(err error, expected int) {
+				mockTracker.EXPECT().StartTimer(gomock.Any(), "1", "ci", "").Return(nil, err)
+
+				handler.StartTimer(responseRecorder, newRequest("POST", "/tasks/1/timer", ""))
+				Expect(responseRecorder.Code).To(Equal(expected))
+			},
+			Entry("missing task", service.ErrNotFound, http.StatusNotFound),
+			Entry("anonymous caller", service.ErrNotTrackable, http.StatusUnauthorized),
+			Entry("running timer", service.ErrTimerRunning, http.StatusConflict),
+			Entry("storage error", errMock, http.StatusInternalServerError),
+		)
+
+		It("rejects a malformed body", func() {
+			handler.StartTimer(responseRecorder, newRequest("POST", "/tasks/1/timer", "{"))
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("stops the timer", func() {
+			mockTracker.EXPECT().StopTimer(gomock.Any(), "1", "ci").
+				Return(&models.Worklog{ID: "4", TaskID: "1", UserID: "ci", StartedAt: startedAt, Duration: 90}, nil)
+
+			handler.StopTimer(responseRecorder, newRequest("DELETE", "/tasks/1/timer", ""))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Body.String()).To(MatchJSON(
+				`{"id":"4","task_id":"1","user_id":"ci","started_at":"2026-01-05T09:00:00Z","duration":90}`))
+		})
+
+		It("returns 404 without a running timer", func() {
+			mockTracker.EXPECT().StopTimer(gomock.Any(), "1", "ci").Return(nil, service.ErrNotFound)
+
+			handler.StopTimer(responseRecorder, newRequest("DELETE", "/tasks/1/timer", ""))
+			Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
+			Expect(responseRecorder.Body.String()).To(ContainSubstring("No running timer on the task"))
+		})
+	})
+
+	Describe("worklogs", func() {
+		It("logs work of the caller", func() {
+			mockTracker.EXPECT().LogWork(gomock.Any(), &models.Worklog{TaskID: "1", UserID: "ci", StartedAt: startedAt, Duration: 3600, Note: "Call"}).
+				DoAndReturn(func(_ any, worklog *models.Worklog) error {
+					worklog.ID = "5"
+					return nil
+				})
+
+			handler.LogWork(responseRecorder, newRequest("POST", "/tasks/1/worklogs",
+				`{"started_at": "2026-01-05T09:00:00Z", "duration": 3600, "note": "Call"}`))
+			Expect(responseRecorder.Code).To(Equal(http.StatusCreated))
+			Expect(responseRecorder.Body.String()).To(MatchJSON(
+				`{"id":"5","task_id":"1","user_id":"ci","started_at":"2026-01-05T09:00:00Z","duration":3600,"note":"Call"}`))
+		})
+
+		It("ends the work now without a start time", func() {
+			mockTracker.EXPECT().LogWork(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, worklog *models.Worklog) error {
+				Expect(worklog.StartedAt).To(BeTemporally("~", time.Now().Add(-time.Hour), time.Second))
+				return nil
+			})
+
+			handler.LogWork(responseRecorder, newRequest("POST", "/tasks/1/worklogs", `{"duration": 3600}`))
+			Expect(responseRecorder.Code).To(Equal(http.StatusCreated))
+		})
+
+		DescribeTable("rejects invalid worklogs",
+			func(body, expected string) {
+				handler.LogWork(responseRecorder, newRequest("POST", "/tasks/1/worklogs", body))
+				Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(responseRecorder.Body.String()).To(ContainSubstring(expected))
+			},
+			Entry("malformed body", `{`, invalidInput),
+			Entry("missing duration", `{"note": "Call"}`, "Invalid duration, expected 1 to 86400 seconds"),
+			Entry("too long", `{"duration": 86401}`, "Invalid duration"),
+		)
+
+		It("refuses the anonymous caller", func() {
+			request, err := http.NewRequest("POST", "/tasks/1/worklogs", strings.NewReader(`{"duration": 60}`))
+			Expect(err).To(Succeed())
+			handler.LogWork(responseRecorder, mux.SetURLVars(request, map[string]string{"id": "1"}))
+			Expect(responseRecorder.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("returns 404 for a missing task", func() {
+			mockTracker.EXPECT().LogWork(gomock.Any(), gomock.Any()).Return(service.ErrNotFound)
+
+			handler.LogWork(responseRecorder, newRequest("POST", "/tasks/1/worklogs", `{"duration": 60}`))
+			Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("returns the worklogs of a task", func() {
+			mockTracker.EXPECT().Worklogs(gomock.Any(), "1").Return([]models.Worklog{{ID: "5", TaskID: "1", UserID: "ci", StartedAt: startedAt, Duration: 60}}, nil)
+
+			handler.GetWorklogs(responseRecorder, newRequest("GET", "/tasks/1/worklogs", ""))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Body.String()).To(MatchJSON(
+				`[{"id":"5","task_id":"1","user_id":"ci","started_at":"2026-01-05T09:00:00Z","duration":60}]`))
+		})
+
+		It("returns 404 for the worklogs of a missing task", func() {
+			mockTracker.EXPECT().Worklogs(gomock.Any(), "1").Return(nil, service.ErrNotFound)
+
+			handler.GetWorklogs(responseRecorder, newRequest("GET", "/tasks/1/worklogs", ""))
+			Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("time report", func() {
+		report := &models.TimeReport{
+			GroupBy: []string{"user", "day"},
+			Rows: []models.TimeReportRow{
+				{UserID: "ci", Day: "2026-01-05", Duration: 5400},
+				{UserID: "qa", Day: "2026-01-05", Duration: 60},
+			},
+			Total: 5460,
+		}
+
+		It("groups by user and task by default", func() {
+			mockTracker.EXPECT().TimeReport(gomock.Any(), models.TimeReportQuery{GroupBy: []string{"user", "task"}}).
+				Return(&models.TimeReport{GroupBy: []string{"user", "task"}, Rows: []models.TimeReportRow{}}, nil)
+
+			handler.GetTimeReport(responseRecorder, newRequest("GET", "/reports/time", ""))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Body.String()).To(MatchJSON(`{"group_by":["user","task"],"rows":[],"total":0}`))
+		})
+
+		It("filters and groups as asked", func() {
+			mockTracker.EXPECT().TimeReport(gomock.Any(), models.TimeReportQuery{
+				From:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:      time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC),
+				UserID:  "ci",
+				TaskID:  "1",
+				GroupBy: []string{"user", "day"},
+			}).Return(report, nil)
+
+			handler.GetTimeReport(responseRecorder, newRequest("GET",
+				"/reports/time?from=2026-01-01&to=2026-02-01T12:00:00Z&user=ci&task=1&group_by=user,day", ""))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Body.String()).To(MatchJSON(`{"group_by":["user","day"],"rows":[
+				{"user_id":"ci","day":"2026-01-05","duration":5400},
+				{"user_id":"qa","day":"2026-01-05","duration":60}
+			],"total":5460}`))
+		})
+
+		It("exports the report as CSV", func() {
+			mockTracker.EXPECT().TimeReport(gomock.Any(), gomock.Any()).Return(report, nil)
+
+			handler.GetTimeReport(responseRecorder, newRequest("GET", "/reports/time?group_by=user,day&format=csv", ""))
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Header().Get("Content-Type")).To(Equal("text/csv"))
+			Expect(responseRecorder.Header().Get("Content-Disposition")).To(Equal(`attachment; filename="time-report.csv"`))
+			Expect(responseRecorder.Body.String()).To(Equal(
+				"user,day,seconds,hours\nci,2026-01-05,5400,1.50\nqa,2026-01-05,60,0.02\n"))
+		})
+
+		DescribeTable("rejects invalid queries",
+			func(query, expected string) {
+				handler.GetTimeReport(responseRecorder, newRequest("GET", "/reports/time?"+query, ""))
+				Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(responseRecorder.Body.String()).To(ContainSubstring(expected))
+			},
+			Entry("from", "from=yesterday", "Invalid from"),
+			Entry("to", "to=2026-13-01", "Invalid to"),
+			Entry("unknown group", "group_by=user,week", "Invalid group_by, expected user, task or day"),
+			Entry("tags", "group_by=user,tag", "Invalid group_by, tasks have no tags yet"),
+			Entry("repeated group", "group_by=day,day", "Invalid group_by"),
+			Entry("format", "format=xlsx", "Invalid format, expected json or csv"),
+		)
+
+		It("returns 500 when the report fails", func() {
+			mockTracker.EXPECT().TimeReport(gomock.Any(), gomock.Any()).Return(nil, errMock)
+
+			handler.GetTimeReport(responseRecorder, newRequest("GET", "/reports/time", ""))
+			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
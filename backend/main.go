@@ -1,21 +1,227 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"github.com/saarzur123/task-management/backend/events"
+	"github.com/saarzur123/task-management/backend/graph"
+	"github.com/saarzur123/task-management/backend/grpcserver"
+	"github.com/saarzur123/task-management/backend/handler"
+	"github.com/saarzur123/task-management/backend/logging"
+	"github.com/saarzur123/task-management/backend/metrics"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/notify"
+	"github.com/saarzur123/task-management/backend/presence"
+	"github.com/saarzur123/task-management/backend/recurrence"
 	"github.com/saarzur123/task-management/backend/service"
+	"github.com/saarzur123/task-management/backend/tracing"
 	"github.com/saarzur123/task-management/backend/utils"
-	"log"
+	"github.com/saarzur123/task-management/backend/webhooks"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/smtp"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Set at build time with -ldflags "-X main.gitSHA=... -X main.buildTime=...".
+var (
+	gitSHA    = "unknown"
+	buildTime = "unknown"
+)
+
+const (
+	// drainDelay leaves load balancers time to notice the failing readiness
+	// probe before the listener closes.
+	drainDelay      = 5 * time.Second
+	shutdownTimeout = 15 * time.Second
 )
 
 func main() {
+	logger := logging.New(os.Stdout, slog.LevelInfo)
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: "task-management-backend",
+		Exporter:    os.Getenv("TRACING_EXPORTER"),
+		Endpoint:    os.Getenv("TRACING_OTLP_ENDPOINT"),
+		Insecure:    os.Getenv("TRACING_OTLP_INSECURE") == "true",
+	})
+	if err != nil {
+		fatal(logger, "failed to set up tracing", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	dbInstance, err := service.InitDB()
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "failed to initialize database", err)
 	}
 
 	defer dbInstance.Close()
 
-	router := utils.SetupRoutes(&service.TaskManager{DB: dbInstance})
+	apiKeyRequired := os.Getenv("API_KEY_REQUIRED") == "true"
+	grpcAddr := os.Getenv("GRPC_ADDR")
+	if grpcAddr == "" {
+		grpcAddr = ":9090"
+	}
+	cors := utils.DefaultCORSConfig()
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		cors.AllowedOrigins = strings.Split(origins, ",")
+	}
+
+	rateLimit := utils.DefaultRateLimitConfig()
+	rateLimit.TrustedProxies, err = utils.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		fatal(logger, "invalid TRUSTED_PROXIES", err)
+	}
+
+	taskManager := &service.TaskManager{DB: dbInstance}
+	registry := metrics.NewRegistry()
+	service.RegisterMetrics(registry, taskManager)
+
+	hub := events.NewHub(events.DefaultReplaySize, events.DefaultSubscriberBuffer)
+	hub.StartIDsAt(uint64(time.Now().UnixMicro()))
+	tasks := service.NewPublishingRepository(taskManager, hub)
+	presenceHub := presence.NewHub(presence.NewMemoryBroker())
+	webhookManager := &service.WebhookManager{DB: dbInstance}
+	outboxManager := &service.OutboxManager{DB: dbInstance}
+	eventFeed := &handler.EventFeedHandler{Outbox: outboxManager}
+	auditManager := &service.AuditManager{DB: dbInstance}
+	apiKeyManager := &service.APIKeyManager{DB: dbInstance}
+	if adminKey := os.Getenv("API_ADMIN_KEY"); adminKey != "" {
+		if err := apiKeyManager.Seed(context.Background(), "admin", adminKey); err != nil {
+			fatal(logger, "invalid API_ADMIN_KEY", err)
+		}
+	}
+	notificationManager := &service.NotificationManager{DB: dbInstance}
+	watcherManager := &service.WatcherManager{DB: dbInstance}
+	worklogManager := &service.WorklogManager{DB: dbInstance}
+
+	notifiers := []notify.Notifier{
+		&notify.InAppNotifier{Store: notificationManager},
+		&notify.WebhookNotifier{Secret: os.Getenv("NOTIFICATION_WEBHOOK_SECRET")},
+	}
+	if smtpAddr := os.Getenv("SMTP_ADDR"); smtpAddr != "" {
+		smtpNotifier := &notify.SMTPNotifier{Addr: smtpAddr, From: os.Getenv("SMTP_FROM")}
+		if username := os.Getenv("SMTP_USERNAME"); username != "" {
+			host, _, _ := net.SplitHostPort(smtpAddr)
+			smtpNotifier.Auth = smtp.PlainAuth("", username, os.Getenv("SMTP_PASSWORD"), host)
+		}
+		notifiers = append(notifiers, smtpNotifier)
+	}
+
+	health := &handler.HealthHandler{
+		DB:        taskManager,
+		BuildInfo: models.BuildInfo{GitSHA: gitSHA, BuildTime: buildTime, GoVersion: runtime.Version()},
+	}
+
+	router := utils.SetupRoutes(tasks,
+		utils.WithAPIKeys(apiKeyManager, auditManager, apiKeyRequired),
+		utils.WithCORS(cors),
+		utils.WithRateLimit(rateLimit),
+		utils.WithMetrics(registry),
+		utils.WithLogger(logger),
+		utils.WithHealth(health),
+		utils.WithEvents(hub),
+		utils.WithPresence(presenceHub),
+		utils.WithWebhooks(webhookManager, auditManager),
+		utils.WithEventFeed(eventFeed),
+		utils.WithTaskPages(taskManager),
+		utils.WithNotifications(notificationManager, notificationManager),
+		utils.WithWatchers(watcherManager),
+		utils.WithTimeTracking(worklogManager),
+		utils.WithBoard(&service.PublishingBoard{TaskBoard: taskManager, Events: hub}),
+		utils.WithSprints(&service.PublishingSprints{SprintPlanner: &service.SprintManager{DB: dbInstance}, Events: hub}),
+		utils.WithFlowReports(&service.FlowReportManager{DB: dbInstance}),
+		utils.WithStats(taskManager),
+		utils.WithGraphQL(&graph.Resolver{
+			Tasks:     tasks,
+			TaskBatch: taskManager,
+			History:   outboxManager,
+			Outbox:    outboxManager,
+			Events:    hub,
+		}))
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(3)
+	go func() {
+		defer workers.Done()
+		webhooks.NewWorker(outboxManager, webhookManager, logger).Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		recurrence.NewScheduler(taskManager, hub, logger).Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		notify.NewReminders(notificationManager, notificationManager, notifiers, logger).Run(workerCtx)
+	}()
+
+	server := &http.Server{Addr: ":8080", Handler: router, ReadHeaderTimeout: 10 * time.Second}
+	// Shutdown waits for active connections and ignores hijacked ones, end
+	// the event streams and presence sockets so neither outlives it, and
+	// answer the long polls of the event feed.
+	server.RegisterOnShutdown(hub.Close)
+	server.RegisterOnShutdown(presenceHub.Close)
+	server.RegisterOnShutdown(eventFeed.Close)
+	serverErr := make(chan error, 2)
+	go func() {
+		logger.Info("listening", slog.String("addr", server.Addr), slog.String("git_sha", gitSHA))
+		serverErr <- server.ListenAndServe()
+	}()
+
+	grpcServer := grpcserver.New(tasks, hub, grpcserver.WithAPIKeys(apiKeyManager, auditManager, apiKeyRequired))
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		fatal(logger, "failed to listen for gRPC", err)
+	}
+	go func() {
+		logger.Info("listening for gRPC", slog.String("addr", grpcAddr))
+		serverErr <- grpcServer.Serve(grpcListener)
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case err = <-serverErr:
+		fatal(logger, "server stopped", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutting down")
+	health.SetShuttingDown()
+	time.Sleep(drainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err = server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("failed to shut down gracefully", slog.Any("error", err))
+	}
+	// The watch streams already ended with the hub, GracefulStop only waits
+	// for the calls in flight.
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+	stopWorkers()
+	workers.Wait()
+}
 
-	log.Fatal(http.ListenAndServe(":8080", router))
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, slog.Any("error", err))
+	os.Exit(1)
 }
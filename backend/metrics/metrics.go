@@ -0,0 +1,409 @@
+// Package metrics implements the small subset of Prometheus metric types the
+// backend needs and renders them in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/logging"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+
+	contentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// DefBuckets are the default latency buckets, in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type collector interface {
+	write(ctx context.Context, w io.Writer) error
+}
+
+// Registry holds every metric exposed by Handler.
+type Registry struct {
+	collectors []collector
+	mu         sync.Mutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Write renders all registered metrics in the text exposition format. The
+// collectors that fail log with the logger of ctx.
+func (r *Registry) Write(ctx context.Context, w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	buf := bufio.NewWriter(w)
+	for _, c := range collectors {
+		if err := c.write(ctx, buf); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+// Handler serves the registry for Prometheus to scrape.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		if err := r.Write(req.Context(), w); err != nil {
+			logging.FromContext(req.Context()).ErrorContext(req.Context(), "failed to write metrics", slog.Any("error", err))
+		}
+	})
+}
+
+type desc struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (d *desc) writeHeader(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, escapeHelp(d.help), d.name, d.kind)
+	return err
+}
+
+func (d *desc) checkLabels(values []string) {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+}
+
+// vec keeps one child per combination of label values.
+type vec[T any] struct {
+	children map[string]*T
+	values   map[string][]string
+	newChild func() *T
+	desc
+	mu sync.RWMutex
+}
+
+func newVec[T any](d desc, newChild func() *T) vec[T] {
+	return vec[T]{desc: d, children: make(map[string]*T), values: make(map[string][]string), newChild: newChild}
+}
+
+func (v *vec[T]) with(labelValues []string) *T {
+	v.checkLabels(labelValues)
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.RLock()
+	child, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return child
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if child, ok = v.children[key]; ok {
+		return child
+	}
+	child = v.newChild()
+	v.children[key] = child
+	v.values[key] = append([]string(nil), labelValues...)
+	return child
+}
+
+// each calls fn for every child, ordered by label values.
+func (v *vec[T]) each(fn func(labelValues []string, child *T) error) error {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.children))
+	for key := range v.children {
+		keys = append(keys, key)
+	}
+	v.mu.RUnlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		v.mu.RLock()
+		child, values := v.children[key], v.values[key]
+		v.mu.RUnlock()
+		if err := fn(values, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// atomicFloat is a float64 updated without locks.
+type atomicFloat struct {
+	bits uint64
+}
+
+func (f *atomicFloat) Add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&f.bits)
+		updated := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(&f.bits, old, updated) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) Set(value float64) {
+	atomic.StoreUint64(&f.bits, math.Float64bits(value))
+}
+
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&f.bits))
+}
+
+// Counter is a value that only goes up.
+type Counter struct {
+	value atomicFloat
+}
+
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("counter cannot decrease")
+	}
+	c.value.Add(delta)
+}
+
+type CounterVec struct {
+	vec[Counter]
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec(desc{name: name, help: help, kind: typeCounter, labels: labels}, func() *Counter { return &Counter{} })}
+	r.register(c)
+	return c
+}
+
+func (c *CounterVec) WithLabelValues(values ...string) *Counter {
+	return c.with(values)
+}
+
+func (c *CounterVec) write(_ context.Context, w io.Writer) error {
+	if err := c.writeHeader(w); err != nil {
+		return err
+	}
+	return c.each(func(values []string, child *Counter) error {
+		return writeSample(w, c.name, c.labels, values, "", "", child.value.Load())
+	})
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	value atomicFloat
+}
+
+func (g *Gauge) Set(value float64) {
+	g.value.Set(value)
+}
+
+func (g *Gauge) Inc() {
+	g.value.Add(1)
+}
+
+func (g *Gauge) Dec() {
+	g.value.Add(-1)
+}
+
+type GaugeVec struct {
+	vec[Gauge]
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newVec(desc{name: name, help: help, kind: typeGauge, labels: labels}, func() *Gauge { return &Gauge{} })}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeVec) WithLabelValues(values ...string) *Gauge {
+	return g.with(values)
+}
+
+func (g *GaugeVec) write(_ context.Context, w io.Writer) error {
+	if err := g.writeHeader(w); err != nil {
+		return err
+	}
+	return g.each(func(values []string, child *Gauge) error {
+		return writeSample(w, g.name, g.labels, values, "", "", child.value.Load())
+	})
+}
+
+// Histogram counts observations in cumulative buckets.
+type Histogram struct {
+	upperBounds []float64
+	counts      []uint64
+	sum         atomicFloat
+	count       uint64
+}
+
+func (h *Histogram) Observe(value float64) {
+	i := sort.SearchFloat64s(h.upperBounds, value)
+	if i < len(h.counts) {
+		atomic.AddUint64(&h.counts[i], 1)
+	}
+	h.sum.Add(value)
+	atomic.AddUint64(&h.count, 1)
+}
+
+type HistogramVec struct {
+	vec[Histogram]
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	upperBounds := append([]float64(nil), buckets...)
+	sort.Float64s(upperBounds)
+	h := &HistogramVec{newVec(desc{name: name, help: help, kind: typeHistogram, labels: labels}, func() *Histogram {
+		return &Histogram{upperBounds: upperBounds, counts: make([]uint64, len(upperBounds))}
+	})}
+	r.register(h)
+	return h
+}
+
+func (h *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	return h.with(values)
+}
+
+func (h *HistogramVec) write(_ context.Context, w io.Writer) error {
+	if err := h.writeHeader(w); err != nil {
+		return err
+	}
+	return h.each(func(values []string, child *Histogram) error {
+		var cumulative uint64
+		for i, upperBound := range child.upperBounds {
+			cumulative += atomic.LoadUint64(&child.counts[i])
+			err := writeSample(w, h.name+"_bucket", h.labels, values, "le", formatFloat(upperBound), float64(cumulative))
+			if err != nil {
+				return err
+			}
+		}
+		count := float64(atomic.LoadUint64(&child.count))
+		if err := writeSample(w, h.name+"_bucket", h.labels, values, "le", "+Inf", count); err != nil {
+			return err
+		}
+		if err := writeSample(w, h.name+"_sum", h.labels, values, "", "", child.sum.Load()); err != nil {
+			return err
+		}
+		return writeSample(w, h.name+"_count", h.labels, values, "", "", count)
+	})
+}
+
+// Sample is one value reported by a func collector.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// funcCollector reads its samples when the registry is scraped.
+type funcCollector struct {
+	collect func() ([]Sample, error)
+	desc
+}
+
+// NewGaugeFunc registers a gauge whose samples are read from collect on every scrape.
+func (r *Registry) NewGaugeFunc(name, help string, labels []string, collect func() ([]Sample, error)) {
+	r.register(&funcCollector{desc: desc{name: name, help: help, kind: typeGauge, labels: labels}, collect: collect})
+}
+
+// NewCounterFunc registers a counter whose samples are read from collect on every scrape.
+func (r *Registry) NewCounterFunc(name, help string, labels []string, collect func() ([]Sample, error)) {
+	r.register(&funcCollector{desc: desc{name: name, help: help, kind: typeCounter, labels: labels}, collect: collect})
+}
+
+func (f *funcCollector) write(ctx context.Context, w io.Writer) error {
+	samples, err := f.collect()
+	if err != nil {
+		// A failing source shouldn't break the whole scrape, skip its family.
+		logging.FromContext(ctx).ErrorContext(ctx, "failed to collect metric", slog.String("metric", f.name), slog.Any("error", err))
+		return nil
+	}
+	if err = f.writeHeader(w); err != nil {
+		return err
+	}
+	for _, sample := range samples {
+		f.checkLabels(sample.LabelValues)
+		if err = writeSample(w, f.name, f.labels, sample.LabelValues, "", "", sample.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSample(w io.Writer, name string, labels, values []string, extraLabel, extraValue string, value float64) error {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		b.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(label)
+			b.WriteString(`="`)
+			b.WriteString(escapeLabelValue(values[i]))
+			b.WriteByte('"')
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(extraLabel)
+			b.WriteString(`="`)
+			b.WriteString(extraValue)
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(value))
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/logging"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Metrics Suite")
+}
+
+var _ = Describe("Registry", func() {
+	var registry *Registry
+
+	BeforeEach(func() {
+		registry = NewRegistry()
+	})
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/plain; version=0.0.4"))
+		return recorder.Body.String()
+	}
+
+	It("renders counters sorted by label values", func() {
+		counter := registry.NewCounterVec("requests_total", "Requests.", "code")
+		counter.WithLabelValues("500").Inc()
+		counter.WithLabelValues("200").Add(2)
+
+		Expect(scrape()).To(Equal("# HELP requests_total Requests.\n" +
+			"# TYPE requests_total counter\n" +
+			"requests_total{code=\"200\"} 2\n" +
+			"requests_total{code=\"500\"} 1\n"))
+	})
+
+	It("renders gauges without labels", func() {
+		gauge := registry.NewGaugeVec("in_flight", "In flight.").WithLabelValues()
+		gauge.Inc()
+		gauge.Inc()
+		gauge.Dec()
+
+		Expect(scrape()).To(ContainSubstring("in_flight 1\n"))
+	})
+
+	It("renders cumulative histogram buckets, sum and count", func() {
+		histogram := registry.NewHistogramVec("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+		histogram.WithLabelValues("/tasks").Observe(0.05)
+		histogram.WithLabelValues("/tasks").Observe(0.5)
+		histogram.WithLabelValues("/tasks").Observe(3)
+
+		Expect(scrape()).To(Equal("# HELP latency_seconds Latency.\n" +
+			"# TYPE latency_seconds histogram\n" +
+			"latency_seconds_bucket{route=\"/tasks\",le=\"0.1\"} 1\n" +
+			"latency_seconds_bucket{route=\"/tasks\",le=\"1\"} 2\n" +
+			"latency_seconds_bucket{route=\"/tasks\",le=\"+Inf\"} 3\n" +
+			"latency_seconds_sum{route=\"/tasks\"} 3.55\n" +
+			"latency_seconds_count{route=\"/tasks\"} 3\n"))
+	})
+
+	It("escapes label values and help text", func() {
+		registry.NewCounterVec("odd_total", "Line\nbreak.", "value").WithLabelValues("a\"b\\c\nd").Inc()
+
+		Expect(scrape()).To(ContainSubstring("# HELP odd_total Line\\nbreak.\n"))
+		Expect(scrape()).To(ContainSubstring(`odd_total{value="a\"b\\c\nd"} 1`))
+	})
+
+	It("reads func collectors on every scrape and skips failing ones", func() {
+		value := 1.0
+		registry.NewGaugeFunc("tasks", "Tasks.", []string{"status"}, func() ([]Sample, error) {
+			return []Sample{{LabelValues: []string{"done"}, Value: value}}, nil
+		})
+		registry.NewGaugeFunc("broken", "Broken.", nil, func() ([]Sample, error) {
+			return nil, errors.New("unavailable")
+		})
+
+		Expect(scrape()).To(ContainSubstring(`tasks{status="done"} 1`))
+		value = 4
+		output := scrape()
+		Expect(output).To(ContainSubstring(`tasks{status="done"} 4`))
+		Expect(output).ToNot(ContainSubstring("broken"))
+	})
+
+	It("logs failing func collectors with the logger of the context", func() {
+		registry.NewGaugeFunc("broken", "Broken.", nil, func() ([]Sample, error) {
+			return nil, errors.New("unavailable")
+		})
+		var logs bytes.Buffer
+		ctx := logging.WithLogger(context.Background(), logging.New(&logs, slog.LevelInfo))
+
+		Expect(registry.Write(ctx, &bytes.Buffer{})).To(Succeed())
+		Expect(logs.String()).To(ContainSubstring("failed to collect metric"))
+		Expect(logs.String()).To(ContainSubstring("broken"))
+	})
+
+	It("panics on a wrong number of label values", func() {
+		counter := registry.NewCounterVec("requests_total", "Requests.", "code")
+		Expect(func() { counter.WithLabelValues() }).To(Panic())
+	})
+
+	It("writes to any writer", func() {
+		registry.NewCounterVec("requests_total", "Requests.").WithLabelValues().Inc()
+		var buf bytes.Buffer
+		Expect(registry.Write(context.Background(), &buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("requests_total 1"))
+	})
+})
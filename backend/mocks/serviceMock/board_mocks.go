@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/service/board.go
+
+// Package serviceMock is a generated GoMock package.
+package serviceMock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/saarzur123/task-management/backend/models"
+)
+
+// MockTaskBoard is a mock of TaskBoard interface.
+type MockTaskBoard struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskBoardMockRecorder
+}
+
+// MockTaskBoardMockRecorder is the mock recorder for MockTaskBoard.
+type MockTaskBoardMockRecorder struct {
+	mock *MockTaskBoard
+}
+
+// NewMockTaskBoard creates a new mock instance.
+func NewMockTaskBoard(ctrl *gomock.Controller) *MockTaskBoard {
+	mock := &MockTaskBoard{ctrl: ctrl}
+	mock.recorder = &MockTaskBoardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskBoard) EXPECT() *MockTaskBoardMockRecorder {
+	return m.recorder
+}
+
+// Board mocks base method.
+func (m *MockTaskBoard) Board(ctx context.Context) (*models.Board, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Board", ctx)
+	ret0, _ := ret[0].(*models.Board)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Board indicates an expected call of Board.
+func (mr *MockTaskBoardMockRecorder) Board(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Board", reflect.TypeOf((*MockTaskBoard)(nil).Board), ctx)
+}
+
+// Move mocks base method.
+func (m *MockTaskBoard) Move(ctx context.Context, id string, move models.TaskMove) (*models.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", ctx, id, move)
+	ret0, _ := ret[0].(*models.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockTaskBoardMockRecorder) Move(ctx, id, move interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockTaskBoard)(nil).Move), ctx, id, move)
+}
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/service/flow.go
+
+// Package serviceMock is a generated GoMock package.
+package serviceMock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/saarzur123/task-management/backend/models"
+)
+
+// MockFlowReporter is a mock of FlowReporter interface.
+type MockFlowReporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockFlowReporterMockRecorder
+}
+
+// MockFlowReporterMockRecorder is the mock recorder for MockFlowReporter.
+type MockFlowReporterMockRecorder struct {
+	mock *MockFlowReporter
+}
+
+// NewMockFlowReporter creates a new mock instance.
+func NewMockFlowReporter(ctrl *gomock.Controller) *MockFlowReporter {
+	mock := &MockFlowReporter{ctrl: ctrl}
+	mock.recorder = &MockFlowReporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFlowReporter) EXPECT() *MockFlowReporterMockRecorder {
+	return m.recorder
+}
+
+// Burndown mocks base method.
+func (m *MockFlowReporter) Burndown(ctx context.Context, sprintID string) (*models.Burndown, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Burndown", ctx, sprintID)
+	ret0, _ := ret[0].(*models.Burndown)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Burndown indicates an expected call of Burndown.
+func (mr *MockFlowReporterMockRecorder) Burndown(ctx, sprintID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Burndown", reflect.TypeOf((*MockFlowReporter)(nil).Burndown), ctx, sprintID)
+}
+
+// CumulativeFlow mocks base method.
+func (m *MockFlowReporter) CumulativeFlow(ctx context.Context, from, to time.Time) (*models.CumulativeFlow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CumulativeFlow", ctx, from, to)
+	ret0, _ := ret[0].(*models.CumulativeFlow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CumulativeFlow indicates an expected call of CumulativeFlow.
+func (mr *MockFlowReporterMockRecorder) CumulativeFlow(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CumulativeFlow", reflect.TypeOf((*MockFlowReporter)(nil).CumulativeFlow), ctx, from, to)
+}
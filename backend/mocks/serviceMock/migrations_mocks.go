@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/service/migrations.go
+
+// Package serviceMock is a generated GoMock package.
+package serviceMock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReadinessChecker is a mock of ReadinessChecker interface.
+type MockReadinessChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockReadinessCheckerMockRecorder
+}
+
+// MockReadinessCheckerMockRecorder is the mock recorder for MockReadinessChecker.
+type MockReadinessCheckerMockRecorder struct {
+	mock *MockReadinessChecker
+}
+
+// NewMockReadinessChecker creates a new mock instance.
+func NewMockReadinessChecker(ctrl *gomock.Controller) *MockReadinessChecker {
+	mock := &MockReadinessChecker{ctrl: ctrl}
+	mock.recorder = &MockReadinessCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReadinessChecker) EXPECT() *MockReadinessCheckerMockRecorder {
+	return m.recorder
+}
+
+// Ready mocks base method.
+func (m *MockReadinessChecker) Ready(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockReadinessCheckerMockRecorder) Ready(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockReadinessChecker)(nil).Ready), ctx)
+}
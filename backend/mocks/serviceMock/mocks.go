@@ -5,6 +5,7 @@
 package serviceMock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,73 +36,149 @@ func (m *MockTaskRepository) EXPECT() *MockTaskRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockTaskRepository) Create(task *models.Task) error {
+func (m *MockTaskRepository) Create(ctx context.Context, task *models.Task) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", task)
+	ret := m.ctrl.Call(m, "Create", ctx, task)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockTaskRepositoryMockRecorder) Create(task interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryMockRecorder) Create(ctx, task interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskRepository)(nil).Create), task)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskRepository)(nil).Create), ctx, task)
 }
 
 // Delete mocks base method.
-func (m *MockTaskRepository) Delete(id string) error {
+func (m *MockTaskRepository) Delete(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockTaskRepositoryMockRecorder) Delete(id interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTaskRepository)(nil).Delete), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTaskRepository)(nil).Delete), ctx, id)
 }
 
 // GetAll mocks base method.
-func (m *MockTaskRepository) GetAll() ([]models.Task, error) {
+func (m *MockTaskRepository) GetAll(ctx context.Context) ([]models.Task, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll")
+	ret := m.ctrl.Call(m, "GetAll", ctx)
 	ret0, _ := ret[0].([]models.Task)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockTaskRepositoryMockRecorder) GetAll() *gomock.Call {
+func (mr *MockTaskRepositoryMockRecorder) GetAll(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTaskRepository)(nil).GetAll))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTaskRepository)(nil).GetAll), ctx)
 }
 
 // GetByID mocks base method.
-func (m *MockTaskRepository) GetByID(id string) (*models.Task, error) {
+func (m *MockTaskRepository) GetByID(ctx context.Context, id string) (*models.Task, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", id)
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
 	ret0, _ := ret[0].(*models.Task)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByID indicates an expected call of GetByID.
-func (mr *MockTaskRepositoryMockRecorder) GetByID(id interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockTaskRepository)(nil).GetByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockTaskRepository)(nil).GetByID), ctx, id)
 }
 
 // Update mocks base method.
-func (m *MockTaskRepository) Update(task *models.Task) error {
+func (m *MockTaskRepository) Update(ctx context.Context, task *models.Task) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", task)
+	ret := m.ctrl.Call(m, "Update", ctx, task)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockTaskRepositoryMockRecorder) Update(task interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryMockRecorder) Update(ctx, task interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaskRepository)(nil).Update), task)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaskRepository)(nil).Update), ctx, task)
+}
+
+// MockTaskPageReader is a mock of TaskPageReader interface.
+type MockTaskPageReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskPageReaderMockRecorder
+}
+
+// MockTaskPageReaderMockRecorder is the mock recorder for MockTaskPageReader.
+type MockTaskPageReaderMockRecorder struct {
+	mock *MockTaskPageReader
+}
+
+// NewMockTaskPageReader creates a new mock instance.
+func NewMockTaskPageReader(ctrl *gomock.Controller) *MockTaskPageReader {
+	mock := &MockTaskPageReader{ctrl: ctrl}
+	mock.recorder = &MockTaskPageReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskPageReader) EXPECT() *MockTaskPageReaderMockRecorder {
+	return m.recorder
+}
+
+// GetPage mocks base method.
+func (m *MockTaskPageReader) GetPage(ctx context.Context, after string, limit int) ([]models.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPage", ctx, after, limit)
+	ret0, _ := ret[0].([]models.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPage indicates an expected call of GetPage.
+func (mr *MockTaskPageReaderMockRecorder) GetPage(ctx, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPage", reflect.TypeOf((*MockTaskPageReader)(nil).GetPage), ctx, after, limit)
+}
+
+// MockTaskBatchReader is a mock of TaskBatchReader interface.
+type MockTaskBatchReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskBatchReaderMockRecorder
+}
+
+// MockTaskBatchReaderMockRecorder is the mock recorder for MockTaskBatchReader.
+type MockTaskBatchReaderMockRecorder struct {
+	mock *MockTaskBatchReader
+}
+
+// NewMockTaskBatchReader creates a new mock instance.
+func NewMockTaskBatchReader(ctrl *gomock.Controller) *MockTaskBatchReader {
+	mock := &MockTaskBatchReader{ctrl: ctrl}
+	mock.recorder = &MockTaskBatchReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskBatchReader) EXPECT() *MockTaskBatchReaderMockRecorder {
+	return m.recorder
+}
+
+// GetByIDs mocks base method.
+func (m *MockTaskBatchReader) GetByIDs(ctx context.Context, ids []string) ([]models.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDs", ctx, ids)
+	ret0, _ := ret[0].([]models.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDs indicates an expected call of GetByIDs.
+func (mr *MockTaskBatchReaderMockRecorder) GetByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDs", reflect.TypeOf((*MockTaskBatchReader)(nil).GetByIDs), ctx, ids)
 }
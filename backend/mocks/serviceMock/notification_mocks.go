@@ -0,0 +1,264 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/service/notification.go
+
+// Package serviceMock is a generated GoMock package.
+package serviceMock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/saarzur123/task-management/backend/models"
+)
+
+// MockNotificationPreferencesRepository is a mock of NotificationPreferencesRepository interface.
+type MockNotificationPreferencesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationPreferencesRepositoryMockRecorder
+}
+
+// MockNotificationPreferencesRepositoryMockRecorder is the mock recorder for MockNotificationPreferencesRepository.
+type MockNotificationPreferencesRepositoryMockRecorder struct {
+	mock *MockNotificationPreferencesRepository
+}
+
+// NewMockNotificationPreferencesRepository creates a new mock instance.
+func NewMockNotificationPreferencesRepository(ctrl *gomock.Controller) *MockNotificationPreferencesRepository {
+	mock := &MockNotificationPreferencesRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationPreferencesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationPreferencesRepository) EXPECT() *MockNotificationPreferencesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetPreferences mocks base method.
+func (m *MockNotificationPreferencesRepository) GetPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", ctx, userID)
+	ret0, _ := ret[0].(*models.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockNotificationPreferencesRepositoryMockRecorder) GetPreferences(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockNotificationPreferencesRepository)(nil).GetPreferences), ctx, userID)
+}
+
+// SavePreferences mocks base method.
+func (m *MockNotificationPreferencesRepository) SavePreferences(ctx context.Context, prefs *models.NotificationPreferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePreferences", ctx, prefs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePreferences indicates an expected call of SavePreferences.
+func (mr *MockNotificationPreferencesRepositoryMockRecorder) SavePreferences(ctx, prefs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePreferences", reflect.TypeOf((*MockNotificationPreferencesRepository)(nil).SavePreferences), ctx, prefs)
+}
+
+// MockReminderStore is a mock of ReminderStore interface.
+type MockReminderStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockReminderStoreMockRecorder
+}
+
+// MockReminderStoreMockRecorder is the mock recorder for MockReminderStore.
+type MockReminderStoreMockRecorder struct {
+	mock *MockReminderStore
+}
+
+// NewMockReminderStore creates a new mock instance.
+func NewMockReminderStore(ctrl *gomock.Controller) *MockReminderStore {
+	mock := &MockReminderStore{ctrl: ctrl}
+	mock.recorder = &MockReminderStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReminderStore) EXPECT() *MockReminderStoreMockRecorder {
+	return m.recorder
+}
+
+// ClaimReminder mocks base method.
+func (m *MockReminderStore) ClaimReminder(ctx context.Context, reminder *models.Reminder, at time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimReminder", ctx, reminder, at)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimReminder indicates an expected call of ClaimReminder.
+func (mr *MockReminderStoreMockRecorder) ClaimReminder(ctx, reminder, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimReminder", reflect.TypeOf((*MockReminderStore)(nil).ClaimReminder), ctx, reminder, at)
+}
+
+// DueTasks mocks base method.
+func (m *MockReminderStore) DueTasks(ctx context.Context, before time.Time) ([]models.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DueTasks", ctx, before)
+	ret0, _ := ret[0].([]models.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DueTasks indicates an expected call of DueTasks.
+func (mr *MockReminderStoreMockRecorder) DueTasks(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DueTasks", reflect.TypeOf((*MockReminderStore)(nil).DueTasks), ctx, before)
+}
+
+// FailReminder mocks base method.
+func (m *MockReminderStore) FailReminder(ctx context.Context, reminder *models.Reminder, retryAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailReminder", ctx, reminder, retryAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailReminder indicates an expected call of FailReminder.
+func (mr *MockReminderStoreMockRecorder) FailReminder(ctx, reminder, retryAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailReminder", reflect.TypeOf((*MockReminderStore)(nil).FailReminder), ctx, reminder, retryAt)
+}
+
+// MarkReminded mocks base method.
+func (m *MockReminderStore) MarkReminded(ctx context.Context, task *models.Task, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkReminded", ctx, task, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkReminded indicates an expected call of MarkReminded.
+func (mr *MockReminderStoreMockRecorder) MarkReminded(ctx, task, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReminded", reflect.TypeOf((*MockReminderStore)(nil).MarkReminded), ctx, task, at)
+}
+
+// ReleaseReminder mocks base method.
+func (m *MockReminderStore) ReleaseReminder(ctx context.Context, reminder *models.Reminder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseReminder", ctx, reminder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseReminder indicates an expected call of ReleaseReminder.
+func (mr *MockReminderStoreMockRecorder) ReleaseReminder(ctx, reminder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseReminder", reflect.TypeOf((*MockReminderStore)(nil).ReleaseReminder), ctx, reminder)
+}
+
+// MockNotificationStore is a mock of NotificationStore interface.
+type MockNotificationStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationStoreMockRecorder
+}
+
+// MockNotificationStoreMockRecorder is the mock recorder for MockNotificationStore.
+type MockNotificationStoreMockRecorder struct {
+	mock *MockNotificationStore
+}
+
+// NewMockNotificationStore creates a new mock instance.
+func NewMockNotificationStore(ctrl *gomock.Controller) *MockNotificationStore {
+	mock := &MockNotificationStore{ctrl: ctrl}
+	mock.recorder = &MockNotificationStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationStore) EXPECT() *MockNotificationStoreMockRecorder {
+	return m.recorder
+}
+
+// AddNotification mocks base method.
+func (m *MockNotificationStore) AddNotification(ctx context.Context, notification *models.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddNotification", ctx, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddNotification indicates an expected call of AddNotification.
+func (mr *MockNotificationStoreMockRecorder) AddNotification(ctx, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNotification", reflect.TypeOf((*MockNotificationStore)(nil).AddNotification), ctx, notification)
+}
+
+// MockNotificationInbox is a mock of NotificationInbox interface.
+type MockNotificationInbox struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationInboxMockRecorder
+}
+
+// MockNotificationInboxMockRecorder is the mock recorder for MockNotificationInbox.
+type MockNotificationInboxMockRecorder struct {
+	mock *MockNotificationInbox
+}
+
+// NewMockNotificationInbox creates a new mock instance.
+func NewMockNotificationInbox(ctrl *gomock.Controller) *MockNotificationInbox {
+	mock := &MockNotificationInbox{ctrl: ctrl}
+	mock.recorder = &MockNotificationInboxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationInbox) EXPECT() *MockNotificationInboxMockRecorder {
+	return m.recorder
+}
+
+// Inbox mocks base method.
+func (m *MockNotificationInbox) Inbox(ctx context.Context, userID, before string, limit int, unreadOnly bool) (*models.Inbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Inbox", ctx, userID, before, limit, unreadOnly)
+	ret0, _ := ret[0].(*models.Inbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Inbox indicates an expected call of Inbox.
+func (mr *MockNotificationInboxMockRecorder) Inbox(ctx, userID, before, limit, unreadOnly interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inbox", reflect.TypeOf((*MockNotificationInbox)(nil).Inbox), ctx, userID, before, limit, unreadOnly)
+}
+
+// MarkAllRead mocks base method.
+func (m *MockNotificationInbox) MarkAllRead(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllRead", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAllRead indicates an expected call of MarkAllRead.
+func (mr *MockNotificationInboxMockRecorder) MarkAllRead(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllRead", reflect.TypeOf((*MockNotificationInbox)(nil).MarkAllRead), ctx, userID)
+}
+
+// MarkRead mocks base method.
+func (m *MockNotificationInbox) MarkRead(ctx context.Context, userID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockNotificationInboxMockRecorder) MarkRead(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockNotificationInbox)(nil).MarkRead), ctx, userID, id)
+}
@@ -0,0 +1,89 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/service/outbox.go
+
+// Package serviceMock is a generated GoMock package.
+package serviceMock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/saarzur123/task-management/backend/models"
+)
+
+// MockOutboxReader is a mock of OutboxReader interface.
+type MockOutboxReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxReaderMockRecorder
+}
+
+// MockOutboxReaderMockRecorder is the mock recorder for MockOutboxReader.
+type MockOutboxReaderMockRecorder struct {
+	mock *MockOutboxReader
+}
+
+// NewMockOutboxReader creates a new mock instance.
+func NewMockOutboxReader(ctrl *gomock.Controller) *MockOutboxReader {
+	mock := &MockOutboxReader{ctrl: ctrl}
+	mock.recorder = &MockOutboxReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxReader) EXPECT() *MockOutboxReaderMockRecorder {
+	return m.recorder
+}
+
+// After mocks base method.
+func (m *MockOutboxReader) After(ctx context.Context, afterID uint64, limit int) ([]models.TaskEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "After", ctx, afterID, limit)
+	ret0, _ := ret[0].([]models.TaskEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// After indicates an expected call of After.
+func (mr *MockOutboxReaderMockRecorder) After(ctx, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "After", reflect.TypeOf((*MockOutboxReader)(nil).After), ctx, afterID, limit)
+}
+
+// MockTaskHistoryReader is a mock of TaskHistoryReader interface.
+type MockTaskHistoryReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskHistoryReaderMockRecorder
+}
+
+// MockTaskHistoryReaderMockRecorder is the mock recorder for MockTaskHistoryReader.
+type MockTaskHistoryReaderMockRecorder struct {
+	mock *MockTaskHistoryReader
+}
+
+// NewMockTaskHistoryReader creates a new mock instance.
+func NewMockTaskHistoryReader(ctrl *gomock.Controller) *MockTaskHistoryReader {
+	mock := &MockTaskHistoryReader{ctrl: ctrl}
+	mock.recorder = &MockTaskHistoryReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskHistoryReader) EXPECT() *MockTaskHistoryReaderMockRecorder {
+	return m.recorder
+}
+
+// History mocks base method.
+func (m *MockTaskHistoryReader) History(ctx context.Context, taskIDs []string, limit int) ([]models.TaskEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, taskIDs, limit)
+	ret0, _ := ret[0].([]models.TaskEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockTaskHistoryReaderMockRecorder) History(ctx, taskIDs, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockTaskHistoryReader)(nil).History), ctx, taskIDs, limit)
+}
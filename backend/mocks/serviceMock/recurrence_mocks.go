@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/service/recurrence.go
+
+// Package serviceMock is a generated GoMock package.
+package serviceMock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/saarzur123/task-management/backend/models"
+)
+
+// MockRecurringTasks is a mock of RecurringTasks interface.
+type MockRecurringTasks struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecurringTasksMockRecorder
+}
+
+// MockRecurringTasksMockRecorder is the mock recorder for MockRecurringTasks.
+type MockRecurringTasksMockRecorder struct {
+	mock *MockRecurringTasks
+}
+
+// NewMockRecurringTasks creates a new mock instance.
+func NewMockRecurringTasks(ctrl *gomock.Controller) *MockRecurringTasks {
+	mock := &MockRecurringTasks{ctrl: ctrl}
+	mock.recorder = &MockRecurringTasksMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecurringTasks) EXPECT() *MockRecurringTasksMockRecorder {
+	return m.recorder
+}
+
+// CreateOccurrence mocks base method.
+func (m *MockRecurringTasks) CreateOccurrence(ctx context.Context, previous, next *models.Task) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOccurrence", ctx, previous, next)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOccurrence indicates an expected call of CreateOccurrence.
+func (mr *MockRecurringTasksMockRecorder) CreateOccurrence(ctx, previous, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOccurrence", reflect.TypeOf((*MockRecurringTasks)(nil).CreateOccurrence), ctx, previous, next)
+}
+
+// RecurringHeads mocks base method.
+func (m *MockRecurringTasks) RecurringHeads(ctx context.Context) ([]models.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecurringHeads", ctx)
+	ret0, _ := ret[0].([]models.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecurringHeads indicates an expected call of RecurringHeads.
+func (mr *MockRecurringTasksMockRecorder) RecurringHeads(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecurringHeads", reflect.TypeOf((*MockRecurringTasks)(nil).RecurringHeads), ctx)
+}
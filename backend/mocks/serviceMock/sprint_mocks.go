@@ -0,0 +1,153 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/service/sprint.go
+
+// Package serviceMock is a generated GoMock package.
+package serviceMock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/saarzur123/task-management/backend/models"
+)
+
+// MockSprintPlanner is a mock of SprintPlanner interface.
+type MockSprintPlanner struct {
+	ctrl     *gomock.Controller
+	recorder *MockSprintPlannerMockRecorder
+}
+
+// MockSprintPlannerMockRecorder is the mock recorder for MockSprintPlanner.
+type MockSprintPlannerMockRecorder struct {
+	mock *MockSprintPlanner
+}
+
+// NewMockSprintPlanner creates a new mock instance.
+func NewMockSprintPlanner(ctrl *gomock.Controller) *MockSprintPlanner {
+	mock := &MockSprintPlanner{ctrl: ctrl}
+	mock.recorder = &MockSprintPlannerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSprintPlanner) EXPECT() *MockSprintPlannerMockRecorder {
+	return m.recorder
+}
+
+// CarryOver mocks base method.
+func (m *MockSprintPlanner) CarryOver(ctx context.Context, fromID, toID string) (*models.CarryOver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CarryOver", ctx, fromID, toID)
+	ret0, _ := ret[0].(*models.CarryOver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CarryOver indicates an expected call of CarryOver.
+func (mr *MockSprintPlannerMockRecorder) CarryOver(ctx, fromID, toID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CarryOver", reflect.TypeOf((*MockSprintPlanner)(nil).CarryOver), ctx, fromID, toID)
+}
+
+// CreateSprint mocks base method.
+func (m *MockSprintPlanner) CreateSprint(ctx context.Context, sprint *models.Sprint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSprint", ctx, sprint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSprint indicates an expected call of CreateSprint.
+func (mr *MockSprintPlannerMockRecorder) CreateSprint(ctx, sprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSprint", reflect.TypeOf((*MockSprintPlanner)(nil).CreateSprint), ctx, sprint)
+}
+
+// DeleteSprint mocks base method.
+func (m *MockSprintPlanner) DeleteSprint(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSprint", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSprint indicates an expected call of DeleteSprint.
+func (mr *MockSprintPlannerMockRecorder) DeleteSprint(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSprint", reflect.TypeOf((*MockSprintPlanner)(nil).DeleteSprint), ctx, id)
+}
+
+// GetSprint mocks base method.
+func (m *MockSprintPlanner) GetSprint(ctx context.Context, id string) (*models.Sprint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSprint", ctx, id)
+	ret0, _ := ret[0].(*models.Sprint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSprint indicates an expected call of GetSprint.
+func (mr *MockSprintPlannerMockRecorder) GetSprint(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSprint", reflect.TypeOf((*MockSprintPlanner)(nil).GetSprint), ctx, id)
+}
+
+// ListSprints mocks base method.
+func (m *MockSprintPlanner) ListSprints(ctx context.Context) ([]models.Sprint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSprints", ctx)
+	ret0, _ := ret[0].([]models.Sprint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSprints indicates an expected call of ListSprints.
+func (mr *MockSprintPlannerMockRecorder) ListSprints(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSprints", reflect.TypeOf((*MockSprintPlanner)(nil).ListSprints), ctx)
+}
+
+// SprintProgress mocks base method.
+func (m *MockSprintPlanner) SprintProgress(ctx context.Context, id string) (*models.SprintProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SprintProgress", ctx, id)
+	ret0, _ := ret[0].(*models.SprintProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SprintProgress indicates an expected call of SprintProgress.
+func (mr *MockSprintPlannerMockRecorder) SprintProgress(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SprintProgress", reflect.TypeOf((*MockSprintPlanner)(nil).SprintProgress), ctx, id)
+}
+
+// SprintTasks mocks base method.
+func (m *MockSprintPlanner) SprintTasks(ctx context.Context, id string) ([]models.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SprintTasks", ctx, id)
+	ret0, _ := ret[0].([]models.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SprintTasks indicates an expected call of SprintTasks.
+func (mr *MockSprintPlannerMockRecorder) SprintTasks(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SprintTasks", reflect.TypeOf((*MockSprintPlanner)(nil).SprintTasks), ctx, id)
+}
+
+// UpdateSprint mocks base method.
+func (m *MockSprintPlanner) UpdateSprint(ctx context.Context, sprint *models.Sprint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSprint", ctx, sprint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSprint indicates an expected call of UpdateSprint.
+func (mr *MockSprintPlannerMockRecorder) UpdateSprint(ctx, sprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSprint", reflect.TypeOf((*MockSprintPlanner)(nil).UpdateSprint), ctx, sprint)
+}
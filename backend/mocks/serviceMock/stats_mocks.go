@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/service/stats.go
+
+// Package serviceMock is a generated GoMock package.
+package serviceMock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/saarzur123/task-management/backend/models"
+)
+
+// MockTaskStatsReader is a mock of TaskStatsReader interface.
+type MockTaskStatsReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskStatsReaderMockRecorder
+}
+
+// MockTaskStatsReaderMockRecorder is the mock recorder for MockTaskStatsReader.
+type MockTaskStatsReaderMockRecorder struct {
+	mock *MockTaskStatsReader
+}
+
+// NewMockTaskStatsReader creates a new mock instance.
+func NewMockTaskStatsReader(ctrl *gomock.Controller) *MockTaskStatsReader {
+	mock := &MockTaskStatsReader{ctrl: ctrl}
+	mock.recorder = &MockTaskStatsReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskStatsReader) EXPECT() *MockTaskStatsReaderMockRecorder {
+	return m.recorder
+}
+
+// Stats mocks base method.
+func (m *MockTaskStatsReader) Stats(ctx context.Context) (*models.TaskStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", ctx)
+	ret0, _ := ret[0].(*models.TaskStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockTaskStatsReaderMockRecorder) Stats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockTaskStatsReader)(nil).Stats), ctx)
+}
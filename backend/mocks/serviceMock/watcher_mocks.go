@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/service/watcher.go
+
+// Package serviceMock is a generated GoMock package.
+package serviceMock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/saarzur123/task-management/backend/models"
+)
+
+// MockWatcherRepository is a mock of WatcherRepository interface.
+type MockWatcherRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWatcherRepositoryMockRecorder
+}
+
+// MockWatcherRepositoryMockRecorder is the mock recorder for MockWatcherRepository.
+type MockWatcherRepositoryMockRecorder struct {
+	mock *MockWatcherRepository
+}
+
+// NewMockWatcherRepository creates a new mock instance.
+func NewMockWatcherRepository(ctrl *gomock.Controller) *MockWatcherRepository {
+	mock := &MockWatcherRepository{ctrl: ctrl}
+	mock.recorder = &MockWatcherRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWatcherRepository) EXPECT() *MockWatcherRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Unwatch mocks base method.
+func (m *MockWatcherRepository) Unwatch(ctx context.Context, taskID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unwatch", ctx, taskID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unwatch indicates an expected call of Unwatch.
+func (mr *MockWatcherRepositoryMockRecorder) Unwatch(ctx, taskID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unwatch", reflect.TypeOf((*MockWatcherRepository)(nil).Unwatch), ctx, taskID, userID)
+}
+
+// Watch mocks base method.
+func (m *MockWatcherRepository) Watch(ctx context.Context, taskID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", ctx, taskID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockWatcherRepositoryMockRecorder) Watch(ctx, taskID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockWatcherRepository)(nil).Watch), ctx, taskID, userID)
+}
+
+// Watchers mocks base method.
+func (m *MockWatcherRepository) Watchers(ctx context.Context, taskID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watchers", ctx, taskID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watchers indicates an expected call of Watchers.
+func (mr *MockWatcherRepositoryMockRecorder) Watchers(ctx, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watchers", reflect.TypeOf((*MockWatcherRepository)(nil).Watchers), ctx, taskID)
+}
+
+// Watching mocks base method.
+func (m *MockWatcherRepository) Watching(ctx context.Context, userID, after string, limit int) ([]models.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watching", ctx, userID, after, limit)
+	ret0, _ := ret[0].([]models.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watching indicates an expected call of Watching.
+func (mr *MockWatcherRepositoryMockRecorder) Watching(ctx, userID, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watching", reflect.TypeOf((*MockWatcherRepository)(nil).Watching), ctx, userID, after, limit)
+}
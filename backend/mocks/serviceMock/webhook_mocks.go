@@ -0,0 +1,279 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/service/webhook.go
+
+// Package serviceMock is a generated GoMock package.
+package serviceMock
+
+import (
+	context "context"
+	sql "database/sql"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/saarzur123/task-management/backend/models"
+)
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWebhookRepository) Create(ctx context.Context, hook *models.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, hook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhookRepositoryMockRecorder) Create(ctx, hook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhookRepository)(nil).Create), ctx, hook)
+}
+
+// Delete mocks base method.
+func (m *MockWebhookRepository) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWebhookRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWebhookRepository)(nil).Delete), ctx, id)
+}
+
+// Deliveries mocks base method.
+func (m *MockWebhookRepository) Deliveries(ctx context.Context, webhookID string) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliveries", ctx, webhookID)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deliveries indicates an expected call of Deliveries.
+func (mr *MockWebhookRepositoryMockRecorder) Deliveries(ctx, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliveries", reflect.TypeOf((*MockWebhookRepository)(nil).Deliveries), ctx, webhookID)
+}
+
+// Enable mocks base method.
+func (m *MockWebhookRepository) Enable(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockWebhookRepositoryMockRecorder) Enable(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockWebhookRepository)(nil).Enable), ctx, id)
+}
+
+// GetAll mocks base method.
+func (m *MockWebhookRepository) GetAll(ctx context.Context) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockWebhookRepositoryMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockWebhookRepository)(nil).GetAll), ctx)
+}
+
+// GetByID mocks base method.
+func (m *MockWebhookRepository) GetByID(ctx context.Context, id string) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockWebhookRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockWebhookRepository)(nil).GetByID), ctx, id)
+}
+
+// Replay mocks base method.
+func (m *MockWebhookRepository) Replay(ctx context.Context, webhookID, deliveryID string) (*models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replay", ctx, webhookID, deliveryID)
+	ret0, _ := ret[0].(*models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replay indicates an expected call of Replay.
+func (mr *MockWebhookRepositoryMockRecorder) Replay(ctx, webhookID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replay", reflect.TypeOf((*MockWebhookRepository)(nil).Replay), ctx, webhookID, deliveryID)
+}
+
+// MockWebhookQueue is a mock of WebhookQueue interface.
+type MockWebhookQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookQueueMockRecorder
+}
+
+// MockWebhookQueueMockRecorder is the mock recorder for MockWebhookQueue.
+type MockWebhookQueueMockRecorder struct {
+	mock *MockWebhookQueue
+}
+
+// NewMockWebhookQueue creates a new mock instance.
+func NewMockWebhookQueue(ctrl *gomock.Controller) *MockWebhookQueue {
+	mock := &MockWebhookQueue{ctrl: ctrl}
+	mock.recorder = &MockWebhookQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookQueue) EXPECT() *MockWebhookQueueMockRecorder {
+	return m.recorder
+}
+
+// Cursor mocks base method.
+func (m *MockWebhookQueue) Cursor(ctx context.Context) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cursor", ctx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cursor indicates an expected call of Cursor.
+func (mr *MockWebhookQueueMockRecorder) Cursor(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cursor", reflect.TypeOf((*MockWebhookQueue)(nil).Cursor), ctx)
+}
+
+// Due mocks base method.
+func (m *MockWebhookQueue) Due(ctx context.Context, now time.Time, limit int) ([]models.PendingDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Due", ctx, now, limit)
+	ret0, _ := ret[0].([]models.PendingDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Due indicates an expected call of Due.
+func (mr *MockWebhookQueueMockRecorder) Due(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Due", reflect.TypeOf((*MockWebhookQueue)(nil).Due), ctx, now, limit)
+}
+
+// Enqueue mocks base method.
+func (m *MockWebhookQueue) Enqueue(ctx context.Context, event models.TaskEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockWebhookQueueMockRecorder) Enqueue(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockWebhookQueue)(nil).Enqueue), ctx, event)
+}
+
+// MarkFailed mocks base method.
+func (m *MockWebhookQueue) MarkFailed(ctx context.Context, delivery *models.PendingDelivery, statusCode int, reason string, retryAt *time.Time, maxFailures int, at time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ctx, delivery, statusCode, reason, retryAt, maxFailures, at)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockWebhookQueueMockRecorder) MarkFailed(ctx, delivery, statusCode, reason, retryAt, maxFailures, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockWebhookQueue)(nil).MarkFailed), ctx, delivery, statusCode, reason, retryAt, maxFailures, at)
+}
+
+// MarkSucceeded mocks base method.
+func (m *MockWebhookQueue) MarkSucceeded(ctx context.Context, delivery *models.PendingDelivery, statusCode int, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSucceeded", ctx, delivery, statusCode, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSucceeded indicates an expected call of MarkSucceeded.
+func (mr *MockWebhookQueueMockRecorder) MarkSucceeded(ctx, delivery, statusCode, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSucceeded", reflect.TypeOf((*MockWebhookQueue)(nil).MarkSucceeded), ctx, delivery, statusCode, at)
+}
+
+// Mockexecer is a mock of execer interface.
+type Mockexecer struct {
+	ctrl     *gomock.Controller
+	recorder *MockexecerMockRecorder
+}
+
+// MockexecerMockRecorder is the mock recorder for Mockexecer.
+type MockexecerMockRecorder struct {
+	mock *Mockexecer
+}
+
+// NewMockexecer creates a new mock instance.
+func NewMockexecer(ctrl *gomock.Controller) *Mockexecer {
+	mock := &Mockexecer{ctrl: ctrl}
+	mock.recorder = &MockexecerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockexecer) EXPECT() *MockexecerMockRecorder {
+	return m.recorder
+}
+
+// ExecContext mocks base method.
+func (m *Mockexecer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecContext", varargs...)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecContext indicates an expected call of ExecContext.
+func (mr *MockexecerMockRecorder) ExecContext(ctx, query interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecContext", reflect.TypeOf((*Mockexecer)(nil).ExecContext), varargs...)
+}
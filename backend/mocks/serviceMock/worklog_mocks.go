@@ -0,0 +1,163 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: backend/service/worklog.go
+
+// Package serviceMock is a generated GoMock package.
+package serviceMock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/saarzur123/task-management/backend/models"
+)
+
+// MockTimeTracker is a mock of TimeTracker interface.
+type MockTimeTracker struct {
+	ctrl     *gomock.Controller
+	recorder *MockTimeTrackerMockRecorder
+}
+
+// MockTimeTrackerMockRecorder is the mock recorder for MockTimeTracker.
+type MockTimeTrackerMockRecorder struct {
+	mock *MockTimeTracker
+}
+
+// NewMockTimeTracker creates a new mock instance.
+func NewMockTimeTracker(ctrl *gomock.Controller) *MockTimeTracker {
+	mock := &MockTimeTracker{ctrl: ctrl}
+	mock.recorder = &MockTimeTrackerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTimeTracker) EXPECT() *MockTimeTrackerMockRecorder {
+	return m.recorder
+}
+
+// LogWork mocks base method.
+func (m *MockTimeTracker) LogWork(ctx context.Context, worklog *models.Worklog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogWork", ctx, worklog)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogWork indicates an expected call of LogWork.
+func (mr *MockTimeTrackerMockRecorder) LogWork(ctx, worklog interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogWork", reflect.TypeOf((*MockTimeTracker)(nil).LogWork), ctx, worklog)
+}
+
+// StartTimer mocks base method.
+func (m *MockTimeTracker) StartTimer(ctx context.Context, taskID, userID, note string) (*models.Worklog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartTimer", ctx, taskID, userID, note)
+	ret0, _ := ret[0].(*models.Worklog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartTimer indicates an expected call of StartTimer.
+func (mr *MockTimeTrackerMockRecorder) StartTimer(ctx, taskID, userID, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTimer", reflect.TypeOf((*MockTimeTracker)(nil).StartTimer), ctx, taskID, userID, note)
+}
+
+// StopTimer mocks base method.
+func (m *MockTimeTracker) StopTimer(ctx context.Context, taskID, userID string) (*models.Worklog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopTimer", ctx, taskID, userID)
+	ret0, _ := ret[0].(*models.Worklog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StopTimer indicates an expected call of StopTimer.
+func (mr *MockTimeTrackerMockRecorder) StopTimer(ctx, taskID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopTimer", reflect.TypeOf((*MockTimeTracker)(nil).StopTimer), ctx, taskID, userID)
+}
+
+// TaskTime mocks base method.
+func (m *MockTimeTracker) TaskTime(ctx context.Context, taskID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TaskTime", ctx, taskID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskTime indicates an expected call of TaskTime.
+func (mr *MockTimeTrackerMockRecorder) TaskTime(ctx, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskTime", reflect.TypeOf((*MockTimeTracker)(nil).TaskTime), ctx, taskID)
+}
+
+// TimeReport mocks base method.
+func (m *MockTimeTracker) TimeReport(ctx context.Context, query models.TimeReportQuery) (*models.TimeReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeReport", ctx, query)
+	ret0, _ := ret[0].(*models.TimeReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TimeReport indicates an expected call of TimeReport.
+func (mr *MockTimeTrackerMockRecorder) TimeReport(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeReport", reflect.TypeOf((*MockTimeTracker)(nil).TimeReport), ctx, query)
+}
+
+// Worklogs mocks base method.
+func (m *MockTimeTracker) Worklogs(ctx context.Context, taskID string) ([]models.Worklog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Worklogs", ctx, taskID)
+	ret0, _ := ret[0].([]models.Worklog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Worklogs indicates an expected call of Worklogs.
+func (mr *MockTimeTrackerMockRecorder) Worklogs(ctx, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Worklogs", reflect.TypeOf((*MockTimeTracker)(nil).Worklogs), ctx, taskID)
+}
+
+// MockTaskTimeReader is a mock of TaskTimeReader interface.
+type MockTaskTimeReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskTimeReaderMockRecorder
+}
+
+// MockTaskTimeReaderMockRecorder is the mock recorder for MockTaskTimeReader.
+type MockTaskTimeReaderMockRecorder struct {
+	mock *MockTaskTimeReader
+}
+
+// NewMockTaskTimeReader creates a new mock instance.
+func NewMockTaskTimeReader(ctrl *gomock.Controller) *MockTaskTimeReader {
+	mock := &MockTaskTimeReader{ctrl: ctrl}
+	mock.recorder = &MockTaskTimeReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskTimeReader) EXPECT() *MockTaskTimeReaderMockRecorder {
+	return m.recorder
+}
+
+// TaskTime mocks base method.
+func (m *MockTaskTimeReader) TaskTime(ctx context.Context, taskID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TaskTime", ctx, taskID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskTime indicates an expected call of TaskTime.
+func (mr *MockTaskTimeReaderMockRecorder) TaskTime(ctx, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskTime", reflect.TypeOf((*MockTaskTimeReader)(nil).TaskTime), ctx, taskID)
+}
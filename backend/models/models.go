@@ -1,11 +1,433 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	ScopeTasksRead   = "tasks:read"
+	ScopeTasksWrite  = "tasks:write"
+	ScopeAPIKeyAdmin = "apikeys:admin"
+)
+
+var (
+	// ErrNotFound reports a missing resource, from the services and from
+	// the API client alike.
+	ErrNotFound = errors.New("NotFound")
+)
+
+const (
+	StatusPending = "pending"
+	StatusDone    = "done"
+)
 
 type Task struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          string     `json:"id" yaml:"id"`
+	Title       string     `json:"title" yaml:"title"`
+	Description string     `json:"description" yaml:"description"`
+	Status      string     `json:"status" yaml:"status"`
+	CreatedAt   time.Time  `json:"created_at" yaml:"created_at"`
+	DueAt       *time.Time `json:"due_at,omitempty" yaml:"due_at,omitempty"`
+	// Recurrence is the RRULE of a recurring task, such as
+	// "FREQ=WEEKLY;BYDAY=MO", whose first occurrence is due at DueAt.
+	Recurrence string `json:"recurrence,omitempty" yaml:"recurrence,omitempty"`
+	// SeriesID is the ID of the first occurrence of a recurring task and
+	// Occurrence the position of the task in the series, from 1. Both are
+	// set by the server.
+	SeriesID   string `json:"series_id,omitempty" yaml:"series_id,omitempty"`
+	Occurrence int    `json:"occurrence,omitempty" yaml:"occurrence,omitempty"`
+	// Assignee is the user reminded of the due date, see
+	// NotificationPreferences.
+	Assignee string `json:"assignee,omitempty" yaml:"assignee,omitempty"`
+	// Rank orders the tasks of a status on the board, see the rank package.
+	// It is set by the server when the task is moved, the tasks never moved
+	// come last.
+	Rank string `json:"rank,omitempty" yaml:"rank,omitempty"`
+	// TimeSpent is the time logged on the task in seconds, the running
+	// timers aside. It is only set on the task returned by GET /tasks/{id}.
+	TimeSpent int64 `json:"time_spent,omitempty" yaml:"time_spent,omitempty"`
+	// StoryPoints estimates the effort of the task, zero when unestimated.
+	StoryPoints int `json:"story_points,omitempty" yaml:"story_points,omitempty"`
+	// SprintID is the sprint the task is planned in, empty for the backlog.
+	SprintID string `json:"sprint_id,omitempty" yaml:"sprint_id,omitempty"`
+}
+
+// Board lists the tasks in a column per status, each ordered by rank.
+type Board struct {
+	Columns []BoardColumn `json:"columns"`
+}
+
+type BoardColumn struct {
+	Status string `json:"status"`
+	Tasks  []Task `json:"tasks"`
+}
+
+// CompareStatuses orders the statuses as the board columns: pending first,
+// done last and the others by name in between.
+func CompareStatuses(a, b string) int {
+	return strings.Compare(statusKey(a), statusKey(b))
+}
+
+func statusKey(status string) string {
+	switch status {
+	case StatusPending:
+		return "0"
+	case StatusDone:
+		return "2"
+	}
+	return "1" + status
+}
+
+// TaskMove moves a task to Status between the tasks After and Before of its
+// column, the IDs of the cards around the drop position. An empty After
+// stands for the top of the column and an empty Before for its bottom.
+type TaskMove struct {
+	Status string `json:"status"`
+	After  string `json:"after,omitempty"`
+	Before string `json:"before,omitempty"`
+}
+
+const (
+	EventTaskCreated = "task.created"
+	EventTaskUpdated = "task.updated"
+	EventTaskDeleted = "task.deleted"
+	// EventTaskStatusChanged follows the task.updated event of an update
+	// that changed the status.
+	EventTaskStatusChanged = "task.status_changed"
+)
+
+// TaskEvent describes a change of a task. Task is nil for deletions and
+// PreviousStatus is only set on task.status_changed events.
+type TaskEvent struct {
+	OccurredAt     time.Time `json:"occurred_at"`
+	Task           *Task     `json:"task,omitempty"`
+	Type           string    `json:"type"`
+	TaskID         string    `json:"task_id"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	// Watchers are the users following the task when the event occurred,
+	// for the subscribers fanning it out.
+	Watchers []string `json:"watchers,omitempty"`
+	ID       uint64   `json:"id"`
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	CreatedAt  time.Time  `json:"created_at"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	ID         string     `json:"id"`
+	URL        string     `json:"url"`
+	// Secret signs the deliveries and is only returned once, on creation.
+	Secret              string   `json:"secret,omitempty"`
+	EventTypes          []string `json:"event_types"`
+	ConsecutiveFailures int      `json:"consecutive_failures"`
+	Enabled             bool     `json:"enabled"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to a webhook.
+type WebhookDelivery struct {
+	CreatedAt      time.Time       `json:"created_at"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	LastError      string          `json:"last_error,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	EventID        uint64          `json:"event_id"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+}
+
+// PendingDelivery is a delivery that is due, along with its destination.
+type PendingDelivery struct {
+	WebhookDelivery
+	URL    string
+	Secret string
+}
+
+type APIKey struct {
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	// Key holds the plain text key and is only returned once, on creation.
+	Key    string   `json:"key,omitempty"`
+	Scopes []string `json:"scopes"`
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type AuditEntry struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        string    `json:"id"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Resource  string    `json:"resource"`
+}
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelInApp   = "in_app"
+
+	ReminderDueSoon = "due_soon"
+	ReminderOverdue = "overdue"
+	// NotificationTaskAssigned tells the assignee of a task. The other
+	// changes of a task are notified with their event type.
+	NotificationTaskAssigned = "task.assigned"
+)
+
+// NotificationPreferences are the channels a user is notified on. The email
+// and webhook notifications are held back during the quiet hours, from
+// QuietStart to QuietEnd as HH:MM in TimeZone; in-app ones are silent and
+// always delivered.
+type NotificationPreferences struct {
+	UserID     string   `json:"user_id"`
+	Email      string   `json:"email,omitempty"`
+	WebhookURL string   `json:"webhook_url,omitempty"`
+	QuietStart string   `json:"quiet_start,omitempty"`
+	QuietEnd   string   `json:"quiet_end,omitempty"`
+	TimeZone   string   `json:"time_zone,omitempty"`
+	Channels   []string `json:"channels"`
+}
+
+// Reminder is a due date reminder sent, or being sent, to a user on one
+// channel. A reminder is only sent once per due date.
+type Reminder struct {
+	DueAt   time.Time
+	TaskID  string
+	UserID  string
+	Kind    string
+	Channel string
+	// Attempts counts the failed deliveries of the reminder.
+	Attempts int
+}
+
+// Notification is an in-app notification of a user.
+type Notification struct {
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	TaskID    string     `json:"task_id"`
+	Kind      string     `json:"kind"`
+	Message   string     `json:"message"`
+	// Actor is the user who changed the task, empty for reminders and the
+	// changes made by the server.
+	Actor string `json:"actor,omitempty"`
+}
+
+// Inbox is a page of the notifications of a user, newest first, with the
+// count of all their unread ones.
+type Inbox struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        int            `json:"unread"`
+}
+
+type BuildInfo struct {
+	GitSHA    string `json:"git_sha"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Worklog is time spent by a user on a task: a timer, or an entry logged by
+// hand.
+type Worklog struct {
+	StartedAt time.Time `json:"started_at"`
+	ID        string    `json:"id"`
+	TaskID    string    `json:"task_id"`
+	UserID    string    `json:"user_id"`
+	// Duration is in seconds, zero while the timer runs.
+	Duration int64  `json:"duration"`
+	Note     string `json:"note,omitempty"`
+	// Running is set on the timer of a user until they stop it. A user has
+	// one running timer at most.
+	Running bool `json:"running,omitempty"`
+}
+
+const (
+	TimeGroupUser = "user"
+	TimeGroupTask = "task"
+	TimeGroupDay  = "day"
+)
+
+// TimeReportQuery selects the worklogs started in [From, To), of UserID and
+// TaskID when set, and groups them by the GroupBy fields in order. A zero
+// From or To leaves the range open.
+type TimeReportQuery struct {
+	From    time.Time
+	To      time.Time
+	UserID  string
+	TaskID  string
+	GroupBy []string
+}
+
+// TimeReport sums the durations of the worklogs per group, the groups in the
+// order of their fields.
+type TimeReport struct {
+	GroupBy []string        `json:"group_by"`
+	Rows    []TimeReportRow `json:"rows"`
+	// Total is the sum of the durations of the rows in seconds.
+	Total int64 `json:"total"`
+}
+
+// TimeReportRow is a group of a TimeReport, only the fields grouped by being
+// set. Day is the UTC date the worklogs started.
+type TimeReportRow struct {
+	UserID   string `json:"user_id,omitempty"`
+	TaskID   string `json:"task_id,omitempty"`
+	Day      string `json:"day,omitempty"`
+	Duration int64  `json:"duration"`
+}
+
+// Sprint is an iteration of the team, from StartDate to EndDate inclusive,
+// both dates in YYYY-MM-DD form.
+type Sprint struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Goal      string `json:"goal,omitempty"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	// Capacity is the story points the team expects to complete in the
+	// sprint, zero when unplanned.
+	Capacity int `json:"capacity"`
+}
+
+// SprintProgress compares the story points committed to a sprint, those of
+// its tasks, with the points of its tasks done.
+type SprintProgress struct {
+	SprintID  string `json:"sprint_id"`
+	Capacity  int    `json:"capacity"`
+	Committed int    `json:"committed"`
+	Completed int    `json:"completed"`
+	Remaining int    `json:"remaining"`
+	// Tasks and DoneTasks count the tasks of the sprint, estimated or not.
+	Tasks     int `json:"tasks"`
+	DoneTasks int `json:"done_tasks"`
+	// OverCapacity is set when more points are committed than the capacity
+	// of the sprint allows.
+	OverCapacity bool `json:"over_capacity"`
+}
+
+// CarryOver is the result of moving the unfinished tasks of a sprint to
+// another one.
+type CarryOver struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Points sums the story points of the tasks carried over.
+	Points int    `json:"points"`
+	Tasks  []Task `json:"tasks"`
+}
+
+// Burndown follows the story points of a sprint left to do, at the end of
+// each of its days up to today.
+type Burndown struct {
+	SprintID string        `json:"sprint_id"`
+	Days     []BurndownDay `json:"days"`
+	// Times are the flow times of the tasks of the sprint during it.
+	Times FlowTimes `json:"times"`
+}
+
+// BurndownDay is the state of a sprint at the end of a UTC day.
+type BurndownDay struct {
+	Day string `json:"day"`
+	// Committed is the story points of the tasks of the sprint, done or not.
+	Committed      int `json:"committed"`
+	Remaining      int `json:"remaining"`
+	RemainingTasks int `json:"remaining_tasks"`
+	// Ideal is what would remain burning the points committed on the first
+	// day at a steady pace, down to zero on the last day.
+	Ideal float64 `json:"ideal"`
+}
+
+// CumulativeFlow counts the tasks per status at the end of each UTC day from
+// From to To, both dates in YYYY-MM-DD form.
+type CumulativeFlow struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Statuses are those counted on any of the days, in board order.
+	Statuses []string  `json:"statuses"`
+	Days     []FlowDay `json:"days"`
+	// Times are the flow times of the status changes from From to To.
+	Times FlowTimes `json:"times"`
+}
+
+// FlowDay has a count for each of the statuses of its CumulativeFlow.
+type FlowDay struct {
+	Day    string         `json:"day"`
+	Counts map[string]int `json:"counts"`
+}
+
+// FlowTimes are percentiles of durations in seconds. Lead times run from
+// the creation of a task to it being done, cycle times from its first status
+// change to it being done, and each transition times the stay in the status
+// it leaves.
+type FlowTimes struct {
+	LeadTime    Percentiles       `json:"lead_time"`
+	CycleTime   Percentiles       `json:"cycle_time"`
+	Transitions []TransitionTimes `json:"transitions"`
+}
+
+// Percentiles of Count durations in seconds, zero when Count is.
+type Percentiles struct {
+	Count int   `json:"count"`
+	P50   int64 `json:"p50"`
+	P85   int64 `json:"p85"`
+	P95   int64 `json:"p95"`
+}
+
+type TransitionTimes struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Percentiles
+}
+
+// TaskStats summarizes the tasks for the dashboard. Open tasks are those not
+// done.
+type TaskStats struct {
+	ByStatus map[string]int `json:"by_status"`
+	Total    int            `json:"total"`
+	Week     WeekStats      `json:"week"`
+	// OldestOpen are the open tasks created first, oldest first.
+	OldestOpen []Task         `json:"oldest_open"`
+	Assignees  []AssigneeLoad `json:"assignees"`
+	// GeneratedAt is when the statistics were computed, as they are cached
+	// for a short while.
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// WeekStats counts the tasks created and the tasks completed since Start,
+// the Monday of the current UTC week in YYYY-MM-DD form. A task completed
+// twice in the week counts once.
+type WeekStats struct {
+	Start     string `json:"start"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+// AssigneeLoad is the open work of an assignee, an empty Assignee standing
+// for the unassigned tasks.
+type AssigneeLoad struct {
+	Assignee string `json:"assignee"`
+	Open     int    `json:"open"`
+	// Points sums the story points of the open tasks.
+	Points int `json:"points"`
 }
@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"github.com/saarzur123/task-management/backend/webhooks"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	// HeaderKind is the notification kind of a webhook notification.
+	HeaderKind = "X-Notification-Kind"
+)
+
+// SMTPNotifier sends the notifications by email through an SMTP relay,
+// upgrading to TLS when the relay offers STARTTLS.
+type SMTPNotifier struct {
+	// Addr is the host:port of the relay.
+	Addr string
+	From string
+	// Auth, when set, authenticates with the relay if it supports AUTH.
+	Auth smtp.Auth
+}
+
+func (n *SMTPNotifier) Channel() string {
+	return models.ChannelEmail
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, prefs *models.NotificationPreferences, message *Message) error {
+	host, _, err := net.SplitHostPort(n.Addr)
+	if err != nil {
+		return err
+	}
+	conn, err := (&net.Dialer{Timeout: requestTimeout}).DialContext(ctx, "tcp", n.Addr)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(requestTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok && n.Auth != nil {
+		if err = client.Auth(n.Auth); err != nil {
+			return err
+		}
+	}
+	if err = client.Mail(n.From); err != nil {
+		return err
+	}
+	if err = client.Rcpt(prefs.Email); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(n.email(prefs.Email, message)); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (n *SMTPNotifier) email(to string, message *Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.From)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// WebhookNotifier posts the notifications as JSON to the webhook URL of the
+// user, signed like the task event webhooks when Secret is set. Without a
+// Client, it uses webhooks.NewClient, which only connects to public addresses.
+type WebhookNotifier struct {
+	Client *http.Client
+	Secret string
+}
+
+type webhookNotification struct {
+	Task    models.Task `json:"task"`
+	UserID  string      `json:"user_id"`
+	Kind    string      `json:"kind"`
+	Subject string      `json:"subject"`
+	Message string      `json:"message"`
+}
+
+func (n *WebhookNotifier) Channel() string {
+	return models.ChannelWebhook
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, prefs *models.NotificationPreferences, message *Message) error {
+	body, err := json.Marshal(webhookNotification{
+		Task:    message.Task,
+		UserID:  prefs.UserID,
+		Kind:    message.Kind,
+		Subject: message.Subject,
+		Message: message.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, prefs.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderKind, message.Kind)
+	if n.Secret != "" {
+		timestamp := time.Now().Unix()
+		request.Header.Set(webhooks.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		request.Header.Set(webhooks.HeaderSignature, webhooks.Sign(n.Secret, timestamp, body))
+	}
+
+	client := n.Client
+	if client == nil {
+		client = webhooks.NewClient()
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// InAppNotifier stores the notifications in the inbox of the user.
+type InAppNotifier struct {
+	Store service.NotificationStore
+}
+
+func (n *InAppNotifier) Channel() string {
+	return models.ChannelInApp
+}
+
+func (n *InAppNotifier) Notify(ctx context.Context, prefs *models.NotificationPreferences, message *Message) error {
+	return n.Store.AddNotification(ctx, &models.Notification{
+		UserID:  prefs.UserID,
+		TaskID:  message.Task.ID,
+		Kind:    message.Kind,
+		Message: message.Subject,
+	})
+}
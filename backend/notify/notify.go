@@ -0,0 +1,110 @@
+// Package notify reminds the assignees of the tasks falling due, or overdue,
+// through the notifiers of the channels they chose: email, webhook and
+// in-app.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/webhooks"
+	"net/mail"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const clockLayout = "15:04"
+
+var (
+	ErrInvalidPreferences = errors.New("InvalidPreferences")
+)
+
+// Message is a notification about a task.
+type Message struct {
+	Task    models.Task
+	Kind    string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages on one channel.
+type Notifier interface {
+	Channel() string
+	// Notify delivers message to the user of prefs.
+	Notify(ctx context.Context, prefs *models.NotificationPreferences, message *Message) error
+}
+
+// ValidatePreferences checks the channels are known and reachable, and the
+// quiet hours and time zone parse.
+func ValidatePreferences(prefs *models.NotificationPreferences) error {
+	for _, channel := range prefs.Channels {
+		switch channel {
+		case models.ChannelEmail:
+			if _, err := mail.ParseAddress(prefs.Email); err != nil {
+				return fmt.Errorf("%w: the email channel needs a valid email", ErrInvalidPreferences)
+			}
+		case models.ChannelWebhook:
+			if u, err := url.Parse(prefs.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("%w: the webhook channel needs an http(s) webhook_url", ErrInvalidPreferences)
+			} else if !webhooks.PublicHost(u.Hostname()) {
+				return fmt.Errorf("%w: the webhook_url must be a public address", ErrInvalidPreferences)
+			}
+		case models.ChannelInApp:
+		default:
+			return fmt.Errorf("%w: unknown channel %q", ErrInvalidPreferences, channel)
+		}
+	}
+	if (prefs.QuietStart == "") != (prefs.QuietEnd == "") {
+		return fmt.Errorf("%w: quiet hours need both quiet_start and quiet_end", ErrInvalidPreferences)
+	}
+	for _, value := range []string{prefs.QuietStart, prefs.QuietEnd} {
+		if _, err := time.Parse(clockLayout, value); value != "" && err != nil {
+			return fmt.Errorf("%w: quiet hours are HH:MM", ErrInvalidPreferences)
+		}
+	}
+	if _, err := time.LoadLocation(prefs.TimeZone); err != nil {
+		return fmt.Errorf("%w: unknown time_zone %q", ErrInvalidPreferences, prefs.TimeZone)
+	}
+	return nil
+}
+
+// Quiet reports whether now falls in the quiet hours of prefs. The quiet hours
+// may span midnight, 22:00 to 07:00 for instance.
+func Quiet(prefs *models.NotificationPreferences, now time.Time) bool {
+	start, err := time.Parse(clockLayout, prefs.QuietStart)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(clockLayout, prefs.QuietEnd)
+	if err != nil {
+		return false
+	}
+	location, err := time.LoadLocation(prefs.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if from <= to {
+		return from <= minute && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// reachable reports whether the user of prefs can be notified on channel.
+func reachable(prefs *models.NotificationPreferences, channel string) bool {
+	if !slices.Contains(prefs.Channels, channel) {
+		return false
+	}
+	switch channel {
+	case models.ChannelEmail:
+		return prefs.Email != ""
+	case models.ChannelWebhook:
+		return prefs.WebhookURL != ""
+	}
+	return true
+}
@@ -0,0 +1,289 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/webhooks"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errMock = errors.New("mock error")
+
+func TestNotify(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Notify Suite")
+}
+
+// smtpStub is a local SMTP server recording the commands and mails it
+// receives. It rejects the recipients in reject.
+type smtpStub struct {
+	listener net.Listener
+	reject   string
+
+	mu       sync.Mutex
+	commands []string
+	mails    []string
+}
+
+func newSMTPStub() *smtpStub {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).To(Succeed())
+	stub := &smtpStub{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go stub.serve(conn)
+		}
+	}()
+	return stub
+}
+
+func (s *smtpStub) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 stub ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimRight(line, "\r\n")
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+
+		switch verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0]); verb {
+		case "EHLO":
+			reply("250-stub")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			reply("235 Authenticated")
+		case "RCPT":
+			if s.reject != "" && strings.Contains(command, s.reject) {
+				reply("550 No such user")
+				continue
+			}
+			reply("250 OK")
+		case "DATA":
+			reply("354 Go ahead")
+			var mail strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				mail.WriteString(line)
+			}
+			s.mu.Lock()
+			s.mails = append(s.mails, mail.String())
+			s.mu.Unlock()
+			reply("250 Queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpStub) received() ([]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...), append([]string(nil), s.mails...)
+}
+
+var _ = Describe("preferences", func() {
+	It("accepts reachable channels and quiet hours", func() {
+		Expect(ValidatePreferences(&models.NotificationPreferences{
+			Channels:   []string{models.ChannelEmail, models.ChannelWebhook, models.ChannelInApp},
+			Email:      "ci@example.com",
+			WebhookURL: "https://example.com/notify",
+			QuietStart: "22:00",
+			QuietEnd:   "07:30",
+			TimeZone:   "Europe/Paris",
+		})).To(Succeed())
+		Expect(ValidatePreferences(&models.NotificationPreferences{Channels: []string{}})).To(Succeed())
+	})
+
+	DescribeTable("rejects invalid preferences",
+		func(prefs models.NotificationPreferences) {
+			Expect(ValidatePreferences(&prefs)).To(MatchError(ErrInvalidPreferences))
+		},
+		Entry("unknown channel", models.NotificationPreferences{Channels: []string{"sms"}}),
+		Entry("email without an address", models.NotificationPreferences{Channels: []string{models.ChannelEmail}}),
+		Entry("invalid email", models.NotificationPreferences{Channels: []string{models.ChannelEmail}, Email: "ci"}),
+		Entry("webhook without a URL", models.NotificationPreferences{Channels: []string{models.ChannelWebhook}}),
+		Entry("webhook URL not http", models.NotificationPreferences{Channels: []string{models.ChannelWebhook}, WebhookURL: "ftp://example.com"}),
+		Entry("webhook URL on loopback", models.NotificationPreferences{Channels: []string{models.ChannelWebhook}, WebhookURL: "http://127.0.0.1:8080/"}),
+		Entry("webhook URL on the metadata service", models.NotificationPreferences{Channels: []string{models.ChannelWebhook}, WebhookURL: "http://169.254.169.254/latest/meta-data/"}),
+		Entry("half quiet hours", models.NotificationPreferences{QuietStart: "22:00"}),
+		Entry("invalid quiet hours", models.NotificationPreferences{QuietStart: "10pm", QuietEnd: "07:00"}),
+		Entry("unknown time zone", models.NotificationPreferences{TimeZone: "Mars/Olympus"}),
+	)
+
+	DescribeTable("quiet hours",
+		func(start, end, at string, quiet bool) {
+			prefs := models.NotificationPreferences{QuietStart: start, QuietEnd: end, TimeZone: "America/New_York"}
+			now, err := time.ParseInLocation("2006-01-02 15:04", "2026-01-05 "+at, time.FixedZone("EST", -5*3600))
+			Expect(err).To(Succeed())
+			Expect(Quiet(&prefs, now.UTC())).To(Equal(quiet))
+		},
+		Entry("none", "", "", "23:00", false),
+		Entry("during the day", "12:00", "14:00", "13:00", true),
+		Entry("at the end", "12:00", "14:00", "14:00", false),
+		Entry("overnight, before midnight", "22:00", "07:00", "23:30", true),
+		Entry("overnight, after midnight", "22:00", "07:00", "06:59", true),
+		Entry("overnight, during the day", "22:00", "07:00", "12:00", false),
+	)
+})
+
+var _ = Describe("notifiers", func() {
+	var (
+		ctx     = context.Background()
+		dueAt   = time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+		task    = models.Task{ID: "7", Title: "Ship the release", DueAt: &dueAt, Assignee: "ci"}
+		message = &Message{Task: task, Kind: models.ReminderDueSoon, Subject: "Task \"Ship the release\" is due soon", Body: "Due.\nSoon."}
+		prefs   *models.NotificationPreferences
+	)
+
+	BeforeEach(func() {
+		prefs = &models.NotificationPreferences{UserID: "ci", Email: "ci@example.com"}
+	})
+
+	Describe("SMTPNotifier", func() {
+		var (
+			stub     *smtpStub
+			notifier *SMTPNotifier
+		)
+
+		BeforeEach(func() {
+			stub = newSMTPStub()
+			DeferCleanup(func() { stub.listener.Close() })
+			notifier = &SMTPNotifier{Addr: stub.listener.Addr().String(), From: "tasks@example.com"}
+		})
+
+		It("sends an email", func() {
+			Expect(notifier.Notify(ctx, prefs, message)).To(Succeed())
+
+			commands, mails := stub.received()
+			Expect(commands).To(ContainElements("MAIL FROM:<tasks@example.com>", "RCPT TO:<ci@example.com>", "DATA", "QUIT"))
+			Expect(mails).To(HaveLen(1))
+			Expect(mails[0]).To(ContainSubstring("To: ci@example.com\r\n"))
+			Expect(mails[0]).To(ContainSubstring("Subject: Task \"Ship the release\" is due soon\r\n"))
+			Expect(mails[0]).To(HaveSuffix("\r\n\r\nDue.\r\nSoon.\r\n"))
+		})
+
+		It("authenticates when configured", func() {
+			notifier.Auth = smtp.PlainAuth("", "user", "password", "127.0.0.1")
+			Expect(notifier.Notify(ctx, prefs, message)).To(Succeed())
+
+			commands, _ := stub.received()
+			credentials := base64.StdEncoding.EncodeToString([]byte("\x00user\x00password"))
+			Expect(commands).To(ContainElement("AUTH PLAIN " + credentials))
+		})
+
+		It("returns the rejections of the relay", func() {
+			stub.reject = "ci@example.com"
+			Expect(notifier.Notify(ctx, prefs, message)).To(MatchError(ContainSubstring("No such user")))
+
+			_, mails := stub.received()
+			Expect(mails).To(BeEmpty())
+		})
+
+		It("returns an error when the relay is down", func() {
+			stub.listener.Close()
+			Expect(notifier.Notify(ctx, prefs, message)).NotTo(Succeed())
+		})
+	})
+
+	Describe("WebhookNotifier", func() {
+		var (
+			receiver *httptest.Server
+			received chan *http.Request
+			body     chan []byte
+			status   int
+		)
+
+		BeforeEach(func() {
+			status = http.StatusNoContent
+			received = make(chan *http.Request, 1)
+			body = make(chan []byte, 1)
+			receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				payload, _ := io.ReadAll(r.Body)
+				received <- r
+				body <- payload
+				w.WriteHeader(status)
+			}))
+			DeferCleanup(receiver.Close)
+			prefs.WebhookURL = receiver.URL
+		})
+
+		It("posts a signed notification", func() {
+			notifier := &WebhookNotifier{Client: receiver.Client(), Secret: "secret"}
+			Expect(notifier.Notify(ctx, prefs, message)).To(Succeed())
+
+			var request *http.Request
+			Eventually(received).Should(Receive(&request))
+			var payload []byte
+			Eventually(body).Should(Receive(&payload))
+			Expect(request.Header.Get(HeaderKind)).To(Equal(models.ReminderDueSoon))
+			Expect(webhooks.Verify("secret", request.Header, payload, time.Minute, time.Now())).To(Succeed())
+
+			var notification map[string]any
+			Expect(json.Unmarshal(payload, &notification)).To(Succeed())
+			Expect(notification).To(HaveKeyWithValue("user_id", "ci"))
+			Expect(notification).To(HaveKeyWithValue("kind", models.ReminderDueSoon))
+			Expect(notification).To(HaveKeyWithValue("message", "Due.\nSoon."))
+			Expect(notification["task"]).To(HaveKeyWithValue("id", "7"))
+		})
+
+		It("returns an error on a failed response", func() {
+			status = http.StatusInternalServerError
+			notifier := &WebhookNotifier{Client: receiver.Client()}
+			Expect(notifier.Notify(ctx, prefs, message)).To(MatchError(ContainSubstring("status 500")))
+		})
+
+		It("refuses addresses that aren't public by default", func() {
+			notifier := &WebhookNotifier{}
+			Expect(notifier.Notify(ctx, prefs, message)).To(MatchError(webhooks.ErrForbiddenAddress))
+			Consistently(received).ShouldNot(Receive())
+		})
+	})
+
+	Describe("InAppNotifier", func() {
+		It("adds a notification to the inbox", func() {
+			store := serviceMock.NewMockNotificationStore(gomock.NewController(GinkgoT()))
+			store.EXPECT().AddNotification(gomock.Any(), &models.Notification{
+				UserID: "ci", TaskID: "7", Kind: models.ReminderDueSoon, Message: message.Subject,
+			}).Return(errMock)
+
+			notifier := &InAppNotifier{Store: store}
+			Expect(notifier.Notify(ctx, prefs, message)).To(MatchError(errMock))
+		})
+	})
+})
@@ -0,0 +1,188 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/clock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"log/slog"
+	"time"
+)
+
+const (
+	DefaultPollInterval = time.Minute
+	// DefaultDueSoon is how long before their due time the tasks are due soon.
+	DefaultDueSoon   = 24 * time.Hour
+	DefaultBaseDelay = time.Minute
+	DefaultMaxDelay  = time.Hour
+	// DefaultMaxAttempts gives up on a reminder after about two hours.
+	DefaultMaxAttempts = 8
+	dueLayout          = "Mon, 02 Jan 2006 15:04 MST"
+)
+
+// Reminders reminds the assignees of their tasks once when they fall due
+// soon, and once more when they are overdue, on every channel they chose.
+// A reminder is claimed before being sent, any number of Reminders can run
+// on the same database.
+type Reminders struct {
+	Store       service.ReminderStore
+	Preferences service.NotificationPreferencesRepository
+	Notifiers   []Notifier
+	Clock       clock.Clock
+	Logger      *slog.Logger
+
+	PollInterval time.Duration
+	DueSoon      time.Duration
+	// The delay before retry n of a failed reminder is BaseDelay * 2^(n-1),
+	// at most MaxDelay.
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	MaxAttempts int
+}
+
+func NewReminders(store service.ReminderStore, preferences service.NotificationPreferencesRepository,
+	notifiers []Notifier, logger *slog.Logger) *Reminders {
+	return &Reminders{
+		Store:        store,
+		Preferences:  preferences,
+		Notifiers:    notifiers,
+		Clock:        clock.Real{},
+		Logger:       logger,
+		PollInterval: DefaultPollInterval,
+		DueSoon:      DefaultDueSoon,
+		BaseDelay:    DefaultBaseDelay,
+		MaxDelay:     DefaultMaxDelay,
+		MaxAttempts:  DefaultMaxAttempts,
+	}
+}
+
+// Run polls until ctx is done.
+func (r *Reminders) Run(ctx context.Context) {
+	for {
+		if err := r.Poll(ctx); err != nil && ctx.Err() == nil {
+			r.Logger.ErrorContext(ctx, "failed to send reminders", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.Clock.After(r.PollInterval):
+		}
+	}
+}
+
+// Poll sends the reminders of the tasks due soon or overdue not sent yet. The
+// email and webhook reminders wait for the end of the quiet hours of their
+// user; a failed delivery is logged and retried with exponential backoff,
+// up to MaxAttempts. Once its overdue reminders are all sent, or given up, a
+// task is marked reminded and no longer polled.
+func (r *Reminders) Poll(ctx context.Context) error {
+	now := r.Clock.Now()
+	tasks, err := r.Store.DueTasks(ctx, now.Add(r.DueSoon))
+	if err != nil {
+		return err
+	}
+
+	preferences := make(map[string]*models.NotificationPreferences)
+	for i := range tasks {
+		task := &tasks[i]
+		prefs, ok := preferences[task.Assignee]
+		if !ok {
+			if prefs, err = r.Preferences.GetPreferences(ctx, task.Assignee); err != nil {
+				return err
+			}
+			preferences[task.Assignee] = prefs
+		}
+
+		kind := models.ReminderDueSoon
+		if !task.DueAt.After(now) {
+			kind = models.ReminderOverdue
+		}
+		quiet := Quiet(prefs, now)
+		reminded := true
+		for _, notifier := range r.Notifiers {
+			channel := notifier.Channel()
+			if !reachable(prefs, channel) {
+				continue
+			}
+			if quiet && channel != models.ChannelInApp {
+				reminded = false
+				continue
+			}
+			done, err := r.remind(ctx, notifier, prefs, task, kind, now)
+			if err != nil {
+				return err
+			}
+			reminded = reminded && done
+		}
+		if kind == models.ReminderOverdue && reminded {
+			if err = r.Store.MarkReminded(ctx, task, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// remind sends one reminder unless it was claimed already, reporting false
+// when it failed and will be retried. Only the storage errors are returned.
+func (r *Reminders) remind(ctx context.Context, notifier Notifier, prefs *models.NotificationPreferences, task *models.Task,
+	kind string, now time.Time) (bool, error) {
+	reminder := models.Reminder{TaskID: task.ID, UserID: prefs.UserID, Kind: kind, Channel: notifier.Channel(), DueAt: *task.DueAt}
+	claimed, err := r.Store.ClaimReminder(ctx, &reminder, now)
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		return true, nil
+	}
+
+	err = notifier.Notify(ctx, prefs, message(prefs, task, kind))
+	if err == nil {
+		return true, nil
+	}
+	if ctx.Err() != nil {
+		return false, errors.Join(err, r.Store.ReleaseReminder(context.WithoutCancel(ctx), &reminder))
+	}
+
+	var retryAt *time.Time
+	if attempts := reminder.Attempts + 1; attempts < r.MaxAttempts {
+		next := now.Add(r.backoff(attempts))
+		retryAt = &next
+	}
+	r.Logger.WarnContext(ctx, "failed to send a reminder", slog.String("task_id", task.ID),
+		slog.String("user_id", prefs.UserID), slog.String("channel", reminder.Channel), slog.Any("error", err),
+		slog.Bool("retrying", retryAt != nil))
+	return retryAt == nil, r.Store.FailReminder(ctx, &reminder, retryAt)
+}
+
+func (r *Reminders) backoff(attempt int) time.Duration {
+	delay := r.BaseDelay
+	for i := 1; i < attempt && delay < r.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, r.MaxDelay)
+}
+
+func message(prefs *models.NotificationPreferences, task *models.Task, kind string) *Message {
+	location, err := time.LoadLocation(prefs.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	due := task.DueAt.In(location).Format(dueLayout)
+
+	message := Message{Task: *task, Kind: kind}
+	if kind == models.ReminderOverdue {
+		message.Subject = fmt.Sprintf("Task %q is overdue", task.Title)
+		message.Body = fmt.Sprintf("Task %s, %q, was due on %s.", task.ID, task.Title, due)
+	} else {
+		message.Subject = fmt.Sprintf("Task %q is due soon", task.Title)
+		message.Body = fmt.Sprintf("Task %s, %q, is due on %s.", task.ID, task.Title, due)
+	}
+	if task.Description != "" {
+		message.Body += "\n\n" + task.Description
+	}
+	return &message
+}
@@ -0,0 +1,302 @@
+package notify
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/clock"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// fakeStore keeps the tasks, preferences and claimed reminders in memory.
+type fakeStore struct {
+	mu          sync.Mutex
+	tasks       []models.Task
+	preferences map[string]*models.NotificationPreferences
+	// claimed holds the failed attempts and the retry time of the claimed
+	// reminders, keyed without their attempts.
+	claimed  map[models.Reminder]*claim
+	reminded map[string]bool
+}
+
+type claim struct {
+	attempts int
+	retryAt  *time.Time
+}
+
+func (f *fakeStore) DueTasks(_ context.Context, before time.Time) ([]models.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	due := make([]models.Task, 0)
+	for _, task := range f.tasks {
+		if !task.DueAt.After(before) && !f.reminded[task.ID] {
+			due = append(due, task)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeStore) MarkReminded(_ context.Context, task *models.Task, _ time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reminded[task.ID] = true
+	return nil
+}
+
+func (f *fakeStore) ClaimReminder(_ context.Context, reminder *models.Reminder, at time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := *reminder
+	key.Attempts = 0
+	c, ok := f.claimed[key]
+	if !ok {
+		f.claimed[key] = &claim{}
+		reminder.Attempts = 0
+		return true, nil
+	}
+	if c.retryAt == nil || c.retryAt.After(at) {
+		return false, nil
+	}
+	c.retryAt = nil
+	reminder.Attempts = c.attempts
+	return true, nil
+}
+
+func (f *fakeStore) FailReminder(_ context.Context, reminder *models.Reminder, retryAt *time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := *reminder
+	key.Attempts = 0
+	c := f.claimed[key]
+	c.attempts++
+	c.retryAt = retryAt
+	return nil
+}
+
+func (f *fakeStore) ReleaseReminder(_ context.Context, reminder *models.Reminder) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := *reminder
+	key.Attempts = 0
+	delete(f.claimed, key)
+	return nil
+}
+
+func (f *fakeStore) GetPreferences(_ context.Context, userID string) (*models.NotificationPreferences, error) {
+	if prefs, ok := f.preferences[userID]; ok {
+		return prefs, nil
+	}
+	return &models.NotificationPreferences{UserID: userID, Channels: []string{models.ChannelInApp}}, nil
+}
+
+func (f *fakeStore) SavePreferences(context.Context, *models.NotificationPreferences) error {
+	return nil
+}
+
+type sent struct {
+	user, task, kind, subject string
+}
+
+// fakeNotifier records the messages sent on its channel, failing while err
+// is set.
+type fakeNotifier struct {
+	channel string
+	err     error
+
+	mu   sync.Mutex
+	sent []sent
+}
+
+func (n *fakeNotifier) Channel() string {
+	return n.channel
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, prefs *models.NotificationPreferences, message *Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, sent{prefs.UserID, message.Task.ID, message.Kind, message.Subject})
+	return nil
+}
+
+func (n *fakeNotifier) messages() []sent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]sent(nil), n.sent...)
+}
+
+var _ = Describe("Reminders", func() {
+	var (
+		// A Monday, 09:00 in Paris.
+		now       = time.Date(2026, time.January, 5, 8, 0, 0, 0, time.UTC)
+		ctx       = context.Background()
+		fakeClock *clock.Fake
+		store     *fakeStore
+		email     *fakeNotifier
+		inApp     *fakeNotifier
+		reminders *Reminders
+	)
+
+	due := func(id, assignee string, in time.Duration) models.Task {
+		dueAt := now.Add(in)
+		return models.Task{ID: id, Title: "Task " + id, Status: models.StatusPending, DueAt: &dueAt, Assignee: assignee}
+	}
+
+	BeforeEach(func() {
+		fakeClock = clock.NewFake(now)
+		store = &fakeStore{
+			preferences: map[string]*models.NotificationPreferences{
+				"ci": {UserID: "ci", Channels: []string{models.ChannelEmail, models.ChannelInApp}, Email: "ci@example.com", TimeZone: "Europe/Paris"},
+			},
+			claimed:  map[models.Reminder]*claim{},
+			reminded: map[string]bool{},
+		}
+		email = &fakeNotifier{channel: models.ChannelEmail}
+		inApp = &fakeNotifier{channel: models.ChannelInApp}
+		reminders = NewReminders(store, store, []Notifier{email, inApp}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		reminders.Clock = fakeClock
+	})
+
+	It("reminds once of the tasks due soon, then once they are overdue", func() {
+		store.tasks = []models.Task{due("1", "ci", 2*time.Hour), due("2", "ci", 48*time.Hour)}
+
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(email.messages()).To(Equal([]sent{{"ci", "1", models.ReminderDueSoon, `Task "Task 1" is due soon`}}))
+		Expect(inApp.messages()).To(Equal(email.messages()))
+
+		fakeClock.Advance(2 * time.Hour)
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(email.messages()).To(Equal([]sent{
+			{"ci", "1", models.ReminderDueSoon, `Task "Task 1" is due soon`},
+			{"ci", "1", models.ReminderOverdue, `Task "Task 1" is overdue`},
+		}))
+	})
+
+	It("reminds again when the due date moves", func() {
+		store.tasks = []models.Task{due("1", "ci", time.Hour)}
+		Expect(reminders.Poll(ctx)).To(Succeed())
+
+		store.tasks = []models.Task{due("1", "ci", 2*time.Hour)}
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(email.messages()).To(HaveLen(2))
+	})
+
+	It("only uses the reachable channels the user chose", func() {
+		store.preferences["ops"] = &models.NotificationPreferences{UserID: "ops", Channels: []string{models.ChannelEmail}}
+		store.tasks = []models.Task{due("1", "ops", time.Hour), due("2", "new", time.Hour)}
+
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(email.messages()).To(BeEmpty(), "ops has no email")
+		Expect(inApp.messages()).To(Equal([]sent{{"new", "2", models.ReminderDueSoon, `Task "Task 2" is due soon`}}))
+	})
+
+	It("holds back the emails during the quiet hours", func() {
+		store.preferences["ci"].QuietStart = "08:00"
+		store.preferences["ci"].QuietEnd = "10:00"
+		store.tasks = []models.Task{due("1", "ci", 4*time.Hour)}
+
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(email.messages()).To(BeEmpty())
+		Expect(inApp.messages()).To(HaveLen(1))
+
+		fakeClock.Advance(time.Hour)
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(email.messages()).To(HaveLen(1))
+		Expect(inApp.messages()).To(HaveLen(1))
+	})
+
+	It("retries the failed deliveries with backoff", func() {
+		email.err = errMock
+		store.tasks = []models.Task{due("1", "ci", time.Hour)}
+
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(inApp.messages()).To(HaveLen(1))
+
+		email.err = nil
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(email.messages()).To(BeEmpty(), "the retry isn't due yet")
+
+		fakeClock.Advance(DefaultBaseDelay)
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(email.messages()).To(HaveLen(1))
+		Expect(inApp.messages()).To(HaveLen(1))
+	})
+
+	It("gives up on a reminder after MaxAttempts", func() {
+		email.err = errMock
+		reminders.MaxAttempts = 3
+		store.tasks = []models.Task{due("1", "ci", -time.Hour)}
+
+		for range 5 {
+			Expect(reminders.Poll(ctx)).To(Succeed())
+			fakeClock.Advance(DefaultMaxDelay)
+		}
+		reminder := models.Reminder{TaskID: "1", UserID: "ci", Kind: models.ReminderOverdue, Channel: models.ChannelEmail, DueAt: *store.tasks[0].DueAt}
+		Expect(store.claimed[reminder]).To(Equal(&claim{attempts: 3}))
+		Expect(store.reminded).To(HaveKey("1"))
+	})
+
+	It("stops polling the tasks once reminded of being overdue", func() {
+		email.err = errMock
+		store.tasks = []models.Task{due("1", "ci", -time.Hour)}
+
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(store.reminded).ToNot(HaveKey("1"), "the email will be retried")
+
+		email.err = nil
+		fakeClock.Advance(DefaultBaseDelay)
+		Expect(reminders.Poll(ctx)).To(Succeed())
+		Expect(store.reminded).To(HaveKey("1"))
+	})
+
+	It("polls on the clock when running", func() {
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			reminders.Run(runCtx)
+		}()
+
+		Eventually(fakeClock.Waiters).Should(Equal(1))
+		store.mu.Lock()
+		store.tasks = []models.Task{due("1", "ci", time.Hour)}
+		store.mu.Unlock()
+		fakeClock.Advance(DefaultPollInterval)
+		Eventually(email.messages).Should(HaveLen(1))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+
+	Describe("storage errors", func() {
+		var mockStore *serviceMock.MockReminderStore
+
+		BeforeEach(func() {
+			mockStore = serviceMock.NewMockReminderStore(gomock.NewController(GinkgoT()))
+			reminders.Store = mockStore
+		})
+
+		It("returns them", func() {
+			mockStore.EXPECT().DueTasks(gomock.Any(), now.Add(DefaultDueSoon)).Return(nil, errMock)
+			Expect(reminders.Poll(ctx)).To(MatchError(errMock))
+		})
+
+		It("stops at the first failed claim", func() {
+			mockStore.EXPECT().DueTasks(gomock.Any(), gomock.Any()).Return([]models.Task{due("1", "ci", time.Hour)}, nil)
+			mockStore.EXPECT().ClaimReminder(gomock.Any(), gomock.Any(), now).Return(false, errMock)
+
+			Expect(reminders.Poll(ctx)).To(MatchError(errMock))
+			Expect(email.messages()).To(BeEmpty())
+		})
+	})
+})
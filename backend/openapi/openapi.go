@@ -0,0 +1,34 @@
+// Package openapi serves the OpenAPI document describing the HTTP API and a
+// Swagger UI to browse it, with its assets embedded in the binary.
+package openapi
+
+import (
+	_ "embed"
+	"github.com/swaggest/swgui/v5emb"
+	"net/http"
+)
+
+const (
+	SpecPath = "/openapi.json"
+	DocsPath = "/docs/"
+	title    = "Task Management API"
+)
+
+// Spec is the OpenAPI 3.1 document. The contract test in utils keeps it in
+// sync with the routes and the responses of the handlers.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// SpecHandler serves Spec.
+func SpecHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(Spec) // nolint: errcheck
+	})
+}
+
+// DocsHandler serves the Swagger UI on DocsPath, loading Spec from SpecPath.
+func DocsHandler() http.Handler {
+	return v5emb.New(title, SpecPath, DocsPath)
+}
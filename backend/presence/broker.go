@@ -0,0 +1,61 @@
+package presence
+
+import (
+	"context"
+	"sync"
+)
+
+// Broker carries the messages of a task between hubs. MemoryBroker serves a
+// single backend; an implementation over Redis pub/sub or NATS lets several
+// backends share presence without changing the Hub.
+type Broker interface {
+	Publish(ctx context.Context, msg Message) error
+	// Subscribe calls deliver with every message published for taskID until
+	// the returned function is called.
+	Subscribe(taskID string, deliver func(Message)) (unsubscribe func())
+}
+
+type MemoryBroker struct {
+	subscribers map[string]map[uint64]func(Message)
+	nextID      uint64
+	mu          sync.RWMutex
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subscribers: make(map[string]map[uint64]func(Message))}
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, msg Message) error {
+	b.mu.RLock()
+	deliveries := make([]func(Message), 0, len(b.subscribers[msg.TaskID]))
+	for _, deliver := range b.subscribers[msg.TaskID] {
+		deliveries = append(deliveries, deliver)
+	}
+	b.mu.RUnlock()
+
+	for _, deliver := range deliveries {
+		deliver(msg)
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(taskID string, deliver func(Message)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	if b.subscribers[taskID] == nil {
+		b.subscribers[taskID] = make(map[uint64]func(Message))
+	}
+	b.subscribers[taskID][id] = deliver
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[taskID], id)
+		if len(b.subscribers[taskID]) == 0 {
+			delete(b.subscribers, taskID)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package presence
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// client is one WebSocket connection. tasks is only used by the goroutine
+// running Serve, writes go through send to writeLoop.
+type client struct {
+	conn      *websocket.Conn
+	hub       *Hub
+	logger    *slog.Logger
+	send      chan Message
+	done      chan struct{}
+	tasks     map[string]struct{}
+	viewer    Viewer
+	canEdit   bool
+	closeOnce sync.Once
+}
+
+func (c *client) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// trySend queues msg without blocking. A client that doesn't keep up is
+// disconnected rather than slowing down the others.
+func (c *client) trySend(msg Message) {
+	select {
+	case <-c.done:
+	case c.send <- msg:
+	default:
+		c.close()
+	}
+}
+
+func (c *client) publish(msg Message) {
+	if err := c.hub.broker.Publish(context.Background(), msg); err != nil {
+		c.logError("Failed to publish "+msg.Type, err)
+	}
+}
+
+// logError reports a failure to the client and the log.
+func (c *client) logError(msg string, err error) {
+	c.logger.Error(msg, slog.String("viewer", c.viewer.ID), slog.Any("error", err))
+	c.trySend(Message{Type: MessageError, Error: msg})
+}
+
+func (c *client) readLoop() {
+	pongWait := c.hub.PongWait
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				c.logger.Debug("presence connection closed", slog.String("viewer", c.viewer.ID), slog.Any("error", err))
+			}
+			return
+		}
+
+		var msg Message
+		if err = json.Unmarshal(data, &msg); err != nil {
+			c.trySend(Message{Type: MessageError, Error: "Invalid message"})
+			continue
+		}
+		c.handle(msg)
+	}
+}
+
+func (c *client) handle(msg Message) {
+	if msg.TaskID == "" {
+		c.trySend(Message{Type: MessageError, Error: "Missing task_id"})
+		return
+	}
+
+	switch msg.Type {
+	case MessageSubscribe:
+		if len(c.tasks) >= maxSubscriptions {
+			c.trySend(Message{Type: MessageError, TaskID: msg.TaskID, Error: "Too many subscriptions"})
+			return
+		}
+		c.hub.join(c, msg.TaskID)
+	case MessageUnsubscribe:
+		c.hub.leave(c, msg.TaskID)
+	case MessageUpdate:
+		switch {
+		case !c.canEdit:
+			c.trySend(Message{Type: MessageError, TaskID: msg.TaskID, Error: "Not allowed to edit tasks"})
+		case !c.subscribed(msg.TaskID):
+			c.trySend(Message{Type: MessageError, TaskID: msg.TaskID, Error: "Not subscribed to task"})
+		case !editableFields[msg.Field]:
+			c.trySend(Message{Type: MessageError, TaskID: msg.TaskID, Error: "Unknown field " + msg.Field})
+		default:
+			viewer := c.viewer
+			c.publish(Message{Type: MessageUpdate, TaskID: msg.TaskID, Viewer: &viewer, Field: msg.Field, Value: msg.Value})
+		}
+	default:
+		c.trySend(Message{Type: MessageError, TaskID: msg.TaskID, Error: "Unknown message type " + msg.Type})
+	}
+}
+
+func (c *client) subscribed(taskID string) bool {
+	_, ok := c.tasks[taskID]
+	return ok
+}
+
+// writeLoop owns the writes to the connection and pings the peer so dead
+// connections are noticed by readLoop's deadline.
+func (c *client) writeLoop() {
+	ticker := time.NewTicker(c.hub.PongWait * 9 / 10)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case msg := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(writeWait))
+			return
+		}
+	}
+}
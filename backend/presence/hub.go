@@ -0,0 +1,221 @@
+package presence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gorilla/websocket"
+	"github.com/saarzur123/task-management/backend/logging"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultPongWait = 60 * time.Second
+	writeWait       = 10 * time.Second
+	maxMessageSize  = 16 * 1024
+	// maxSubscriptions bounds the tasks a single connection can watch.
+	maxSubscriptions = 100
+	sendBuffer       = 32
+)
+
+// room holds the local connections subscribed to a task and the viewers of
+// the task learned from the broker.
+type room struct {
+	clients     map[*client]struct{}
+	viewers     map[string]Viewer
+	unsubscribe func()
+}
+
+// Hub relays presence between the connections of this backend and, through
+// its Broker, the other backends. A hub only learns about viewers that joined
+// while it had a subscriber for the task, which is always the case with a
+// MemoryBroker.
+type Hub struct {
+	broker  Broker
+	rooms   map[string]*room
+	clients map[*client]struct{}
+	// PongWait is how long a connection may stay silent before it is
+	// dropped. Pings are sent at 9/10 of it.
+	PongWait time.Duration
+	closed   bool
+	mu       sync.Mutex
+}
+
+func NewHub(broker Broker) *Hub {
+	return &Hub{
+		broker:   broker,
+		rooms:    make(map[string]*room),
+		clients:  make(map[*client]struct{}),
+		PongWait: DefaultPongWait,
+	}
+}
+
+// Serve runs the presence protocol on conn until it is closed. user names
+// the viewer to the others, canEdit allows relaying field updates.
+func (h *Hub) Serve(ctx context.Context, conn *websocket.Conn, user string, canEdit bool) {
+	c := &client{
+		conn:    conn,
+		hub:     h,
+		logger:  logging.FromContext(ctx),
+		send:    make(chan Message, sendBuffer),
+		done:    make(chan struct{}),
+		tasks:   make(map[string]struct{}),
+		viewer:  Viewer{ID: newViewerID(), User: user},
+		canEdit: canEdit,
+	}
+	if !h.register(c) {
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(writeWait))
+		_ = conn.Close()
+		return
+	}
+
+	go c.writeLoop()
+	viewer := c.viewer
+	c.trySend(Message{Type: MessageHello, Viewer: &viewer})
+	c.readLoop()
+
+	for taskID := range c.tasks {
+		h.leave(c, taskID)
+	}
+	h.unregister(c)
+	c.close()
+}
+
+// Close disconnects every client, so a graceful shutdown doesn't wait for
+// the hijacked connections. Later connections are refused.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.clients {
+		c.close()
+	}
+}
+
+func (h *Hub) register(c *client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.clients[c] = struct{}{}
+	return true
+}
+
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, c)
+}
+
+func (h *Hub) join(c *client, taskID string) {
+	if _, ok := c.tasks[taskID]; ok {
+		return
+	}
+
+	h.mu.Lock()
+	r, ok := h.rooms[taskID]
+	if !ok {
+		r = &room{clients: make(map[*client]struct{}), viewers: make(map[string]Viewer)}
+		r.unsubscribe = h.broker.Subscribe(taskID, h.deliver)
+		h.rooms[taskID] = r
+	}
+	r.clients[c] = struct{}{}
+	h.mu.Unlock()
+	c.tasks[taskID] = struct{}{}
+
+	viewer := c.viewer
+	c.publish(Message{Type: MessageJoin, TaskID: taskID, Viewer: &viewer})
+	c.trySend(Message{Type: MessageViewers, TaskID: taskID, Viewers: h.viewers(taskID)})
+}
+
+func (h *Hub) leave(c *client, taskID string) {
+	if _, ok := c.tasks[taskID]; !ok {
+		return
+	}
+	delete(c.tasks, taskID)
+
+	h.mu.Lock()
+	r := h.rooms[taskID]
+	delete(r.clients, c)
+	h.mu.Unlock()
+
+	viewer := c.viewer
+	c.publish(Message{Type: MessageLeave, TaskID: taskID, Viewer: &viewer})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(r.clients) == 0 && h.rooms[taskID] == r {
+		r.unsubscribe()
+		delete(h.rooms, taskID)
+	}
+}
+
+// deliver hands a message from the broker to the local subscribers of its
+// task, except the connection it came from.
+func (h *Hub) deliver(msg Message) {
+	h.mu.Lock()
+	r := h.rooms[msg.TaskID]
+	if r == nil {
+		h.mu.Unlock()
+		return
+	}
+	if msg.Viewer != nil {
+		switch msg.Type {
+		case MessageJoin:
+			r.viewers[msg.Viewer.ID] = *msg.Viewer
+		case MessageLeave:
+			delete(r.viewers, msg.Viewer.ID)
+		}
+	}
+	recipients := make([]*client, 0, len(r.clients))
+	for c := range r.clients {
+		if msg.Viewer == nil || c.viewer.ID != msg.Viewer.ID {
+			recipients = append(recipients, c)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, c := range recipients {
+		c.trySend(msg)
+	}
+}
+
+func (h *Hub) viewers(taskID string) []Viewer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	viewers := make([]Viewer, 0)
+	if r := h.rooms[taskID]; r != nil {
+		for _, viewer := range r.viewers {
+			viewers = append(viewers, viewer)
+		}
+	}
+	sort.Slice(viewers, func(i, j int) bool {
+		if viewers[i].User != viewers[j].User {
+			return viewers[i].User < viewers[j].User
+		}
+		return viewers[i].ID < viewers[j].ID
+	})
+	return viewers
+}
+
+func newViewerID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// ViewerCount returns the number of viewers of a task known to the hub.
+func (h *Hub) ViewerCount(taskID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if r := h.rooms[taskID]; r != nil {
+		return len(r.viewers)
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+// Package presence tracks who has a task open and relays their unsaved field
+// edits over WebSocket connections.
+package presence
+
+const (
+	// Subprotocol is the WebSocket subprotocol spoken by clients.
+	Subprotocol = "presence.v1"
+
+	// Sent by clients.
+	MessageSubscribe   = "subscribe"
+	MessageUnsubscribe = "unsubscribe"
+	MessageUpdate      = "update"
+
+	// Sent by the server. MessageUpdate is relayed to the other viewers.
+	MessageHello   = "hello"
+	MessageViewers = "viewers"
+	MessageJoin    = "join"
+	MessageLeave   = "leave"
+	MessageError   = "error"
+)
+
+// editableFields are the task fields whose edits are relayed.
+var editableFields = map[string]bool{"title": true, "description": true, "status": true}
+
+// Viewer is one connection looking at tasks. User is the authenticated
+// principal, so the same user may appear once per open tab.
+type Viewer struct {
+	ID   string `json:"id"`
+	User string `json:"user"`
+}
+
+type Message struct {
+	Viewer  *Viewer  `json:"viewer,omitempty"`
+	Type    string   `json:"type"`
+	TaskID  string   `json:"task_id,omitempty"`
+	Field   string   `json:"field,omitempty"`
+	Value   string   `json:"value,omitempty"`
+	Error   string   `json:"error,omitempty"`
+	Viewers []Viewer `json:"viewers,omitempty"`
+}
@@ -0,0 +1,207 @@
+package presence
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPresence(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Presence Suite")
+}
+
+// testClient speaks the presence protocol the way the frontend does.
+type testClient struct {
+	conn   *websocket.Conn
+	viewer Viewer
+}
+
+func dial(server *httptest.Server, user string, canEdit bool) *testClient {
+	url := fmt.Sprintf("ws%s?user=%s&edit=%t", strings.TrimPrefix(server.URL, "http"), user, canEdit)
+	conn, response, err := (&websocket.Dialer{Subprotocols: []string{Subprotocol}}).Dial(url, nil)
+	Expect(err).To(Succeed())
+	Expect(response.Header.Get("Sec-WebSocket-Protocol")).To(Equal(Subprotocol))
+
+	client := &testClient{conn: conn}
+	hello := client.receive()
+	Expect(hello.Type).To(Equal(MessageHello))
+	client.viewer = *hello.Viewer
+	DeferCleanup(func() { _ = conn.Close() })
+	return client
+}
+
+func (c *testClient) send(msg Message) {
+	ExpectWithOffset(1, c.conn.WriteJSON(msg)).To(Succeed())
+}
+
+func (c *testClient) receive() Message {
+	var msg Message
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	ExpectWithOffset(1, c.conn.ReadJSON(&msg)).To(Succeed())
+	return msg
+}
+
+// subscribe joins taskID and returns the viewers already there.
+func (c *testClient) subscribe(taskID string) []Viewer {
+	c.send(Message{Type: MessageSubscribe, TaskID: taskID})
+	msg := c.receive()
+	ExpectWithOffset(1, msg.Type).To(Equal(MessageViewers))
+	return msg.Viewers
+}
+
+var _ = Describe("Hub", func() {
+	var (
+		hub    *Hub
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		hub = NewHub(NewMemoryBroker())
+		upgrader := websocket.Upgrader{Subprotocols: []string{Subprotocol}}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			hub.Serve(context.Background(), conn, r.URL.Query().Get("user"), r.URL.Query().Get("edit") == "true")
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("announces viewers joining and leaving a task", func() {
+		alice := dial(server, "alice", true)
+		Expect(alice.subscribe("1")).To(ConsistOf(alice.viewer))
+
+		bob := dial(server, "bob", true)
+		Expect(bob.subscribe("1")).To(ConsistOf(alice.viewer, bob.viewer))
+		Expect(alice.receive()).To(Equal(Message{Type: MessageJoin, TaskID: "1", Viewer: &bob.viewer}))
+
+		bob.send(Message{Type: MessageUnsubscribe, TaskID: "1"})
+		Expect(alice.receive()).To(Equal(Message{Type: MessageLeave, TaskID: "1", Viewer: &bob.viewer}))
+		Expect(hub.ViewerCount("1")).To(Equal(1))
+	})
+
+	It("announces a leave when the connection closes", func() {
+		alice := dial(server, "alice", true)
+		alice.subscribe("1")
+		bob := dial(server, "bob", true)
+		bob.subscribe("1")
+		alice.receive()
+
+		Expect(bob.conn.Close()).To(Succeed())
+		Expect(alice.receive()).To(Equal(Message{Type: MessageLeave, TaskID: "1", Viewer: &bob.viewer}))
+	})
+
+	It("relays field updates to the other viewers of the task only", func() {
+		alice := dial(server, "alice", true)
+		alice.subscribe("1")
+		bob := dial(server, "bob", true)
+		bob.subscribe("1")
+		alice.receive()
+		carol := dial(server, "carol", true)
+		carol.subscribe("2")
+
+		bob.send(Message{Type: MessageUpdate, TaskID: "1", Field: "title", Value: "Draft"})
+		Expect(alice.receive()).To(Equal(Message{Type: MessageUpdate, TaskID: "1", Viewer: &bob.viewer, Field: "title", Value: "Draft"}))
+
+		// Nothing queued for carol, the next message she gets answers her own request.
+		carol.send(Message{Type: MessageUpdate, TaskID: "2", Field: "owner"})
+		Expect(carol.receive().Error).To(Equal("Unknown field owner"))
+	})
+
+	DescribeTable("rejects invalid updates",
+		func(canEdit bool, msg Message, expected string) {
+			client := dial(server, "alice", canEdit)
+			client.subscribe("1")
+
+			client.send(msg)
+			reply := client.receive()
+			Expect(reply.Type).To(Equal(MessageError))
+			Expect(reply.Error).To(Equal(expected))
+		},
+		Entry("read only viewer", false, Message{Type: MessageUpdate, TaskID: "1", Field: "title"}, "Not allowed to edit tasks"),
+		Entry("not subscribed", true, Message{Type: MessageUpdate, TaskID: "2", Field: "title"}, "Not subscribed to task"),
+		Entry("missing task", true, Message{Type: MessageSubscribe}, "Missing task_id"),
+		Entry("unknown type", true, Message{Type: "rename", TaskID: "1"}, "Unknown message type rename"),
+	)
+
+	It("drops connections that stop answering pings", func() {
+		hub.PongWait = 100 * time.Millisecond
+		alice := dial(server, "alice", true)
+		alice.subscribe("1")
+
+		// Pings are only answered while reading, so the silent client times out.
+		silent := dial(server, "silent", true)
+		silent.send(Message{Type: MessageSubscribe, TaskID: "1"})
+		Expect(alice.receive().Type).To(Equal(MessageJoin))
+
+		leave := alice.receive()
+		Expect(leave.Type).To(Equal(MessageLeave))
+		Expect(leave.Viewer).To(Equal(&silent.viewer))
+	})
+
+	It("closes every connection on Close", func() {
+		alice := dial(server, "alice", true)
+		hub.Close()
+
+		_ = alice.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, _, err := alice.conn.ReadMessage()
+		Expect(websocket.IsCloseError(err, websocket.CloseGoingAway)).To(BeTrue())
+	})
+
+	It("serves concurrent subscribers", func() {
+		const subscribers = 25
+		editor := dial(server, "editor", true)
+		editor.subscribe("1")
+
+		clients := make([]*testClient, subscribers)
+		for i := range clients {
+			clients[i] = dial(server, fmt.Sprintf("viewer%d", i), false)
+		}
+
+		var wg sync.WaitGroup
+		for _, client := range clients {
+			wg.Add(1)
+			go func(client *testClient) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				client.subscribe("1")
+			}(client)
+		}
+		wg.Wait()
+		Eventually(func() int { return hub.ViewerCount("1") }).Should(Equal(subscribers + 1))
+
+		// Skip the joins, then every subscriber gets the edit once.
+		for i := 0; i < subscribers; i++ {
+			Expect(editor.receive().Type).To(Equal(MessageJoin))
+		}
+		editor.send(Message{Type: MessageUpdate, TaskID: "1", Field: "status", Value: "done"})
+
+		for _, client := range clients {
+			wg.Add(1)
+			go func(client *testClient) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for {
+					msg := client.receive()
+					if msg.Type == MessageUpdate {
+						Expect(msg.Viewer).To(Equal(&editor.viewer))
+						Expect(msg.Value).To(Equal("done"))
+						return
+					}
+					Expect(msg.Type).To(Equal(MessageJoin))
+				}
+			}(client)
+		}
+		wg.Wait()
+	})
+})
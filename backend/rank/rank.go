@@ -0,0 +1,134 @@
+// Package rank orders items with fractional indexing: every item has a rank,
+// a string of base 62 digits compared lexicographically, and an item moved
+// between two others gets a rank between theirs, leaving the others as they
+// are. Ranks are fractions 0.d1d2d3... and never end with the zero digit, so
+// there is always room for a rank before another.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// digits are in ASCII order, so ranks compare as plain strings.
+	digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	base   = len(digits)
+
+	// MaxLength is the length above which the ranks of a list should be
+	// spread again. Moving items to the same place again and again grows
+	// the ranks by a digit every five or six moves.
+	MaxLength = 32
+)
+
+var (
+	ErrInvalidRank = errors.New("InvalidRank")
+	ErrOutOfOrder  = errors.New("OutOfOrder")
+)
+
+// Valid reports whether rank is a rank that Between and Spread could have
+// returned.
+func Valid(rank string) bool {
+	if rank == "" || rank[len(rank)-1] == digits[0] {
+		return false
+	}
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(digits, rank[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Between returns a rank greater than before and less than after. An empty
+// before stands for the start of the list and an empty after for its end, so
+// Between("", "") is the rank of the first item of an empty list.
+func Between(before, after string) (string, error) {
+	if (before != "" && !Valid(before)) || (after != "" && !Valid(after)) {
+		return "", ErrInvalidRank
+	}
+	if after != "" && before >= after {
+		return "", ErrOutOfOrder
+	}
+	return midpoint(before, after), nil
+}
+
+// midpoint returns a rank between a and b, b being empty for the end of the
+// list.
+func midpoint(a, b string) string {
+	if b != "" {
+		// Keep the common prefix, a being padded with zeros.
+		n := 0
+		for n < len(b) && digitAt(a, n) == index(b[n]) {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	low, high := digitAt(a, 0), base
+	if b != "" {
+		high = index(b[0])
+	}
+	if high-low > 1 {
+		return string(digits[(low+high)/2])
+	}
+	// The first digits are consecutive: b alone when it has more digits,
+	// which is less than b and greater than a, or a's first digit followed
+	// by a rank after the rest of a.
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[low]) + midpoint(rest, "")
+}
+
+// Spread returns n ranks in increasing order, evenly spaced and as short as
+// possible while leaving room for a few moves between each of them.
+func Spread(n int) []string {
+	width := 1
+	for capacity := base; capacity < (n+1)*base; capacity *= base {
+		width++
+	}
+	capacity := 1
+	for range width {
+		capacity *= base
+	}
+
+	step := capacity / (n + 1)
+	ranks := make([]string, n)
+	for i := range ranks {
+		ranks[i] = format((i+1)*step, width)
+	}
+	return ranks
+}
+
+// format writes value with width digits, without the trailing zeros.
+func format(value, width int) string {
+	encoded := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		encoded[i] = digits[value%base]
+		value /= base
+	}
+	return strings.TrimRight(string(encoded), digits[:1])
+}
+
+func index(digit byte) int {
+	return strings.IndexByte(digits, digit)
+}
+
+// digitAt returns the value of the digit at i of rank, zero past its end.
+func digitAt(rank string, i int) int {
+	if i >= len(rank) {
+		return 0
+	}
+	return index(rank[i])
+}
@@ -0,0 +1,101 @@
+package rank
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestRank(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rank Suite")
+}
+
+// between returns Between(before, after) after checking it is in order.
+func between(before, after string) string {
+	rank, err := Between(before, after)
+	Expect(err).To(Succeed())
+	Expect(Valid(rank)).To(BeTrue(), rank)
+	Expect(rank > before).To(BeTrue(), "%q after %q", rank, before)
+	if after != "" {
+		Expect(rank < after).To(BeTrue(), "%q before %q", rank, after)
+	}
+	return rank
+}
+
+var _ = Describe("rank", func() {
+	DescribeTable("Between",
+		func(before, after, expected string) {
+			Expect(between(before, after)).To(Equal(expected))
+		},
+		Entry("empty list", "", "", "V"),
+		Entry("first", "", "V", "F"),
+		Entry("last", "V", "", "k"),
+		Entry("middle", "F", "V", "N"),
+		Entry("consecutive digits", "A", "B", "AV"),
+		Entry("shorter after", "A", "A1", "A0V"),
+		Entry("common prefix", "A5", "A6", "A5V"),
+		Entry("longer after", "A", "B5", "B"),
+		Entry("after the last digit", "z", "", "zV"),
+		Entry("before the first digit", "", "01", "00V"),
+	)
+
+	DescribeTable("rejects",
+		func(before, after string, expected error) {
+			_, err := Between(before, after)
+			Expect(err).To(MatchError(expected))
+		},
+		Entry("a trailing zero", "A0", "", ErrInvalidRank),
+		Entry("a foreign digit", "", "a-b", ErrInvalidRank),
+		Entry("ranks out of order", "V", "F", ErrOutOfOrder),
+		Entry("equal ranks", "V", "V", ErrOutOfOrder),
+	)
+
+	It("keeps inserting at the same place", func() {
+		before, after := "F", "G"
+		for range 200 {
+			after = between(before, after)
+		}
+		Expect(len(after)).To(BeNumerically(">", MaxLength))
+	})
+
+	It("keeps a list in order through random moves", func() {
+		random := rand.New(rand.NewSource(1))
+		list := Spread(20)
+		for range 1000 {
+			position := random.Intn(len(list) + 1)
+			before, after := "", ""
+			if position > 0 {
+				before = list[position-1]
+			}
+			if position < len(list) {
+				after = list[position]
+			}
+			list = slices.Insert(list, position, between(before, after))
+		}
+		Expect(slices.IsSorted(list)).To(BeTrue())
+		Expect(slices.Compact(slices.Clone(list))).To(HaveLen(len(list)))
+	})
+
+	DescribeTable("Spread",
+		func(n, width int) {
+			ranks := Spread(n)
+			Expect(ranks).To(HaveLen(n))
+			Expect(slices.IsSorted(ranks)).To(BeTrue())
+			for i, rank := range ranks {
+				Expect(Valid(rank)).To(BeTrue(), rank)
+				Expect(len(rank)).To(BeNumerically("<=", width))
+				if i > 0 {
+					Expect(rank).NotTo(Equal(ranks[i-1]))
+				}
+			}
+		},
+		Entry("nothing", 0, 0),
+		Entry("one", 1, 2),
+		Entry("a column", 30, 2),
+		Entry("a long column", 100, 3),
+		Entry("a huge column", 10000, 4),
+	)
+})
@@ -0,0 +1,129 @@
+// Package recurrence creates the occurrences of the recurring tasks: the
+// next one of a series is created when the latest is done, or when it falls
+// due even though the latest isn't.
+package recurrence
+
+import (
+	"context"
+	"github.com/saarzur123/task-management/backend/clock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/rrule"
+	"github.com/saarzur123/task-management/backend/service"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPollInterval = time.Minute
+)
+
+// Scheduler polls the recurring tasks and creates their next occurrences.
+// Creating an occurrence is idempotent, any number of schedulers can run on
+// the same database.
+type Scheduler struct {
+	Tasks service.RecurringTasks
+	// Events, when set, publishes the created occurrences to the live
+	// subscribers. The outbox records them either way.
+	Events service.EventPublisher
+	Clock  clock.Clock
+	Logger *slog.Logger
+
+	PollInterval time.Duration
+}
+
+func NewScheduler(tasks service.RecurringTasks, events service.EventPublisher, logger *slog.Logger) *Scheduler {
+	return &Scheduler{
+		Tasks:        tasks,
+		Events:       events,
+		Clock:        clock.Real{},
+		Logger:       logger,
+		PollInterval: DefaultPollInterval,
+	}
+}
+
+// Run polls until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		if err := s.Poll(ctx); err != nil && ctx.Err() == nil {
+			s.Logger.ErrorContext(ctx, "failed to create recurring tasks", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.Clock.After(s.PollInterval):
+		}
+	}
+}
+
+// Poll creates the next occurrence of the series whose latest occurrence is
+// done or whose next one is due. It repeats until none is left, so a series
+// that missed several occurrences catches up with all of them.
+func (s *Scheduler) Poll(ctx context.Context) error {
+	now := s.Clock.Now()
+	for {
+		heads, err := s.Tasks.RecurringHeads(ctx)
+		if err != nil {
+			return err
+		}
+
+		created := false
+		for i := range heads {
+			next := s.next(ctx, &heads[i], now)
+			if next == nil {
+				continue
+			}
+			ok, err := s.Tasks.CreateOccurrence(ctx, &heads[i], next)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			created = true
+			if s.Events != nil {
+				s.Events.Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: next.ID, Task: next})
+			}
+		}
+		if !created {
+			return nil
+		}
+	}
+}
+
+// next returns the occurrence following head when it should be created by
+// now, or nil.
+func (s *Scheduler) next(ctx context.Context, head *models.Task, now time.Time) *models.Task {
+	rule, err := rrule.Parse(head.Recurrence)
+	if err != nil {
+		s.Logger.WarnContext(ctx, "skipping task with an invalid recurrence",
+			slog.String("task_id", head.ID), slog.Any("error", err))
+		return nil
+	}
+	if rule.Count > 0 && head.Occurrence >= rule.Count {
+		return nil
+	}
+	due, ok := rule.Next(*head.DueAt, *head.DueAt)
+	if !ok || (!done(head.Status) && due.After(now)) {
+		return nil
+	}
+
+	return &models.Task{
+		Title:       head.Title,
+		Description: head.Description,
+		Status:      models.StatusPending,
+		DueAt:       &due,
+		Recurrence:  head.Recurrence,
+		SeriesID:    head.SeriesID,
+		Occurrence:  head.Occurrence + 1,
+		Assignee:    head.Assignee,
+		StoryPoints: head.StoryPoints,
+	}
+}
+
+// done reports whether status completes a task. The statuses are free text,
+// "completed" is common too.
+func done(status string) bool {
+	return strings.EqualFold(status, models.StatusDone) || strings.EqualFold(status, "completed")
+}
@@ -0,0 +1,227 @@
+package recurrence
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/clock"
+	"github.com/saarzur123/task-management/backend/events"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errMock = errors.New("mock error")
+
+func TestRecurrence(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Recurrence Suite")
+}
+
+// fakeTasks keeps the series in memory the way TaskManager stores them.
+type fakeTasks struct {
+	mu       sync.Mutex
+	tasks    []models.Task
+	recurred map[string]bool
+}
+
+func (f *fakeTasks) RecurringHeads(context.Context) ([]models.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	heads := make([]models.Task, 0)
+	for _, task := range f.tasks {
+		if task.Recurrence != "" && task.DueAt != nil && !f.recurred[task.ID] {
+			heads = append(heads, task)
+		}
+	}
+	return heads, nil
+}
+
+func (f *fakeTasks) CreateOccurrence(_ context.Context, previous, next *models.Task) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.recurred[previous.ID] {
+		return false, nil
+	}
+	f.recurred[previous.ID] = true
+	next.ID = strconv.Itoa(len(f.tasks) + 1)
+	f.tasks = append(f.tasks, *next)
+	return true, nil
+}
+
+func (f *fakeTasks) complete(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.tasks {
+		if f.tasks[i].ID == id {
+			f.tasks[i].Status = models.StatusDone
+		}
+	}
+}
+
+func (f *fakeTasks) dueDates() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var dates []string
+	for _, task := range f.tasks {
+		dates = append(dates, task.DueAt.Format("2006-01-02"))
+	}
+	return dates
+}
+
+var _ = Describe("Scheduler", func() {
+	var (
+		// A Monday.
+		start     = time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+		fakeClock *clock.Fake
+		tasks     *fakeTasks
+		hub       *events.Hub
+		scheduler *Scheduler
+		ctx       = context.Background()
+	)
+
+	recurring := func(rule string) {
+		tasks.tasks = []models.Task{{
+			ID: "1", Title: "Standup", Description: "Daily sync", Status: models.StatusPending,
+			DueAt: &start, Recurrence: rule, SeriesID: "1", Occurrence: 1,
+		}}
+	}
+
+	BeforeEach(func() {
+		fakeClock = clock.NewFake(start.Add(-time.Hour))
+		tasks = &fakeTasks{recurred: map[string]bool{}}
+		hub = events.NewHub(events.DefaultReplaySize, events.DefaultSubscriberBuffer)
+		scheduler = NewScheduler(tasks, hub, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		scheduler.Clock = fakeClock
+	})
+
+	It("waits for the next occurrence to fall due", func() {
+		recurring("FREQ=DAILY")
+
+		Expect(scheduler.Poll(ctx)).To(Succeed())
+		fakeClock.Advance(24 * time.Hour)
+		Expect(scheduler.Poll(ctx)).To(Succeed())
+		Expect(tasks.dueDates()).To(Equal([]string{"2026-01-05"}))
+
+		fakeClock.Advance(time.Hour)
+		Expect(scheduler.Poll(ctx)).To(Succeed())
+		Expect(tasks.dueDates()).To(Equal([]string{"2026-01-05", "2026-01-06"}))
+		Expect(tasks.tasks[1]).To(Equal(models.Task{
+			ID: "2", Title: "Standup", Description: "Daily sync", Status: models.StatusPending,
+			DueAt: tasks.tasks[1].DueAt, Recurrence: "FREQ=DAILY", SeriesID: "1", Occurrence: 2,
+		}))
+	})
+
+	It("creates the next occurrence as soon as the latest is done", func() {
+		recurring("FREQ=WEEKLY;BYDAY=MO,TH")
+		tasks.complete("1")
+
+		Expect(scheduler.Poll(ctx)).To(Succeed())
+		Expect(tasks.dueDates()).To(Equal([]string{"2026-01-05", "2026-01-08"}))
+	})
+
+	It("is idempotent", func() {
+		recurring("FREQ=DAILY")
+		tasks.complete("1")
+
+		Expect(scheduler.Poll(ctx)).To(Succeed())
+		Expect(scheduler.Poll(ctx)).To(Succeed())
+		Expect(tasks.dueDates()).To(HaveLen(2))
+	})
+
+	It("catches up with the occurrences missed while it wasn't running", func() {
+		recurring("FREQ=WEEKLY;BYDAY=MO,WE,FR")
+		fakeClock.Advance(8 * 24 * time.Hour)
+
+		Expect(scheduler.Poll(ctx)).To(Succeed())
+		Expect(tasks.dueDates()).To(Equal([]string{"2026-01-05", "2026-01-07", "2026-01-09", "2026-01-12"}))
+	})
+
+	It("stops after COUNT occurrences", func() {
+		recurring("FREQ=DAILY;COUNT=3")
+		fakeClock.Advance(30 * 24 * time.Hour)
+
+		Expect(scheduler.Poll(ctx)).To(Succeed())
+		Expect(tasks.dueDates()).To(Equal([]string{"2026-01-05", "2026-01-06", "2026-01-07"}))
+	})
+
+	It("stops at UNTIL", func() {
+		recurring("FREQ=MONTHLY;UNTIL=20260305")
+		fakeClock.Advance(365 * 24 * time.Hour)
+
+		Expect(scheduler.Poll(ctx)).To(Succeed())
+		Expect(tasks.dueDates()).To(Equal([]string{"2026-01-05", "2026-02-05", "2026-03-05"}))
+	})
+
+	It("publishes the created occurrences", func() {
+		recurring("FREQ=DAILY")
+		tasks.complete("1")
+		subscription, _, _ := hub.Subscribe(0)
+		defer subscription.Unsubscribe()
+
+		Expect(scheduler.Poll(ctx)).To(Succeed())
+		var event models.TaskEvent
+		Expect(subscription.C).To(Receive(&event))
+		Expect(event.Type).To(Equal(models.EventTaskCreated))
+		Expect(event.TaskID).To(Equal("2"))
+	})
+
+	It("skips invalid recurrences", func() {
+		recurring("FREQ=YEARLY")
+		tasks.complete("1")
+
+		Expect(scheduler.Poll(ctx)).To(Succeed())
+		Expect(tasks.dueDates()).To(HaveLen(1))
+	})
+
+	It("polls on the clock when running", func() {
+		recurring("FREQ=DAILY")
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			scheduler.Run(runCtx)
+		}()
+
+		Eventually(fakeClock.Waiters).Should(Equal(1))
+		Expect(tasks.dueDates()).To(HaveLen(1))
+		fakeClock.Advance(25 * time.Hour)
+		Eventually(tasks.dueDates).Should(HaveLen(2))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+
+	Describe("storage errors", func() {
+		var mockTasks *serviceMock.MockRecurringTasks
+
+		BeforeEach(func() {
+			mockTasks = serviceMock.NewMockRecurringTasks(gomock.NewController(GinkgoT()))
+			scheduler.Tasks = mockTasks
+		})
+
+		It("returns them", func() {
+			mockTasks.EXPECT().RecurringHeads(gomock.Any()).Return(nil, errMock)
+			Expect(scheduler.Poll(ctx)).To(MatchError(errMock))
+		})
+
+		It("stops at the first failed occurrence", func() {
+			mockTasks.EXPECT().RecurringHeads(gomock.Any()).Return([]models.Task{
+				{ID: "1", Status: models.StatusDone, DueAt: &start, Recurrence: "FREQ=DAILY", SeriesID: "1", Occurrence: 1},
+				{ID: "2", Status: models.StatusDone, DueAt: &start, Recurrence: "FREQ=DAILY", SeriesID: "2", Occurrence: 1},
+			}, nil)
+			mockTasks.EXPECT().CreateOccurrence(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, errMock)
+
+			Expect(scheduler.Poll(ctx)).To(MatchError(errMock))
+		})
+	})
+})
@@ -0,0 +1,347 @@
+// Package rrule parses and expands the subset of the RFC 5545 recurrence
+// rules that recurring tasks support: FREQ=DAILY, WEEKLY or MONTHLY with
+// INTERVAL, BYDAY, COUNT and UNTIL.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+
+	untilDateLayout     = "20060102"
+	untilDateTimeLayout = "20060102T150405Z"
+	// maxEmptyPeriods ends the expansion of rules that never match, such as
+	// the Tuesdays of every 7th day starting on a Wednesday.
+	maxEmptyPeriods = 1000
+)
+
+var (
+	ErrInvalidRule = errors.New("InvalidRule")
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Weekday is a BYDAY entry. N picks the Nth such day of the month, counting
+// from the end when negative, and is zero for every one of them.
+type Weekday struct {
+	Day time.Weekday
+	N   int
+}
+
+func (w Weekday) String() string {
+	day := strings.ToUpper(w.Day.String()[:2])
+	if w.N == 0 {
+		return day
+	}
+	return strconv.Itoa(w.N) + day
+}
+
+// Rule is a parsed recurrence rule. Count and Until are zero when the rule
+// doesn't set them.
+type Rule struct {
+	Until    time.Time
+	Freq     Frequency
+	ByDay    []Weekday
+	Interval int
+	Count    int
+	// untilDate is set when UNTIL is a date, which includes the whole day.
+	untilDate bool
+}
+
+// Parse parses a rule such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10", with or
+// without the "RRULE:" prefix. Its errors match ErrInvalidRule.
+func Parse(value string) (*Rule, error) {
+	rule := &Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(value), "RRULE:"), ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, invalid("expected NAME=VALUE, got %q", part)
+		}
+		if seen[name] {
+			return nil, invalid("%s is set twice", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(value)
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly {
+				return nil, invalid("unsupported FREQ %s, expected DAILY, WEEKLY or MONTHLY", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = positive(name, value)
+		case "COUNT":
+			rule.Count, err = positive(name, value)
+		case "UNTIL":
+			err = rule.parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		default:
+			return nil, invalid("unsupported %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, invalid("FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, invalid("COUNT and UNTIL can't both be set")
+	}
+	if rule.Freq != Monthly {
+		for _, day := range rule.ByDay {
+			if day.N != 0 {
+				return nil, invalid("BYDAY %s needs FREQ=MONTHLY", day)
+			}
+		}
+	}
+	return rule, nil
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalidRule}, args...)...)
+}
+
+func positive(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, invalid("%s must be a positive integer, got %s", name, value)
+	}
+	return n, nil
+}
+
+func (r *Rule) parseUntil(value string) error {
+	if until, err := time.Parse(untilDateTimeLayout, value); err == nil {
+		r.Until = until
+		return nil
+	}
+	until, err := time.Parse(untilDateLayout, value)
+	if err != nil {
+		return invalid("UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ, got %s", value)
+	}
+	r.Until = until
+	r.untilDate = true
+	return nil
+}
+
+func parseByDay(value string) ([]Weekday, error) {
+	var days []Weekday
+	for _, entry := range strings.Split(value, ",") {
+		if len(entry) < 2 {
+			return nil, invalid("invalid BYDAY %s", entry)
+		}
+		day, ok := weekdays[entry[len(entry)-2:]]
+		if !ok {
+			return nil, invalid("invalid BYDAY %s", entry)
+		}
+		n := 0
+		if ordinal := entry[:len(entry)-2]; ordinal != "" {
+			var err error
+			n, err = strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, invalid("invalid BYDAY %s, the ordinal must be 1 to 5 or -5 to -1", entry)
+			}
+		}
+		days = append(days, Weekday{Day: day, N: n})
+	}
+	return days, nil
+}
+
+// String returns the rule in its canonical form, which Parse accepts.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.untilDate {
+		parts = append(parts, "UNTIL="+r.Until.Format(untilDateLayout))
+	} else if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilDateTimeLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+// All iterates over the occurrences of the rule starting at start, which
+// is the first one when it matches the rule, until COUNT or UNTIL ends the
+// series. The occurrences keep the time of day and the location of start.
+func (r *Rule) All(start time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		count := 0
+		for occurrence := range r.expand(start) {
+			if r.Count > 0 && count == r.Count {
+				return
+			}
+			if !yield(occurrence) {
+				return
+			}
+			count++
+		}
+	}
+}
+
+// Next returns the first occurrence after after of the rule starting at
+// start, and false once UNTIL ended the series. COUNT is left to the caller,
+// who knows how many occurrences came before start.
+func (r *Rule) Next(start, after time.Time) (time.Time, bool) {
+	for occurrence := range r.expand(start) {
+		if occurrence.After(after) {
+			return occurrence, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// expand iterates over the occurrences from start until UNTIL, ignoring
+// COUNT.
+func (r *Rule) expand(start time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		for period, empty := 0, 0; empty < maxEmptyPeriods; period++ {
+			candidates := r.period(start, period)
+			if len(candidates) == 0 {
+				empty++
+				continue
+			}
+			empty = 0
+			for _, candidate := range candidates {
+				if candidate.Before(start) {
+					continue
+				}
+				if r.ended(candidate) {
+					return
+				}
+				if !yield(candidate) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Rule) ended(t time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+	if r.untilDate {
+		year, month, day := t.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).After(r.Until)
+	}
+	return t.After(r.Until)
+}
+
+// period returns the candidates of the nth period of the rule in order:
+// the days, weeks or months INTERVAL apart from the one of start. Weeks
+// start on Monday.
+func (r *Rule) period(start time.Time, n int) []time.Time {
+	year, month, day := start.Date()
+	switch r.Freq {
+	case Daily:
+		candidate := at(start, year, month, day+n*r.Interval)
+		if len(r.ByDay) > 0 && !slices.ContainsFunc(r.ByDay, func(w Weekday) bool { return w.Day == candidate.Weekday() }) {
+			return nil
+		}
+		return []time.Time{candidate}
+
+	case Weekly:
+		monday := day - daysSinceMonday(start.Weekday()) + 7*n*r.Interval
+		days := []time.Weekday{start.Weekday()}
+		if len(r.ByDay) > 0 {
+			days = days[:0]
+			for _, w := range r.ByDay {
+				days = append(days, w.Day)
+			}
+		}
+		offsets := make([]int, 0, len(days))
+		for _, d := range days {
+			offsets = append(offsets, daysSinceMonday(d))
+		}
+		slices.Sort(offsets)
+		offsets = slices.Compact(offsets)
+
+		candidates := make([]time.Time, len(offsets))
+		for i, offset := range offsets {
+			candidates[i] = at(start, year, month, monday+offset)
+		}
+		return candidates
+
+	default:
+		first := time.Date(year, month+time.Month(n*r.Interval), 1, 0, 0, 0, 0, time.UTC)
+		year, month = first.Year(), first.Month()
+		length := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		if len(r.ByDay) == 0 {
+			if day > length {
+				return nil
+			}
+			return []time.Time{at(start, year, month, day)}
+		}
+
+		var days []int
+		for _, w := range r.ByDay {
+			var matches []int
+			for d := 1 + (int(w.Day)-int(first.Weekday())+7)%7; d <= length; d += 7 {
+				matches = append(matches, d)
+			}
+			switch {
+			case w.N == 0:
+				days = append(days, matches...)
+			case w.N > 0 && w.N <= len(matches):
+				days = append(days, matches[w.N-1])
+			case w.N < 0 && -w.N <= len(matches):
+				days = append(days, matches[len(matches)+w.N])
+			}
+		}
+		slices.Sort(days)
+		days = slices.Compact(days)
+
+		candidates := make([]time.Time, len(days))
+		for i, d := range days {
+			candidates[i] = at(start, year, month, d)
+		}
+		return candidates
+	}
+}
+
+// at returns the date at the time of day of start, in its location. Days
+// out of range roll over into the next months as with time.Date.
+func at(start time.Time, year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+}
+
+func daysSinceMonday(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
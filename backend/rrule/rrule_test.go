@@ -0,0 +1,173 @@
+package rrule
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRRule(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RRule Suite")
+}
+
+// dates lists the first n occurrences of rule from start as YYYY-MM-DD.
+func dates(rule string, start time.Time, n int) []string {
+	parsed, err := Parse(rule)
+	Expect(err).To(Succeed())
+
+	var occurrences []string
+	for occurrence := range parsed.All(start) {
+		if len(occurrences) == n {
+			break
+		}
+		occurrences = append(occurrences, occurrence.Format("2006-01-02"))
+	}
+	return occurrences
+}
+
+var _ = Describe("rrule", func() {
+	// A Wednesday.
+	start := time.Date(2026, time.January, 7, 9, 30, 0, 0, time.UTC)
+
+	Describe("Parse", func() {
+		It("parses every supported part", func() {
+			rule, err := Parse("RRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=1MO,-1FR;COUNT=4")
+			Expect(err).To(Succeed())
+			Expect(rule.Freq).To(Equal(Monthly))
+			Expect(rule.Interval).To(Equal(2))
+			Expect(rule.ByDay).To(Equal([]Weekday{{Day: time.Monday, N: 1}, {Day: time.Friday, N: -1}}))
+			Expect(rule.Count).To(Equal(4))
+		})
+
+		It("ignores case and defaults INTERVAL to 1", func() {
+			rule, err := Parse("freq=daily;until=20260110")
+			Expect(err).To(Succeed())
+			Expect(rule.Interval).To(Equal(1))
+			Expect(rule.Until).To(Equal(time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC)))
+		})
+
+		DescribeTable("formats rules canonically",
+			func(value, expected string) {
+				rule, err := Parse(value)
+				Expect(err).To(Succeed())
+				Expect(rule.String()).To(Equal(expected))
+			},
+			Entry("daily", "FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"),
+			Entry("weekly", "BYDAY=MO,WE;FREQ=WEEKLY;INTERVAL=2", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"),
+			Entry("until a date", "FREQ=DAILY;UNTIL=20261231", "FREQ=DAILY;UNTIL=20261231"),
+			Entry("until a time", "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20261231T170000Z", "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20261231T170000Z"),
+		)
+
+		DescribeTable("rejects invalid rules",
+			func(value string) {
+				_, err := Parse(value)
+				Expect(err).To(MatchError(ErrInvalidRule))
+			},
+			Entry("empty", ""),
+			Entry("without FREQ", "COUNT=3"),
+			Entry("yearly", "FREQ=YEARLY"),
+			Entry("unsupported part", "FREQ=DAILY;BYHOUR=9"),
+			Entry("repeated part", "FREQ=DAILY;FREQ=WEEKLY"),
+			Entry("zero interval", "FREQ=DAILY;INTERVAL=0"),
+			Entry("negative count", "FREQ=DAILY;COUNT=-1"),
+			Entry("invalid until", "FREQ=DAILY;UNTIL=tomorrow"),
+			Entry("count and until", "FREQ=DAILY;COUNT=2;UNTIL=20261231"),
+			Entry("unknown weekday", "FREQ=WEEKLY;BYDAY=XX"),
+			Entry("ordinal out of range", "FREQ=MONTHLY;BYDAY=6MO"),
+			Entry("ordinal on a weekly rule", "FREQ=WEEKLY;BYDAY=1MO"),
+		)
+	})
+
+	Describe("All", func() {
+		It("repeats daily at the time of the start", func() {
+			rule, err := Parse("FREQ=DAILY;INTERVAL=2;COUNT=3")
+			Expect(err).To(Succeed())
+			Expect(slices.Collect(rule.All(start))).To(Equal([]time.Time{
+				start,
+				time.Date(2026, time.January, 9, 9, 30, 0, 0, time.UTC),
+				time.Date(2026, time.January, 11, 9, 30, 0, 0, time.UTC),
+			}))
+		})
+
+		It("limits daily rules to BYDAY", func() {
+			Expect(dates("FREQ=DAILY;BYDAY=MO,FR", start, 4)).To(Equal([]string{"2026-01-09", "2026-01-12", "2026-01-16", "2026-01-19"}))
+		})
+
+		It("repeats weekly on the day of the start", func() {
+			Expect(dates("FREQ=WEEKLY;INTERVAL=2", start, 3)).To(Equal([]string{"2026-01-07", "2026-01-21", "2026-02-04"}))
+		})
+
+		It("repeats weekly on BYDAY, skipping the days before the start", func() {
+			Expect(dates("FREQ=WEEKLY;BYDAY=MO,WE,FR", start, 5)).To(Equal([]string{"2026-01-07", "2026-01-09", "2026-01-12", "2026-01-14", "2026-01-16"}))
+		})
+
+		It("counts BYDAY weeks from Monday", func() {
+			Expect(dates("FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO", start, 4)).To(Equal([]string{"2026-01-11", "2026-01-19", "2026-01-25", "2026-02-02"}))
+		})
+
+		It("repeats monthly on the day of the start, skipping the months without it", func() {
+			end := time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC)
+			Expect(dates("FREQ=MONTHLY", end, 4)).To(Equal([]string{"2026-01-31", "2026-03-31", "2026-05-31", "2026-07-31"}))
+		})
+
+		It("repeats monthly on the Nth weekdays", func() {
+			Expect(dates("FREQ=MONTHLY;BYDAY=1MO,-1FR", start, 4)).To(Equal([]string{"2026-01-30", "2026-02-02", "2026-02-27", "2026-03-02"}))
+		})
+
+		It("repeats monthly on every such weekday", func() {
+			Expect(dates("FREQ=MONTHLY;INTERVAL=3;BYDAY=TH", start, 6)).To(Equal([]string{
+				"2026-01-08", "2026-01-15", "2026-01-22", "2026-01-29", "2026-04-02", "2026-04-09",
+			}))
+		})
+
+		It("skips the months without the fifth weekday", func() {
+			Expect(dates("FREQ=MONTHLY;BYDAY=5FR", start, 3)).To(Equal([]string{"2026-01-30", "2026-05-29", "2026-07-31"}))
+		})
+
+		It("stops at UNTIL, a date including the whole day", func() {
+			Expect(dates("FREQ=DAILY;UNTIL=20260109", start, 10)).To(Equal([]string{"2026-01-07", "2026-01-08", "2026-01-09"}))
+			Expect(dates("FREQ=DAILY;UNTIL=20260109T090000Z", start, 10)).To(Equal([]string{"2026-01-07", "2026-01-08"}))
+		})
+
+		It("keeps the wall clock time across daylight saving changes", func() {
+			newYork, err := time.LoadLocation("America/New_York")
+			Expect(err).To(Succeed())
+			rule, err := Parse("FREQ=DAILY")
+			Expect(err).To(Succeed())
+
+			next, ok := rule.Next(time.Date(2026, time.March, 7, 9, 0, 0, 0, newYork), time.Date(2026, time.March, 7, 9, 0, 0, 0, newYork))
+			Expect(ok).To(BeTrue())
+			Expect(next).To(Equal(time.Date(2026, time.March, 8, 9, 0, 0, 0, newYork)))
+		})
+
+		It("ends rules that never match again", func() {
+			Expect(dates("FREQ=DAILY;INTERVAL=7;BYDAY=TU", start, 1)).To(BeEmpty())
+		})
+	})
+
+	Describe("Next", func() {
+		It("returns the first occurrence after the given time", func() {
+			rule, err := Parse("FREQ=WEEKLY;BYDAY=MO,FR")
+			Expect(err).To(Succeed())
+
+			next, ok := rule.Next(start, time.Date(2026, time.January, 12, 9, 30, 0, 0, time.UTC))
+			Expect(ok).To(BeTrue())
+			Expect(next).To(Equal(time.Date(2026, time.January, 16, 9, 30, 0, 0, time.UTC)))
+		})
+
+		It("ignores COUNT but reports the end of UNTIL", func() {
+			rule, err := Parse("FREQ=DAILY;COUNT=1")
+			Expect(err).To(Succeed())
+			_, ok := rule.Next(start, start)
+			Expect(ok).To(BeTrue())
+
+			rule, err = Parse("FREQ=DAILY;UNTIL=20260107")
+			Expect(err).To(Succeed())
+			_, ok = rule.Next(start, start)
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
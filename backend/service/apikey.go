@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"github.com/saarzur123/task-management/backend/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetAll(ctx context.Context) ([]models.APIKey, error)
+	Revoke(ctx context.Context, id string) error
+	Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error)
+	MarkUsed(ctx context.Context, key *models.APIKey) error
+}
+
+type APIKeyManager struct {
+	DB *sql.DB
+}
+
+const (
+	apiKeyTokenPrefix = "tm_"
+	apiKeySecretBytes = 32
+	apiKeyPrefixLen   = len(apiKeyTokenPrefix) + 8
+	// apiKeyMinSeedLen keeps seeded keys as hard to guess as generated ones.
+	apiKeyMinSeedLen = len(apiKeyTokenPrefix) + 2*apiKeySecretBytes
+	scopesSeparator  = ","
+)
+
+const (
+	AnonymousUser = "anonymous"
+)
+
+var (
+	ErrInvalidAPIKey = errors.New("InvalidAPIKey")
+)
+
+type contextKey int
+
+const (
+	apiKeyContextKey contextKey = iota
+)
+
+// ContextWithAPIKey returns a copy of ctx carrying the authenticated API key.
+func ContextWithAPIKey(ctx context.Context, key *models.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey, key)
+}
+
+// APIKeyFromContext returns the API key the request was authenticated with, if any.
+func APIKeyFromContext(ctx context.Context) (*models.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey).(*models.APIKey)
+	return key, ok
+}
+
+// UserFromContext returns the user acting in ctx: the name of its API key,
+// AnonymousUser without one.
+func UserFromContext(ctx context.Context) string {
+	if key, ok := APIKeyFromContext(ctx); ok {
+		return key.Name
+	}
+	return AnonymousUser
+}
+
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIKey() (string, error) {
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return apiKeyTokenPrefix + hex.EncodeToString(secret), nil
+}
+
+// Create generates a new random key, stores only its hash and sets the plain
+// text value on key.Key so it can be handed to the caller once.
+func (m *APIKeyManager) Create(ctx context.Context, key *models.APIKey) error {
+	rawKey, err := generateAPIKey()
+	if err != nil {
+		return err
+	}
+
+	key.Prefix = rawKey[:apiKeyPrefixLen]
+	key.CreatedAt = time.Now()
+	query := `INSERT INTO api_keys (name, prefix, key_hash, scopes, created_at) VALUES (?, ?, ?, ?, ?)`
+	row, err := m.DB.ExecContext(ctx, query, key.Name, key.Prefix, hashAPIKey(rawKey), strings.Join(key.Scopes, scopesSeparator), key.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	dbID, err := row.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	key.ID = strconv.FormatInt(dbID, 10)
+	key.Key = rawKey
+	return nil
+}
+
+func (m *APIKeyManager) GetAll(ctx context.Context) ([]models.APIKey, error) {
+	query := `SELECT id, name, prefix, scopes, created_at, last_used_at, revoked_at FROM api_keys`
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (m *APIKeyManager) Revoke(ctx context.Context, id string) error {
+	query := `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+	rows, err := m.DB.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := rows.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Seed stores rawKey as an admin key named name unless it is already stored,
+// so the first key can be provided before any key exists to create it with.
+// A revoked seed stays revoked.
+func (m *APIKeyManager) Seed(ctx context.Context, name, rawKey string) error {
+	if !strings.HasPrefix(rawKey, apiKeyTokenPrefix) || len(rawKey) < apiKeyMinSeedLen {
+		return ErrInvalidAPIKey
+	}
+
+	query := `INSERT INTO api_keys (name, prefix, key_hash, scopes, created_at) VALUES (?, ?, ?, ?, ?) ON CONFLICT (key_hash) DO NOTHING`
+	_, err := m.DB.ExecContext(ctx, query, name, rawKey[:apiKeyPrefixLen], hashAPIKey(rawKey), models.ScopeAPIKeyAdmin, time.Now())
+	return err
+}
+
+// Authenticate looks up an active key by the hash of rawKey. It only reads,
+// so requests refused before reaching a handler, e.g. for their rate, leave
+// no trace; MarkUsed records the requests let through.
+func (m *APIKeyManager) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyTokenPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	query := `SELECT id, name, prefix, scopes, created_at, last_used_at, revoked_at FROM api_keys WHERE key_hash = ? AND revoked_at IS NULL`
+	key, err := scanAPIKey(m.DB.QueryRowContext(ctx, query, hashAPIKey(rawKey)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// MarkUsed records that key was just used.
+func (m *APIKeyManager) MarkUsed(ctx context.Context, key *models.APIKey) error {
+	now := time.Now()
+	_, err := m.DB.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, now, key.ID)
+	if err != nil {
+		return err
+	}
+	key.LastUsedAt = &now
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var (
+		key       models.APIKey
+		scopes    string
+		lastUsed  sql.NullTime
+		revokedAt sql.NullTime
+	)
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &scopes, &key.CreatedAt, &lastUsed, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = make([]string, 0)
+	if scopes != "" {
+		key.Scopes = strings.Split(scopes, scopesSeparator)
+	}
+	if lastUsed.Valid {
+		key.LastUsedAt = &lastUsed.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return &key, nil
+}
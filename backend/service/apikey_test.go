@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"strings"
+	"time"
+)
+
+var _ = Describe("APIKeyManager", func() {
+	var (
+		manager  *APIKeyManager
+		database *sql.DB
+		mockSQL  sqlmock.Sqlmock
+		columns  = []string{"id", "name", "prefix", "scopes", "created_at", "last_used_at", "revoked_at"}
+		err      error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &APIKeyManager{DB: database}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	Describe("Create", func() {
+		It("stores the hash of a generated key and returns the plain key once", func() {
+			key := models.APIKey{Name: "ci", Scopes: []string{models.ScopeTasksRead, models.ScopeTasksWrite}}
+			mockSQL.ExpectExec("INSERT INTO api_keys").
+				WithArgs("ci", sqlmock.AnyArg(), sqlmock.AnyArg(), "tasks:read,tasks:write", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			Expect(manager.Create(ctx, &key)).To(Succeed())
+			Expect(key.ID).To(Equal("1"))
+			Expect(key.Key).To(HavePrefix("tm_"))
+			Expect(strings.HasPrefix(key.Key, key.Prefix)).To(BeTrue())
+			Expect(key.CreatedAt).ToNot(BeZero())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns error when failed on exec", func() {
+			mockSQL.ExpectExec("INSERT INTO api_keys").WillReturnError(errMock)
+
+			err := manager.Create(ctx, &models.APIKey{Name: "ci"})
+			Expect(err).To(MatchError(errMock))
+		})
+	})
+
+	Describe("Authenticate", func() {
+		const rawKey = "tm_0123456789abcdef"
+
+		It("returns the active key matching the hash without writing", func() {
+			mockSQL.ExpectQuery("SELECT id, name, prefix, scopes, created_at, last_used_at, revoked_at FROM api_keys WHERE key_hash").
+				WithArgs(hashAPIKey(rawKey)).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow("1", "ci", "tm_01234567", "tasks:read", time.Now(), nil, nil))
+
+			key, err := manager.Authenticate(ctx, rawKey)
+			Expect(err).To(Succeed())
+			Expect(key.Name).To(Equal("ci"))
+			Expect(key.Scopes).To(Equal([]string{models.ScopeTasksRead}))
+			Expect(key.LastUsedAt).To(BeNil())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrInvalidAPIKey when no active key matches", func() {
+			mockSQL.ExpectQuery("SELECT id, name, prefix, scopes").
+				WithArgs(hashAPIKey(rawKey)).
+				WillReturnError(sql.ErrNoRows)
+
+			_, err := manager.Authenticate(ctx, rawKey)
+			Expect(err).To(MatchError(ErrInvalidAPIKey))
+		})
+
+		It("rejects keys without the token prefix without querying", func() {
+			_, err := manager.Authenticate(ctx, "not-a-key")
+			Expect(err).To(MatchError(ErrInvalidAPIKey))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Describe("MarkUsed", func() {
+		It("records the time the key was used", func() {
+			mockSQL.ExpectExec(`UPDATE api_keys SET last_used_at = \? WHERE id = \?`).
+				WithArgs(sqlmock.AnyArg(), "1").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			key := models.APIKey{ID: "1"}
+			Expect(manager.MarkUsed(ctx, &key)).To(Succeed())
+			Expect(key.LastUsedAt).NotTo(BeNil())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Describe("Seed", func() {
+		rawKey := "tm_" + strings.Repeat("ab", apiKeySecretBytes)
+
+		It("stores the hash of the key with the admin scope unless it is stored", func() {
+			mockSQL.ExpectExec(`INSERT INTO api_keys (.+) ON CONFLICT \(key_hash\) DO NOTHING`).
+				WithArgs("admin", "tm_abababab", hashAPIKey(rawKey), models.ScopeAPIKeyAdmin, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			Expect(manager.Seed(ctx, "admin", rawKey)).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		DescribeTable("rejects keys easier to guess than generated ones",
+			func(rawKey string) {
+				Expect(manager.Seed(ctx, "admin", rawKey)).To(MatchError(ErrInvalidAPIKey))
+				Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+			},
+			Entry("without the token prefix", strings.Repeat("ab", apiKeySecretBytes+2)),
+			Entry("too short", "tm_secret"),
+		)
+	})
+
+	Describe("Revoke", func() {
+		It("marks the key as revoked", func() {
+			mockSQL.ExpectExec(`UPDATE api_keys SET revoked_at = \? WHERE id = \? AND revoked_at IS NULL`).
+				WithArgs(sqlmock.AnyArg(), "1").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			Expect(manager.Revoke(ctx, "1")).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrNotFound when no active key was revoked", func() {
+			mockSQL.ExpectExec("UPDATE api_keys SET revoked_at").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(manager.Revoke(ctx, "1")).To(MatchError(ErrNotFound))
+		})
+	})
+
+	Describe("GetAll", func() {
+		It("returns keys without their hashes", func() {
+			revoked := time.Now()
+			mockSQL.ExpectQuery("SELECT id, name, prefix, scopes, created_at, last_used_at, revoked_at FROM api_keys").
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("1", "ci", "tm_01234567", "tasks:read,tasks:write", time.Now(), nil, revoked))
+
+			keys, err := manager.GetAll(ctx)
+			Expect(err).To(Succeed())
+			Expect(keys).To(HaveLen(1))
+			Expect(keys[0].Scopes).To(HaveLen(2))
+			Expect(keys[0].RevokedAt).ToNot(BeNil())
+			Expect(keys[0].Key).To(BeEmpty())
+		})
+	})
+
+	Describe("context", func() {
+		It("round-trips the authenticated key", func() {
+			key := &models.APIKey{Name: "ci"}
+			ctx := ContextWithAPIKey(context.Background(), key)
+
+			fromCtx, ok := APIKeyFromContext(ctx)
+			Expect(ok).To(BeTrue())
+			Expect(fromCtx).To(Equal(key))
+
+			_, ok = APIKeyFromContext(context.Background())
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
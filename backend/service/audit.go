@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/saarzur123/task-management/backend/models"
+	"strconv"
+	"time"
+)
+
+type AuditRepository interface {
+	Record(ctx context.Context, entry *models.AuditEntry) error
+	GetAll(ctx context.Context) ([]models.AuditEntry, error)
+}
+
+type AuditManager struct {
+	DB *sql.DB
+}
+
+func (m *AuditManager) Record(ctx context.Context, entry *models.AuditEntry) error {
+	entry.CreatedAt = time.Now()
+	query := `INSERT INTO audit_log (actor, action, resource, created_at) VALUES (?, ?, ?, ?)`
+	row, err := m.DB.ExecContext(ctx, query, entry.Actor, entry.Action, entry.Resource, entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	dbID, err := row.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	entry.ID = strconv.FormatInt(dbID, 10)
+	return nil
+}
+
+func (m *AuditManager) GetAll(ctx context.Context) ([]models.AuditEntry, error) {
+	query := `SELECT id, actor, action, resource, created_at FROM audit_log ORDER BY id`
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]models.AuditEntry, 0)
+	for rows.Next() {
+		var entry models.AuditEntry
+		err = rows.Scan(&entry.ID, &entry.Actor, &entry.Action, &entry.Resource, &entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/rank"
+	"slices"
+)
+
+// TaskBoard shows the tasks as a Kanban board, a column per status, and moves
+// them across it.
+type TaskBoard interface {
+	// Board returns the columns of the board: pending first, done last and
+	// the other statuses in between in alphabetical order. The tasks of a
+	// column are ordered by rank, the tasks never moved last by ID.
+	Board(ctx context.Context) (*models.Board, error)
+	// Move moves the task to move.Status between the tasks move.After and
+	// move.Before, and records its outbox events like Update. It returns
+	// ErrNotFound when the task doesn't exist and ErrInvalidMove when the
+	// neighbors aren't next to each other in the column.
+	Move(ctx context.Context, id string, move models.TaskMove) (*models.Task, error)
+}
+
+var (
+	ErrInvalidMove = errors.New("InvalidMove")
+)
+
+// columnOrder orders the tasks of a column, the tasks never moved last.
+const columnOrder = `rank = '', rank, id`
+
+func (m *TaskManager) Board(ctx context.Context) (_ *models.Board, err error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks ORDER BY status, ` + columnOrder
+	ctx, end := m.observe(ctx, "TaskManager.Board", query)
+	defer func() { end(err) }()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := map[string][]models.Task{models.StatusPending: {}, models.StatusDone: {}}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		columns[task.Status] = append(columns[task.Status], task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	board := &models.Board{Columns: make([]models.BoardColumn, 0, len(columns))}
+	for status, tasks := range columns {
+		board.Columns = append(board.Columns, models.BoardColumn{Status: status, Tasks: tasks})
+	}
+	slices.SortFunc(board.Columns, func(a, b models.BoardColumn) int {
+		return models.CompareStatuses(a.Status, b.Status)
+	})
+	return board, nil
+}
+
+// Move ranks the task between its new neighbors. When they leave no room
+// between them, or one is ranked after tasks never moved, the ranks of the
+// whole column are spread again.
+func (m *TaskManager) Move(ctx context.Context, id string, move models.TaskMove) (_ *models.Task, err error) {
+	query := `UPDATE tasks SET status = ?, rank = ? WHERE id = ?`
+	ctx, end := m.observe(ctx, "TaskManager.Move", query)
+	defer func() { end(err) }()
+	defer m.stats.invalidate()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	task, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	previousStatus := task.Status
+
+	column, err := columnRanks(ctx, tx, move.Status, id)
+	if err != nil {
+		return nil, err
+	}
+	position, err := dropPosition(column, move)
+	if err != nil {
+		return nil, err
+	}
+
+	var before, after string
+	if position > 0 {
+		before = column[position-1].rank
+	}
+	if position < len(column) {
+		after = column[position].rank
+	}
+	task.Rank, err = rank.Between(before, after)
+	if (position > 0 && before == "") || err != nil || len(task.Rank) > rank.MaxLength {
+		task.Rank, err = spreadColumn(ctx, tx, column, position)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	task.Status = move.Status
+	if _, err = tx.ExecContext(ctx, query, task.Status, task.Rank, id); err != nil {
+		return nil, err
+	}
+
+	if err = recordUpdate(ctx, tx, &task, previousStatus, task.Assignee); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+type rankedTask struct {
+	id, rank string
+}
+
+// columnRanks returns the tasks of the column of status but the moving one,
+// in order.
+func columnRanks(ctx context.Context, tx *sql.Tx, status, moving string) ([]rankedTask, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, rank FROM tasks WHERE status = ? AND id != ? ORDER BY `+columnOrder, status, moving)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	column := make([]rankedTask, 0)
+	for rows.Next() {
+		var task rankedTask
+		if err = rows.Scan(&task.id, &task.rank); err != nil {
+			return nil, err
+		}
+		column = append(column, task)
+	}
+	return column, rows.Err()
+}
+
+// dropPosition returns the index of column the moved task goes to, checking
+// that move.After and move.Before are the tasks around it.
+func dropPosition(column []rankedTask, move models.TaskMove) (int, error) {
+	position := 0
+	if move.After != "" {
+		index := slices.IndexFunc(column, func(task rankedTask) bool { return task.id == move.After })
+		if index < 0 {
+			return 0, ErrInvalidMove
+		}
+		position = index + 1
+	}
+
+	if move.Before == "" {
+		if position != len(column) {
+			return 0, ErrInvalidMove
+		}
+		return position, nil
+	}
+	if position == len(column) || column[position].id != move.Before {
+		return 0, ErrInvalidMove
+	}
+	return position, nil
+}
+
+// spreadColumn ranks the tasks of column again, leaving room at position, and
+// returns the rank of the task going there.
+func spreadColumn(ctx context.Context, tx *sql.Tx, column []rankedTask, position int) (string, error) {
+	ranks := rank.Spread(len(column) + 1)
+	for i, task := range column {
+		newRank := ranks[i]
+		if i >= position {
+			newRank = ranks[i+1]
+		}
+		if newRank == task.rank {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE tasks SET rank = ? WHERE id = ?`, newRank, task.id); err != nil {
+			return "", err
+		}
+	}
+	return ranks[position], nil
+}
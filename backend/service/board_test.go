@@ -0,0 +1,263 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"strings"
+	"time"
+)
+
+var _ = Describe("TaskBoard", func() {
+	const (
+		columnQuery = `SELECT id, rank FROM tasks WHERE status = \? AND id != \? ORDER BY rank = '', rank, id`
+		moveQuery   = `UPDATE tasks SET status = \?, rank = \? WHERE id = \?`
+	)
+
+	var (
+		manager   *TaskManager
+		database  *sql.DB
+		mockSQL   sqlmock.Sqlmock
+		columns   = []string{"id", "title", "description", "status", "created_at", "due_at", "recurrence", "series_id", "occurrence", "assignee", "rank", "story_points", "sprint_id"}
+		createdAt = time.Now()
+		err       error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &TaskManager{DB: database}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	Describe("Board", func() {
+		It("orders the columns pending first and done last", func() {
+			mockSQL.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks ORDER BY status, rank = '', rank, id`).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("4", "Task 4", "", "done", createdAt, nil, "", nil, 0, "", "", 0, nil).
+					AddRow("2", "Task 2", "", "in_progress", createdAt, nil, "", nil, 0, "", "V", 0, nil).
+					AddRow("3", "Task 3", "", "pending", createdAt, nil, "", nil, 0, "", "F", 0, nil).
+					AddRow("1", "Task 1", "", "pending", createdAt, nil, "", nil, 0, "", "", 0, nil).
+					AddRow("5", "Task 5", "", "blocked", createdAt, nil, "", nil, 0, "", "", 0, nil))
+
+			board, err := manager.Board(ctx)
+			Expect(err).To(Succeed())
+			Expect(board.Columns).To(Equal([]models.BoardColumn{
+				{Status: "pending", Tasks: []models.Task{
+					{ID: "3", Title: "Task 3", Status: "pending", CreatedAt: createdAt, Rank: "F"},
+					{ID: "1", Title: "Task 1", Status: "pending", CreatedAt: createdAt},
+				}},
+				{Status: "blocked", Tasks: []models.Task{{ID: "5", Title: "Task 5", Status: "blocked", CreatedAt: createdAt}}},
+				{Status: "in_progress", Tasks: []models.Task{{ID: "2", Title: "Task 2", Status: "in_progress", CreatedAt: createdAt, Rank: "V"}}},
+				{Status: "done", Tasks: []models.Task{{ID: "4", Title: "Task 4", Status: "done", CreatedAt: createdAt}}},
+			}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("always has the pending and done columns", func() {
+			mockSQL.ExpectQuery(`SELECT`).WillReturnRows(sqlmock.NewRows(columns))
+
+			board, err := manager.Board(ctx)
+			Expect(err).To(Succeed())
+			Expect(board.Columns).To(Equal([]models.BoardColumn{
+				{Status: "pending", Tasks: []models.Task{}},
+				{Status: "done", Tasks: []models.Task{}},
+			}))
+		})
+
+		It("returns an error when the query fails", func() {
+			mockSQL.ExpectQuery(`SELECT`).WillReturnError(errMock)
+
+			_, err := manager.Board(ctx)
+			Expect(err).To(MatchError(errMock))
+		})
+	})
+
+	Describe("Move", func() {
+		expectTask := func(status, rank string) {
+			mockSQL.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks WHERE id = \?`).WithArgs("1").
+				WillReturnRows(sqlmock.NewRows(columns).AddRow("1", "Task 1", "", status, createdAt, nil, "", nil, 0, "", rank, 0, nil))
+		}
+
+		expectColumn := func(status string, ranks ...string) {
+			rows := sqlmock.NewRows([]string{"id", "rank"})
+			for i := 0; i < len(ranks); i += 2 {
+				rows.AddRow(ranks[i], ranks[i+1])
+			}
+			mockSQL.ExpectQuery(columnQuery).WithArgs(status, "1").WillReturnRows(rows)
+		}
+
+		expectRecorded := func(eventTypes ...string) {
+			mockSQL.ExpectQuery(`SELECT user_id FROM task_watchers WHERE task_id = \?`).WithArgs("1").
+				WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+			for _, eventType := range eventTypes {
+				mockSQL.ExpectExec("INSERT INTO outbox").
+					WithArgs(eventType, "1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+		}
+
+		It("ranks the task between its neighbors", func() {
+			mockSQL.ExpectBegin()
+			expectTask("pending", "")
+			expectColumn("done", "2", "F", "3", "V")
+			mockSQL.ExpectExec(moveQuery).WithArgs("done", "N", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+			expectRecorded(models.EventTaskUpdated, models.EventTaskStatusChanged)
+			mockSQL.ExpectCommit()
+
+			task, err := manager.Move(ctx, "1", models.TaskMove{Status: "done", After: "2", Before: "3"})
+			Expect(err).To(Succeed())
+			Expect(task).To(Equal(&models.Task{ID: "1", Title: "Task 1", Status: "done", CreatedAt: createdAt, Rank: "N"}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("ranks the task at the top of its column", func() {
+			mockSQL.ExpectBegin()
+			expectTask("pending", "V")
+			expectColumn("pending", "2", "V")
+			mockSQL.ExpectExec(moveQuery).WithArgs("pending", "F", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+			expectRecorded(models.EventTaskUpdated)
+			mockSQL.ExpectCommit()
+
+			task, err := manager.Move(ctx, "1", models.TaskMove{Status: "pending", Before: "2"})
+			Expect(err).To(Succeed())
+			Expect(task.Rank).To(Equal("F"))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("spreads the column when the task goes after a task never moved", func() {
+			mockSQL.ExpectBegin()
+			expectTask("pending", "")
+			expectColumn("done", "2", "", "3", "")
+			mockSQL.ExpectExec(`UPDATE tasks SET rank = \? WHERE id = \?`).WithArgs("FV", "2").WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec(`UPDATE tasks SET rank = \? WHERE id = \?`).WithArgs("kV", "3").WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec(moveQuery).WithArgs("done", "V", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+			expectRecorded(models.EventTaskUpdated, models.EventTaskStatusChanged)
+			mockSQL.ExpectCommit()
+
+			task, err := manager.Move(ctx, "1", models.TaskMove{Status: "done", After: "2", Before: "3"})
+			Expect(err).To(Succeed())
+			Expect(task.Rank).To(Equal("V"))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("spreads the column when the rank gets too long", func() {
+			long := "FV" + strings.Repeat("0", 29) + "1"
+			mockSQL.ExpectBegin()
+			expectTask("done", "")
+			expectColumn("done", "2", "FV", "3", long)
+			mockSQL.ExpectExec(`UPDATE tasks SET rank = \? WHERE id = \?`).WithArgs("kV", "3").WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec(moveQuery).WithArgs("done", "V", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+			expectRecorded(models.EventTaskUpdated)
+			mockSQL.ExpectCommit()
+
+			task, err := manager.Move(ctx, "1", models.TaskMove{Status: "done", After: "2", Before: "3"})
+			Expect(err).To(Succeed())
+			Expect(task.Rank).To(Equal("V"))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		DescribeTable("refuses neighbors that aren't next to each other",
+			func(move models.TaskMove) {
+				mockSQL.ExpectBegin()
+				expectTask("pending", "")
+				expectColumn("done", "2", "F", "3", "V")
+				mockSQL.ExpectRollback()
+
+				_, err := manager.Move(ctx, "1", move)
+				Expect(err).To(MatchError(ErrInvalidMove))
+				Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+			},
+			Entry("missing after", models.TaskMove{Status: "done", After: "9", Before: "3"}),
+			Entry("apart", models.TaskMove{Status: "done", Before: "3"}),
+			Entry("not at the bottom", models.TaskMove{Status: "done", After: "2"}),
+			Entry("swapped", models.TaskMove{Status: "done", After: "3", Before: "2"}),
+			Entry("empty column expected", models.TaskMove{Status: "done"}),
+		)
+
+		It("returns ErrNotFound for a missing task", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT`).WithArgs("1").WillReturnRows(sqlmock.NewRows(columns))
+			mockSQL.ExpectRollback()
+
+			_, err := manager.Move(ctx, "1", models.TaskMove{Status: "done"})
+			Expect(err).To(MatchError(ErrNotFound))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns an error when the update fails", func() {
+			mockSQL.ExpectBegin()
+			expectTask("pending", "")
+			expectColumn("done")
+			mockSQL.ExpectExec(moveQuery).WithArgs("done", "V", "1").WillReturnError(errMock)
+			mockSQL.ExpectRollback()
+
+			_, err := manager.Move(ctx, "1", models.TaskMove{Status: "done"})
+			Expect(err).To(MatchError(errMock))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Describe("with SQLite", func() {
+		BeforeEach(func() {
+			manager = &TaskManager{DB: newSQLiteDB()}
+		})
+
+		It("keeps the order the tasks were dropped in", func() {
+			for _, title := range []string{"Task 1", "Task 2", "Task 3", "Task 4"} {
+				Expect(manager.Create(ctx, &models.Task{Title: title, Status: models.StatusPending})).To(Succeed())
+			}
+
+			_, err := manager.Move(ctx, "3", models.TaskMove{Status: "in_progress"})
+			Expect(err).To(Succeed())
+			_, err = manager.Move(ctx, "1", models.TaskMove{Status: "in_progress", After: "3"})
+			Expect(err).To(Succeed())
+			_, err = manager.Move(ctx, "2", models.TaskMove{Status: "in_progress", Before: "3"})
+			Expect(err).To(Succeed())
+			_, err = manager.Move(ctx, "4", models.TaskMove{Status: models.StatusPending})
+			Expect(err).To(Succeed())
+			_, err = manager.Move(ctx, "1", models.TaskMove{Status: "in_progress", After: "2"})
+			Expect(err).To(MatchError(ErrInvalidMove))
+
+			board, err := manager.Board(ctx)
+			Expect(err).To(Succeed())
+			ids := func(column models.BoardColumn) []string {
+				var ids []string
+				for _, task := range column.Tasks {
+					ids = append(ids, task.ID)
+				}
+				return ids
+			}
+			Expect(board.Columns).To(HaveLen(3))
+			Expect(board.Columns[0].Status).To(Equal(models.StatusPending))
+			Expect(ids(board.Columns[0])).To(Equal([]string{"4"}))
+			Expect(board.Columns[1].Status).To(Equal("in_progress"))
+			Expect(ids(board.Columns[1])).To(Equal([]string{"2", "3", "1"}))
+			Expect(board.Columns[2].Status).To(Equal(models.StatusDone))
+			Expect(board.Columns[2].Tasks).To(BeEmpty())
+		})
+
+		It("spreads the ranks of a column when a task is dropped after tasks never moved", func() {
+			for _, title := range []string{"Task 1", "Task 2", "Task 3"} {
+				Expect(manager.Create(ctx, &models.Task{Title: title, Status: models.StatusPending})).To(Succeed())
+			}
+
+			_, err := manager.Move(ctx, "3", models.TaskMove{Status: models.StatusPending, After: "1", Before: "2"})
+			Expect(err).To(Succeed())
+
+			board, err := manager.Board(ctx)
+			Expect(err).To(Succeed())
+			var ids, ranks []string
+			for _, task := range board.Columns[0].Tasks {
+				ids, ranks = append(ids, task.ID), append(ranks, task.Rank)
+			}
+			Expect(ids).To(Equal([]string{"1", "3", "2"}))
+			Expect(ranks).To(HaveEach(Not(BeEmpty())))
+		})
+	})
+})
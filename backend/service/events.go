@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"github.com/saarzur123/task-management/backend/models"
+)
+
+type EventPublisher interface {
+	Publish(event models.TaskEvent) models.TaskEvent
+}
+
+// PublishingRepository decorates a TaskRepository and publishes an event for
+// every successful mutation.
+type PublishingRepository struct {
+	TaskRepository
+	Events EventPublisher
+}
+
+func NewPublishingRepository(repository TaskRepository, events EventPublisher) *PublishingRepository {
+	return &PublishingRepository{TaskRepository: repository, Events: events}
+}
+
+func (p *PublishingRepository) Create(ctx context.Context, task *models.Task) error {
+	if err := p.TaskRepository.Create(ctx, task); err != nil {
+		return err
+	}
+	created := *task
+	p.Events.Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: task.ID, Task: &created})
+	return nil
+}
+
+func (p *PublishingRepository) Update(ctx context.Context, task *models.Task) error {
+	if err := p.TaskRepository.Update(ctx, task); err != nil {
+		return err
+	}
+	updated := *task
+	p.Events.Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: task.ID, Task: &updated})
+	return nil
+}
+
+func (p *PublishingRepository) Delete(ctx context.Context, id string) error {
+	if err := p.TaskRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	p.Events.Publish(models.TaskEvent{Type: models.EventTaskDeleted, TaskID: id})
+	return nil
+}
+
+// PublishingBoard decorates a TaskBoard and publishes task.updated for every
+// task moved, so the boards of the other clients follow.
+type PublishingBoard struct {
+	TaskBoard
+	Events EventPublisher
+}
+
+func (p *PublishingBoard) Move(ctx context.Context, id string, move models.TaskMove) (*models.Task, error) {
+	task, err := p.TaskBoard.Move(ctx, id, move)
+	if err != nil {
+		return nil, err
+	}
+	moved := *task
+	p.Events.Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: task.ID, Task: &moved})
+	return task, nil
+}
+
+// PublishingSprints decorates a SprintPlanner and publishes task.updated for
+// every task carried over to another sprint.
+type PublishingSprints struct {
+	SprintPlanner
+	Events EventPublisher
+}
+
+func (p *PublishingSprints) CarryOver(ctx context.Context, fromID, toID string) (*models.CarryOver, error) {
+	carryOver, err := p.SprintPlanner.CarryOver(ctx, fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range carryOver.Tasks {
+		moved := task
+		p.Events.Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: task.ID, Task: &moved})
+	}
+	return carryOver, nil
+}
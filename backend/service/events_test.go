@@ -0,0 +1,123 @@
+package service
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+)
+
+var _ = Describe("PublishingRepository", func() {
+	var (
+		mockRepository *serviceMock.MockTaskRepository
+		mockEvents     *serviceMock.MockEventPublisher
+		repository     *PublishingRepository
+		task           = models.Task{ID: "1", Title: "Task 1", Status: "pending"}
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		mockRepository = serviceMock.NewMockTaskRepository(controller)
+		mockEvents = serviceMock.NewMockEventPublisher(controller)
+		repository = NewPublishingRepository(mockRepository, mockEvents)
+	})
+
+	It("publishes created tasks", func() {
+		mockRepository.EXPECT().Create(ctx, &task).Return(nil)
+		mockEvents.EXPECT().Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: "1", Task: &task})
+
+		Expect(repository.Create(ctx, &task)).To(Succeed())
+	})
+
+	It("publishes updated tasks", func() {
+		mockRepository.EXPECT().Update(ctx, &task).Return(nil)
+		mockEvents.EXPECT().Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: "1", Task: &task})
+
+		Expect(repository.Update(ctx, &task)).To(Succeed())
+	})
+
+	It("publishes deleted task IDs", func() {
+		mockRepository.EXPECT().Delete(ctx, "1").Return(nil)
+		mockEvents.EXPECT().Publish(models.TaskEvent{Type: models.EventTaskDeleted, TaskID: "1"})
+
+		Expect(repository.Delete(ctx, "1")).To(Succeed())
+	})
+
+	It("doesn't publish failed changes", func() {
+		mockRepository.EXPECT().Delete(ctx, "1").Return(ErrNotFound)
+
+		Expect(repository.Delete(ctx, "1")).To(MatchError(ErrNotFound))
+	})
+
+	It("reads through to the wrapped repository", func() {
+		mockRepository.EXPECT().GetByID(ctx, "1").Return(&task, nil)
+
+		Expect(repository.GetByID(ctx, "1")).To(Equal(&task))
+	})
+})
+
+var _ = Describe("PublishingBoard", func() {
+	var (
+		mockBoard  *serviceMock.MockTaskBoard
+		mockEvents *serviceMock.MockEventPublisher
+		board      *PublishingBoard
+		move       = models.TaskMove{Status: "done", After: "2"}
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		mockBoard = serviceMock.NewMockTaskBoard(controller)
+		mockEvents = serviceMock.NewMockEventPublisher(controller)
+		board = &PublishingBoard{TaskBoard: mockBoard, Events: mockEvents}
+	})
+
+	It("publishes moved tasks", func() {
+		task := &models.Task{ID: "1", Title: "Task 1", Status: "done", Rank: "V"}
+		mockBoard.EXPECT().Move(ctx, "1", move).Return(task, nil)
+		mockEvents.EXPECT().Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: "1", Task: task})
+
+		Expect(board.Move(ctx, "1", move)).To(Equal(task))
+	})
+
+	It("doesn't publish failed moves", func() {
+		mockBoard.EXPECT().Move(ctx, "1", move).Return(nil, ErrInvalidMove)
+
+		_, err := board.Move(ctx, "1", move)
+		Expect(err).To(MatchError(ErrInvalidMove))
+	})
+})
+
+var _ = Describe("PublishingSprints", func() {
+	var (
+		mockSprints *serviceMock.MockSprintPlanner
+		mockEvents  *serviceMock.MockEventPublisher
+		sprints     *PublishingSprints
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		mockSprints = serviceMock.NewMockSprintPlanner(controller)
+		mockEvents = serviceMock.NewMockEventPublisher(controller)
+		sprints = &PublishingSprints{SprintPlanner: mockSprints, Events: mockEvents}
+	})
+
+	It("publishes the tasks carried over", func() {
+		carryOver := &models.CarryOver{From: "1", To: "2", Points: 3, Tasks: []models.Task{
+			{ID: "4", Title: "Task 4", Status: "pending", StoryPoints: 1, SprintID: "2"},
+			{ID: "6", Title: "Task 6", Status: "pending", StoryPoints: 2, SprintID: "2"},
+		}}
+		mockSprints.EXPECT().CarryOver(ctx, "1", "").Return(carryOver, nil)
+		mockEvents.EXPECT().Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: "4", Task: &carryOver.Tasks[0]})
+		mockEvents.EXPECT().Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: "6", Task: &carryOver.Tasks[1]})
+
+		Expect(sprints.CarryOver(ctx, "1", "")).To(Equal(carryOver))
+	})
+
+	It("doesn't publish failed carry-overs", func() {
+		mockSprints.EXPECT().CarryOver(ctx, "1", "").Return(nil, ErrNoNextSprint)
+
+		_, err := sprints.CarryOver(ctx, "1", "")
+		Expect(err).To(MatchError(ErrNoNextSprint))
+	})
+})
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saarzur123/task-management/backend/clock"
+	"github.com/saarzur123/task-management/backend/flow"
+	"github.com/saarzur123/task-management/backend/models"
+	"time"
+)
+
+// FlowReporter reports how the tasks flow through their statuses, replaying
+// their history from the outbox.
+type FlowReporter interface {
+	// Burndown follows the sprint up to today from the history of the tasks
+	// that were in it at some point.
+	Burndown(ctx context.Context, sprintID string) (*models.Burndown, error)
+	// CumulativeFlow counts the tasks per status from the day of from to the
+	// day of to.
+	CumulativeFlow(ctx context.Context, from, to time.Time) (*models.CumulativeFlow, error)
+}
+
+type FlowReportManager struct {
+	DB *sql.DB
+	// Clock tells the day of today, the real clock when nil.
+	Clock clock.Clock
+}
+
+// historyQuery selects the events before a time. The task.status_changed
+// events repeat the task.updated events before them, so they are left out.
+const historyQuery = `SELECT id, event_type, task_id, payload, previous_status, watchers, created_at FROM outbox ` +
+	`WHERE event_type IN (?, ?, ?) AND created_at < ?`
+
+func (m *FlowReportManager) Burndown(ctx context.Context, sprintID string) (*models.Burndown, error) {
+	sprint, err := scanSprint(m.DB.QueryRowContext(ctx, `SELECT `+sprintColumns+` FROM sprints WHERE id = ?`, sprintID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	start, err := time.Parse(time.DateOnly, sprint.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse(time.DateOnly, sprint.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	end = end.AddDate(0, 0, 1)
+
+	query := historyQuery + ` AND task_id IN (SELECT task_id FROM outbox WHERE json_extract(payload, '$.sprint_id') = ?) ORDER BY id`
+	histories, err := m.histories(ctx, query, end, sprint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	burndown := &models.Burndown{SprintID: sprint.ID, Times: flow.Times(histories, start, end)}
+	if burndown.Days, err = flow.Burndown(histories, sprint, m.now()); err != nil {
+		return nil, err
+	}
+	return burndown, nil
+}
+
+func (m *FlowReportManager) CumulativeFlow(ctx context.Context, from, to time.Time) (*models.CumulativeFlow, error) {
+	from, to = from.UTC().Truncate(24*time.Hour), to.UTC().Truncate(24*time.Hour)
+	end := to.AddDate(0, 0, 1)
+
+	histories, err := m.histories(ctx, historyQuery+` ORDER BY id`, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CumulativeFlow{From: from.Format(time.DateOnly), To: to.Format(time.DateOnly), Times: flow.Times(histories, from, end)}
+	report.Statuses, report.Days = flow.CumulativeFlow(histories, from, to)
+	return report, nil
+}
+
+// histories replays the events of query, a historyQuery of the events
+// before end followed by the conditions taking args.
+func (m *FlowReportManager) histories(ctx context.Context, query string, end time.Time, args ...any) ([]flow.History, error) {
+	args = append([]any{models.EventTaskCreated, models.EventTaskUpdated, models.EventTaskDeleted, end.UTC()}, args...)
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+	return flow.Histories(events), nil
+}
+
+func (m *FlowReportManager) now() time.Time {
+	if m.Clock == nil {
+		return time.Now()
+	}
+	return m.Clock.Now()
+}
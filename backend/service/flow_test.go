@@ -0,0 +1,178 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/clock"
+	"github.com/saarzur123/task-management/backend/models"
+	"time"
+)
+
+var _ = Describe("FlowReportManager", func() {
+	var (
+		manager    *FlowReportManager
+		database   *sql.DB
+		mockSQL    sqlmock.Sqlmock
+		sprintRows = []string{"id", "name", "goal", "start_date", "end_date", "capacity"}
+		columns    = []string{"id", "event_type", "task_id", "payload", "previous_status", "watchers", "created_at"}
+		err        error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &FlowReportManager{DB: database, Clock: clock.NewFake(time.Date(2026, time.January, 6, 12, 0, 0, 0, time.UTC))}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, time.January, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	Describe("Burndown", func() {
+		It("replays the history of the tasks that were in the sprint up to today", func() {
+			mockSQL.ExpectQuery(`SELECT id, name, goal, start_date, end_date, capacity FROM sprints WHERE id = \?`).WithArgs("1").
+				WillReturnRows(sqlmock.NewRows(sprintRows).AddRow("1", "Sprint 1", "", "2026-01-05", "2026-01-07", 10))
+			mockSQL.ExpectQuery(`SELECT id, event_type, task_id, payload, previous_status, watchers, created_at FROM outbox `+
+				`WHERE event_type IN \(\?, \?, \?\) AND created_at < \? `+
+				`AND task_id IN \(SELECT task_id FROM outbox WHERE json_extract\(payload, '\$.sprint_id'\) = \?\) ORDER BY id`).
+				WithArgs(models.EventTaskCreated, models.EventTaskUpdated, models.EventTaskDeleted, at(8, 0), "1").
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, models.EventTaskCreated, "1", `{"id":"1","status":"pending","story_points":5,"sprint_id":"1"}`, nil, nil, at(4, 9)).
+					AddRow(2, models.EventTaskCreated, "2", `{"id":"2","status":"pending","story_points":3}`, nil, nil, at(4, 10)).
+					AddRow(3, models.EventTaskUpdated, "2", `{"id":"2","status":"pending","story_points":3,"sprint_id":"1"}`, nil, nil, at(5, 9)).
+					AddRow(4, models.EventTaskUpdated, "1", `{"id":"1","status":"done","story_points":5,"sprint_id":"1"}`, nil, nil, at(6, 9)))
+
+			burndown, err := manager.Burndown(ctx, "1")
+			Expect(err).To(Succeed())
+			Expect(burndown.SprintID).To(Equal("1"))
+			Expect(burndown.Days).To(Equal([]models.BurndownDay{
+				{Day: "2026-01-05", Committed: 8, Remaining: 8, RemainingTasks: 2, Ideal: 8},
+				{Day: "2026-01-06", Committed: 8, Remaining: 3, RemainingTasks: 1, Ideal: 4},
+			}))
+			Expect(burndown.Times.LeadTime).To(Equal(models.Percentiles{Count: 1, P50: 48 * 3600, P85: 48 * 3600, P95: 48 * 3600}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrNotFound for a missing sprint", func() {
+			mockSQL.ExpectQuery("SELECT (.+) FROM sprints").WithArgs("9").WillReturnRows(sqlmock.NewRows(sprintRows))
+
+			_, err := manager.Burndown(ctx, "9")
+			Expect(err).To(MatchError(ErrNotFound))
+		})
+
+		It("returns the errors of the outbox", func() {
+			mockSQL.ExpectQuery("SELECT (.+) FROM sprints").
+				WillReturnRows(sqlmock.NewRows(sprintRows).AddRow("1", "Sprint 1", "", "2026-01-05", "2026-01-07", 10))
+			mockSQL.ExpectQuery("SELECT (.+) FROM outbox").WillReturnError(errMock)
+
+			_, err := manager.Burndown(ctx, "1")
+			Expect(err).To(MatchError(errMock))
+		})
+	})
+
+	Describe("CumulativeFlow", func() {
+		It("counts the tasks per status each day, from the events before the end of the last", func() {
+			mockSQL.ExpectQuery(`SELECT id, event_type, task_id, payload, previous_status, watchers, created_at FROM outbox `+
+				`WHERE event_type IN \(\?, \?, \?\) AND created_at < \? ORDER BY id`).
+				WithArgs(models.EventTaskCreated, models.EventTaskUpdated, models.EventTaskDeleted, at(7, 0)).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, models.EventTaskCreated, "1", `{"id":"1","status":"pending"}`, nil, nil, at(4, 9)).
+					AddRow(2, models.EventTaskUpdated, "1", `{"id":"1","status":"done"}`, nil, nil, at(5, 9)).
+					AddRow(3, models.EventTaskCreated, "2", `{"id":"2","status":"pending"}`, nil, nil, at(5, 10)).
+					AddRow(4, models.EventTaskDeleted, "2", nil, nil, nil, at(6, 10)))
+
+			report, err := manager.CumulativeFlow(ctx, at(5, 8), at(6, 20))
+			Expect(err).To(Succeed())
+			Expect(report.From).To(Equal("2026-01-05"))
+			Expect(report.To).To(Equal("2026-01-06"))
+			Expect(report.Statuses).To(Equal([]string{"pending", "done"}))
+			Expect(report.Days).To(Equal([]models.FlowDay{
+				{Day: "2026-01-05", Counts: map[string]int{"pending": 1, "done": 1}},
+				{Day: "2026-01-06", Counts: map[string]int{"pending": 0, "done": 1}},
+			}))
+			Expect(report.Times.Transitions).To(Equal([]models.TransitionTimes{
+				{From: "pending", To: "done", Percentiles: models.Percentiles{Count: 1, P50: 24 * 3600, P85: 24 * 3600, P95: 24 * 3600}},
+			}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns the errors of the outbox", func() {
+			mockSQL.ExpectQuery("SELECT (.+) FROM outbox").WillReturnError(errMock)
+
+			_, err := manager.CumulativeFlow(ctx, at(5, 0), at(6, 0))
+			Expect(err).To(MatchError(errMock))
+		})
+	})
+
+	Describe("with SQLite", func() {
+		BeforeEach(func() {
+			database := newSQLiteDB()
+			manager.DB = database
+			tasks := &TaskManager{DB: database}
+
+			sprint := models.Sprint{Name: "Sprint 1", StartDate: "2026-01-05", EndDate: "2026-01-07", Capacity: 10}
+			Expect((&SprintManager{DB: database}).CreateSprint(ctx, &sprint)).To(Succeed())
+			a := models.Task{Title: "A", Status: models.StatusPending, StoryPoints: 5, SprintID: sprint.ID}
+			b := models.Task{Title: "B", Status: models.StatusPending, StoryPoints: 3}
+			c := models.Task{Title: "C", Status: models.StatusPending, StoryPoints: 1}
+			Expect(tasks.Create(ctx, &a)).To(Succeed())
+			Expect(tasks.Create(ctx, &b)).To(Succeed())
+			b.SprintID = sprint.ID
+			Expect(tasks.Update(ctx, &b)).To(Succeed())
+			Expect(tasks.Create(ctx, &c)).To(Succeed())
+			a.Status = models.StatusDone
+			Expect(tasks.Update(ctx, &a)).To(Succeed())
+			Expect(tasks.Delete(ctx, c.ID)).To(Succeed())
+			b.Status = "in_progress"
+			Expect(tasks.Update(ctx, &b)).To(Succeed())
+
+			// The outbox records when the events were written, move them to
+			// the days of the reports.
+			times := []time.Time{
+				at(4, 9), at(4, 10), // A and B created
+				at(5, 9),           // B planned in the sprint
+				at(5, 10),          // C created
+				at(6, 9), at(6, 9), // A done, updated and status changed
+				at(6, 10),          // C deleted
+				at(8, 9), at(8, 9), // B started after the sprint
+			}
+			var count int
+			Expect(database.QueryRowContext(ctx, `SELECT COUNT(*) FROM outbox`).Scan(&count)).To(Succeed())
+			Expect(count).To(Equal(len(times)))
+			for i, t := range times {
+				_, err := database.ExecContext(ctx, `UPDATE outbox SET created_at = ? WHERE id = ?`, t, i+1)
+				Expect(err).To(Succeed())
+			}
+		})
+
+		It("follows the burndown of the tasks that were in the sprint", func() {
+			burndown, err := manager.Burndown(ctx, "1")
+			Expect(err).To(Succeed())
+			Expect(burndown.Days).To(Equal([]models.BurndownDay{
+				{Day: "2026-01-05", Committed: 8, Remaining: 8, RemainingTasks: 2, Ideal: 8},
+				{Day: "2026-01-06", Committed: 8, Remaining: 3, RemainingTasks: 1, Ideal: 4},
+			}))
+			Expect(burndown.Times.LeadTime).To(Equal(models.Percentiles{Count: 1, P50: 48 * 3600, P85: 48 * 3600, P95: 48 * 3600}))
+		})
+
+		It("counts the tasks per status at the end of each day", func() {
+			report, err := manager.CumulativeFlow(ctx, at(4, 0), at(6, 0))
+			Expect(err).To(Succeed())
+			Expect(report.Statuses).To(Equal([]string{"pending", "done"}))
+			Expect(report.Days).To(Equal([]models.FlowDay{
+				{Day: "2026-01-04", Counts: map[string]int{"pending": 2, "done": 0}},
+				{Day: "2026-01-05", Counts: map[string]int{"pending": 3, "done": 0}},
+				{Day: "2026-01-06", Counts: map[string]int{"pending": 1, "done": 1}},
+			}))
+			Expect(report.Times.Transitions).To(Equal([]models.TransitionTimes{
+				{From: "pending", To: "done", Percentiles: models.Percentiles{Count: 1, P50: 48 * 3600, P85: 48 * 3600, P95: 48 * 3600}},
+			}))
+		})
+	})
+})
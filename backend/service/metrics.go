@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"github.com/saarzur123/task-management/backend/metrics"
+)
+
+// CountByStatus returns the number of tasks in each status.
+func (m *TaskManager) CountByStatus(ctx context.Context) (_ map[string]int, err error) {
+	query := `SELECT status, COUNT(*) FROM tasks GROUP BY status`
+	ctx, end := m.observe(ctx, "TaskManager.CountByStatus", query)
+	defer func() { end(err) }()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var (
+			status string
+			count  int
+		)
+		if err = rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// RegisterMetrics exposes the connection pool statistics of m.DB and the
+// number of tasks per status, both read on every scrape.
+func RegisterMetrics(registry *metrics.Registry, m *TaskManager) {
+	poolGauge := func(value func() float64) func() ([]metrics.Sample, error) {
+		return func() ([]metrics.Sample, error) {
+			return []metrics.Sample{{Value: value()}}, nil
+		}
+	}
+
+	registry.NewGaugeFunc("db_open_connections", "Number of established database connections.", nil,
+		poolGauge(func() float64 { return float64(m.DB.Stats().OpenConnections) }))
+	registry.NewGaugeFunc("db_in_use_connections", "Number of database connections currently in use.", nil,
+		poolGauge(func() float64 { return float64(m.DB.Stats().InUse) }))
+	registry.NewGaugeFunc("db_idle_connections", "Number of idle database connections.", nil,
+		poolGauge(func() float64 { return float64(m.DB.Stats().Idle) }))
+	registry.NewCounterFunc("db_wait_count_total", "Total number of connections waited for.", nil,
+		poolGauge(func() float64 { return float64(m.DB.Stats().WaitCount) }))
+	registry.NewCounterFunc("db_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", nil,
+		poolGauge(func() float64 { return m.DB.Stats().WaitDuration.Seconds() }))
+
+	registry.NewGaugeFunc("tasks", "Number of tasks per status.", []string{"status"}, func() ([]metrics.Sample, error) {
+		counts, err := m.CountByStatus(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		samples := make([]metrics.Sample, 0, len(counts))
+		for status, count := range counts {
+			samples = append(samples, metrics.Sample{LabelValues: []string{status}, Value: float64(count)})
+		}
+		return samples, nil
+	})
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/metrics"
+	"strings"
+)
+
+var _ = Describe("TaskManager metrics", func() {
+	var (
+		manager  *TaskManager
+		database *sql.DB
+		mockSQL  sqlmock.Sqlmock
+		err      error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &TaskManager{DB: database}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	It("counts tasks per status with an aggregate query", func() {
+		mockSQL.ExpectQuery(`SELECT status, COUNT\(\*\) FROM tasks GROUP BY status`).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).AddRow("pending", 3).AddRow("done", 1))
+
+		counts, err := manager.CountByStatus(ctx)
+		Expect(err).To(Succeed())
+		Expect(counts).To(Equal(map[string]int{"pending": 3, "done": 1}))
+	})
+
+	It("exposes pool stats and tasks per status", func() {
+		registry := metrics.NewRegistry()
+		RegisterMetrics(registry, manager)
+		mockSQL.ExpectQuery("SELECT status, COUNT").
+			WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).AddRow("pending", 3))
+
+		var output strings.Builder
+		Expect(registry.Write(ctx, &output)).To(Succeed())
+		Expect(output.String()).To(ContainSubstring("db_open_connections "))
+		Expect(output.String()).To(ContainSubstring("db_wait_count_total 0"))
+		Expect(output.String()).To(ContainSubstring(`tasks{status="pending"} 3`))
+	})
+})
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type ReadinessChecker interface {
+	Ready(ctx context.Context) error
+}
+
+var (
+	ErrMigrationsPending = errors.New("MigrationsPending")
+)
+
+// migrations are applied in order, migration i having version i+1. Existing
+// entries must never change, append new ones instead.
+var migrations = []string{
+	"CREATE TABLE IF NOT EXISTS tasks (" +
+		"id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT," +
+		"title TEXT NOT NULL," +
+		"description TEXT NOT NULL," +
+		"status TEXT NOT NULL," +
+		"created_at TIMESTAMP NOT NULL);",
+	"CREATE TABLE IF NOT EXISTS api_keys (" +
+		"id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT," +
+		"name TEXT NOT NULL," +
+		"prefix TEXT NOT NULL," +
+		"key_hash TEXT NOT NULL UNIQUE," +
+		"scopes TEXT NOT NULL," +
+		"created_at TIMESTAMP NOT NULL," +
+		"last_used_at TIMESTAMP," +
+		"revoked_at TIMESTAMP);",
+	"CREATE TABLE IF NOT EXISTS audit_log (" +
+		"id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT," +
+		"actor TEXT NOT NULL," +
+		"action TEXT NOT NULL," +
+		"resource TEXT NOT NULL," +
+		"created_at TIMESTAMP NOT NULL);",
+	"CREATE TABLE IF NOT EXISTS outbox (" +
+		"id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT," +
+		"event_type TEXT NOT NULL," +
+		"task_id TEXT NOT NULL," +
+		"payload TEXT," +
+		"created_at TIMESTAMP NOT NULL);",
+	"CREATE TABLE IF NOT EXISTS outbox_cursors (" +
+		"name TEXT NOT NULL PRIMARY KEY," +
+		"position INTEGER NOT NULL);",
+	"CREATE TABLE IF NOT EXISTS webhooks (" +
+		"id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT," +
+		"url TEXT NOT NULL," +
+		"event_types TEXT NOT NULL," +
+		"secret TEXT NOT NULL," +
+		"enabled BOOLEAN NOT NULL," +
+		"consecutive_failures INTEGER NOT NULL DEFAULT 0," +
+		"created_at TIMESTAMP NOT NULL," +
+		"disabled_at TIMESTAMP);",
+	"CREATE TABLE IF NOT EXISTS webhook_deliveries (" +
+		"id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT," +
+		"webhook_id INTEGER NOT NULL," +
+		"event_id INTEGER NOT NULL," +
+		"event_type TEXT NOT NULL," +
+		"payload TEXT NOT NULL," +
+		"status TEXT NOT NULL," +
+		"attempts INTEGER NOT NULL DEFAULT 0," +
+		"last_status_code INTEGER," +
+		"last_error TEXT," +
+		"created_at TIMESTAMP NOT NULL," +
+		"next_attempt_at TIMESTAMP NOT NULL," +
+		"delivered_at TIMESTAMP);",
+	"CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);",
+	"ALTER TABLE outbox ADD COLUMN previous_status TEXT;",
+	"CREATE INDEX IF NOT EXISTS outbox_task_id ON outbox (task_id, id);",
+	"ALTER TABLE tasks ADD COLUMN due_at TIMESTAMP;",
+	"ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';",
+	"ALTER TABLE tasks ADD COLUMN series_id INTEGER;",
+	"ALTER TABLE tasks ADD COLUMN occurrence INTEGER NOT NULL DEFAULT 0;",
+	// recurred is set once the next occurrence of a recurring task exists.
+	"ALTER TABLE tasks ADD COLUMN recurred BOOLEAN NOT NULL DEFAULT 0;",
+	"CREATE UNIQUE INDEX IF NOT EXISTS tasks_series_occurrence ON tasks (series_id, occurrence);",
+	"ALTER TABLE tasks ADD COLUMN assignee TEXT NOT NULL DEFAULT '';",
+	"CREATE TABLE IF NOT EXISTS notification_preferences (" +
+		"user_id TEXT NOT NULL PRIMARY KEY," +
+		"channels TEXT NOT NULL," +
+		"email TEXT NOT NULL," +
+		"webhook_url TEXT NOT NULL," +
+		"quiet_start TEXT NOT NULL," +
+		"quiet_end TEXT NOT NULL," +
+		"time_zone TEXT NOT NULL);",
+	"CREATE TABLE IF NOT EXISTS reminders (" +
+		"id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT," +
+		"task_id INTEGER NOT NULL," +
+		"user_id TEXT NOT NULL," +
+		"kind TEXT NOT NULL," +
+		"channel TEXT NOT NULL," +
+		"due_at TIMESTAMP NOT NULL," +
+		"sent_at TIMESTAMP NOT NULL," +
+		"UNIQUE (task_id, user_id, kind, channel, due_at));",
+	"CREATE TABLE IF NOT EXISTS notifications (" +
+		"id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT," +
+		"user_id TEXT NOT NULL," +
+		"task_id TEXT NOT NULL," +
+		"kind TEXT NOT NULL," +
+		"message TEXT NOT NULL," +
+		"created_at TIMESTAMP NOT NULL," +
+		"read_at TIMESTAMP);",
+	"CREATE INDEX IF NOT EXISTS notifications_user_id ON notifications (user_id, id);",
+	"ALTER TABLE notifications ADD COLUMN actor TEXT NOT NULL DEFAULT '';",
+	"CREATE TABLE IF NOT EXISTS task_watchers (" +
+		"task_id INTEGER NOT NULL," +
+		"user_id TEXT NOT NULL," +
+		"created_at DATETIME NOT NULL," +
+		"PRIMARY KEY (task_id, user_id)" +
+		");",
+	"CREATE INDEX IF NOT EXISTS task_watchers_user_id ON task_watchers (user_id, task_id);",
+	"ALTER TABLE outbox ADD COLUMN watchers TEXT;",
+	"ALTER TABLE tasks ADD COLUMN rank TEXT NOT NULL DEFAULT '';",
+	"CREATE INDEX IF NOT EXISTS tasks_status_rank ON tasks (status, rank, id);",
+	"CREATE TABLE IF NOT EXISTS worklogs (" +
+		"id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT," +
+		"task_id INTEGER NOT NULL," +
+		"user_id TEXT NOT NULL," +
+		"started_at TIMESTAMP NOT NULL," +
+		"duration INTEGER NOT NULL," +
+		"note TEXT NOT NULL," +
+		"running INTEGER NOT NULL);",
+	"CREATE INDEX IF NOT EXISTS worklogs_task_id ON worklogs (task_id, started_at);",
+	"CREATE INDEX IF NOT EXISTS worklogs_started_at ON worklogs (started_at);",
+	"CREATE UNIQUE INDEX IF NOT EXISTS worklogs_running ON worklogs (user_id) WHERE running = 1;",
+	"CREATE TABLE IF NOT EXISTS sprints (" +
+		"id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT," +
+		"name TEXT NOT NULL," +
+		"goal TEXT NOT NULL," +
+		"start_date TEXT NOT NULL," +
+		"end_date TEXT NOT NULL," +
+		"capacity INTEGER NOT NULL);",
+	"ALTER TABLE tasks ADD COLUMN story_points INTEGER NOT NULL DEFAULT 0;",
+	"ALTER TABLE tasks ADD COLUMN sprint_id INTEGER;",
+	"CREATE INDEX IF NOT EXISTS tasks_sprint_id ON tasks (sprint_id, status);",
+	"ALTER TABLE tasks ADD COLUMN reminded_at TIMESTAMP;",
+	"CREATE INDEX IF NOT EXISTS tasks_reminders ON tasks (julianday(due_at)) WHERE reminded_at IS NULL;",
+	"ALTER TABLE reminders ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;",
+	"ALTER TABLE reminders ADD COLUMN next_attempt_at TIMESTAMP;",
+}
+
+// Migrate applies the migrations not yet recorded in schema_migrations, each
+// in its own transaction.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations ("+
+		"version INTEGER NOT NULL PRIMARY KEY,"+
+		"applied_at TIMESTAMP NOT NULL);")
+	if err != nil {
+		return err
+	}
+
+	current, err := schemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	for version := current + 1; version <= len(migrations); version++ {
+		if err = applyMigration(ctx, db, version); err != nil {
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, version int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	if _, err = tx.ExecContext(ctx, migrations[version-1]); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version sql.NullInt64
+	err := db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// Ready reports whether the database answers and has every migration applied.
+func (m *TaskManager) Ready(ctx context.Context) error {
+	if err := m.DB.PingContext(ctx); err != nil {
+		return err
+	}
+
+	version, err := schemaVersion(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	if version < len(migrations) {
+		return fmt.Errorf("%w: at version %d of %d", ErrMigrationsPending, version, len(migrations))
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("migrations", func() {
+	var (
+		database *sql.DB
+		mockSQL  sqlmock.Sqlmock
+		err      error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New(sqlmock.MonitorPingsOption(true))
+		Expect(err).To(Succeed())
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	expectVersion := func(version any) {
+		mockSQL.ExpectQuery(`SELECT MAX\(version\) FROM schema_migrations`).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(version))
+	}
+
+	Describe("Migrate", func() {
+		It("applies only the pending migrations, each in a transaction", func() {
+			mockSQL.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+			expectVersion(len(migrations) - 1)
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec(".+").WillReturnResult(sqlmock.NewResult(0, 0))
+			mockSQL.ExpectExec("INSERT INTO schema_migrations").
+				WithArgs(len(migrations), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectCommit()
+
+			Expect(Migrate(ctx, database)).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("rolls back and stops on a failing migration", func() {
+			mockSQL.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+			expectVersion(nil)
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("CREATE TABLE IF NOT EXISTS tasks").WillReturnError(errMock)
+			mockSQL.ExpectRollback()
+
+			err := Migrate(ctx, database)
+			Expect(err).To(MatchError(errMock))
+			Expect(err.Error()).To(ContainSubstring("migration 1"))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Describe("Ready", func() {
+		It("succeeds when the database answers and is up to date", func() {
+			mockSQL.ExpectPing()
+			expectVersion(len(migrations))
+
+			Expect((&TaskManager{DB: database}).Ready(ctx)).To(Succeed())
+		})
+
+		It("fails while migrations are pending", func() {
+			mockSQL.ExpectPing()
+			expectVersion(1)
+
+			Expect((&TaskManager{DB: database}).Ready(ctx)).To(MatchError(ErrMigrationsPending))
+		})
+
+		It("fails when the database doesn't answer", func() {
+			mockSQL.ExpectPing().WillReturnError(errMock)
+
+			Expect((&TaskManager{DB: database}).Ready(ctx)).To(MatchError(errMock))
+		})
+	})
+})
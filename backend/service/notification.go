@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/saarzur123/task-management/backend/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type NotificationPreferencesRepository interface {
+	// GetPreferences returns the preferences of the user, in-app
+	// notifications alone when none were saved.
+	GetPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error)
+	SavePreferences(ctx context.Context, prefs *models.NotificationPreferences) error
+}
+
+// ReminderStore is the storage of the due date reminders.
+type ReminderStore interface {
+	// DueTasks returns the open tasks with an assignee due at or before the
+	// given time and not marked reminded, the earliest due first.
+	DueTasks(ctx context.Context, before time.Time) ([]models.Task, error)
+	// MarkReminded records that every reminder of the task was sent for its
+	// current due date and assignee, so DueTasks skips it until they change.
+	MarkReminded(ctx context.Context, task *models.Task, at time.Time) error
+	// ClaimReminder records reminder as sent at the given time, reporting
+	// false when it was sent already or its retry isn't due. The failed
+	// attempts so far are set on reminder.
+	ClaimReminder(ctx context.Context, reminder *models.Reminder, at time.Time) (bool, error)
+	// FailReminder records a failed delivery of a claimed reminder, to be
+	// claimed again at retryAt, or never when retryAt is nil.
+	FailReminder(ctx context.Context, reminder *models.Reminder, retryAt *time.Time) error
+	// ReleaseReminder forgets a claimed reminder that couldn't be delivered,
+	// so it is sent again.
+	ReleaseReminder(ctx context.Context, reminder *models.Reminder) error
+}
+
+type NotificationStore interface {
+	AddNotification(ctx context.Context, notification *models.Notification) error
+}
+
+// NotificationInbox is the in-app inbox of the users.
+type NotificationInbox interface {
+	// Inbox returns up to limit notifications of the user older than the
+	// before ID, or the latest ones when before is empty, newest first.
+	Inbox(ctx context.Context, userID, before string, limit int, unreadOnly bool) (*models.Inbox, error)
+	// MarkRead marks a notification of the user as read, or returns
+	// ErrNotFound.
+	MarkRead(ctx context.Context, userID, id string) error
+	MarkAllRead(ctx context.Context, userID string) error
+}
+
+type NotificationManager struct {
+	DB *sql.DB
+}
+
+const channelsSeparator = ","
+
+func (m *NotificationManager) GetPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	prefs := models.NotificationPreferences{UserID: userID}
+	var channels string
+	query := `SELECT channels, email, webhook_url, quiet_start, quiet_end, time_zone FROM notification_preferences WHERE user_id = ?`
+	err := m.DB.QueryRowContext(ctx, query, userID).
+		Scan(&channels, &prefs.Email, &prefs.WebhookURL, &prefs.QuietStart, &prefs.QuietEnd, &prefs.TimeZone)
+	if errors.Is(err, sql.ErrNoRows) {
+		prefs.Channels = []string{models.ChannelInApp}
+		return &prefs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefs.Channels = make([]string, 0)
+	if channels != "" {
+		prefs.Channels = strings.Split(channels, channelsSeparator)
+	}
+	return &prefs, nil
+}
+
+func (m *NotificationManager) SavePreferences(ctx context.Context, prefs *models.NotificationPreferences) error {
+	query := `INSERT INTO notification_preferences (user_id, channels, email, webhook_url, quiet_start, quiet_end, time_zone) ` +
+		`VALUES (?, ?, ?, ?, ?, ?, ?) ON CONFLICT(user_id) DO UPDATE SET channels = excluded.channels, email = excluded.email, ` +
+		`webhook_url = excluded.webhook_url, quiet_start = excluded.quiet_start, quiet_end = excluded.quiet_end, time_zone = excluded.time_zone`
+	_, err := m.DB.ExecContext(ctx, query, prefs.UserID, strings.Join(prefs.Channels, channelsSeparator), prefs.Email,
+		prefs.WebhookURL, prefs.QuietStart, prefs.QuietEnd, prefs.TimeZone)
+	return err
+}
+
+// DueTasks compares the due dates with julianday: they are stored as text with
+// the offset they were given in, which doesn't sort as time. The
+// tasks_reminders index covers the expression.
+func (m *NotificationManager) DueTasks(ctx context.Context, before time.Time) ([]models.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE julianday(due_at) <= julianday(?) AND reminded_at IS NULL ` +
+		`AND assignee != '' AND LOWER(status) NOT IN (?, ?) ORDER BY julianday(due_at)`
+	rows, err := m.DB.QueryContext(ctx, query, before, models.StatusDone, "completed")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]models.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// MarkReminded leaves the task alone when its due date or assignee changed
+// since it was read, or one of its reminders waits for a retry.
+func (m *NotificationManager) MarkReminded(ctx context.Context, task *models.Task, at time.Time) error {
+	query := `UPDATE tasks SET reminded_at = ? WHERE id = ? AND julianday(due_at) = julianday(?) AND assignee = ? ` +
+		`AND NOT EXISTS (SELECT 1 FROM reminders WHERE task_id = tasks.id AND next_attempt_at IS NOT NULL)`
+	_, err := m.DB.ExecContext(ctx, query, at, task.ID, task.DueAt, task.Assignee)
+	return err
+}
+
+// ClaimReminder inserts the reminder, or takes back a failed one whose retry
+// is due. The times of the retries are stored in UTC, so they compare as text.
+func (m *NotificationManager) ClaimReminder(ctx context.Context, reminder *models.Reminder, at time.Time) (bool, error) {
+	query := `INSERT OR IGNORE INTO reminders (task_id, user_id, kind, channel, due_at, sent_at) VALUES (?, ?, ?, ?, ?, ?)`
+	row, err := m.DB.ExecContext(ctx, query, reminder.TaskID, reminder.UserID, reminder.Kind, reminder.Channel,
+		reminder.DueAt, at)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := row.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected > 0 {
+		reminder.Attempts = 0
+		return true, nil
+	}
+
+	query = `UPDATE reminders SET sent_at = ?, next_attempt_at = NULL WHERE task_id = ? AND user_id = ? AND kind = ? ` +
+		`AND channel = ? AND due_at = ? AND next_attempt_at <= ? RETURNING attempts`
+	err = m.DB.QueryRowContext(ctx, query, at, reminder.TaskID, reminder.UserID, reminder.Kind, reminder.Channel,
+		reminder.DueAt, at.UTC()).Scan(&reminder.Attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// FailReminder polls the task of the reminder again when it will be retried,
+// it may have been marked reminded while the reminder was being sent.
+func (m *NotificationManager) FailReminder(ctx context.Context, reminder *models.Reminder, retryAt *time.Time) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	var nextAttemptAt sql.NullTime
+	if retryAt != nil {
+		nextAttemptAt = sql.NullTime{Time: retryAt.UTC(), Valid: true}
+	}
+	query := `UPDATE reminders SET attempts = attempts + 1, next_attempt_at = ? ` +
+		`WHERE task_id = ? AND user_id = ? AND kind = ? AND channel = ? AND due_at = ?`
+	_, err = tx.ExecContext(ctx, query, nextAttemptAt, reminder.TaskID, reminder.UserID, reminder.Kind,
+		reminder.Channel, reminder.DueAt)
+	if err != nil {
+		return err
+	}
+	if retryAt != nil {
+		if _, err = tx.ExecContext(ctx, `UPDATE tasks SET reminded_at = NULL WHERE id = ?`, reminder.TaskID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m *NotificationManager) ReleaseReminder(ctx context.Context, reminder *models.Reminder) error {
+	query := `DELETE FROM reminders WHERE task_id = ? AND user_id = ? AND kind = ? AND channel = ? AND due_at = ?`
+	_, err := m.DB.ExecContext(ctx, query, reminder.TaskID, reminder.UserID, reminder.Kind, reminder.Channel, reminder.DueAt)
+	return err
+}
+
+// removeReminders forgets the reminders of a deleted task within db, the
+// failed ones waiting for a retry included.
+func removeReminders(ctx context.Context, db execer, taskID string) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM reminders WHERE task_id = ?`, taskID)
+	return err
+}
+
+// AddNotification stores an unread notification and sets its ID and creation
+// time.
+func (m *NotificationManager) AddNotification(ctx context.Context, notification *models.Notification) error {
+	return insertNotification(ctx, m.DB, notification)
+}
+
+func (m *NotificationManager) Inbox(ctx context.Context, userID, before string, limit int, unreadOnly bool) (*models.Inbox, error) {
+	inbox := models.Inbox{Notifications: make([]models.Notification, 0)}
+	err := m.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`, userID).
+		Scan(&inbox.Unread)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, user_id, task_id, kind, message, actor, created_at, read_at FROM notifications WHERE user_id = ?`
+	args := []any{userID}
+	if before != "" {
+		query += ` AND id < ?`
+		args = append(args, before)
+	}
+	if unreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			notification models.Notification
+			readAt       sql.NullTime
+		)
+		err = rows.Scan(&notification.ID, &notification.UserID, &notification.TaskID, &notification.Kind,
+			&notification.Message, &notification.Actor, &notification.CreatedAt, &readAt)
+		if err != nil {
+			return nil, err
+		}
+		if readAt.Valid {
+			notification.ReadAt = &readAt.Time
+		}
+		inbox.Notifications = append(inbox.Notifications, notification)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &inbox, nil
+}
+
+// MarkRead keeps the time a notification was first read.
+func (m *NotificationManager) MarkRead(ctx context.Context, userID, id string) error {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, ?) WHERE id = ? AND user_id = ?`
+	rows, err := m.DB.ExecContext(ctx, query, time.Now(), id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := rows.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (m *NotificationManager) MarkAllRead(ctx context.Context, userID string) error {
+	query := `UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL`
+	_, err := m.DB.ExecContext(ctx, query, time.Now(), userID)
+	return err
+}
+
+func insertNotification(ctx context.Context, db execer, notification *models.Notification) error {
+	notification.CreatedAt = time.Now()
+	query := `INSERT INTO notifications (user_id, task_id, kind, message, actor, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	row, err := db.ExecContext(ctx, query, notification.UserID, notification.TaskID, notification.Kind,
+		notification.Message, notification.Actor, notification.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	dbID, err := row.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	notification.ID = strconv.FormatInt(dbID, 10)
+	return nil
+}
+
+// taskNotification returns the notification of a change of task by actor,
+// kind being NotificationTaskAssigned or the type of the change event.
+func taskNotification(kind string, task *models.Task, actor, previousStatus string) models.Notification {
+	notification := models.Notification{TaskID: task.ID, Kind: kind, Actor: actor}
+	switch kind {
+	case models.NotificationTaskAssigned:
+		notification.Message = fmt.Sprintf("Task %q was assigned to you", task.Title)
+	case models.EventTaskStatusChanged:
+		notification.Message = fmt.Sprintf("Task %q moved from %s to %s", task.Title, previousStatus, task.Status)
+	case models.EventTaskDeleted:
+		notification.Message = fmt.Sprintf("Task %q was deleted", task.Title)
+	default:
+		notification.Message = fmt.Sprintf("Task %q was updated", task.Title)
+	}
+	return notification
+}
+
+// notifyUsers adds notification to the inbox of each of users, once, except
+// the actor of the change: nobody is notified of their own changes.
+func notifyUsers(ctx context.Context, db execer, notification models.Notification, users ...string) error {
+	notified := map[string]bool{"": true, notification.Actor: true}
+	for _, user := range users {
+		if notified[user] {
+			continue
+		}
+		notified[user] = true
+		notification.UserID = user
+		if err := insertNotification(ctx, db, &notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"time"
+)
+
+var _ = Describe("NotificationManager", func() {
+	var (
+		manager     *NotificationManager
+		database    *sql.DB
+		mockSQL     sqlmock.Sqlmock
+		prefColumns = []string{"channels", "email", "webhook_url", "quiet_start", "quiet_end", "time_zone"}
+		dueColumns  = []string{"id", "title", "description", "status", "created_at", "due_at", "recurrence", "series_id", "occurrence", "assignee", "rank", "story_points", "sprint_id"}
+		err         error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &NotificationManager{DB: database}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	Describe("GetPreferences", func() {
+		It("returns the saved preferences", func() {
+			mockSQL.ExpectQuery(`SELECT (.+) FROM notification_preferences WHERE user_id = \?`).WithArgs("ci").
+				WillReturnRows(sqlmock.NewRows(prefColumns).
+					AddRow("email,in_app", "ci@example.com", "", "22:00", "07:00", "Europe/Paris"))
+
+			prefs, err := manager.GetPreferences(ctx, "ci")
+			Expect(err).To(Succeed())
+			Expect(prefs).To(Equal(&models.NotificationPreferences{
+				UserID: "ci", Channels: []string{models.ChannelEmail, models.ChannelInApp}, Email: "ci@example.com",
+				QuietStart: "22:00", QuietEnd: "07:00", TimeZone: "Europe/Paris",
+			}))
+		})
+
+		It("defaults to in-app notifications", func() {
+			mockSQL.ExpectQuery("SELECT (.+) FROM notification_preferences").WillReturnRows(sqlmock.NewRows(prefColumns))
+
+			prefs, err := manager.GetPreferences(ctx, "ci")
+			Expect(err).To(Succeed())
+			Expect(prefs).To(Equal(&models.NotificationPreferences{UserID: "ci", Channels: []string{models.ChannelInApp}}))
+		})
+
+		It("keeps the notifications turned off", func() {
+			mockSQL.ExpectQuery("SELECT (.+) FROM notification_preferences").
+				WillReturnRows(sqlmock.NewRows(prefColumns).AddRow("", "", "", "", "", ""))
+
+			prefs, err := manager.GetPreferences(ctx, "ci")
+			Expect(err).To(Succeed())
+			Expect(prefs.Channels).To(BeEmpty())
+		})
+
+		It("returns an error when the query fails", func() {
+			mockSQL.ExpectQuery("SELECT (.+) FROM notification_preferences").WillReturnError(errMock)
+
+			prefs, err := manager.GetPreferences(ctx, "ci")
+			Expect(err).To(MatchError(errMock))
+			Expect(prefs).To(BeNil())
+		})
+	})
+
+	It("upserts the preferences", func() {
+		mockSQL.ExpectExec(`INSERT INTO notification_preferences (.+) ON CONFLICT\(user_id\) DO UPDATE`).
+			WithArgs("ci", "email,webhook", "ci@example.com", "https://example.com/hook", "", "", "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		Expect(manager.SavePreferences(ctx, &models.NotificationPreferences{
+			UserID: "ci", Channels: []string{models.ChannelEmail, models.ChannelWebhook},
+			Email: "ci@example.com", WebhookURL: "https://example.com/hook",
+		})).To(Succeed())
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Describe("DueTasks", func() {
+		It("returns the open assigned tasks due by then not reminded yet", func() {
+			now := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+			mockSQL.ExpectQuery(`SELECT (.+) FROM tasks WHERE julianday\(due_at\) <= julianday\(\?\) AND reminded_at IS NULL `+
+				`AND assignee != '' AND LOWER\(status\) NOT IN \(\?, \?\) ORDER BY julianday\(due_at\)`).
+				WithArgs(now.Add(2*time.Hour), models.StatusDone, "completed").
+				WillReturnRows(sqlmock.NewRows(dueColumns).
+					AddRow("3", "Sooner", "", "pending", now, now.Add(-time.Hour), "", nil, 0, "ops", "", 0, nil).
+					AddRow("1", "Later", "", "pending", now, now.Add(time.Hour), "", nil, 0, "ci", "", 0, nil))
+
+			tasks, err := manager.DueTasks(ctx, now.Add(2*time.Hour))
+			Expect(err).To(Succeed())
+			Expect(tasks).To(HaveLen(2))
+			Expect(tasks[0].ID).To(Equal("3"))
+			Expect(tasks[1].ID).To(Equal("1"))
+		})
+
+		It("returns an error when the query fails", func() {
+			mockSQL.ExpectQuery("SELECT (.+) FROM tasks").WillReturnError(errMock)
+
+			tasks, err := manager.DueTasks(ctx, time.Now())
+			Expect(err).To(MatchError(errMock))
+			Expect(tasks).To(BeNil())
+		})
+	})
+
+	Describe("reminders", func() {
+		var (
+			reminder = &models.Reminder{
+				TaskID: "1", UserID: "ci", Kind: models.ReminderDueSoon, Channel: models.ChannelEmail,
+				DueAt: time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC),
+			}
+			now = time.Date(2026, time.January, 5, 8, 0, 0, 0, time.UTC)
+		)
+
+		It("claims a reminder once", func() {
+			mockSQL.ExpectExec(`INSERT OR IGNORE INTO reminders`).
+				WithArgs("1", "ci", models.ReminderDueSoon, models.ChannelEmail, reminder.DueAt, now).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectExec(`INSERT OR IGNORE INTO reminders`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mockSQL.ExpectQuery(`UPDATE reminders SET sent_at = \?, next_attempt_at = NULL WHERE (.+) AND next_attempt_at <= \? RETURNING attempts`).
+				WithArgs(now, "1", "ci", models.ReminderDueSoon, models.ChannelEmail, reminder.DueAt, now).
+				WillReturnRows(sqlmock.NewRows([]string{"attempts"}))
+
+			Expect(manager.ClaimReminder(ctx, reminder, now)).To(BeTrue())
+			Expect(manager.ClaimReminder(ctx, reminder, now)).To(BeFalse())
+		})
+
+		It("claims a failed reminder whose retry is due", func() {
+			claimed := *reminder
+			mockSQL.ExpectExec(`INSERT OR IGNORE INTO reminders`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mockSQL.ExpectQuery(`UPDATE reminders SET sent_at`).
+				WillReturnRows(sqlmock.NewRows([]string{"attempts"}).AddRow(2))
+
+			Expect(manager.ClaimReminder(ctx, &claimed, now)).To(BeTrue())
+			Expect(claimed.Attempts).To(Equal(2))
+		})
+
+		It("returns an error when the claim fails", func() {
+			mockSQL.ExpectExec(`INSERT OR IGNORE INTO reminders`).WillReturnError(errMock)
+
+			claimed, err := manager.ClaimReminder(ctx, reminder, now)
+			Expect(err).To(MatchError(errMock))
+			Expect(claimed).To(BeFalse())
+		})
+
+		It("schedules the retry of a failed reminder and polls its task again", func() {
+			retryAt := now.Add(time.Minute)
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec(`UPDATE reminders SET attempts = attempts \+ 1, next_attempt_at = \? WHERE task_id = \? AND user_id = \? AND kind = \? AND channel = \? AND due_at = \?`).
+				WithArgs(retryAt, "1", "ci", models.ReminderDueSoon, models.ChannelEmail, reminder.DueAt).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec(`UPDATE tasks SET reminded_at = NULL WHERE id = \?`).WithArgs("1").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectCommit()
+
+			Expect(manager.FailReminder(ctx, reminder, &retryAt)).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("releases a reminder", func() {
+			mockSQL.ExpectExec(`DELETE FROM reminders WHERE task_id = \? AND user_id = \? AND kind = \? AND channel = \? AND due_at = \?`).
+				WithArgs("1", "ci", models.ReminderDueSoon, models.ChannelEmail, reminder.DueAt).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			Expect(manager.ReleaseReminder(ctx, reminder)).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		Describe("with SQLite", func() {
+			var tasks *TaskManager
+
+			BeforeEach(func() {
+				manager.DB = newSQLiteDB()
+				tasks = &TaskManager{DB: manager.DB}
+			})
+
+			create := func(title, assignee string, dueAt time.Time) *models.Task {
+				task := models.Task{Title: title, Status: models.StatusPending, Assignee: assignee, DueAt: &dueAt}
+				Expect(tasks.Create(ctx, &task)).To(Succeed())
+				return &task
+			}
+
+			dueIDs := func(before time.Time) []string {
+				due, err := manager.DueTasks(ctx, before)
+				Expect(err).To(Succeed())
+				ids := make([]string, 0, len(due))
+				for _, task := range due {
+					ids = append(ids, task.ID)
+				}
+				return ids
+			}
+
+			It("compares the due dates given in any offset", func() {
+				paris := time.FixedZone("CET", 3600)
+				later := create("Later", "ci", now.Add(time.Hour))
+				create("Too late", "ci", now.Add(3*time.Hour))
+				sooner := create("Sooner", "ops", now.In(paris).Add(-time.Hour))
+				create("Unassigned", "", now)
+				done := create("Done", "ci", now)
+				done.Status = models.StatusDone
+				Expect(tasks.Update(ctx, done)).To(Succeed())
+
+				Expect(dueIDs(now.Add(2 * time.Hour))).To(Equal([]string{sooner.ID, later.ID}))
+			})
+
+			It("skips the reminded tasks until their due date changes", func() {
+				task := create("Ship", "ci", now.Add(-time.Hour))
+				Expect(manager.MarkReminded(ctx, task, now)).To(Succeed())
+				Expect(dueIDs(now)).To(BeEmpty())
+
+				moved := now.Add(-time.Minute)
+				task.DueAt = &moved
+				Expect(tasks.Update(ctx, task)).To(Succeed())
+				Expect(dueIDs(now)).To(Equal([]string{task.ID}))
+			})
+
+			It("retries the failed reminders when due and keeps their tasks polled", func() {
+				task := create("Ship", "ci", now.Add(-time.Hour))
+				failed := models.Reminder{TaskID: task.ID, UserID: "ci", Kind: models.ReminderOverdue, Channel: models.ChannelEmail, DueAt: *task.DueAt}
+				Expect(manager.ClaimReminder(ctx, &failed, now)).To(BeTrue())
+				retryAt := now.Add(time.Minute)
+				Expect(manager.FailReminder(ctx, &failed, &retryAt)).To(Succeed())
+
+				Expect(manager.MarkReminded(ctx, task, now)).To(Succeed())
+				Expect(dueIDs(now)).To(Equal([]string{task.ID}), "a reminder waits for a retry")
+
+				Expect(manager.ClaimReminder(ctx, &failed, now)).To(BeFalse())
+				Expect(manager.ClaimReminder(ctx, &failed, retryAt)).To(BeTrue())
+				Expect(failed.Attempts).To(Equal(1))
+				Expect(manager.ClaimReminder(ctx, &failed, retryAt)).To(BeFalse())
+
+				Expect(manager.MarkReminded(ctx, task, now)).To(Succeed())
+				Expect(dueIDs(now)).To(BeEmpty())
+			})
+		})
+	})
+
+	It("adds an unread notification", func() {
+		mockSQL.ExpectExec(`INSERT INTO notifications \(user_id, task_id, kind, message, actor, created_at\)`).
+			WithArgs("ci", "1", models.ReminderOverdue, "Task \"Ship\" is overdue", "", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(7, 1))
+
+		notification := models.Notification{UserID: "ci", TaskID: "1", Kind: models.ReminderOverdue, Message: "Task \"Ship\" is overdue"}
+		Expect(manager.AddNotification(ctx, &notification)).To(Succeed())
+		Expect(notification.ID).To(Equal("7"))
+		Expect(notification.CreatedAt).NotTo(BeZero())
+		Expect(notification.ReadAt).To(BeNil())
+	})
+
+	Describe("Inbox", func() {
+		inboxColumns := []string{"id", "user_id", "task_id", "kind", "message", "actor", "created_at", "read_at"}
+		now := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+
+		It("returns a page of notifications with the unread count", func() {
+			mockSQL.ExpectQuery(`SELECT COUNT\(\*\) FROM notifications WHERE user_id = \? AND read_at IS NULL`).WithArgs("ci").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+			mockSQL.ExpectQuery(`SELECT (.+) FROM notifications WHERE user_id = \? AND id < \? ORDER BY id DESC LIMIT \?`).
+				WithArgs("ci", "9", 2).
+				WillReturnRows(sqlmock.NewRows(inboxColumns).
+					AddRow(8, "ci", "1", models.EventTaskUpdated, "Task \"Ship\" was updated", "ops", now, nil).
+					AddRow(5, "ci", "1", models.NotificationTaskAssigned, "Task \"Ship\" was assigned to you", "ops", now, now))
+
+			inbox, err := manager.Inbox(ctx, "ci", "9", 2, false)
+			Expect(err).To(Succeed())
+			Expect(inbox.Unread).To(Equal(3))
+			Expect(inbox.Notifications).To(Equal([]models.Notification{
+				{ID: "8", UserID: "ci", TaskID: "1", Kind: models.EventTaskUpdated, Message: "Task \"Ship\" was updated", Actor: "ops", CreatedAt: now},
+				{ID: "5", UserID: "ci", TaskID: "1", Kind: models.NotificationTaskAssigned, Message: "Task \"Ship\" was assigned to you", Actor: "ops", CreatedAt: now, ReadAt: &now},
+			}))
+		})
+
+		It("filters the unread notifications", func() {
+			mockSQL.ExpectQuery(`SELECT COUNT`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mockSQL.ExpectQuery(`SELECT (.+) FROM notifications WHERE user_id = \? AND read_at IS NULL ORDER BY id DESC LIMIT \?`).
+				WithArgs("ci", 50).
+				WillReturnRows(sqlmock.NewRows(inboxColumns))
+
+			inbox, err := manager.Inbox(ctx, "ci", "", 50, true)
+			Expect(err).To(Succeed())
+			Expect(inbox).To(Equal(&models.Inbox{Notifications: []models.Notification{}}))
+		})
+
+		It("returns an error when the count fails", func() {
+			mockSQL.ExpectQuery(`SELECT COUNT`).WillReturnError(errMock)
+
+			inbox, err := manager.Inbox(ctx, "ci", "", 50, false)
+			Expect(err).To(MatchError(errMock))
+			Expect(inbox).To(BeNil())
+		})
+
+		It("marks a notification of the user read", func() {
+			mockSQL.ExpectExec(`UPDATE notifications SET read_at = COALESCE\(read_at, \?\) WHERE id = \? AND user_id = \?`).
+				WithArgs(sqlmock.AnyArg(), "8", "ci").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec(`UPDATE notifications SET read_at`).
+				WithArgs(sqlmock.AnyArg(), "8", "ops").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(manager.MarkRead(ctx, "ci", "8")).To(Succeed())
+			Expect(manager.MarkRead(ctx, "ops", "8")).To(MatchError(ErrNotFound))
+		})
+
+		It("marks all the notifications of the user read", func() {
+			mockSQL.ExpectExec(`UPDATE notifications SET read_at = \? WHERE user_id = \? AND read_at IS NULL`).
+				WithArgs(sqlmock.AnyArg(), "ci").
+				WillReturnResult(sqlmock.NewResult(0, 4))
+
+			Expect(manager.MarkAllRead(ctx, "ci")).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Describe("task changes", func() {
+		var (
+			tasks    *TaskManager
+			actorCtx = ContextWithAPIKey(ctx, &models.APIKey{Name: "ops"})
+		)
+
+		BeforeEach(func() {
+			tasks = &TaskManager{DB: database}
+		})
+
+		expectWatchers := func(users ...string) {
+			rows := sqlmock.NewRows([]string{"user_id"})
+			for _, user := range users {
+				rows.AddRow(user)
+			}
+			mockSQL.ExpectQuery(`SELECT user_id FROM task_watchers WHERE task_id = \?`).WithArgs("1").WillReturnRows(rows)
+		}
+
+		expectTaskCleanup := func() {
+			mockSQL.ExpectExec("UPDATE worklogs").WillReturnResult(sqlmock.NewResult(0, 0))
+			mockSQL.ExpectExec("DELETE FROM reminders").WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+
+		expectNotification := func(user, kind, message string) {
+			mockSQL.ExpectExec(`INSERT INTO notifications`).
+				WithArgs(user, "1", kind, message, "ops", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+
+		It("notifies the assignee of a new task followed by its creator", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("INSERT INTO tasks").WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectExec("INSERT OR IGNORE INTO task_watchers").WithArgs(int64(1), "ops", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectExec("INSERT INTO outbox").WithArgs(models.EventTaskCreated, "1", sqlmock.AnyArg(), sqlmock.AnyArg(), `["ops"]`, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			expectNotification("ci", models.NotificationTaskAssigned, `Task "Ship" was assigned to you`)
+			mockSQL.ExpectCommit()
+
+			Expect(tasks.Create(actorCtx, &models.Task{Title: "Ship", Assignee: "ci"})).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("notifies the previous assignee and the watchers of the change and the new assignee of the assignment", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery("SELECT status, created_at, series_id, occurrence, assignee, rank FROM tasks").
+				WillReturnRows(sqlmock.NewRows([]string{"status", "created_at", "series_id", "occurrence", "assignee", "rank"}).
+					AddRow("todo", time.Now(), nil, 0, "ci", ""))
+			mockSQL.ExpectExec("UPDATE tasks SET").WillReturnResult(sqlmock.NewResult(0, 1))
+			expectWatchers("ops", "ci", "pm")
+			mockSQL.ExpectExec("INSERT INTO outbox").WithArgs(models.EventTaskUpdated, "1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectExec("INSERT INTO outbox").WithArgs(models.EventTaskStatusChanged, "1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(2, 1))
+			expectNotification("qa", models.NotificationTaskAssigned, `Task "Ship" was assigned to you`)
+			expectNotification("ci", models.EventTaskStatusChanged, `Task "Ship" moved from todo to review`)
+			expectNotification("pm", models.EventTaskStatusChanged, `Task "Ship" moved from todo to review`)
+			mockSQL.ExpectCommit()
+
+			Expect(tasks.Update(actorCtx, &models.Task{ID: "1", Title: "Ship", Status: "review", Assignee: "qa"})).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("doesn't notify users of their own changes", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery("SELECT status, created_at, series_id, occurrence, assignee, rank FROM tasks").
+				WillReturnRows(sqlmock.NewRows([]string{"status", "created_at", "series_id", "occurrence", "assignee", "rank"}).
+					AddRow("todo", time.Now(), nil, 0, "ops", ""))
+			mockSQL.ExpectExec("UPDATE tasks SET").WillReturnResult(sqlmock.NewResult(0, 1))
+			expectWatchers("ops")
+			mockSQL.ExpectExec("INSERT INTO outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectCommit()
+
+			Expect(tasks.Update(actorCtx, &models.Task{ID: "1", Title: "Ship", Status: "todo", Assignee: "ops"})).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("notifies the assignee and the watchers of a deleted task", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT title, assignee FROM tasks WHERE id = \?`).WithArgs("1").
+				WillReturnRows(sqlmock.NewRows([]string{"title", "assignee"}).AddRow("Ship", "ci"))
+			mockSQL.ExpectExec("DELETE FROM tasks").WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectQuery("DELETE FROM task_watchers").WithArgs("1").
+				WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("pm"))
+			expectTaskCleanup()
+			mockSQL.ExpectExec("INSERT INTO outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+			expectNotification("ci", models.EventTaskDeleted, `Task "Ship" was deleted`)
+			expectNotification("pm", models.EventTaskDeleted, `Task "Ship" was deleted`)
+			mockSQL.ExpectCommit()
+
+			Expect(tasks.Delete(actorCtx, "1")).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("rolls the change back when the notification fails", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT title, assignee FROM tasks`).
+				WillReturnRows(sqlmock.NewRows([]string{"title", "assignee"}).AddRow("Ship", "ci"))
+			mockSQL.ExpectExec("DELETE FROM tasks").WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectQuery("DELETE FROM task_watchers").WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+			expectTaskCleanup()
+			mockSQL.ExpectExec("INSERT INTO outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectExec("INSERT INTO notifications").WillReturnError(errMock)
+			mockSQL.ExpectRollback()
+
+			Expect(tasks.Delete(actorCtx, "1")).To(MatchError(errMock))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+})
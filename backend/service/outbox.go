@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/saarzur123/task-management/backend/models"
+	"time"
+)
+
+// OutboxReader reads the task events recorded by TaskManager, each in the
+// transaction of the change it describes. Event IDs are sequence numbers:
+// they grow monotonically and writes are serialized, so an event never
+// becomes visible after one with a greater ID.
+type OutboxReader interface {
+	// After returns up to limit events with an ID greater than afterID,
+	// oldest first.
+	After(ctx context.Context, afterID uint64, limit int) ([]models.TaskEvent, error)
+}
+
+// TaskHistoryReader reads the outbox events of several tasks at once.
+type TaskHistoryReader interface {
+	// History returns the latest limit events of each of the tasks, oldest
+	// first.
+	History(ctx context.Context, taskIDs []string, limit int) ([]models.TaskEvent, error)
+}
+
+type OutboxManager struct {
+	DB *sql.DB
+}
+
+// writeOutbox records event within tx. Its ID and time are assigned by the
+// outbox, the time in UTC so that it compares as text.
+func writeOutbox(ctx context.Context, tx *sql.Tx, event models.TaskEvent) error {
+	var payload sql.NullString
+	if event.Task != nil {
+		data, err := json.Marshal(event.Task)
+		if err != nil {
+			return err
+		}
+		payload = sql.NullString{String: string(data), Valid: true}
+	}
+	previousStatus := sql.NullString{String: event.PreviousStatus, Valid: event.PreviousStatus != ""}
+	var watchers sql.NullString
+	if len(event.Watchers) > 0 {
+		data, err := json.Marshal(event.Watchers)
+		if err != nil {
+			return err
+		}
+		watchers = sql.NullString{String: string(data), Valid: true}
+	}
+
+	query := `INSERT INTO outbox (event_type, task_id, payload, previous_status, watchers, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err := tx.ExecContext(ctx, query, event.Type, event.TaskID, payload, previousStatus, watchers, time.Now().UTC())
+	return err
+}
+
+func (m *OutboxManager) After(ctx context.Context, afterID uint64, limit int) ([]models.TaskEvent, error) {
+	query := `SELECT id, event_type, task_id, payload, previous_status, watchers, created_at FROM outbox WHERE id > ? ORDER BY id LIMIT ?`
+	rows, err := m.DB.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+func (m *OutboxManager) History(ctx context.Context, taskIDs []string, limit int) ([]models.TaskEvent, error) {
+	if len(taskIDs) == 0 {
+		return []models.TaskEvent{}, nil
+	}
+
+	query := `SELECT id, event_type, task_id, payload, previous_status, watchers, created_at FROM (
+		SELECT *, ROW_NUMBER() OVER (PARTITION BY task_id ORDER BY id DESC) AS position FROM outbox WHERE task_id IN (` + placeholders(len(taskIDs)) + `)
+	) WHERE position <= ? ORDER BY id`
+	args := make([]any, 0, len(taskIDs)+1)
+	for _, id := range taskIDs {
+		args = append(args, id)
+	}
+	rows, err := m.DB.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+// scanEvents reads and closes rows selecting the outbox columns of After.
+func scanEvents(rows *sql.Rows) ([]models.TaskEvent, error) {
+	defer rows.Close()
+
+	events := make([]models.TaskEvent, 0)
+	for rows.Next() {
+		var (
+			event                             models.TaskEvent
+			payload, previousStatus, watchers sql.NullString
+		)
+		err := rows.Scan(&event.ID, &event.Type, &event.TaskID, &payload, &previousStatus, &watchers, &event.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+		event.PreviousStatus = previousStatus.String
+		if watchers.Valid {
+			if err = json.Unmarshal([]byte(watchers.String), &event.Watchers); err != nil {
+				return nil, err
+			}
+		}
+		if payload.Valid {
+			event.Task = &models.Task{}
+			if err = json.Unmarshal([]byte(payload.String), event.Task); err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"time"
+)
+
+var _ = Describe("OutboxManager", func() {
+	var (
+		manager  *OutboxManager
+		database *sql.DB
+		mockSQL  sqlmock.Sqlmock
+		columns  = []string{"id", "event_type", "task_id", "payload", "previous_status", "watchers", "created_at"}
+		err      error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &OutboxManager{DB: database}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	It("returns the events after the given ID with their tasks and watchers", func() {
+		now := time.Now()
+		mockSQL.ExpectQuery(`SELECT id, event_type, task_id, payload, previous_status, watchers, created_at FROM outbox WHERE id > \? ORDER BY id LIMIT \?`).
+			WithArgs(4, 10).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(5, models.EventTaskCreated, "1", `{"id":"1","title":"Task 1"}`, nil, `["ci"]`, now).
+				AddRow(6, models.EventTaskStatusChanged, "1", `{"id":"1","title":"Task 1","status":"done"}`, "pending", `["ci","qa"]`, now).
+				AddRow(7, models.EventTaskDeleted, "1", nil, nil, nil, now))
+
+		events, err := manager.After(ctx, 4, 10)
+		Expect(err).To(Succeed())
+		Expect(events).To(Equal([]models.TaskEvent{
+			{ID: 5, Type: models.EventTaskCreated, TaskID: "1", Task: &models.Task{ID: "1", Title: "Task 1"}, Watchers: []string{"ci"}, OccurredAt: now},
+			{ID: 6, Type: models.EventTaskStatusChanged, TaskID: "1", Task: &models.Task{ID: "1", Title: "Task 1", Status: "done"},
+				PreviousStatus: "pending", Watchers: []string{"ci", "qa"}, OccurredAt: now},
+			{ID: 7, Type: models.EventTaskDeleted, TaskID: "1", OccurredAt: now},
+		}))
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("returns an error when the query fails", func() {
+		mockSQL.ExpectQuery("SELECT (.+) FROM outbox").WillReturnError(errMock)
+
+		_, err := manager.After(ctx, 0, 10)
+		Expect(err).To(MatchError(errMock))
+	})
+
+	Describe("History", func() {
+		It("returns the latest events of each task", func() {
+			now := time.Now()
+			mockSQL.ExpectQuery(`PARTITION BY task_id ORDER BY id DESC\) AS position FROM outbox WHERE task_id IN \(\?, \?\)\s+\) WHERE position <= \? ORDER BY id`).
+				WithArgs("1", "2", 20).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(5, models.EventTaskCreated, "1", `{"id":"1","title":"Task 1"}`, nil, nil, now).
+					AddRow(8, models.EventTaskDeleted, "2", nil, nil, nil, now))
+
+			events, err := manager.History(ctx, []string{"1", "2"}, 20)
+			Expect(err).To(Succeed())
+			Expect(events).To(Equal([]models.TaskEvent{
+				{ID: 5, Type: models.EventTaskCreated, TaskID: "1", Task: &models.Task{ID: "1", Title: "Task 1"}, OccurredAt: now},
+				{ID: 8, Type: models.EventTaskDeleted, TaskID: "2", OccurredAt: now},
+			}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("doesn't query without tasks", func() {
+			events, err := manager.History(ctx, nil, 20)
+			Expect(err).To(Succeed())
+			Expect(events).To(BeEmpty())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"github.com/saarzur123/task-management/backend/models"
+	"strconv"
+	"time"
+)
+
+// RecurringTasks lets the recurrence scheduler create the occurrences of the
+// recurring tasks.
+type RecurringTasks interface {
+	// RecurringHeads returns the latest occurrence of every recurring series:
+	// the tasks with a recurrence and a due time whose next occurrence wasn't
+	// created yet, the earliest due first.
+	RecurringHeads(ctx context.Context) ([]models.Task, error)
+	// CreateOccurrence creates next as the occurrence following previous,
+	// along with its outbox event, and sets its ID and creation time. It
+	// reports false without creating anything when the next occurrence of
+	// previous was created already or previous stopped recurring.
+	CreateOccurrence(ctx context.Context, previous, next *models.Task) (bool, error)
+}
+
+func (m *TaskManager) RecurringHeads(ctx context.Context) (_ []models.Task, err error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE recurrence != '' AND recurred = 0 AND due_at IS NOT NULL ORDER BY due_at`
+	ctx, end := m.observe(ctx, "TaskManager.RecurringHeads", query)
+	defer func() { end(err) }()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]models.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// CreateOccurrence marks previous as recurred before inserting next, in one
+// transaction, so schedulers racing on the same series create it only once.
+// The watchers of previous follow next too.
+func (m *TaskManager) CreateOccurrence(ctx context.Context, previous, next *models.Task) (_ bool, err error) {
+	query := `INSERT INTO tasks (title, description, status, created_at, due_at, recurrence, series_id, occurrence, assignee, story_points) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	ctx, end := m.observe(ctx, "TaskManager.CreateOccurrence", query)
+	defer func() { end(err) }()
+	defer m.stats.invalidate()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	claimed, err := tx.ExecContext(ctx, `UPDATE tasks SET recurred = 1 WHERE id = ? AND recurred = 0 AND recurrence != ''`, previous.ID)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := claimed.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	next.CreatedAt = time.Now()
+	row, err := tx.ExecContext(ctx, query, next.Title, next.Description, next.Status, next.CreatedAt, next.DueAt,
+		next.Recurrence, next.SeriesID, next.Occurrence, next.Assignee, next.StoryPoints)
+	if err != nil {
+		return false, err
+	}
+	dbID, err := row.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+
+	next.ID = strconv.FormatInt(dbID, 10)
+	watchers, err := copyWatchers(ctx, tx, previous.ID, dbID)
+	if err != nil {
+		return false, err
+	}
+	event := models.TaskEvent{Type: models.EventTaskCreated, TaskID: next.ID, Task: next, Watchers: watchers}
+	if err = writeOutbox(ctx, tx, event); err != nil {
+		return false, err
+	}
+	assigned := taskNotification(models.NotificationTaskAssigned, next, "", "")
+	if err = notifyUsers(ctx, tx, assigned, next.Assignee); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"time"
+)
+
+var _ = Describe("recurring tasks", func() {
+	var (
+		manager  *TaskManager
+		database *sql.DB
+		mockSQL  sqlmock.Sqlmock
+		columns  = []string{"id", "title", "description", "status", "created_at", "due_at", "recurrence", "series_id", "occurrence", "assignee", "rank", "story_points", "sprint_id"}
+		dueAt    = time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+		err      error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &TaskManager{DB: database}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	It("starts a series when creating a recurring task", func() {
+		task := models.Task{Title: "Standup", Status: models.StatusPending, DueAt: &dueAt, Recurrence: "FREQ=DAILY"}
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectExec("INSERT INTO tasks").
+			WithArgs(task.Title, task.Description, task.Status, sqlmock.AnyArg(), dueAt, "FREQ=DAILY", "", 0, nil).
+			WillReturnResult(sqlmock.NewResult(3, 1))
+		mockSQL.ExpectExec(`UPDATE tasks SET series_id = id, occurrence = 1 WHERE id = \?`).
+			WithArgs(int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec("INSERT INTO outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+		mockSQL.ExpectCommit()
+
+		Expect(manager.Create(ctx, &task)).To(Succeed())
+		Expect(task.SeriesID).To(Equal("3"))
+		Expect(task.Occurrence).To(Equal(1))
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("starts a series when a task becomes recurring", func() {
+		task := models.Task{ID: "3", Title: "Standup", DueAt: &dueAt, Recurrence: "FREQ=DAILY"}
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectQuery(`SELECT status, created_at, series_id, occurrence, assignee, rank FROM tasks WHERE id = \?`).
+			WithArgs("3").
+			WillReturnRows(sqlmock.NewRows([]string{"status", "created_at", "series_id", "occurrence", "assignee", "rank"}).AddRow("", dueAt, nil, 0, "", ""))
+		mockSQL.ExpectExec("UPDATE tasks SET").
+			WithArgs(task.Title, "", "", dueAt, "FREQ=DAILY", "3", 1, "", 0, nil, "3").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectQuery("SELECT user_id FROM task_watchers").WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mockSQL.ExpectExec("INSERT INTO outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+		mockSQL.ExpectCommit()
+
+		Expect(manager.Update(ctx, &task)).To(Succeed())
+		Expect(task.SeriesID).To(Equal("3"))
+		Expect(task.Occurrence).To(Equal(1))
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("keeps the series of an occurrence", func() {
+		task := models.Task{ID: "5", Title: "Standup", SeriesID: "9", Occurrence: 7}
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectQuery(`SELECT status, created_at, series_id, occurrence, assignee, rank FROM tasks WHERE id = \?`).
+			WithArgs("5").
+			WillReturnRows(sqlmock.NewRows([]string{"status", "created_at", "series_id", "occurrence", "assignee", "rank"}).AddRow("", dueAt, "3", 3, "", ""))
+		mockSQL.ExpectExec("UPDATE tasks SET").
+			WithArgs(task.Title, "", "", nil, "", "3", 3, "", 0, nil, "5").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectQuery("SELECT user_id FROM task_watchers").WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mockSQL.ExpectExec("INSERT INTO outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+		mockSQL.ExpectCommit()
+
+		Expect(manager.Update(ctx, &task)).To(Succeed())
+		Expect(task.SeriesID).To(Equal("3"), "set by the server")
+		Expect(task.Occurrence).To(Equal(3), "set by the server")
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Describe("RecurringHeads", func() {
+		It("returns the latest occurrences due first", func() {
+			mockSQL.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks WHERE recurrence != '' AND recurred = 0 AND due_at IS NOT NULL ORDER BY due_at`).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(4, "Standup", "", "pending", dueAt, dueAt, "FREQ=DAILY", 3, 2, "", "", 0, nil))
+
+			heads, err := manager.RecurringHeads(ctx)
+			Expect(err).To(Succeed())
+			Expect(heads).To(Equal([]models.Task{{
+				ID: "4", Title: "Standup", Status: "pending", CreatedAt: dueAt,
+				DueAt: &dueAt, Recurrence: "FREQ=DAILY", SeriesID: "3", Occurrence: 2,
+			}}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns an error when the query fails", func() {
+			mockSQL.ExpectQuery("SELECT (.+) FROM tasks WHERE recurrence").WillReturnError(errMock)
+
+			heads, err := manager.RecurringHeads(ctx)
+			Expect(err).To(MatchError(errMock))
+			Expect(heads).To(BeNil())
+		})
+	})
+
+	Describe("CreateOccurrence", func() {
+		var (
+			previous = models.Task{ID: "4", SeriesID: "3", Occurrence: 2}
+			next     models.Task
+		)
+
+		BeforeEach(func() {
+			nextDue := dueAt.AddDate(0, 0, 1)
+			next = models.Task{Title: "Standup", Status: models.StatusPending, DueAt: &nextDue, Recurrence: "FREQ=DAILY", SeriesID: "3", Occurrence: 3}
+		})
+
+		It("claims the previous occurrence and inserts the next one with its watchers and event", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec(`UPDATE tasks SET recurred = 1 WHERE id = \? AND recurred = 0 AND recurrence != ''`).
+				WithArgs("4").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec(`INSERT INTO tasks \(title, description, status, created_at, due_at, recurrence, series_id, occurrence, assignee, story_points\)`).
+				WithArgs("Standup", "", models.StatusPending, sqlmock.AnyArg(), dueAt.AddDate(0, 0, 1), "FREQ=DAILY", "3", 3, "", 0).
+				WillReturnResult(sqlmock.NewResult(5, 1))
+			mockSQL.ExpectQuery(`INSERT INTO task_watchers \(task_id, user_id, created_at\) SELECT \?, user_id, created_at FROM task_watchers WHERE task_id = \?`).
+				WithArgs(int64(5), "4").
+				WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("ci"))
+			mockSQL.ExpectExec("INSERT INTO outbox").
+				WithArgs(models.EventTaskCreated, "5", sqlmock.AnyArg(), sqlmock.AnyArg(), `["ci"]`, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectCommit()
+
+			created, err := manager.CreateOccurrence(ctx, &previous, &next)
+			Expect(err).To(Succeed())
+			Expect(created).To(BeTrue())
+			Expect(next.ID).To(Equal("5"))
+			Expect(next.CreatedAt).NotTo(BeZero())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("creates nothing when the next occurrence was already created", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("UPDATE tasks SET recurred = 1").
+				WithArgs("4").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mockSQL.ExpectRollback()
+
+			created, err := manager.CreateOccurrence(ctx, &previous, &next)
+			Expect(err).To(Succeed())
+			Expect(created).To(BeFalse())
+			Expect(next.ID).To(BeEmpty())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("rolls back the claim when the insert fails", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("UPDATE tasks SET recurred = 1").
+				WithArgs("4").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec("INSERT INTO tasks").WillReturnError(errMock)
+			mockSQL.ExpectRollback()
+
+			created, err := manager.CreateOccurrence(ctx, &previous, &next)
+			Expect(err).To(MatchError(errMock))
+			Expect(created).To(BeFalse())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+})
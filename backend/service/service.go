@@ -1,44 +1,71 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	_ "github.com/mattn/go-sqlite3" // nolint: revive
+	"github.com/saarzur123/task-management/backend/logging"
 	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/tracing"
+	"log/slog"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type TaskRepository interface {
-	Create(task *models.Task) error
-	GetByID(id string) (*models.Task, error)
-	Update(task *models.Task) error
-	Delete(id string) error
-	GetAll() ([]models.Task, error)
+	Create(ctx context.Context, task *models.Task) error
+	GetByID(ctx context.Context, id string) (*models.Task, error)
+	Update(ctx context.Context, task *models.Task) error
+	Delete(ctx context.Context, id string) error
+	GetAll(ctx context.Context) ([]models.Task, error)
+}
+
+// TaskPageReader reads the tasks a page at a time, for the clients that
+// can't take them all at once.
+type TaskPageReader interface {
+	// GetPage returns up to limit tasks with an ID greater than after, or
+	// the first ones when after is empty, ordered by ID.
+	GetPage(ctx context.Context, after string, limit int) ([]models.Task, error)
+}
+
+// TaskBatchReader loads several tasks in one query, for callers batching
+// their lookups.
+type TaskBatchReader interface {
+	// GetByIDs returns the tasks among ids that exist, in no particular order.
+	GetByIDs(ctx context.Context, ids []string) ([]models.Task, error)
 }
 
 type TaskManager struct {
 	DB *sql.DB
+	// SlowQueryThreshold is the duration above which statements are logged,
+	// defaultSlowQueryThreshold when zero.
+	SlowQueryThreshold time.Duration
+	// StatsTTL is how long Stats are cached, defaultStatsTTL when zero.
+	StatsTTL time.Duration
+
+	stats statsCache
 }
 
+const (
+	defaultSlowQueryThreshold = 100 * time.Millisecond
+	taskColumns               = `id, title, description, status, created_at, due_at, recurrence, series_id, occurrence, assignee, rank, story_points, sprint_id`
+)
+
 var (
-	ErrNotFound = errors.New("NotFound")
+	ErrNotFound = models.ErrNotFound
 )
 
 func InitDB() (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", "./tasks.db")
+	// The webhook worker writes concurrently with requests: wait for locks
+	// instead of failing, and take the write lock when transactions begin.
+	db, err := sql.Open("sqlite3", "./tasks.db?_busy_timeout=5000&_txlock=immediate")
 	if err != nil {
 		return nil, err
 	}
 
-	sqlStmt := "CREATE TABLE IF NOT EXISTS tasks (" +
-		"id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT," +
-		"title TEXT NOT NULL," +
-		"description TEXT NOT NULL," +
-		"status TEXT NOT NULL," +
-		"created_at TIMESTAMP NOT NULL);"
-
-	_, err = db.Exec(sqlStmt)
+	err = Migrate(context.Background(), db)
 	if err != nil {
 		return nil, err
 	}
@@ -46,10 +73,56 @@ func InitDB() (*sql.DB, error) {
 	return db, nil
 }
 
-func (m *TaskManager) Create(task *models.Task) error {
+// logSlowQuery logs query with the logger of the request when it ran for
+// longer than the slow query threshold. Call it deferred with the start time.
+func (m *TaskManager) logSlowQuery(ctx context.Context, query string, start time.Time) {
+	threshold := m.SlowQueryThreshold
+	if threshold == 0 {
+		threshold = defaultSlowQueryThreshold
+	}
+
+	elapsed := time.Since(start)
+	if elapsed < threshold {
+		return
+	}
+	logging.FromContext(ctx).WarnContext(ctx, "slow query",
+		slog.String("query", query),
+		slog.Duration("elapsed", elapsed))
+}
+
+// observe starts the span of a TaskManager call running query. The returned
+// function ends it and logs the statement when it was slow.
+func (m *TaskManager) observe(ctx context.Context, name, query string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, endSpan := tracing.StartQuery(ctx, name, query)
+	return ctx, func(err error) {
+		endSpan(err)
+		m.logSlowQuery(ctx, query, start)
+	}
+}
+
+// Create inserts the task and its outbox event in one transaction, the
+// creator following the task. A recurring task starts a series of which it
+// is the first occurrence. It returns ErrSprintNotFound when the sprint of
+// the task doesn't exist.
+func (m *TaskManager) Create(ctx context.Context, task *models.Task) (err error) {
 	task.CreatedAt = time.Now()
-	query := `INSERT INTO tasks (title, description, status, created_at) VALUES (?, ?, ?, ?)`
-	row, err := m.DB.Exec(query, task.Title, task.Description, task.Status, task.CreatedAt)
+	query := `INSERT INTO tasks (title, description, status, created_at, due_at, recurrence, assignee, story_points, sprint_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	ctx, end := m.observe(ctx, "TaskManager.Create", query)
+	defer func() { end(err) }()
+	defer m.stats.invalidate()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	if err = checkSprint(ctx, tx, task.SprintID); err != nil {
+		return err
+	}
+	row, err := tx.ExecContext(ctx, query, task.Title, task.Description, task.Status, task.CreatedAt, task.DueAt, task.Recurrence,
+		task.Assignee, task.StoryPoints, nullableID(task.SprintID))
 	if err != nil {
 		return err
 	}
@@ -60,25 +133,147 @@ func (m *TaskManager) Create(task *models.Task) error {
 	}
 
 	task.ID = strconv.FormatInt(dbID, 10)
-	return nil
+	task.SeriesID, task.Occurrence = "", 0
+	if task.Recurrence != "" {
+		_, err = tx.ExecContext(ctx, `UPDATE tasks SET series_id = id, occurrence = 1 WHERE id = ?`, dbID)
+		if err != nil {
+			return err
+		}
+		task.SeriesID, task.Occurrence = task.ID, 1
+	}
+	actor := UserFromContext(ctx)
+	event := models.TaskEvent{Type: models.EventTaskCreated, TaskID: task.ID, Task: task}
+	if watchable(actor) {
+		if err = addWatchers(ctx, tx, dbID, actor); err != nil {
+			return err
+		}
+		event.Watchers = []string{actor}
+	}
+	if err = writeOutbox(ctx, tx, event); err != nil {
+		return err
+	}
+	assigned := taskNotification(models.NotificationTaskAssigned, task, actor, "")
+	if err = notifyUsers(ctx, tx, assigned, task.Assignee); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (m *TaskManager) GetByID(id string) (*models.Task, error) {
-	query := `SELECT id, title, description, status, created_at FROM tasks WHERE id = ?`
-	row := m.DB.QueryRow(query, id)
+func (m *TaskManager) GetByID(ctx context.Context, id string) (_ *models.Task, err error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = ?`
+	ctx, end := m.observe(ctx, "TaskManager.GetByID", query)
+	defer func() { end(err) }()
 
-	task := models.Task{}
-	err := row.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.CreatedAt)
+	task, err := scanTask(m.DB.QueryRowContext(ctx, query, id))
 	if err != nil {
-		return &task, err
+		return &models.Task{}, err
 	}
 
 	return &task, nil
 }
 
-func (m *TaskManager) Update(task *models.Task) error {
-	query := `UPDATE tasks SET title = ?, description = ?, status = ? WHERE id = ?`
-	rows, err := m.DB.Exec(query, task.Title, task.Description, task.Status, task.ID)
+func scanTask(row rowScanner) (models.Task, error) {
+	var (
+		task     models.Task
+		dueAt    sql.NullTime
+		seriesID sql.NullString
+		sprintID sql.NullString
+	)
+	err := row.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.CreatedAt,
+		&dueAt, &task.Recurrence, &seriesID, &task.Occurrence, &task.Assignee, &task.Rank, &task.StoryPoints, &sprintID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	if dueAt.Valid {
+		task.DueAt = &dueAt.Time
+	}
+	task.SeriesID = seriesID.String
+	task.SprintID = sprintID.String
+	return task, nil
+}
+
+func (m *TaskManager) GetByIDs(ctx context.Context, ids []string) (_ []models.Task, err error) {
+	if len(ids) == 0 {
+		return []models.Task{}, nil
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id IN (` + placeholders(len(ids)) + `)`
+	ctx, end := m.observe(ctx, "TaskManager.GetByIDs", query)
+	defer func() { end(err) }()
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]models.Task, 0, len(ids))
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// placeholders returns n comma-separated bind parameters for an IN clause.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// Update changes the task and records its outbox events in one transaction,
+// see recordUpdate.
+// The creation time, series and rank of the stored task are set on task,
+// giving the tasks that become recurring a series of their own. It returns
+// ErrSprintNotFound when the sprint of the task doesn't exist.
+func (m *TaskManager) Update(ctx context.Context, task *models.Task) (err error) {
+	query := `UPDATE tasks SET title = ?, description = ?, status = ?, due_at = ?, recurrence = ?, series_id = ?, occurrence = ?, assignee = ?, story_points = ?, sprint_id = ?, reminded_at = NULL WHERE id = ?`
+	ctx, end := m.observe(ctx, "TaskManager.Update", query)
+	defer func() { end(err) }()
+	defer m.stats.invalidate()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	var (
+		previousStatus, previousAssignee string
+		seriesID                         sql.NullString
+	)
+	err = tx.QueryRowContext(ctx, `SELECT status, created_at, series_id, occurrence, assignee, rank FROM tasks WHERE id = ?`, task.ID).
+		Scan(&previousStatus, &task.CreatedAt, &seriesID, &task.Occurrence, &previousAssignee, &task.Rank)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if task.Recurrence != "" && !seriesID.Valid {
+		seriesID = sql.NullString{String: task.ID, Valid: true}
+		task.Occurrence = 1
+	}
+	task.SeriesID = seriesID.String
+	if err = checkSprint(ctx, tx, task.SprintID); err != nil {
+		return err
+	}
+
+	rows, err := tx.ExecContext(ctx, query, task.Title, task.Description, task.Status, task.DueAt, task.Recurrence,
+		seriesID, task.Occurrence, task.Assignee, task.StoryPoints, nullableID(task.SprintID), task.ID)
 	if err != nil {
 		return err
 	}
@@ -91,12 +286,76 @@ func (m *TaskManager) Update(task *models.Task) error {
 	if rowsAffected == 0 {
 		return ErrNotFound
 	}
-	return err
+
+	if err = recordUpdate(ctx, tx, task, previousStatus, previousAssignee); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (m *TaskManager) Delete(id string) error {
+// recordUpdate records the outbox events and the notifications of task
+// being updated in tx: task.updated, followed by task.status_changed when the
+// status changed, both carrying the watchers of the task.
+func recordUpdate(ctx context.Context, tx *sql.Tx, task *models.Task, previousStatus, previousAssignee string) error {
+	watchers, err := taskWatchers(ctx, tx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	event := models.TaskEvent{Type: models.EventTaskUpdated, TaskID: task.ID, Task: task, Watchers: watchers}
+	if err = writeOutbox(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if task.Status != previousStatus {
+		event = models.TaskEvent{Type: models.EventTaskStatusChanged, TaskID: task.ID, Task: task, PreviousStatus: previousStatus, Watchers: watchers}
+		if err = writeOutbox(ctx, tx, event); err != nil {
+			return err
+		}
+	}
+
+	// The previous assignee and the watchers hear of the change, a new
+	// assignee of the assignment.
+	actor := UserFromContext(ctx)
+	if task.Assignee != previousAssignee {
+		assigned := taskNotification(models.NotificationTaskAssigned, task, actor, "")
+		if err = notifyUsers(ctx, tx, assigned, task.Assignee); err != nil {
+			return err
+		}
+	}
+	kind := models.EventTaskUpdated
+	if task.Status != previousStatus {
+		kind = models.EventTaskStatusChanged
+	}
+	notification := taskNotification(kind, task, actor, previousStatus)
+	return notifyUsers(ctx, tx, notification, append([]string{previousAssignee}, watchers...)...)
+}
+
+// Delete removes the task, its watchers and its reminders, stops the timers
+// running on it and records its outbox event in one transaction.
+func (m *TaskManager) Delete(ctx context.Context, id string) (err error) {
 	query := `DELETE FROM tasks WHERE id = ?`
-	rows, err := m.DB.Exec(query, id)
+	ctx, end := m.observe(ctx, "TaskManager.Delete", query)
+	defer func() { end(err) }()
+	defer m.stats.invalidate()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	deleted := models.Task{ID: id}
+	err = tx.QueryRowContext(ctx, `SELECT title, assignee FROM tasks WHERE id = ?`, id).Scan(&deleted.Title, &deleted.Assignee)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -110,12 +369,34 @@ func (m *TaskManager) Delete(id string) error {
 		return ErrNotFound
 	}
 
-	return err
+	watchers, err := removeWatchers(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err = stopTimers(ctx, tx, id, time.Now()); err != nil {
+		return err
+	}
+	if err = removeReminders(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err = writeOutbox(ctx, tx, models.TaskEvent{Type: models.EventTaskDeleted, TaskID: id, Watchers: watchers}); err != nil {
+		return err
+	}
+	notification := taskNotification(models.EventTaskDeleted, &deleted, UserFromContext(ctx), "")
+	if err = notifyUsers(ctx, tx, notification, append([]string{deleted.Assignee}, watchers...)...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (m *TaskManager) GetAll() ([]models.Task, error) {
-	query := `SELECT id, title, description, status, created_at FROM tasks`
-	rows, err := m.DB.Query(query)
+func (m *TaskManager) GetAll(ctx context.Context) (_ []models.Task, err error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks`
+	ctx, end := m.observe(ctx, "TaskManager.GetAll", query)
+	defer func() { end(err) }()
+
+	rows, err := m.DB.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -123,8 +404,41 @@ func (m *TaskManager) GetAll() ([]models.Task, error) {
 
 	tasks := make([]models.Task, 0)
 	for rows.Next() {
-		var task models.Task
-		err = rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.CreatedAt)
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (m *TaskManager) GetPage(ctx context.Context, after string, limit int) (_ []models.Task, err error) {
+	afterID := int64(0)
+	if after != "" {
+		if afterID, err = strconv.ParseInt(after, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id > ? ORDER BY id LIMIT ?`
+	ctx, end := m.observe(ctx, "TaskManager.GetPage", query)
+	defer func() { end(err) }()
+
+	rows, err := m.DB.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]models.Task, 0, limit)
+	for rows.Next() {
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
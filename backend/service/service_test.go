@@ -1,13 +1,21 @@
 package service
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/logging"
 	"github.com/saarzur123/task-management/backend/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"log/slog"
 	"testing"
 	"time"
 )
@@ -19,8 +27,21 @@ func TestDB(t *testing.T) {
 
 var (
 	errMock = errors.New("mock error")
+	ctx     = context.Background()
 )
 
+// newSQLiteDB returns a migrated in-memory database, for the tests checking
+// what the aggregate queries compute rather than their text.
+func newSQLiteDB() *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	Expect(err).To(Succeed())
+	// Every connection opens its own in-memory database.
+	db.SetMaxOpenConns(1)
+	DeferCleanup(db.Close)
+	Expect(Migrate(ctx, db)).To(Succeed())
+	return db
+}
+
 var _ = Describe("TaskManager", func() {
 	const (
 		taskID1 = "1"
@@ -39,7 +60,7 @@ var _ = Describe("TaskManager", func() {
 			Description: "This is a test task",
 			Status:      "pending",
 		}
-		columns = []string{"id", "title", "description", "status", "created_at"}
+		columns = []string{"id", "title", "description", "status", "created_at", "due_at", "recurrence", "series_id", "occurrence", "assignee", "rank", "story_points", "sprint_id"}
 		err     error
 	)
 
@@ -53,11 +74,34 @@ var _ = Describe("TaskManager", func() {
 		database.Close()
 	})
 
+	expectOutbox := func(eventType string) {
+		mockSQL.ExpectExec("INSERT INTO outbox").
+			WithArgs(eventType, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	expectWatchers := func(query string, users ...string) {
+		rows := sqlmock.NewRows([]string{"user_id"})
+		for _, user := range users {
+			rows.AddRow(user)
+		}
+		mockSQL.ExpectQuery(query).WithArgs(taskID1).WillReturnRows(rows)
+	}
+
+	expectTaskCleanup := func() {
+		mockSQL.ExpectExec(`UPDATE worklogs SET duration = (.+), running = 0 WHERE task_id = \? AND running = 1`).
+			WithArgs(sqlmock.AnyArg(), taskID1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectExec(`DELETE FROM reminders WHERE task_id = \?`).WithArgs(taskID1).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
 	Describe("Create", func() {
 		It("succeeds to create new task when database is empty", func() {
-			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(task.Title, task.Description, task.Status, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(task.Title, task.Description, task.Status, sqlmock.AnyArg(), nil, "", "", 0, nil).WillReturnResult(sqlmock.NewResult(1, 1))
+			expectOutbox(models.EventTaskCreated)
+			mockSQL.ExpectCommit()
 
-			err := manager.Create(&task)
+			err := manager.Create(ctx, &task)
 			Expect(err).To(Succeed())
 			Expect(task.ID).To(Equal("1"), "defined by the database")
 			Expect(task.CreatedAt).ToNot(BeZero())
@@ -65,13 +109,19 @@ var _ = Describe("TaskManager", func() {
 		})
 
 		It("succeeds to create new task when database is not empty", func() {
-			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(oldTask.Title, oldTask.Description, oldTask.Status, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
-			err := manager.Create(&oldTask)
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(oldTask.Title, oldTask.Description, oldTask.Status, sqlmock.AnyArg(), nil, "", "", 0, nil).WillReturnResult(sqlmock.NewResult(1, 1))
+			expectOutbox(models.EventTaskCreated)
+			mockSQL.ExpectCommit()
+			err := manager.Create(ctx, &oldTask)
 			Expect(err).To(Succeed())
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 
-			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(task.Title, task.Description, task.Status, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(2, 1))
-			err = manager.Create(&task)
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(task.Title, task.Description, task.Status, sqlmock.AnyArg(), nil, "", "", 0, nil).WillReturnResult(sqlmock.NewResult(2, 1))
+			expectOutbox(models.EventTaskCreated)
+			mockSQL.ExpectCommit()
+			err = manager.Create(ctx, &task)
 			Expect(err).To(Succeed())
 			Expect(task.ID).To(Equal("2"), "defined by the database")
 			Expect(task.CreatedAt).ToNot(BeZero())
@@ -79,32 +129,70 @@ var _ = Describe("TaskManager", func() {
 		})
 
 		It("returns error and doesn't create new task when failed on exec", func() {
-			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(task.Title, task.Description, task.Status, sqlmock.AnyArg()).WillReturnError(errMock)
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(task.Title, task.Description, task.Status, sqlmock.AnyArg(), nil, "", "", 0, nil).WillReturnError(errMock)
+			mockSQL.ExpectRollback()
 
-			err := manager.Create(&task)
+			err := manager.Create(ctx, &task)
 			Expect(err).To(MatchError(errMock))
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 		})
 
 		It("returns error and doesn't create new task when failed on getting LastInsertId", func() {
-			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(task.Title, task.Description, task.Status, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewErrorResult(errMock))
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(task.Title, task.Description, task.Status, sqlmock.AnyArg(), nil, "", "", 0, nil).WillReturnResult(sqlmock.NewErrorResult(errMock))
+			mockSQL.ExpectRollback()
 
-			err := manager.Create(&task)
+			err := manager.Create(ctx, &task)
 			Expect(err).To(MatchError(errMock))
 			Expect(task.ID).To(Equal("2"), "defined by the database - last inserted")
 			Expect(task.CreatedAt).ToNot(BeZero())
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 		})
+
+		It("rolls back the task when the outbox write fails", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(task.Title, task.Description, task.Status, sqlmock.AnyArg(), nil, "", "", 0, nil).WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectExec("INSERT INTO outbox").WithArgs(models.EventTaskCreated, "1", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg()).WillReturnError(errMock)
+			mockSQL.ExpectRollback()
+
+			err := manager.Create(ctx, &task)
+			Expect(err).To(MatchError(errMock))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("plans the task in its sprint", func() {
+			planned := models.Task{Title: "Task", Status: "pending", StoryPoints: 5, SprintID: "2"}
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM sprints WHERE id = \?\)`).WithArgs("2").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs("Task", "", "pending", sqlmock.AnyArg(), nil, "", "", 5, "2").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			expectOutbox(models.EventTaskCreated)
+			mockSQL.ExpectCommit()
+
+			Expect(manager.Create(ctx, &planned)).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrSprintNotFound for a missing sprint", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT EXISTS`).WithArgs("9").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			mockSQL.ExpectRollback()
+
+			Expect(manager.Create(ctx, &models.Task{Title: "Task", SprintID: "9"})).To(MatchError(ErrSprintNotFound))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
 	})
 
 	Describe("GetByID", func() {
 		It("succeeds to get task by ID", func() {
-			mockSQL.ExpectQuery("SELECT id, title, description, status, created_at FROM tasks").
+			mockSQL.ExpectQuery("SELECT " + taskColumns + " FROM tasks").
 				WithArgs(taskID1).
 				WillReturnRows(sqlmock.NewRows(columns).
-					AddRow(taskID1, task.Title, task.Description, task.Status, task.CreatedAt))
+					AddRow(taskID1, task.Title, task.Description, task.Status, task.CreatedAt, nil, "", nil, 0, "", "", 0, nil))
 
-			resultTask, err := manager.GetByID(taskID1)
+			resultTask, err := manager.GetByID(ctx, taskID1)
 			Expect(err).To(Succeed())
 			Expect(resultTask.ID).To(Equal(taskID1), "defined by the database")
 			Expect(resultTask.CreatedAt).NotTo(BeZero())
@@ -115,26 +203,61 @@ var _ = Describe("TaskManager", func() {
 		})
 
 		It("should return an error if the task is not found", func() {
-			mockSQL.ExpectQuery("SELECT id, title, description, status, created_at FROM tasks").
+			mockSQL.ExpectQuery("SELECT " + taskColumns + " FROM tasks").
 				WithArgs(taskID1).
 				WillReturnError(sql.ErrNoRows)
 
-			task, err := manager.GetByID(taskID1)
+			task, err := manager.GetByID(ctx, taskID1)
 			Expect(err).To(MatchError(sql.ErrNoRows))
 			Expect(task).To(Equal(&models.Task{}))
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 		})
 	})
 
+	Describe("GetByIDs", func() {
+		It("gets the tasks in one query", func() {
+			mockSQL.ExpectQuery(`SELECT `+taskColumns+` FROM tasks WHERE id IN \(\?, \?\)`).
+				WithArgs(taskID1, "9").
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(taskID1, task.Title, task.Description, task.Status, task.CreatedAt, nil, "", nil, 0, "", "", 0, nil))
+
+			tasks, err := manager.GetByIDs(ctx, []string{taskID1, "9"})
+			Expect(err).To(Succeed())
+			Expect(tasks).To(HaveLen(1))
+			Expect(tasks[0].ID).To(Equal(taskID1))
+			Expect(tasks[0].Title).To(Equal(task.Title))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("doesn't query without IDs", func() {
+			tasks, err := manager.GetByIDs(ctx, nil)
+			Expect(err).To(Succeed())
+			Expect(tasks).To(BeEmpty())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns an error when the query fails", func() {
+			mockSQL.ExpectQuery("SELECT (.+) FROM tasks WHERE id IN").WillReturnError(errMock)
+
+			_, err := manager.GetByIDs(ctx, []string{taskID1})
+			Expect(err).To(MatchError(errMock))
+		})
+	})
+
 	Describe("Update", func() {
+		const updateQuery = `UPDATE tasks SET title = \?, description = \?, status = \?, due_at = \?, recurrence = \?, series_id = \?, occurrence = \?, assignee = \?, story_points = \?, sprint_id = \?, reminded_at = NULL WHERE id = \?`
 		var (
-			updatedTask = &models.Task{Title: task.Title, Description: task.Description, Status: task.Status, CreatedAt: oldTask.CreatedAt, ID: taskID1}
+			updateColumns = []string{"status", "created_at", "series_id", "occurrence", "assignee", "rank"}
+			updatedTask   = &models.Task{Title: task.Title, Description: task.Description, Status: task.Status, CreatedAt: oldTask.CreatedAt, ID: taskID1}
 		)
 
 		It("succeeds to update task", func() {
 			// fill data
-			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(oldTask.Title, oldTask.Description, oldTask.Status, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
-			err := manager.Create(&oldTask)
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(oldTask.Title, oldTask.Description, oldTask.Status, sqlmock.AnyArg(), nil, "", "", 0, nil).WillReturnResult(sqlmock.NewResult(1, 1))
+			expectOutbox(models.EventTaskCreated)
+			mockSQL.ExpectCommit()
+			err := manager.Create(ctx, &oldTask)
 			Expect(err).To(Succeed())
 			Expect(oldTask.ID).To(Equal("1"), "defined by the database")
 			Expect(oldTask.CreatedAt).ToNot(BeZero())
@@ -143,11 +266,18 @@ var _ = Describe("TaskManager", func() {
 			updatedTask.CreatedAt = oldTask.CreatedAt
 
 			// update
-			mockSQL.ExpectExec(`UPDATE tasks SET title = \?, description = \?, status = \? WHERE id = \?`).
-				WithArgs(updatedTask.Title, updatedTask.Description, updatedTask.Status, updatedTask.ID).
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT status, created_at, series_id, occurrence, assignee, rank FROM tasks WHERE id = \?`).
+				WithArgs(updatedTask.ID).
+				WillReturnRows(sqlmock.NewRows(updateColumns).AddRow(oldTask.Status, oldTask.CreatedAt, nil, 0, "", ""))
+			mockSQL.ExpectExec(updateQuery).
+				WithArgs(updatedTask.Title, updatedTask.Description, updatedTask.Status, nil, "", nil, 0, "", 0, nil, updatedTask.ID).
 				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectWatchers(`SELECT user_id FROM task_watchers WHERE task_id = \?`)
+			expectOutbox(models.EventTaskUpdated)
+			mockSQL.ExpectCommit()
 
-			err = manager.Update(updatedTask)
+			err = manager.Update(ctx, updatedTask)
 			Expect(err).To(Succeed())
 			Expect(updatedTask.ID).To(Equal(oldTask.ID), "shouldn't be changed")
 			Expect(updatedTask.CreatedAt).To(Equal(oldTask.CreatedAt), "shouldn't be changed")
@@ -157,32 +287,84 @@ var _ = Describe("TaskManager", func() {
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 		})
 
+		It("records a status change after the update, with the watchers", func() {
+			changedTask := &models.Task{ID: "1", Title: "Task 1", Status: "done"}
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT status, created_at, series_id, occurrence, assignee, rank FROM tasks WHERE id = \?`).
+				WithArgs(changedTask.ID).
+				WillReturnRows(sqlmock.NewRows(updateColumns).AddRow("pending", oldTask.CreatedAt, nil, 0, "", ""))
+			mockSQL.ExpectExec("UPDATE tasks").
+				WithArgs(changedTask.Title, changedTask.Description, changedTask.Status, nil, "", nil, 0, "", 0, nil, changedTask.ID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectWatchers(`SELECT user_id FROM task_watchers WHERE task_id = \?`, "qa")
+			mockSQL.ExpectExec("INSERT INTO outbox").
+				WithArgs(models.EventTaskUpdated, "1", sqlmock.AnyArg(), sqlmock.AnyArg(), sql.NullString{String: `["qa"]`, Valid: true}, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectExec("INSERT INTO outbox").
+				WithArgs(models.EventTaskStatusChanged, "1", sqlmock.AnyArg(), sql.NullString{String: "pending", Valid: true}, sql.NullString{String: `["qa"]`, Valid: true}, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(2, 1))
+			mockSQL.ExpectExec("INSERT INTO notifications").
+				WithArgs("qa", "1", models.EventTaskStatusChanged, `Task "Task 1" moved from pending to done`, AnonymousUser, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectCommit()
+
+			Expect(manager.Update(ctx, changedTask)).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
 		It("returns an error if the update query fails", func() {
-			mockSQL.ExpectExec(`UPDATE tasks SET title = \?, description = \?, status = \? WHERE id = \?`).
-				WithArgs(updatedTask.Title, updatedTask.Description, updatedTask.Status, updatedTask.ID).
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT status, created_at, series_id, occurrence, assignee, rank FROM tasks WHERE id = \?`).
+				WithArgs(updatedTask.ID).
+				WillReturnRows(sqlmock.NewRows(updateColumns).AddRow(oldTask.Status, oldTask.CreatedAt, nil, 0, "", ""))
+			mockSQL.ExpectExec(updateQuery).
+				WithArgs(updatedTask.Title, updatedTask.Description, updatedTask.Status, nil, "", nil, 0, "", 0, nil, updatedTask.ID).
 				WillReturnError(errMock)
+			mockSQL.ExpectRollback()
 
-			err := manager.Update(updatedTask)
+			err := manager.Update(ctx, updatedTask)
 			Expect(err).To(MatchError(errMock))
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 		})
 
 		It("returns an error when failed on getting rows affected", func() {
-			mockSQL.ExpectExec(`UPDATE tasks SET title = \?, description = \?, status = \? WHERE id = \?`).
-				WithArgs(updatedTask.Title, updatedTask.Description, updatedTask.Status, updatedTask.ID).
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT status, created_at, series_id, occurrence, assignee, rank FROM tasks WHERE id = \?`).
+				WithArgs(updatedTask.ID).
+				WillReturnRows(sqlmock.NewRows(updateColumns).AddRow(oldTask.Status, oldTask.CreatedAt, nil, 0, "", ""))
+			mockSQL.ExpectExec(updateQuery).
+				WithArgs(updatedTask.Title, updatedTask.Description, updatedTask.Status, nil, "", nil, 0, "", 0, nil, updatedTask.ID).
 				WillReturnResult(sqlmock.NewErrorResult(errMock))
+			mockSQL.ExpectRollback()
 
-			err := manager.Update(updatedTask)
+			err := manager.Update(ctx, updatedTask)
 			Expect(err).To(MatchError(errMock))
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 		})
 
 		It("returns an error when no rows were updated", func() {
-			mockSQL.ExpectExec(`UPDATE tasks SET title = \?, description = \?, status = \? WHERE id = \?`).
-				WithArgs(updatedTask.Title, updatedTask.Description, updatedTask.Status, updatedTask.ID).
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT status, created_at, series_id, occurrence, assignee, rank FROM tasks WHERE id = \?`).
+				WithArgs(updatedTask.ID).
+				WillReturnRows(sqlmock.NewRows(updateColumns).AddRow(oldTask.Status, oldTask.CreatedAt, nil, 0, "", ""))
+			mockSQL.ExpectExec(updateQuery).
+				WithArgs(updatedTask.Title, updatedTask.Description, updatedTask.Status, nil, "", nil, 0, "", 0, nil, updatedTask.ID).
 				WillReturnResult(sqlmock.NewResult(0, 0))
+			mockSQL.ExpectRollback()
+
+			err := manager.Update(ctx, updatedTask)
+			Expect(err).To(MatchError(ErrNotFound))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns an error when the task doesn't exist", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT status, created_at, series_id, occurrence, assignee, rank FROM tasks WHERE id = \?`).
+				WithArgs(updatedTask.ID).
+				WillReturnError(sql.ErrNoRows)
+			mockSQL.ExpectRollback()
 
-			err := manager.Update(updatedTask)
+			err := manager.Update(ctx, updatedTask)
 			Expect(err).To(MatchError(ErrNotFound))
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 		})
@@ -191,49 +373,75 @@ var _ = Describe("TaskManager", func() {
 	Describe("Delete", func() {
 		It("succeeds to delete task", func() {
 			// fill data
-			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(oldTask.Title, oldTask.Description, oldTask.Status, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
-			err := manager.Create(&oldTask)
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("INSERT INTO tasks").WithArgs(oldTask.Title, oldTask.Description, oldTask.Status, sqlmock.AnyArg(), nil, "", "", 0, nil).WillReturnResult(sqlmock.NewResult(1, 1))
+			expectOutbox(models.EventTaskCreated)
+			mockSQL.ExpectCommit()
+			err := manager.Create(ctx, &oldTask)
 			Expect(err).To(Succeed())
 			Expect(oldTask.ID).To(Equal("1"), "defined by the database")
 			Expect(oldTask.CreatedAt).ToNot(BeZero())
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 
 			// delete
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT title, assignee FROM tasks WHERE id = \?`).
+				WithArgs(taskID1).
+				WillReturnRows(sqlmock.NewRows([]string{"title", "assignee"}).AddRow(oldTask.Title, ""))
 			mockSQL.ExpectExec(`DELETE FROM tasks WHERE id = \?`).
 				WithArgs(taskID1).
 				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectWatchers(`DELETE FROM task_watchers WHERE task_id = \? RETURNING user_id`)
+			expectTaskCleanup()
+			expectOutbox(models.EventTaskDeleted)
+			mockSQL.ExpectCommit()
 
-			err = manager.Delete(taskID1)
+			err = manager.Delete(ctx, taskID1)
 			Expect(err).To(Succeed())
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 		})
 
 		It("returns an error when fails on exec", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT title, assignee FROM tasks WHERE id = \?`).
+				WithArgs(taskID1).
+				WillReturnRows(sqlmock.NewRows([]string{"title", "assignee"}).AddRow(oldTask.Title, ""))
 			mockSQL.ExpectExec(`DELETE FROM tasks WHERE id = \?`).
 				WithArgs(taskID1).
 				WillReturnError(errMock)
+			mockSQL.ExpectRollback()
 
-			err := manager.Delete(taskID1)
+			err := manager.Delete(ctx, taskID1)
 			Expect(err).To(MatchError(errMock))
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 		})
 
 		It("returns an error when failed on getting rows affected", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT title, assignee FROM tasks WHERE id = \?`).
+				WithArgs(taskID1).
+				WillReturnRows(sqlmock.NewRows([]string{"title", "assignee"}).AddRow(oldTask.Title, ""))
 			mockSQL.ExpectExec(`DELETE FROM tasks WHERE id = \?`).
 				WithArgs(taskID1).
 				WillReturnResult(sqlmock.NewErrorResult(errMock))
+			mockSQL.ExpectRollback()
 
-			err := manager.Delete(taskID1)
+			err := manager.Delete(ctx, taskID1)
 			Expect(err).To(MatchError(errMock))
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 		})
 
 		It("returns an error when no rows were deleted", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT title, assignee FROM tasks WHERE id = \?`).
+				WithArgs(taskID1).
+				WillReturnRows(sqlmock.NewRows([]string{"title", "assignee"}).AddRow(oldTask.Title, ""))
 			mockSQL.ExpectExec(`DELETE FROM tasks WHERE id = \?`).
 				WithArgs(taskID1).
 				WillReturnResult(sqlmock.NewResult(0, 0))
+			mockSQL.ExpectRollback()
 
-			err := manager.Delete(taskID1)
+			err := manager.Delete(ctx, taskID1)
 			Expect(err).To(MatchError(ErrNotFound))
 			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
 		})
@@ -243,19 +451,19 @@ var _ = Describe("TaskManager", func() {
 		var (
 			time1 = time.Now()
 			time2 = time.Now()
-			task1 = models.Task{"1", "Task 1", "Description 1", "pending", time1}
-			task2 = models.Task{"2", "Task 2", "Description 2", "completed", time2}
-			row1  = []driver.Value{"1", "Task 1", "Description 1", "pending", time1}
+			task1 = models.Task{ID: "1", Title: "Task 1", Description: "Description 1", Status: "pending", CreatedAt: time1}
+			task2 = models.Task{ID: "2", Title: "Task 2", Description: "Description 2", Status: "completed", CreatedAt: time2}
+			row1  = []driver.Value{"1", "Task 1", "Description 1", "pending", time1, nil, "", nil, 0, "", "", 0, nil}
 		)
 
 		It("succeeds to get all tasks", func() {
 			taskRows := sqlmock.NewRows(columns).
 				AddRow(row1...).
-				AddRow("2", "Task 2", "Description 2", "completed", time2)
-			mockSQL.ExpectQuery(`SELECT id, title, description, status, created_at FROM tasks`).
+				AddRow("2", "Task 2", "Description 2", "completed", time2, nil, "", nil, 0, "", "", 0, nil)
+			mockSQL.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks`).
 				WillReturnRows(taskRows)
 
-			tasks, err := manager.GetAll()
+			tasks, err := manager.GetAll(ctx)
 			Expect(err).To(Succeed())
 			Expect(tasks).To(HaveLen(2))
 			Expect(tasks[0]).To(Equal(task1))
@@ -263,19 +471,19 @@ var _ = Describe("TaskManager", func() {
 		})
 
 		It("returns an empty slice when no tasks exist", func() {
-			mockSQL.ExpectQuery(`SELECT id, title, description, status, created_at FROM tasks`).
+			mockSQL.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks`).
 				WillReturnRows(sqlmock.NewRows(columns))
 
-			tasks, err := manager.GetAll()
+			tasks, err := manager.GetAll(ctx)
 			Expect(err).To(Succeed())
 			Expect(tasks).To(BeEmpty())
 		})
 
 		It("returns an error when fails on exec query", func() {
-			mockSQL.ExpectQuery(`SELECT id, title, description, status, created_at FROM tasks`).
+			mockSQL.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks`).
 				WillReturnError(errMock)
 
-			tasks, err := manager.GetAll()
+			tasks, err := manager.GetAll(ctx)
 			Expect(err).To(MatchError(errMock))
 			Expect(tasks).To(BeNil())
 		})
@@ -283,11 +491,11 @@ var _ = Describe("TaskManager", func() {
 		It("returns an error when row scanning fails", func() {
 			taskRowsFail := sqlmock.NewRows(columns).
 				AddRow(row1...).
-				AddRow(nil, "Task 2", "Description 2", "completed", time.Now())
-			mockSQL.ExpectQuery(`SELECT id, title, description, status, created_at FROM tasks`).
+				AddRow(nil, "Task 2", "Description 2", "completed", time.Now(), nil, "", nil, 0, "", "", 0, nil)
+			mockSQL.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks`).
 				WillReturnRows(taskRowsFail)
 
-			tasks, err := manager.GetAll()
+			tasks, err := manager.GetAll(ctx)
 			Expect(err).To(HaveOccurred())
 			Expect(tasks).To(BeNil())
 		})
@@ -295,14 +503,107 @@ var _ = Describe("TaskManager", func() {
 		It("returns an error when rows.Err() returns an error", func() {
 			taskRows := sqlmock.NewRows(columns).
 				AddRow(row1...)
-			mockSQL.ExpectQuery(`SELECT id, title, description, status, created_at FROM tasks`).
+			mockSQL.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks`).
 				WillReturnRows(taskRows).
 				WillReturnError(errMock)
 
-			tasks, err := manager.GetAll()
+			tasks, err := manager.GetAll(ctx)
+			Expect(err).To(MatchError(errMock))
+			Expect(tasks).To(BeNil())
+		})
+	})
+
+	Describe("GetPage", func() {
+		const pageQuery = `SELECT ` + taskColumns + ` FROM tasks WHERE id > \? ORDER BY id LIMIT \?`
+
+		It("returns the tasks after the given ID", func() {
+			createdAt := time.Now()
+			mockSQL.ExpectQuery(pageQuery).
+				WithArgs(int64(2), 2).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("3", "Task 3", "Description 3", "pending", createdAt, nil, "", nil, 0, "", "", 0, nil).
+					AddRow("4", "Task 4", "Description 4", "done", createdAt, nil, "", nil, 0, "", "", 0, nil))
+
+			tasks, err := manager.GetPage(ctx, "2", 2)
+			Expect(err).To(Succeed())
+			Expect(tasks).To(Equal([]models.Task{
+				{ID: "3", Title: "Task 3", Description: "Description 3", Status: "pending", CreatedAt: createdAt},
+				{ID: "4", Title: "Task 4", Description: "Description 4", Status: "done", CreatedAt: createdAt},
+			}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("starts from the first task without after", func() {
+			mockSQL.ExpectQuery(pageQuery).
+				WithArgs(int64(0), 10).
+				WillReturnRows(sqlmock.NewRows(columns))
+
+			tasks, err := manager.GetPage(ctx, "", 10)
+			Expect(err).To(Succeed())
+			Expect(tasks).To(BeEmpty())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("rejects a malformed after", func() {
+			tasks, err := manager.GetPage(ctx, "abc", 10)
+			Expect(err).To(HaveOccurred())
+			Expect(tasks).To(BeNil())
+		})
+
+		It("returns an error when fails on exec query", func() {
+			mockSQL.ExpectQuery(pageQuery).
+				WithArgs(int64(0), 10).
+				WillReturnError(errMock)
+
+			tasks, err := manager.GetPage(ctx, "", 10)
 			Expect(err).To(MatchError(errMock))
 			Expect(tasks).To(BeNil())
 		})
 	})
 
+	Describe("slow queries", func() {
+		It("logs statements above the threshold with the request logger", func() {
+			var output bytes.Buffer
+			logger := logging.New(&output, slog.LevelInfo).With(slog.String("request_id", "req-1"))
+			manager.SlowQueryThreshold = time.Nanosecond
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT title, assignee FROM tasks WHERE id = \?`).
+				WithArgs(taskID1).
+				WillReturnRows(sqlmock.NewRows([]string{"title", "assignee"}).AddRow(oldTask.Title, ""))
+			mockSQL.ExpectExec(`DELETE FROM tasks WHERE id = \?`).
+				WithArgs(taskID1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectWatchers(`DELETE FROM task_watchers WHERE task_id = \? RETURNING user_id`)
+			expectTaskCleanup()
+			expectOutbox(models.EventTaskDeleted)
+			mockSQL.ExpectCommit()
+
+			err := manager.Delete(logging.WithLogger(ctx, logger), taskID1)
+			Expect(err).To(Succeed())
+			Expect(output.String()).To(ContainSubstring(`"msg":"slow query"`))
+			Expect(output.String()).To(ContainSubstring(`"request_id":"req-1"`))
+			Expect(output.String()).To(ContainSubstring(`"query":"DELETE FROM tasks WHERE id = ?"`))
+		})
+	})
+	Describe("tracing", func() {
+		It("creates a child span per call with the statement", func() {
+			exporter := tracetest.NewInMemoryExporter()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+			parentCtx, parent := otel.Tracer("test").Start(ctx, "GET /tasks/{id}")
+			mockSQL.ExpectQuery("SELECT " + taskColumns + " FROM tasks").
+				WithArgs(taskID1).
+				WillReturnError(sql.ErrNoRows)
+
+			_, err := manager.GetByID(parentCtx, taskID1)
+			Expect(err).To(MatchError(sql.ErrNoRows))
+			parent.End()
+
+			spans := exporter.GetSpans()
+			Expect(spans).To(HaveLen(2))
+			Expect(spans[0].Name).To(Equal("TaskManager.GetByID"))
+			Expect(spans[0].Parent.SpanID()).To(Equal(parent.SpanContext().SpanID()))
+			Expect(spans[0].Attributes).To(ContainElement(attribute.String("db.query.text",
+				"SELECT "+taskColumns+" FROM tasks WHERE id = ?")))
+		})
+	})
 })
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saarzur123/task-management/backend/models"
+)
+
+// SprintPlanner plans the tasks in sprints and follows the story points
+// committed to them.
+type SprintPlanner interface {
+	// CreateSprint inserts the sprint and sets its ID.
+	CreateSprint(ctx context.Context, sprint *models.Sprint) error
+	GetSprint(ctx context.Context, id string) (*models.Sprint, error)
+	// ListSprints returns the sprints in the order they start.
+	ListSprints(ctx context.Context) ([]models.Sprint, error)
+	UpdateSprint(ctx context.Context, sprint *models.Sprint) error
+	// DeleteSprint removes the sprint, its tasks going back to the backlog.
+	DeleteSprint(ctx context.Context, id string) error
+	// SprintTasks returns the tasks of the sprint ordered by ID.
+	SprintTasks(ctx context.Context, id string) ([]models.Task, error)
+	// SprintProgress sums the story points committed to the sprint and
+	// those completed.
+	SprintProgress(ctx context.Context, id string) (*models.SprintProgress, error)
+	// CarryOver moves the tasks of sprint fromID not done yet to sprint
+	// toID, or to the sprint starting next when toID is empty, in one
+	// transaction. It returns ErrSprintNotFound when toID doesn't exist and
+	// ErrNoNextSprint when no sprint starts after fromID.
+	CarryOver(ctx context.Context, fromID, toID string) (*models.CarryOver, error)
+}
+
+type SprintManager struct {
+	DB *sql.DB
+}
+
+const sprintColumns = `id, name, goal, start_date, end_date, capacity`
+
+var (
+	ErrSprintNotFound = errors.New("SprintNotFound")
+	ErrNoNextSprint   = errors.New("NoNextSprint")
+)
+
+func (m *SprintManager) CreateSprint(ctx context.Context, sprint *models.Sprint) error {
+	row, err := m.DB.ExecContext(ctx, `INSERT INTO sprints (name, goal, start_date, end_date, capacity) VALUES (?, ?, ?, ?, ?)`,
+		sprint.Name, sprint.Goal, sprint.StartDate, sprint.EndDate, sprint.Capacity)
+	if err != nil {
+		return err
+	}
+	sprint.ID, err = insertedID(row)
+	return err
+}
+
+func (m *SprintManager) GetSprint(ctx context.Context, id string) (*models.Sprint, error) {
+	sprint, err := scanSprint(m.DB.QueryRowContext(ctx, `SELECT `+sprintColumns+` FROM sprints WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+func (m *SprintManager) ListSprints(ctx context.Context) ([]models.Sprint, error) {
+	rows, err := m.DB.QueryContext(ctx, `SELECT `+sprintColumns+` FROM sprints ORDER BY start_date, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sprints := make([]models.Sprint, 0)
+	for rows.Next() {
+		sprint, err := scanSprint(rows)
+		if err != nil {
+			return nil, err
+		}
+		sprints = append(sprints, sprint)
+	}
+	return sprints, rows.Err()
+}
+
+func (m *SprintManager) UpdateSprint(ctx context.Context, sprint *models.Sprint) error {
+	rows, err := m.DB.ExecContext(ctx, `UPDATE sprints SET name = ?, goal = ?, start_date = ?, end_date = ?, capacity = ? WHERE id = ?`,
+		sprint.Name, sprint.Goal, sprint.StartDate, sprint.EndDate, sprint.Capacity, sprint.ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := rows.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSprint records the outbox events of the tasks going back to the
+// backlog in the same transaction.
+func (m *SprintManager) DeleteSprint(ctx context.Context, id string) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	rows, err := tx.ExecContext(ctx, `DELETE FROM sprints WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := rows.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE sprint_id = ? ORDER BY id`
+	if _, err = moveSprintTasks(ctx, tx, "", query, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *SprintManager) SprintTasks(ctx context.Context, id string) ([]models.Task, error) {
+	if _, err := m.GetSprint(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.DB.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE sprint_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]models.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// SprintProgress counts a task as completed once its status is done.
+func (m *SprintManager) SprintProgress(ctx context.Context, id string) (*models.SprintProgress, error) {
+	sprint, err := m.GetSprint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &models.SprintProgress{SprintID: sprint.ID, Capacity: sprint.Capacity}
+	err = m.DB.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(story_points), 0), `+
+		`COALESCE(SUM(status = ?), 0), COALESCE(SUM(CASE WHEN status = ? THEN story_points ELSE 0 END), 0) `+
+		`FROM tasks WHERE sprint_id = ?`, models.StatusDone, models.StatusDone, id).
+		Scan(&progress.Tasks, &progress.Committed, &progress.DoneTasks, &progress.Completed)
+	if err != nil {
+		return nil, err
+	}
+	progress.Remaining = progress.Committed - progress.Completed
+	progress.OverCapacity = sprint.Capacity > 0 && progress.Committed > sprint.Capacity
+	return progress, nil
+}
+
+func (m *SprintManager) CarryOver(ctx context.Context, fromID, toID string) (*models.CarryOver, error) {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	var start string
+	err = tx.QueryRowContext(ctx, `SELECT start_date FROM sprints WHERE id = ?`, fromID).Scan(&start)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if toID == "" {
+		err = tx.QueryRowContext(ctx, `SELECT id FROM sprints WHERE start_date > ? ORDER BY start_date, id LIMIT 1`, start).Scan(&toID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoNextSprint
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else if err = checkSprint(ctx, tx, toID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE sprint_id = ? AND status != ? ORDER BY id`
+	tasks, err := moveSprintTasks(ctx, tx, toID, query, fromID, models.StatusDone)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	carryOver := &models.CarryOver{From: fromID, To: toID, Tasks: tasks}
+	for _, task := range tasks {
+		carryOver.Points += task.StoryPoints
+	}
+	return carryOver, nil
+}
+
+// moveSprintTasks moves the tasks selected by query to sprint toID, or to the
+// backlog when it is empty, and records their updates.
+func moveSprintTasks(ctx context.Context, tx *sql.Tx, toID, query string, args ...any) ([]models.Task, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]models.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range tasks {
+		task := &tasks[i]
+		if _, err = tx.ExecContext(ctx, `UPDATE tasks SET sprint_id = ? WHERE id = ?`, nullableID(toID), task.ID); err != nil {
+			return nil, err
+		}
+		task.SprintID = toID
+		if err = recordUpdate(ctx, tx, task, task.Status, task.Assignee); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
+// checkSprint returns ErrSprintNotFound unless the sprint exists, an empty ID
+// standing for the backlog.
+func checkSprint(ctx context.Context, tx *sql.Tx, id string) error {
+	if id == "" {
+		return nil
+	}
+	var exists bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM sprints WHERE id = ?)`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrSprintNotFound
+	}
+	return nil
+}
+
+// nullableID stores an empty ID as NULL.
+func nullableID(id string) sql.NullString {
+	return sql.NullString{String: id, Valid: id != ""}
+}
+
+func scanSprint(row rowScanner) (models.Sprint, error) {
+	var sprint models.Sprint
+	err := row.Scan(&sprint.ID, &sprint.Name, &sprint.Goal, &sprint.StartDate, &sprint.EndDate, &sprint.Capacity)
+	return sprint, err
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"time"
+)
+
+var _ = Describe("SprintManager", func() {
+	var (
+		manager    *SprintManager
+		database   *sql.DB
+		mockSQL    sqlmock.Sqlmock
+		sprintRows = []string{"id", "name", "goal", "start_date", "end_date", "capacity"}
+		columns    = []string{"id", "title", "description", "status", "created_at", "due_at", "recurrence", "series_id", "occurrence", "assignee", "rank", "story_points", "sprint_id"}
+		createdAt  = time.Now()
+		err        error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &SprintManager{DB: database}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	expectRecorded := func(taskID string) {
+		mockSQL.ExpectQuery(`SELECT user_id FROM task_watchers WHERE task_id = \?`).WithArgs(taskID).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mockSQL.ExpectExec("INSERT INTO outbox").
+			WithArgs(models.EventTaskUpdated, taskID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	It("creates a sprint", func() {
+		sprint := &models.Sprint{Name: "Sprint 1", Goal: "Ship", StartDate: "2026-01-05", EndDate: "2026-01-16", Capacity: 20}
+		mockSQL.ExpectExec(`INSERT INTO sprints \(name, goal, start_date, end_date, capacity\) VALUES \(\?, \?, \?, \?, \?\)`).
+			WithArgs("Sprint 1", "Ship", "2026-01-05", "2026-01-16", 20).
+			WillReturnResult(sqlmock.NewResult(3, 1))
+
+		Expect(manager.CreateSprint(ctx, sprint)).To(Succeed())
+		Expect(sprint.ID).To(Equal("3"))
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("lists the sprints in the order they start", func() {
+		mockSQL.ExpectQuery(`SELECT id, name, goal, start_date, end_date, capacity FROM sprints ORDER BY start_date, id`).
+			WillReturnRows(sqlmock.NewRows(sprintRows).
+				AddRow("1", "Sprint 1", "", "2026-01-05", "2026-01-16", 20).
+				AddRow("2", "Sprint 2", "", "2026-01-19", "2026-01-30", 0))
+
+		sprints, err := manager.ListSprints(ctx)
+		Expect(err).To(Succeed())
+		Expect(sprints).To(Equal([]models.Sprint{
+			{ID: "1", Name: "Sprint 1", StartDate: "2026-01-05", EndDate: "2026-01-16", Capacity: 20},
+			{ID: "2", Name: "Sprint 2", StartDate: "2026-01-19", EndDate: "2026-01-30"},
+		}))
+	})
+
+	It("returns ErrNotFound for a missing sprint", func() {
+		mockSQL.ExpectQuery(`SELECT`).WithArgs("9").WillReturnRows(sqlmock.NewRows(sprintRows))
+
+		_, err := manager.GetSprint(ctx, "9")
+		Expect(err).To(MatchError(ErrNotFound))
+	})
+
+	It("returns ErrNotFound when updating a missing sprint", func() {
+		mockSQL.ExpectExec(`UPDATE sprints SET name = \?, goal = \?, start_date = \?, end_date = \?, capacity = \? WHERE id = \?`).
+			WithArgs("Sprint 9", "", "2026-01-05", "2026-01-16", 0, "9").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := manager.UpdateSprint(ctx, &models.Sprint{ID: "9", Name: "Sprint 9", StartDate: "2026-01-05", EndDate: "2026-01-16"})
+		Expect(err).To(MatchError(ErrNotFound))
+	})
+
+	Describe("DeleteSprint", func() {
+		It("sends the tasks of the sprint back to the backlog", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec(`DELETE FROM sprints WHERE id = \?`).WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks WHERE sprint_id = \? ORDER BY id`).WithArgs("1").
+				WillReturnRows(sqlmock.NewRows(columns).AddRow("4", "Task 4", "", "pending", createdAt, nil, "", nil, 0, "", "", 3, "1"))
+			mockSQL.ExpectExec(`UPDATE tasks SET sprint_id = \? WHERE id = \?`).WithArgs(nil, "4").WillReturnResult(sqlmock.NewResult(0, 1))
+			expectRecorded("4")
+			mockSQL.ExpectCommit()
+
+			Expect(manager.DeleteSprint(ctx, "1")).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrNotFound for a missing sprint", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec(`DELETE FROM sprints`).WithArgs("9").WillReturnResult(sqlmock.NewResult(0, 0))
+			mockSQL.ExpectRollback()
+
+			Expect(manager.DeleteSprint(ctx, "9")).To(MatchError(ErrNotFound))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	It("returns the tasks of the sprint", func() {
+		mockSQL.ExpectQuery(`SELECT`).WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(sprintRows).AddRow("1", "Sprint 1", "", "2026-01-05", "2026-01-16", 20))
+		mockSQL.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks WHERE sprint_id = \? ORDER BY id`).WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("4", "Task 4", "", "pending", createdAt, nil, "", nil, 0, "", "", 3, "1"))
+
+		tasks, err := manager.SprintTasks(ctx, "1")
+		Expect(err).To(Succeed())
+		Expect(tasks).To(Equal([]models.Task{{ID: "4", Title: "Task 4", Status: "pending", CreatedAt: createdAt, StoryPoints: 3, SprintID: "1"}}))
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Describe("SprintProgress", func() {
+		const progressQuery = `SELECT COUNT\(\*\), COALESCE\(SUM\(story_points\), 0\), COALESCE\(SUM\(status = \?\), 0\), ` +
+			`COALESCE\(SUM\(CASE WHEN status = \? THEN story_points ELSE 0 END\), 0\) FROM tasks WHERE sprint_id = \?`
+
+		expectSprint := func(capacity int) {
+			mockSQL.ExpectQuery(`SELECT`).WithArgs("1").
+				WillReturnRows(sqlmock.NewRows(sprintRows).AddRow("1", "Sprint 1", "", "2026-01-05", "2026-01-16", capacity))
+		}
+
+		It("compares the committed points with the completed ones", func() {
+			expectSprint(20)
+			mockSQL.ExpectQuery(progressQuery).WithArgs(models.StatusDone, models.StatusDone, "1").
+				WillReturnRows(sqlmock.NewRows([]string{"tasks", "committed", "done_tasks", "completed"}).AddRow(5, 21, 2, 8))
+
+			progress, err := manager.SprintProgress(ctx, "1")
+			Expect(err).To(Succeed())
+			Expect(progress).To(Equal(&models.SprintProgress{
+				SprintID: "1", Capacity: 20, Committed: 21, Completed: 8, Remaining: 13, Tasks: 5, DoneTasks: 2, OverCapacity: true,
+			}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("is never over an unplanned capacity", func() {
+			expectSprint(0)
+			mockSQL.ExpectQuery(progressQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"tasks", "committed", "done_tasks", "completed"}).AddRow(1, 3, 0, 0))
+
+			progress, err := manager.SprintProgress(ctx, "1")
+			Expect(err).To(Succeed())
+			Expect(progress.OverCapacity).To(BeFalse())
+		})
+
+		It("returns ErrNotFound for a missing sprint", func() {
+			mockSQL.ExpectQuery(`SELECT`).WithArgs("9").WillReturnRows(sqlmock.NewRows(sprintRows))
+
+			_, err := manager.SprintProgress(ctx, "9")
+			Expect(err).To(MatchError(ErrNotFound))
+		})
+	})
+
+	Describe("CarryOver", func() {
+		const (
+			startQuery = `SELECT start_date FROM sprints WHERE id = \?`
+			nextQuery  = `SELECT id FROM sprints WHERE start_date > \? ORDER BY start_date, id LIMIT 1`
+		)
+
+		expectUnfinished := func(rows *sqlmock.Rows) {
+			mockSQL.ExpectQuery(`SELECT `+taskColumns+` FROM tasks WHERE sprint_id = \? AND status != \? ORDER BY id`).
+				WithArgs("1", models.StatusDone).WillReturnRows(rows)
+		}
+
+		It("moves the unfinished tasks to the next sprint", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(startQuery).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"start_date"}).AddRow("2026-01-05"))
+			mockSQL.ExpectQuery(nextQuery).WithArgs("2026-01-05").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("2"))
+			expectUnfinished(sqlmock.NewRows(columns).
+				AddRow("4", "Task 4", "", "pending", createdAt, nil, "", nil, 0, "", "", 3, "1").
+				AddRow("6", "Task 6", "", "in_progress", createdAt, nil, "", nil, 0, "", "", 5, "1"))
+			mockSQL.ExpectExec(`UPDATE tasks SET sprint_id = \? WHERE id = \?`).WithArgs("2", "4").WillReturnResult(sqlmock.NewResult(0, 1))
+			expectRecorded("4")
+			mockSQL.ExpectExec(`UPDATE tasks SET sprint_id = \? WHERE id = \?`).WithArgs("2", "6").WillReturnResult(sqlmock.NewResult(0, 1))
+			expectRecorded("6")
+			mockSQL.ExpectCommit()
+
+			carryOver, err := manager.CarryOver(ctx, "1", "")
+			Expect(err).To(Succeed())
+			Expect(carryOver).To(Equal(&models.CarryOver{From: "1", To: "2", Points: 8, Tasks: []models.Task{
+				{ID: "4", Title: "Task 4", Status: "pending", CreatedAt: createdAt, StoryPoints: 3, SprintID: "2"},
+				{ID: "6", Title: "Task 6", Status: "in_progress", CreatedAt: createdAt, StoryPoints: 5, SprintID: "2"},
+			}}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("moves the unfinished tasks to the given sprint", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(startQuery).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"start_date"}).AddRow("2026-01-05"))
+			mockSQL.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM sprints WHERE id = \?\)`).WithArgs("7").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			expectUnfinished(sqlmock.NewRows(columns))
+			mockSQL.ExpectCommit()
+
+			carryOver, err := manager.CarryOver(ctx, "1", "7")
+			Expect(err).To(Succeed())
+			Expect(carryOver).To(Equal(&models.CarryOver{From: "1", To: "7", Tasks: []models.Task{}}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrNoNextSprint after the last sprint", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(startQuery).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"start_date"}).AddRow("2026-01-05"))
+			mockSQL.ExpectQuery(nextQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mockSQL.ExpectRollback()
+
+			_, err := manager.CarryOver(ctx, "1", "")
+			Expect(err).To(MatchError(ErrNoNextSprint))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrSprintNotFound for a missing target", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(startQuery).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"start_date"}).AddRow("2026-01-05"))
+			mockSQL.ExpectQuery(`SELECT EXISTS`).WithArgs("9").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			mockSQL.ExpectRollback()
+
+			_, err := manager.CarryOver(ctx, "1", "9")
+			Expect(err).To(MatchError(ErrSprintNotFound))
+		})
+
+		It("returns ErrNotFound for a missing sprint", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(startQuery).WithArgs("9").WillReturnRows(sqlmock.NewRows([]string{"start_date"}))
+			mockSQL.ExpectRollback()
+
+			_, err := manager.CarryOver(ctx, "9", "")
+			Expect(err).To(MatchError(ErrNotFound))
+		})
+
+		It("rolls back when a task can't move", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(startQuery).WillReturnRows(sqlmock.NewRows([]string{"start_date"}).AddRow("2026-01-05"))
+			mockSQL.ExpectQuery(nextQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("2"))
+			expectUnfinished(sqlmock.NewRows(columns).AddRow("4", "Task 4", "", "pending", createdAt, nil, "", nil, 0, "", "", 3, "1"))
+			mockSQL.ExpectExec(`UPDATE tasks SET sprint_id`).WillReturnError(errMock)
+			mockSQL.ExpectRollback()
+
+			_, err := manager.CarryOver(ctx, "1", "")
+			Expect(err).To(MatchError(errMock))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Describe("with SQLite", func() {
+		var tasks *TaskManager
+
+		BeforeEach(func() {
+			database := newSQLiteDB()
+			manager = &SprintManager{DB: database}
+			tasks = &TaskManager{DB: database}
+
+			for _, sprint := range []models.Sprint{
+				{Name: "Sprint 1", StartDate: "2026-01-05", EndDate: "2026-01-16", Capacity: 8},
+				{Name: "Sprint 3", StartDate: "2026-02-02", EndDate: "2026-02-13"},
+				{Name: "Sprint 2", StartDate: "2026-01-19", EndDate: "2026-01-30"},
+			} {
+				Expect(manager.CreateSprint(ctx, &sprint)).To(Succeed())
+			}
+			for _, task := range []models.Task{
+				{Title: "Done", Status: models.StatusDone, StoryPoints: 3, SprintID: "1"},
+				{Title: "Pending", Status: models.StatusPending, StoryPoints: 5, SprintID: "1"},
+				{Title: "Started", Status: "in_progress", StoryPoints: 2, SprintID: "1"},
+				{Title: "Unestimated", Status: models.StatusPending, SprintID: "1"},
+				{Title: "Next", Status: models.StatusPending, StoryPoints: 1, SprintID: "3"},
+				{Title: "Backlog", Status: models.StatusPending, StoryPoints: 8},
+			} {
+				Expect(tasks.Create(ctx, &task)).To(Succeed())
+			}
+		})
+
+		It("sums the points committed to the sprint and completed", func() {
+			progress, err := manager.SprintProgress(ctx, "1")
+			Expect(err).To(Succeed())
+			Expect(progress).To(Equal(&models.SprintProgress{
+				SprintID: "1", Capacity: 8, Committed: 10, Completed: 3, Remaining: 7,
+				Tasks: 4, DoneTasks: 1, OverCapacity: true,
+			}))
+		})
+
+		It("carries the unfinished tasks over to the sprint starting next", func() {
+			carryOver, err := manager.CarryOver(ctx, "1", "")
+			Expect(err).To(Succeed())
+			Expect(carryOver.To).To(Equal("3"))
+			Expect(carryOver.Points).To(Equal(7))
+
+			remaining, err := manager.SprintTasks(ctx, "1")
+			Expect(err).To(Succeed())
+			Expect(remaining).To(HaveLen(1))
+			Expect(remaining[0].Title).To(Equal("Done"))
+
+			next, err := manager.SprintProgress(ctx, "3")
+			Expect(err).To(Succeed())
+			Expect(next.Committed).To(Equal(8))
+			Expect(next.Tasks).To(Equal(4))
+
+			_, err = manager.CarryOver(ctx, "2", "")
+			Expect(err).To(MatchError(ErrNoNextSprint))
+		})
+
+		It("returns the tasks of a deleted sprint to the backlog", func() {
+			Expect(manager.DeleteSprint(ctx, "1")).To(Succeed())
+
+			backlog, err := tasks.GetAll(ctx)
+			Expect(err).To(Succeed())
+			for _, task := range backlog {
+				Expect(task.SprintID).NotTo(Equal("1"))
+			}
+		})
+	})
+})
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"github.com/saarzur123/task-management/backend/models"
+	"sync"
+	"time"
+)
+
+// TaskStatsReader computes the statistics of the dashboard.
+type TaskStatsReader interface {
+	Stats(ctx context.Context) (*models.TaskStats, error)
+}
+
+const (
+	defaultStatsTTL = 30 * time.Second
+	// oldestOpenTasks is the number of open tasks in TaskStats.OldestOpen.
+	oldestOpenTasks = 5
+)
+
+// statsCache keeps the last statistics until they expire or a task changes.
+// The generation grows with every change, so statistics computed while a
+// task changed aren't kept.
+type statsCache struct {
+	mu         sync.Mutex
+	stats      *models.TaskStats
+	expires    time.Time
+	generation uint64
+}
+
+// get returns the cached statistics, or nil and the generation to store the
+// statistics computed next with.
+func (c *statsCache) get(now time.Time) (*models.TaskStats, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stats != nil && now.Before(c.expires) {
+		return c.stats, c.generation
+	}
+	return nil, c.generation
+}
+
+// put keeps stats until expires unless a task changed since generation.
+func (c *statsCache) put(stats *models.TaskStats, generation uint64, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.generation {
+		c.stats, c.expires = stats, expires
+	}
+}
+
+// invalidate drops the cached statistics. The methods of TaskManager changing
+// tasks call it deferred, once their transaction is over.
+func (c *statsCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = nil
+	c.generation++
+}
+
+// Stats returns the cached statistics while they are fresh, StatsTTL after
+// they were computed, and no task changed since.
+func (m *TaskManager) Stats(ctx context.Context) (*models.TaskStats, error) {
+	now := time.Now()
+	stats, generation := m.stats.get(now)
+	if stats != nil {
+		return stats, nil
+	}
+
+	stats, err := m.computeStats(ctx, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	ttl := m.StatsTTL
+	if ttl == 0 {
+		ttl = defaultStatsTTL
+	}
+	m.stats.put(stats, generation, now.Add(ttl))
+	return stats, nil
+}
+
+func (m *TaskManager) computeStats(ctx context.Context, now time.Time) (_ *models.TaskStats, err error) {
+	stats := &models.TaskStats{GeneratedAt: now}
+	if stats.ByStatus, err = m.CountByStatus(ctx); err != nil {
+		return nil, err
+	}
+	for _, count := range stats.ByStatus {
+		stats.Total += count
+	}
+	if stats.Week, err = m.weekStats(ctx, now); err != nil {
+		return nil, err
+	}
+	if stats.OldestOpen, err = m.oldestOpen(ctx); err != nil {
+		return nil, err
+	}
+	if stats.Assignees, err = m.assigneeLoads(ctx); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// weekStats reads the outbox, the tasks keeping neither when they were
+// completed nor the tasks deleted since.
+func (m *TaskManager) weekStats(ctx context.Context, now time.Time) (_ models.WeekStats, err error) {
+	query := `SELECT COALESCE(SUM(event_type = ?), 0), ` +
+		`COUNT(DISTINCT CASE WHEN event_type = ? AND json_extract(payload, '$.status') = ? THEN task_id END) ` +
+		`FROM outbox WHERE event_type IN (?, ?) AND created_at >= ?`
+	ctx, end := m.observe(ctx, "TaskManager.Stats", query)
+	defer func() { end(err) }()
+
+	today := now.Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	week := models.WeekStats{Start: start.Format(time.DateOnly)}
+	err = m.DB.QueryRowContext(ctx, query, models.EventTaskCreated, models.EventTaskStatusChanged, models.StatusDone,
+		models.EventTaskCreated, models.EventTaskStatusChanged, start).Scan(&week.Created, &week.Completed)
+	return week, err
+}
+
+func (m *TaskManager) oldestOpen(ctx context.Context) (_ []models.Task, err error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE status != ? ORDER BY created_at, id LIMIT ?`
+	ctx, end := m.observe(ctx, "TaskManager.Stats", query)
+	defer func() { end(err) }()
+
+	rows, err := m.DB.QueryContext(ctx, query, models.StatusDone, oldestOpenTasks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]models.Task, 0, oldestOpenTasks)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// assigneeLoads orders the assignees by their number of open tasks, the most
+// loaded first.
+func (m *TaskManager) assigneeLoads(ctx context.Context) (_ []models.AssigneeLoad, err error) {
+	query := `SELECT assignee, COUNT(*), COALESCE(SUM(story_points), 0) FROM tasks WHERE status != ? ` +
+		`GROUP BY assignee ORDER BY COUNT(*) DESC, assignee`
+	ctx, end := m.observe(ctx, "TaskManager.Stats", query)
+	defer func() { end(err) }()
+
+	rows, err := m.DB.QueryContext(ctx, query, models.StatusDone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loads := make([]models.AssigneeLoad, 0)
+	for rows.Next() {
+		var load models.AssigneeLoad
+		if err = rows.Scan(&load.Assignee, &load.Open, &load.Points); err != nil {
+			return nil, err
+		}
+		loads = append(loads, load)
+	}
+	return loads, rows.Err()
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"time"
+)
+
+var _ = Describe("Stats", func() {
+	var (
+		manager   *TaskManager
+		database  *sql.DB
+		mockSQL   sqlmock.Sqlmock
+		columns   = []string{"id", "title", "description", "status", "created_at", "due_at", "recurrence", "series_id", "occurrence", "assignee", "rank", "story_points", "sprint_id"}
+		createdAt = time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+		err       error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &TaskManager{DB: database}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	expectStats := func() {
+		mockSQL.ExpectQuery(`SELECT status, COUNT\(\*\) FROM tasks GROUP BY status`).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).AddRow("pending", 3).AddRow("in_progress", 1).AddRow("done", 2))
+		mockSQL.ExpectQuery(`SELECT COALESCE\(SUM\(event_type = \?\), 0\), `+
+			`COUNT\(DISTINCT CASE WHEN event_type = \? AND json_extract\(payload, '\$.status'\) = \? THEN task_id END\) `+
+			`FROM outbox WHERE event_type IN \(\?, \?\) AND created_at >= \?`).
+			WithArgs(models.EventTaskCreated, models.EventTaskStatusChanged, models.StatusDone,
+				models.EventTaskCreated, models.EventTaskStatusChanged, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"created", "completed"}).AddRow(4, 2))
+		mockSQL.ExpectQuery(`SELECT (.+) FROM tasks WHERE status != \? ORDER BY created_at, id LIMIT \?`).
+			WithArgs(models.StatusDone, oldestOpenTasks).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("1", "Task 1", "", "pending", createdAt, nil, "", nil, 0, "ann", "", 3, nil).
+				AddRow("3", "Task 3", "", "in_progress", createdAt.Add(time.Hour), nil, "", nil, 0, "", "", 0, "2"))
+		mockSQL.ExpectQuery(`SELECT assignee, COUNT\(\*\), COALESCE\(SUM\(story_points\), 0\) FROM tasks WHERE status != \? ` +
+			`GROUP BY assignee ORDER BY COUNT\(\*\) DESC, assignee`).
+			WithArgs(models.StatusDone).
+			WillReturnRows(sqlmock.NewRows([]string{"assignee", "open", "points"}).AddRow("ann", 3, 8).AddRow("", 1, 0))
+	}
+
+	It("computes the statistics with aggregate queries", func() {
+		expectStats()
+
+		stats, err := manager.Stats(ctx)
+		Expect(err).To(Succeed())
+		Expect(stats.ByStatus).To(Equal(map[string]int{"pending": 3, "in_progress": 1, "done": 2}))
+		Expect(stats.Total).To(Equal(6))
+		Expect(stats.Week.Created).To(Equal(4))
+		Expect(stats.Week.Completed).To(Equal(2))
+		Expect(stats.OldestOpen).To(Equal([]models.Task{
+			{ID: "1", Title: "Task 1", Status: "pending", CreatedAt: createdAt, Assignee: "ann", StoryPoints: 3},
+			{ID: "3", Title: "Task 3", Status: "in_progress", CreatedAt: createdAt.Add(time.Hour), SprintID: "2"},
+		}))
+		Expect(stats.Assignees).To(Equal([]models.AssigneeLoad{{Assignee: "ann", Open: 3, Points: 8}, {Open: 1}}))
+		Expect(stats.GeneratedAt).To(BeTemporally("~", time.Now(), time.Minute))
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+	})
+
+	DescribeTable("starts the week on Monday",
+		func(now time.Time) {
+			monday := time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC)
+			mockSQL.ExpectQuery("SELECT (.+) FROM outbox").
+				WithArgs(models.EventTaskCreated, models.EventTaskStatusChanged, models.StatusDone,
+					models.EventTaskCreated, models.EventTaskStatusChanged, monday).
+				WillReturnRows(sqlmock.NewRows([]string{"created", "completed"}).AddRow(0, 0))
+
+			week, err := manager.weekStats(ctx, now)
+			Expect(err).To(Succeed())
+			Expect(week.Start).To(Equal("2026-01-05"))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		},
+		Entry("on Monday", time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC)),
+		Entry("midweek", time.Date(2026, time.January, 7, 13, 0, 0, 0, time.UTC)),
+		Entry("on Sunday", time.Date(2026, time.January, 11, 23, 0, 0, 0, time.UTC)),
+	)
+
+	It("caches the statistics until they expire", func() {
+		manager.StatsTTL = time.Hour
+		expectStats()
+
+		first, err := manager.Stats(ctx)
+		Expect(err).To(Succeed())
+		second, err := manager.Stats(ctx)
+		Expect(err).To(Succeed())
+		Expect(second).To(BeIdenticalTo(first))
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+
+		manager.stats.expires = time.Now()
+		expectStats()
+		third, err := manager.Stats(ctx)
+		Expect(err).To(Succeed())
+		Expect(third).NotTo(BeIdenticalTo(first))
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("drops the cached statistics when a task changes", func() {
+		manager.StatsTTL = time.Hour
+		expectStats()
+		first, err := manager.Stats(ctx)
+		Expect(err).To(Succeed())
+
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectQuery(`SELECT title, assignee FROM tasks WHERE id = \?`).WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"title", "assignee"}).AddRow("Task 1", ""))
+		mockSQL.ExpectExec(`DELETE FROM tasks WHERE id = \?`).WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectQuery(`DELETE FROM task_watchers WHERE task_id = \? RETURNING user_id`).WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mockSQL.ExpectExec("UPDATE worklogs").WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectExec("DELETE FROM reminders").WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectExec("INSERT INTO outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+		mockSQL.ExpectCommit()
+		Expect(manager.Delete(ctx, "1")).To(Succeed())
+
+		expectStats()
+		second, err := manager.Stats(ctx)
+		Expect(err).To(Succeed())
+		Expect(second).NotTo(BeIdenticalTo(first))
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("doesn't keep statistics computed while a task changed", func() {
+		var cache statsCache
+		stats, generation := cache.get(time.Now())
+		Expect(stats).To(BeNil())
+
+		cache.invalidate()
+		cache.put(&models.TaskStats{}, generation, time.Now().Add(time.Hour))
+		stats, generation = cache.get(time.Now())
+		Expect(stats).To(BeNil())
+
+		cache.put(&models.TaskStats{Total: 1}, generation, time.Now().Add(time.Hour))
+		stats, _ = cache.get(time.Now())
+		Expect(stats).To(Equal(&models.TaskStats{Total: 1}))
+	})
+
+	It("returns the errors of the queries", func() {
+		mockSQL.ExpectQuery("SELECT status").WillReturnError(errMock)
+
+		_, err := manager.Stats(ctx)
+		Expect(err).To(MatchError(errMock))
+	})
+
+	Describe("with SQLite", func() {
+		BeforeEach(func() {
+			database := newSQLiteDB()
+			manager = &TaskManager{DB: database}
+
+			var tasks []*models.Task
+			for _, task := range []models.Task{
+				{Title: "A", Status: models.StatusPending, StoryPoints: 3, Assignee: "ann"},
+				{Title: "B", Status: "in_progress", StoryPoints: 5, Assignee: "ann"},
+				{Title: "C", Status: models.StatusPending, Assignee: "ann"},
+				{Title: "D", Status: models.StatusPending, StoryPoints: 2, Assignee: "bob"},
+				{Title: "E", Status: models.StatusPending, StoryPoints: 1},
+			} {
+				Expect(manager.Create(ctx, &task)).To(Succeed())
+				tasks = append(tasks, &task)
+			}
+			// D is completed twice, A once.
+			for _, change := range []struct {
+				task   *models.Task
+				status string
+			}{
+				{tasks[3], models.StatusDone}, {tasks[3], models.StatusPending}, {tasks[3], models.StatusDone},
+				{tasks[0], models.StatusDone},
+			} {
+				change.task.Status = change.status
+				Expect(manager.Update(ctx, change.task)).To(Succeed())
+			}
+
+			// A was created the week before.
+			_, err := database.ExecContext(ctx, `UPDATE outbox SET created_at = ?`, time.Date(2026, time.January, 6, 9, 0, 0, 0, time.UTC))
+			Expect(err).To(Succeed())
+			_, err = database.ExecContext(ctx, `UPDATE outbox SET created_at = ? WHERE task_id = ? AND event_type = ?`,
+				time.Date(2026, time.January, 2, 9, 0, 0, 0, time.UTC), tasks[0].ID, models.EventTaskCreated)
+			Expect(err).To(Succeed())
+		})
+
+		It("computes the statistics of the tasks", func() {
+			stats, err := manager.computeStats(ctx, time.Date(2026, time.January, 7, 13, 0, 0, 0, time.UTC))
+			Expect(err).To(Succeed())
+			Expect(stats.ByStatus).To(Equal(map[string]int{"pending": 2, "in_progress": 1, "done": 2}))
+			Expect(stats.Total).To(Equal(5))
+			Expect(stats.Week).To(Equal(models.WeekStats{Start: "2026-01-05", Created: 4, Completed: 2}))
+
+			var oldest []string
+			for _, task := range stats.OldestOpen {
+				oldest = append(oldest, task.Title)
+			}
+			Expect(oldest).To(Equal([]string{"B", "C", "E"}))
+			Expect(stats.Assignees).To(Equal([]models.AssigneeLoad{
+				{Assignee: "ann", Open: 2, Points: 5},
+				{Open: 1, Points: 1},
+			}))
+		})
+	})
+})
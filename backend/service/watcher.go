@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saarzur123/task-management/backend/models"
+	"strconv"
+	"time"
+)
+
+// WatcherRepository manages the users following a task. The creator of a
+// task follows it from the start, and every follower is notified of its
+// changes.
+type WatcherRepository interface {
+	// Watch makes the user follow the task, or returns ErrNotFound when the
+	// task doesn't exist and ErrNotWatchable for the anonymous user.
+	// Following a task twice is not an error.
+	Watch(ctx context.Context, taskID, userID string) error
+	Unwatch(ctx context.Context, taskID, userID string) error
+	// Watchers returns the followers of the task, in the order they started
+	// following it, or ErrNotFound when the task doesn't exist.
+	Watchers(ctx context.Context, taskID string) ([]string, error)
+	// Watching returns up to limit tasks followed by the user with an ID
+	// greater than after, or the first ones when after is empty, ordered by
+	// ID.
+	Watching(ctx context.Context, userID, after string, limit int) ([]models.Task, error)
+}
+
+type WatcherManager struct {
+	DB *sql.DB
+}
+
+var (
+	ErrNotWatchable = errors.New("NotWatchable")
+)
+
+func (m *WatcherManager) Watch(ctx context.Context, taskID, userID string) error {
+	if !watchable(userID) {
+		return ErrNotWatchable
+	}
+
+	var id int64
+	err := m.DB.QueryRowContext(ctx, `SELECT id FROM tasks WHERE id = ?`, taskID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return addWatchers(ctx, m.DB, id, userID)
+}
+
+func (m *WatcherManager) Unwatch(ctx context.Context, taskID, userID string) error {
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM task_watchers WHERE task_id = ? AND user_id = ?`, taskID, userID)
+	return err
+}
+
+func (m *WatcherManager) Watchers(ctx context.Context, taskID string) ([]string, error) {
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ?)`, taskID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := m.DB.QueryContext(ctx, `SELECT user_id FROM task_watchers WHERE task_id = ? ORDER BY created_at, user_id`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWatchers(rows)
+}
+
+func (m *WatcherManager) Watching(ctx context.Context, userID, after string, limit int) ([]models.Task, error) {
+	afterID := int64(0)
+	if after != "" {
+		var err error
+		if afterID, err = strconv.ParseInt(after, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id IN (SELECT task_id FROM task_watchers WHERE user_id = ?) ` +
+		`AND id > ? ORDER BY id LIMIT ?`
+	rows, err := m.DB.QueryContext(ctx, query, userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]models.Task, 0, limit)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// watchable reports whether user can follow tasks: the callers without an
+// API key all act as the anonymous user, who follows nothing.
+func watchable(user string) bool {
+	return user != "" && user != AnonymousUser
+}
+
+// addWatchers makes the watchable users follow the task, ignoring the ones
+// already following it.
+func addWatchers(ctx context.Context, db execer, taskID int64, users ...string) error {
+	query := `INSERT OR IGNORE INTO task_watchers (task_id, user_id, created_at) VALUES (?, ?, ?)`
+	for _, user := range users {
+		if !watchable(user) {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, query, taskID, user, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskWatchers returns the followers of the task within tx.
+func taskWatchers(ctx context.Context, tx *sql.Tx, taskID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT user_id FROM task_watchers WHERE task_id = ? ORDER BY created_at, user_id`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWatchers(rows)
+}
+
+// copyWatchers makes the followers of a task follow another one within tx,
+// such as the next occurrence of a recurring task, and returns them.
+func copyWatchers(ctx context.Context, tx *sql.Tx, fromTaskID string, toTaskID int64) ([]string, error) {
+	query := `INSERT INTO task_watchers (task_id, user_id, created_at) ` +
+		`SELECT ?, user_id, created_at FROM task_watchers WHERE task_id = ? RETURNING user_id`
+	rows, err := tx.QueryContext(ctx, query, toTaskID, fromTaskID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWatchers(rows)
+}
+
+// removeWatchers deletes the followers of a deleted task within tx and
+// returns them.
+func removeWatchers(ctx context.Context, tx *sql.Tx, taskID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `DELETE FROM task_watchers WHERE task_id = ? RETURNING user_id`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWatchers(rows)
+}
+
+func scanWatchers(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	watchers := make([]string, 0)
+	for rows.Next() {
+		var user string
+		if err := rows.Scan(&user); err != nil {
+			return nil, err
+		}
+		watchers = append(watchers, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return watchers, nil
+}
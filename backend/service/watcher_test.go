@@ -0,0 +1,116 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"time"
+)
+
+var _ = Describe("WatcherManager", func() {
+	var (
+		manager  *WatcherManager
+		database *sql.DB
+		mockSQL  sqlmock.Sqlmock
+		columns  = []string{"id", "title", "description", "status", "created_at", "due_at", "recurrence", "series_id", "occurrence", "assignee", "rank", "story_points", "sprint_id"}
+		err      error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &WatcherManager{DB: database}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	Describe("Watch", func() {
+		It("adds the user to the watchers of the task", func() {
+			mockSQL.ExpectQuery(`SELECT id FROM tasks WHERE id = \?`).WithArgs("1").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mockSQL.ExpectExec(`INSERT OR IGNORE INTO task_watchers \(task_id, user_id, created_at\) VALUES \(\?, \?, \?\)`).
+				WithArgs(int64(1), "ci", sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			Expect(manager.Watch(ctx, "1", "ci")).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrNotFound for a missing task", func() {
+			mockSQL.ExpectQuery(`SELECT id FROM tasks`).WithArgs("9").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+			Expect(manager.Watch(ctx, "9", "ci")).To(MatchError(ErrNotFound))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("refuses the anonymous user", func() {
+			Expect(manager.Watch(ctx, "1", AnonymousUser)).To(MatchError(ErrNotWatchable))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	It("removes the user from the watchers of the task", func() {
+		mockSQL.ExpectExec(`DELETE FROM task_watchers WHERE task_id = \? AND user_id = \?`).WithArgs("1", "ci").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		Expect(manager.Unwatch(ctx, "1", "ci")).To(Succeed())
+		Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Describe("Watchers", func() {
+		It("returns the watchers of the task", func() {
+			mockSQL.ExpectQuery(`SELECT EXISTS`).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			mockSQL.ExpectQuery(`SELECT user_id FROM task_watchers WHERE task_id = \? ORDER BY created_at, user_id`).WithArgs("1").
+				WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("ci").AddRow("qa"))
+
+			watchers, err := manager.Watchers(ctx, "1")
+			Expect(err).To(Succeed())
+			Expect(watchers).To(Equal([]string{"ci", "qa"}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrNotFound for a missing task", func() {
+			mockSQL.ExpectQuery(`SELECT EXISTS`).WithArgs("9").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+			_, err := manager.Watchers(ctx, "9")
+			Expect(err).To(MatchError(ErrNotFound))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Describe("Watching", func() {
+		const watchingQuery = `SELECT ` + taskColumns + ` FROM tasks WHERE id IN \(SELECT task_id FROM task_watchers WHERE user_id = \?\) AND id > \? ORDER BY id LIMIT \?`
+
+		It("returns the tasks the user follows after the given ID", func() {
+			createdAt := time.Now()
+			mockSQL.ExpectQuery(watchingQuery).WithArgs("ci", int64(2), 2).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("3", "Task 3", "", "pending", createdAt, nil, "", nil, 0, "", "", 0, nil).
+					AddRow("7", "Task 7", "", "done", createdAt, nil, "", nil, 0, "qa", "", 0, nil))
+
+			tasks, err := manager.Watching(ctx, "ci", "2", 2)
+			Expect(err).To(Succeed())
+			Expect(tasks).To(Equal([]models.Task{
+				{ID: "3", Title: "Task 3", Status: "pending", CreatedAt: createdAt},
+				{ID: "7", Title: "Task 7", Status: "done", CreatedAt: createdAt, Assignee: "qa"},
+			}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("rejects a malformed after", func() {
+			_, err := manager.Watching(ctx, "ci", "abc", 10)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error when the query fails", func() {
+			mockSQL.ExpectQuery(watchingQuery).WillReturnError(errMock)
+
+			_, err := manager.Watching(ctx, "ci", "", 10)
+			Expect(err).To(MatchError(errMock))
+		})
+	})
+})
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/saarzur123/task-management/backend/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, hook *models.Webhook) error
+	GetAll(ctx context.Context) ([]models.Webhook, error)
+	GetByID(ctx context.Context, id string) (*models.Webhook, error)
+	Delete(ctx context.Context, id string) error
+	// Enable turns a disabled webhook back on and resets its failure count.
+	Enable(ctx context.Context, id string) error
+	Deliveries(ctx context.Context, webhookID string) ([]models.WebhookDelivery, error)
+	// Replay queues a new delivery with the payload of an earlier one.
+	Replay(ctx context.Context, webhookID, deliveryID string) (*models.WebhookDelivery, error)
+}
+
+// WebhookQueue is the storage of the webhook delivery worker.
+type WebhookQueue interface {
+	// Cursor returns the ID of the last outbox event queued for delivery.
+	Cursor(ctx context.Context) (uint64, error)
+	// Enqueue creates a delivery of event for every enabled webhook
+	// subscribed to its type and moves the cursor past it, atomically.
+	Enqueue(ctx context.Context, event models.TaskEvent) error
+	Due(ctx context.Context, now time.Time, limit int) ([]models.PendingDelivery, error)
+	MarkSucceeded(ctx context.Context, delivery *models.PendingDelivery, statusCode int, at time.Time) error
+	// MarkFailed records a failed attempt, to be retried at retryAt or given
+	// up when it is nil. The webhook is disabled once maxFailures attempts in
+	// a row failed, which is reported by disabled.
+	MarkFailed(ctx context.Context, delivery *models.PendingDelivery, statusCode int, reason string, retryAt *time.Time, maxFailures int, at time.Time) (disabled bool, err error)
+}
+
+type WebhookManager struct {
+	DB *sql.DB
+}
+
+const (
+	webhookSecretPrefix = "whsec_"
+	webhookSecretBytes  = 24
+	webhookCursor       = "webhooks"
+	deliveriesLimit     = 100
+	eventTypesSeparator = ","
+)
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(secret), nil
+}
+
+// Create stores an enabled webhook, generating its secret unless one is set.
+func (m *WebhookManager) Create(ctx context.Context, hook *models.Webhook) error {
+	if hook.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return err
+		}
+		hook.Secret = secret
+	}
+
+	hook.CreatedAt = time.Now()
+	hook.Enabled = true
+	query := `INSERT INTO webhooks (url, event_types, secret, enabled, created_at) VALUES (?, ?, ?, ?, ?)`
+	row, err := m.DB.ExecContext(ctx, query, hook.URL, strings.Join(hook.EventTypes, eventTypesSeparator), hook.Secret, hook.Enabled, hook.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	dbID, err := row.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	hook.ID = strconv.FormatInt(dbID, 10)
+	return nil
+}
+
+const webhookColumns = `id, url, event_types, enabled, consecutive_failures, created_at, disabled_at`
+
+// GetAll returns the webhooks without their secrets.
+func (m *WebhookManager) GetAll(ctx context.Context) ([]models.Webhook, error) {
+	rows, err := m.DB.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hooks := make([]models.Webhook, 0)
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, *hook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+// GetByID returns the webhook without its secret.
+func (m *WebhookManager) GetByID(ctx context.Context, id string) (*models.Webhook, error) {
+	hook, err := scanWebhook(m.DB.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return hook, err
+}
+
+// Delete removes the webhook and its delivery log.
+func (m *WebhookManager) Delete(ctx context.Context, id string) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	rows, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := rows.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *WebhookManager) Enable(ctx context.Context, id string) error {
+	query := `UPDATE webhooks SET enabled = ?, consecutive_failures = 0, disabled_at = NULL WHERE id = ?`
+	rows, err := m.DB.ExecContext(ctx, query, true, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := rows.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, last_status_code, last_error, created_at, next_attempt_at, delivered_at`
+
+// Deliveries returns the latest deliveries of the webhook, newest first.
+func (m *WebhookManager) Deliveries(ctx context.Context, webhookID string) ([]models.WebhookDelivery, error) {
+	if _, err := m.GetByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?`
+	rows, err := m.DB.QueryContext(ctx, query, webhookID, deliveriesLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (m *WebhookManager) Replay(ctx context.Context, webhookID, deliveryID string) (*models.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = ? AND webhook_id = ?`
+	original, err := scanDelivery(m.DB.QueryRowContext(ctx, query, deliveryID, webhookID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID: original.WebhookID,
+		EventID:   original.EventID,
+		EventType: original.EventType,
+		Payload:   original.Payload,
+	}
+	if err = insertDelivery(ctx, m.DB, &delivery, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertDelivery queues delivery to be sent right away.
+func insertDelivery(ctx context.Context, db execer, delivery *models.WebhookDelivery, now time.Time) error {
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.CreatedAt = now
+	delivery.NextAttemptAt = now
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, created_at, next_attempt_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	row, err := db.ExecContext(ctx, query, delivery.WebhookID, delivery.EventID, delivery.EventType, string(delivery.Payload),
+		delivery.Status, delivery.CreatedAt, delivery.NextAttemptAt)
+	if err != nil {
+		return err
+	}
+
+	dbID, err := row.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	delivery.ID = strconv.FormatInt(dbID, 10)
+	return nil
+}
+
+func (m *WebhookManager) Cursor(ctx context.Context) (uint64, error) {
+	var position uint64
+	err := m.DB.QueryRowContext(ctx, `SELECT position FROM outbox_cursors WHERE name = ?`, webhookCursor).Scan(&position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return position, err
+}
+
+func (m *WebhookManager) Enqueue(ctx context.Context, event models.TaskEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, event_types FROM webhooks WHERE enabled`)
+	if err != nil {
+		return err
+	}
+	subscribed := make([]string, 0)
+	for rows.Next() {
+		var id, eventTypes string
+		if err = rows.Scan(&id, &eventTypes); err != nil {
+			rows.Close()
+			return err
+		}
+		for _, eventType := range strings.Split(eventTypes, eventTypesSeparator) {
+			if eventType == event.Type {
+				subscribed = append(subscribed, id)
+				break
+			}
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, id := range subscribed {
+		delivery := models.WebhookDelivery{WebhookID: id, EventID: event.ID, EventType: event.Type, Payload: payload}
+		if err = insertDelivery(ctx, tx, &delivery, now); err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO outbox_cursors (name, position) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET position = excluded.position`
+	if _, err = tx.ExecContext(ctx, query, webhookCursor, event.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Due returns the pending deliveries of enabled webhooks whose next attempt
+// is due, oldest first.
+func (m *WebhookManager) Due(ctx context.Context, now time.Time, limit int) ([]models.PendingDelivery, error) {
+	query := `SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.last_status_code, d.last_error, ` +
+		`d.created_at, d.next_attempt_at, d.delivered_at, w.url, w.secret ` +
+		`FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id ` +
+		`WHERE d.status = ? AND d.next_attempt_at <= ? AND w.enabled ORDER BY d.next_attempt_at, d.id LIMIT ?`
+	rows, err := m.DB.QueryContext(ctx, query, models.WebhookDeliveryPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pending := make([]models.PendingDelivery, 0)
+	for rows.Next() {
+		var delivery models.PendingDelivery
+		if err = scanDeliveryInto(rows, &delivery.WebhookDelivery, &delivery.URL, &delivery.Secret); err != nil {
+			return nil, err
+		}
+		pending = append(pending, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+func (m *WebhookManager) MarkSucceeded(ctx context.Context, delivery *models.PendingDelivery, statusCode int, at time.Time) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	query := `UPDATE webhook_deliveries SET status = ?, attempts = attempts + 1, last_status_code = ?, last_error = NULL, delivered_at = ? WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, query, models.WebhookDeliverySucceeded, statusCode, at, delivery.ID); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE webhooks SET consecutive_failures = 0 WHERE id = ?`, delivery.WebhookID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *WebhookManager) MarkFailed(ctx context.Context, delivery *models.PendingDelivery, statusCode int, reason string,
+	retryAt *time.Time, maxFailures int, at time.Time) (bool, error) {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	status, nextAttemptAt := models.WebhookDeliveryFailed, at
+	if retryAt != nil {
+		status, nextAttemptAt = models.WebhookDeliveryPending, *retryAt
+	}
+	lastStatusCode := sql.NullInt64{Int64: int64(statusCode), Valid: statusCode != 0}
+	query := `UPDATE webhook_deliveries SET status = ?, attempts = attempts + 1, last_status_code = ?, last_error = ?, next_attempt_at = ? WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, query, status, lastStatusCode, reason, nextAttemptAt, delivery.ID); err != nil {
+		return false, err
+	}
+	query = `UPDATE webhooks SET consecutive_failures = consecutive_failures + 1 WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, query, delivery.WebhookID); err != nil {
+		return false, err
+	}
+	query = `UPDATE webhooks SET enabled = ?, disabled_at = ? WHERE id = ? AND enabled AND consecutive_failures >= ?`
+	rows, err := tx.ExecContext(ctx, query, false, at, delivery.WebhookID, maxFailures)
+	if err != nil {
+		return false, err
+	}
+	disabled, err := rows.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return disabled > 0, tx.Commit()
+}
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var (
+		hook       models.Webhook
+		eventTypes string
+		disabledAt sql.NullTime
+	)
+	err := row.Scan(&hook.ID, &hook.URL, &eventTypes, &hook.Enabled, &hook.ConsecutiveFailures, &hook.CreatedAt, &disabledAt)
+	if err != nil {
+		return nil, err
+	}
+
+	hook.EventTypes = strings.Split(eventTypes, eventTypesSeparator)
+	if disabledAt.Valid {
+		hook.DisabledAt = &disabledAt.Time
+	}
+
+	return &hook, nil
+}
+
+func scanDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := scanDeliveryInto(row, &delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// scanDeliveryInto scans the delivery columns followed by extra.
+func scanDeliveryInto(row rowScanner, delivery *models.WebhookDelivery, extra ...any) error {
+	var (
+		payload        string
+		lastStatusCode sql.NullInt64
+		lastError      sql.NullString
+		deliveredAt    sql.NullTime
+	)
+	dest := append([]any{&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &payload, &delivery.Status,
+		&delivery.Attempts, &lastStatusCode, &lastError, &delivery.CreatedAt, &delivery.NextAttemptAt, &deliveredAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+
+	delivery.Payload = json.RawMessage(payload)
+	delivery.LastStatusCode = int(lastStatusCode.Int64)
+	delivery.LastError = lastError.String
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return nil
+}
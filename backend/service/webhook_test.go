@@ -0,0 +1,179 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"time"
+)
+
+var _ = Describe("WebhookManager", func() {
+	var (
+		manager  *WebhookManager
+		database *sql.DB
+		mockSQL  sqlmock.Sqlmock
+		err      error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &WebhookManager{DB: database}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	Context("Create", func() {
+		It("generates a secret when none is given", func() {
+			mockSQL.ExpectExec("INSERT INTO webhooks").
+				WithArgs("https://example.com/hook", "task.created,task.deleted", sqlmock.AnyArg(), true, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(3, 1))
+
+			hook := models.Webhook{URL: "https://example.com/hook", EventTypes: []string{models.EventTaskCreated, models.EventTaskDeleted}}
+			Expect(manager.Create(ctx, &hook)).To(Succeed())
+			Expect(hook.ID).To(Equal("3"))
+			Expect(hook.Enabled).To(BeTrue())
+			Expect(hook.Secret).To(HavePrefix(webhookSecretPrefix))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("keeps the given secret", func() {
+			mockSQL.ExpectExec("INSERT INTO webhooks").
+				WithArgs("https://example.com/hook", "task.created", "a-very-secret-value", true, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			hook := models.Webhook{URL: "https://example.com/hook", EventTypes: []string{models.EventTaskCreated}, Secret: "a-very-secret-value"}
+			Expect(manager.Create(ctx, &hook)).To(Succeed())
+			Expect(hook.Secret).To(Equal("a-very-secret-value"))
+		})
+	})
+
+	Context("GetByID", func() {
+		It("returns the webhook without its secret", func() {
+			now := time.Now()
+			mockSQL.ExpectQuery(`SELECT (.+) FROM webhooks WHERE id = \?`).WithArgs("1").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "url", "event_types", "enabled", "consecutive_failures", "created_at", "disabled_at"}).
+					AddRow("1", "https://example.com/hook", "task.created,task.updated", false, 20, now, now))
+
+			hook, err := manager.GetByID(ctx, "1")
+			Expect(err).To(Succeed())
+			Expect(hook).To(Equal(&models.Webhook{
+				ID: "1", URL: "https://example.com/hook", EventTypes: []string{models.EventTaskCreated, models.EventTaskUpdated},
+				ConsecutiveFailures: 20, CreatedAt: now, DisabledAt: &now,
+			}))
+		})
+
+		It("returns ErrNotFound for an unknown webhook", func() {
+			mockSQL.ExpectQuery("SELECT (.+) FROM webhooks").WithArgs("9").WillReturnError(sql.ErrNoRows)
+
+			_, err := manager.GetByID(ctx, "9")
+			Expect(err).To(MatchError(ErrNotFound))
+		})
+	})
+
+	Context("Delete", func() {
+		It("removes the webhook with its deliveries", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec(`DELETE FROM webhooks WHERE id = \?`).WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec(`DELETE FROM webhook_deliveries WHERE webhook_id = \?`).WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 4))
+			mockSQL.ExpectCommit()
+
+			Expect(manager.Delete(ctx, "1")).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrNotFound for an unknown webhook", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("DELETE FROM webhooks").WithArgs("9").WillReturnResult(sqlmock.NewResult(0, 0))
+			mockSQL.ExpectRollback()
+
+			Expect(manager.Delete(ctx, "9")).To(MatchError(ErrNotFound))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Context("Enqueue", func() {
+		It("queues the event for the subscribed webhooks and moves the cursor", func() {
+			event := models.TaskEvent{ID: 7, Type: models.EventTaskUpdated, TaskID: "1"}
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery("SELECT id, event_types FROM webhooks WHERE enabled").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "event_types"}).
+					AddRow("1", "task.created").
+					AddRow("2", "task.created,task.updated"))
+			mockSQL.ExpectExec("INSERT INTO webhook_deliveries").
+				WithArgs("2", uint64(7), models.EventTaskUpdated, sqlmock.AnyArg(), models.WebhookDeliveryPending, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mockSQL.ExpectExec("INSERT INTO outbox_cursors").WithArgs(webhookCursor, uint64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectCommit()
+
+			Expect(manager.Enqueue(ctx, event)).To(Succeed())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("leaves the cursor when a delivery can't be queued", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery("SELECT id, event_types FROM webhooks").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "event_types"}).AddRow("1", "task.deleted"))
+			mockSQL.ExpectExec("INSERT INTO webhook_deliveries").WillReturnError(errMock)
+			mockSQL.ExpectRollback()
+
+			err := manager.Enqueue(ctx, models.TaskEvent{ID: 8, Type: models.EventTaskDeleted, TaskID: "1"})
+			Expect(err).To(MatchError(errMock))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Context("MarkFailed", func() {
+		delivery := &models.PendingDelivery{WebhookDelivery: models.WebhookDelivery{ID: "5", WebhookID: "1"}}
+
+		It("schedules a retry", func() {
+			now := time.Now()
+			retryAt := now.Add(time.Minute)
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("UPDATE webhook_deliveries").
+				WithArgs(models.WebhookDeliveryPending, int64(500), "HTTP 500", retryAt, "5").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec(`UPDATE webhooks SET consecutive_failures = consecutive_failures \+ 1`).WithArgs("1").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec("UPDATE webhooks SET enabled").WithArgs(false, now, "1", 20).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mockSQL.ExpectCommit()
+
+			disabled, err := manager.MarkFailed(ctx, delivery, 500, "HTTP 500", &retryAt, 20, now)
+			Expect(err).To(Succeed())
+			Expect(disabled).To(BeFalse())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("gives up and reports the webhook disabled", func() {
+			now := time.Now()
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectExec("UPDATE webhook_deliveries").
+				WithArgs(models.WebhookDeliveryFailed, nil, "connection refused", now, "5").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec("UPDATE webhooks SET consecutive_failures").WithArgs("1").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectExec("UPDATE webhooks SET enabled").WithArgs(false, now, "1", 20).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockSQL.ExpectCommit()
+
+			disabled, err := manager.MarkFailed(ctx, delivery, 0, "connection refused", nil, 20, now)
+			Expect(err).To(Succeed())
+			Expect(disabled).To(BeTrue())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Context("Replay", func() {
+		It("returns ErrNotFound for a delivery of another webhook", func() {
+			mockSQL.ExpectQuery("SELECT (.+) FROM webhook_deliveries").WithArgs("5", "2").WillReturnError(sql.ErrNoRows)
+
+			_, err := manager.Replay(ctx, "2", "5")
+			Expect(err).To(MatchError(ErrNotFound))
+		})
+	})
+})
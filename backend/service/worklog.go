@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saarzur123/task-management/backend/models"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeTracker records the time users spend on tasks, with timers or by hand,
+// and sums it up for billing.
+type TimeTracker interface {
+	TaskTimeReader
+	// StartTimer starts a timer of the user on the task. It returns
+	// ErrNotFound when the task doesn't exist, ErrNotTrackable for the
+	// anonymous user and ErrTimerRunning when the user has a running timer
+	// already, on any task.
+	StartTimer(ctx context.Context, taskID, userID, note string) (*models.Worklog, error)
+	// StopTimer stops the running timer of the user on the task and returns
+	// it with its duration, or ErrNotFound when there is none.
+	StopTimer(ctx context.Context, taskID, userID string) (*models.Worklog, error)
+	// LogWork records time spent on worklog.TaskID by worklog.UserID, and
+	// sets the ID of the worklog. It returns ErrNotFound when the task
+	// doesn't exist.
+	LogWork(ctx context.Context, worklog *models.Worklog) error
+	// Worklogs returns the worklogs of the task, running timers included,
+	// in the order they started, or ErrNotFound when the task doesn't exist.
+	Worklogs(ctx context.Context, taskID string) ([]models.Worklog, error)
+	// TimeReport sums the duration of the worklogs selected by query, the
+	// running timers aside.
+	TimeReport(ctx context.Context, query models.TimeReportQuery) (*models.TimeReport, error)
+}
+
+// TaskTimeReader sums the time logged on a task.
+type TaskTimeReader interface {
+	// TaskTime returns the time logged on the task in seconds, the running
+	// timers aside.
+	TaskTime(ctx context.Context, taskID string) (int64, error)
+}
+
+type WorklogManager struct {
+	DB *sql.DB
+}
+
+const worklogColumns = `id, task_id, user_id, started_at, duration, note, running`
+
+var (
+	ErrNotTrackable = errors.New("NotTrackable")
+	ErrTimerRunning = errors.New("TimerRunning")
+)
+
+func (m *WorklogManager) StartTimer(ctx context.Context, taskID, userID, note string) (*models.Worklog, error) {
+	// Hours are billed to someone, the anonymous user tracks nothing.
+	if !watchable(userID) {
+		return nil, ErrNotTrackable
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM tasks WHERE id = ?`, taskID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var running bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM worklogs WHERE user_id = ? AND running = 1)`, userID).Scan(&running)
+	if err != nil {
+		return nil, err
+	}
+	if running {
+		return nil, ErrTimerRunning
+	}
+
+	worklog := &models.Worklog{TaskID: taskID, UserID: userID, StartedAt: time.Now().UTC(), Note: note, Running: true}
+	row, err := tx.ExecContext(ctx, `INSERT INTO worklogs (task_id, user_id, started_at, duration, note, running) VALUES (?, ?, ?, 0, ?, 1)`,
+		id, userID, worklog.StartedAt, note)
+	if err != nil {
+		return nil, err
+	}
+	if worklog.ID, err = insertedID(row); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return worklog, nil
+}
+
+func (m *WorklogManager) StopTimer(ctx context.Context, taskID, userID string) (*models.Worklog, error) {
+	query := `SELECT ` + worklogColumns + ` FROM worklogs WHERE task_id = ? AND user_id = ? AND running = 1`
+	worklog, err := scanWorklog(m.DB.QueryRowContext(ctx, query, taskID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	worklog.Duration = int64(time.Since(worklog.StartedAt) / time.Second)
+	worklog.Running = false
+	// Only the first of concurrent stops records the duration.
+	rows, err := m.DB.ExecContext(ctx, `UPDATE worklogs SET duration = ?, running = 0 WHERE id = ? AND running = 1`,
+		worklog.Duration, worklog.ID)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := rows.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, ErrNotFound
+	}
+	return &worklog, nil
+}
+
+// stopTimers stops the timers running on a deleted task within db, recording
+// the time spent until at: the worklogs of deleted tasks stay billed.
+func stopTimers(ctx context.Context, db execer, taskID string, at time.Time) error {
+	query := `UPDATE worklogs SET duration = MAX(0, unixepoch(?) - unixepoch(started_at)), running = 0 WHERE task_id = ? AND running = 1`
+	_, err := db.ExecContext(ctx, query, at.UTC(), taskID)
+	return err
+}
+
+func (m *WorklogManager) LogWork(ctx context.Context, worklog *models.Worklog) error {
+	worklog.StartedAt = worklog.StartedAt.UTC()
+	worklog.Running = false
+	query := `INSERT INTO worklogs (task_id, user_id, started_at, duration, note, running) ` +
+		`SELECT id, ?, ?, ?, ?, 0 FROM tasks WHERE id = ?`
+	row, err := m.DB.ExecContext(ctx, query, worklog.UserID, worklog.StartedAt, worklog.Duration, worklog.Note, worklog.TaskID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := row.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	worklog.ID, err = insertedID(row)
+	return err
+}
+
+func (m *WorklogManager) Worklogs(ctx context.Context, taskID string) ([]models.Worklog, error) {
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ?)`, taskID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := m.DB.QueryContext(ctx, `SELECT `+worklogColumns+` FROM worklogs WHERE task_id = ? ORDER BY started_at, id`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	worklogs := make([]models.Worklog, 0)
+	for rows.Next() {
+		worklog, err := scanWorklog(rows)
+		if err != nil {
+			return nil, err
+		}
+		worklogs = append(worklogs, worklog)
+	}
+	return worklogs, rows.Err()
+}
+
+func (m *WorklogManager) TaskTime(ctx context.Context, taskID string) (int64, error) {
+	var total int64
+	err := m.DB.QueryRowContext(ctx, `SELECT COALESCE(SUM(duration), 0) FROM worklogs WHERE task_id = ? AND running = 0`, taskID).
+		Scan(&total)
+	return total, err
+}
+
+// TimeReport groups the worklogs here rather than in SQL, the day of a
+// worklog being its UTC date.
+func (m *WorklogManager) TimeReport(ctx context.Context, query models.TimeReportQuery) (*models.TimeReport, error) {
+	conditions := []string{"running = 0"}
+	var args []any
+	if !query.From.IsZero() {
+		conditions = append(conditions, "started_at >= ?")
+		args = append(args, query.From.UTC())
+	}
+	if !query.To.IsZero() {
+		conditions = append(conditions, "started_at < ?")
+		args = append(args, query.To.UTC())
+	}
+	if query.UserID != "" {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, query.UserID)
+	}
+	if query.TaskID != "" {
+		conditions = append(conditions, "task_id = ?")
+		args = append(args, query.TaskID)
+	}
+
+	rows, err := m.DB.QueryContext(ctx, `SELECT task_id, user_id, started_at, duration FROM worklogs WHERE `+
+		strings.Join(conditions, " AND "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &models.TimeReport{GroupBy: query.GroupBy, Rows: make([]models.TimeReportRow, 0)}
+	groups := map[models.TimeReportRow]int{}
+	for rows.Next() {
+		var (
+			worklog models.Worklog
+			key     models.TimeReportRow
+		)
+		if err = rows.Scan(&worklog.TaskID, &worklog.UserID, &worklog.StartedAt, &worklog.Duration); err != nil {
+			return nil, err
+		}
+		for _, field := range query.GroupBy {
+			switch field {
+			case models.TimeGroupUser:
+				key.UserID = worklog.UserID
+			case models.TimeGroupTask:
+				key.TaskID = worklog.TaskID
+			case models.TimeGroupDay:
+				key.Day = worklog.StartedAt.UTC().Format(time.DateOnly)
+			}
+		}
+
+		i, ok := groups[key]
+		if !ok {
+			i = len(report.Rows)
+			groups[key] = i
+			report.Rows = append(report.Rows, key)
+		}
+		report.Rows[i].Duration += worklog.Duration
+		report.Total += worklog.Duration
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(report.Rows, func(a, b models.TimeReportRow) int {
+		for _, field := range query.GroupBy {
+			var c int
+			switch field {
+			case models.TimeGroupUser:
+				c = strings.Compare(a.UserID, b.UserID)
+			case models.TimeGroupTask:
+				c = compareIDs(a.TaskID, b.TaskID)
+			case models.TimeGroupDay:
+				c = strings.Compare(a.Day, b.Day)
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return report, nil
+}
+
+// compareIDs compares numeric IDs, shorter ones being smaller.
+func compareIDs(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
+
+func scanWorklog(row rowScanner) (models.Worklog, error) {
+	var worklog models.Worklog
+	err := row.Scan(&worklog.ID, &worklog.TaskID, &worklog.UserID, &worklog.StartedAt, &worklog.Duration,
+		&worklog.Note, &worklog.Running)
+	return worklog, err
+}
+
+func insertedID(result sql.Result) (string, error) {
+	id, err := result.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
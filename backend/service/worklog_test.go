@@ -0,0 +1,268 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/models"
+	"time"
+)
+
+var _ = Describe("WorklogManager", func() {
+	var (
+		manager   *WorklogManager
+		database  *sql.DB
+		mockSQL   sqlmock.Sqlmock
+		columns   = []string{"id", "task_id", "user_id", "started_at", "duration", "note", "running"}
+		startedAt = time.Date(2026, 1, 5, 23, 30, 0, 0, time.UTC)
+		err       error
+	)
+
+	BeforeEach(func() {
+		database, mockSQL, err = sqlmock.New()
+		Expect(err).To(Succeed())
+		manager = &WorklogManager{DB: database}
+	})
+
+	AfterEach(func() {
+		database.Close()
+	})
+
+	Describe("StartTimer", func() {
+		const runningQuery = `SELECT EXISTS \(SELECT 1 FROM worklogs WHERE user_id = \? AND running = 1\)`
+
+		It("starts a timer on the task", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT id FROM tasks WHERE id = \?`).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mockSQL.ExpectQuery(runningQuery).WithArgs("ci").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			mockSQL.ExpectExec(`INSERT INTO worklogs \(task_id, user_id, started_at, duration, note, running\) VALUES \(\?, \?, \?, 0, \?, 1\)`).
+				WithArgs(int64(1), "ci", sqlmock.AnyArg(), "Review").
+				WillReturnResult(sqlmock.NewResult(4, 1))
+			mockSQL.ExpectCommit()
+
+			worklog, err := manager.StartTimer(ctx, "1", "ci", "Review")
+			Expect(err).To(Succeed())
+			Expect(worklog.ID).To(Equal("4"))
+			Expect(worklog.Running).To(BeTrue())
+			Expect(worklog.StartedAt).To(BeTemporally("~", time.Now(), time.Second))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("refuses a second running timer", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT id FROM tasks`).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mockSQL.ExpectQuery(runningQuery).WithArgs("ci").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			mockSQL.ExpectRollback()
+
+			_, err := manager.StartTimer(ctx, "1", "ci", "")
+			Expect(err).To(MatchError(ErrTimerRunning))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrNotFound for a missing task", func() {
+			mockSQL.ExpectBegin()
+			mockSQL.ExpectQuery(`SELECT id FROM tasks`).WithArgs("9").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mockSQL.ExpectRollback()
+
+			_, err := manager.StartTimer(ctx, "9", "ci", "")
+			Expect(err).To(MatchError(ErrNotFound))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("refuses the anonymous user", func() {
+			_, err := manager.StartTimer(ctx, "1", AnonymousUser, "")
+			Expect(err).To(MatchError(ErrNotTrackable))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Describe("StopTimer", func() {
+		const (
+			timerQuery = `SELECT ` + worklogColumns + ` FROM worklogs WHERE task_id = \? AND user_id = \? AND running = 1`
+			stopQuery  = `UPDATE worklogs SET duration = \?, running = 0 WHERE id = \? AND running = 1`
+		)
+
+		It("records the duration of the timer", func() {
+			started := time.Now().Add(-90 * time.Second)
+			mockSQL.ExpectQuery(timerQuery).WithArgs("1", "ci").
+				WillReturnRows(sqlmock.NewRows(columns).AddRow("4", "1", "ci", started, 0, "Review", true))
+			mockSQL.ExpectExec(stopQuery).WithArgs(int64(90), "4").WillReturnResult(sqlmock.NewResult(0, 1))
+
+			worklog, err := manager.StopTimer(ctx, "1", "ci")
+			Expect(err).To(Succeed())
+			Expect(worklog).To(Equal(&models.Worklog{ID: "4", TaskID: "1", UserID: "ci", StartedAt: started, Duration: 90, Note: "Review"}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrNotFound without a running timer", func() {
+			mockSQL.ExpectQuery(timerQuery).WithArgs("1", "ci").WillReturnRows(sqlmock.NewRows(columns))
+
+			_, err := manager.StopTimer(ctx, "1", "ci")
+			Expect(err).To(MatchError(ErrNotFound))
+		})
+
+		It("returns ErrNotFound when a concurrent stop won", func() {
+			mockSQL.ExpectQuery(timerQuery).WithArgs("1", "ci").
+				WillReturnRows(sqlmock.NewRows(columns).AddRow("4", "1", "ci", time.Now(), 0, "", true))
+			mockSQL.ExpectExec(stopQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			_, err := manager.StopTimer(ctx, "1", "ci")
+			Expect(err).To(MatchError(ErrNotFound))
+		})
+	})
+
+	Describe("LogWork", func() {
+		const logQuery = `INSERT INTO worklogs \(task_id, user_id, started_at, duration, note, running\) SELECT id, \?, \?, \?, \?, 0 FROM tasks WHERE id = \?`
+
+		It("records the worklog in UTC", func() {
+			worklog := &models.Worklog{TaskID: "1", UserID: "ci", StartedAt: startedAt.In(time.FixedZone("CET", 3600)), Duration: 3600, Note: "Call"}
+			mockSQL.ExpectExec(logQuery).WithArgs("ci", startedAt, int64(3600), "Call", "1").WillReturnResult(sqlmock.NewResult(5, 1))
+
+			Expect(manager.LogWork(ctx, worklog)).To(Succeed())
+			Expect(worklog.ID).To(Equal("5"))
+			Expect(worklog.StartedAt.Location()).To(Equal(time.UTC))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrNotFound for a missing task", func() {
+			mockSQL.ExpectExec(logQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(manager.LogWork(ctx, &models.Worklog{TaskID: "9", UserID: "ci", Duration: 60})).To(MatchError(ErrNotFound))
+		})
+	})
+
+	Describe("Worklogs", func() {
+		It("returns the worklogs of the task", func() {
+			mockSQL.ExpectQuery(`SELECT EXISTS`).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			mockSQL.ExpectQuery(`SELECT ` + worklogColumns + ` FROM worklogs WHERE task_id = \? ORDER BY started_at, id`).WithArgs("1").
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("5", "1", "qa", startedAt, 3600, "Call", false).
+					AddRow("4", "1", "ci", startedAt.Add(time.Hour), 0, "", true))
+
+			worklogs, err := manager.Worklogs(ctx, "1")
+			Expect(err).To(Succeed())
+			Expect(worklogs).To(Equal([]models.Worklog{
+				{ID: "5", TaskID: "1", UserID: "qa", StartedAt: startedAt, Duration: 3600, Note: "Call"},
+				{ID: "4", TaskID: "1", UserID: "ci", StartedAt: startedAt.Add(time.Hour), Running: true},
+			}))
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns ErrNotFound for a missing task", func() {
+			mockSQL.ExpectQuery(`SELECT EXISTS`).WithArgs("9").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+			_, err := manager.Worklogs(ctx, "9")
+			Expect(err).To(MatchError(ErrNotFound))
+		})
+	})
+
+	It("sums the time logged on a task", func() {
+		mockSQL.ExpectQuery(`SELECT COALESCE\(SUM\(duration\), 0\) FROM worklogs WHERE task_id = \? AND running = 0`).WithArgs("1").
+			WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(5400))
+
+		Expect(manager.TaskTime(ctx, "1")).To(Equal(int64(5400)))
+	})
+
+	Describe("TimeReport", func() {
+		reportColumns := []string{"task_id", "user_id", "started_at", "duration"}
+		worklogs := func() *sqlmock.Rows {
+			return sqlmock.NewRows(reportColumns).
+				AddRow("10", "qa", startedAt, 60).
+				AddRow("9", "ci", startedAt, 1800).
+				AddRow("10", "ci", startedAt.Add(time.Hour), 3600).
+				AddRow("9", "ci", startedAt.Add(-time.Hour), 600)
+		}
+
+		It("groups by the fields in order", func() {
+			mockSQL.ExpectQuery(`SELECT task_id, user_id, started_at, duration FROM worklogs WHERE running = 0$`).WillReturnRows(worklogs())
+
+			report, err := manager.TimeReport(ctx, models.TimeReportQuery{GroupBy: []string{"user", "task"}})
+			Expect(err).To(Succeed())
+			Expect(report).To(Equal(&models.TimeReport{
+				GroupBy: []string{"user", "task"},
+				Rows: []models.TimeReportRow{
+					{UserID: "ci", TaskID: "9", Duration: 2400},
+					{UserID: "ci", TaskID: "10", Duration: 3600},
+					{UserID: "qa", TaskID: "10", Duration: 60},
+				},
+				Total: 6060,
+			}))
+		})
+
+		It("groups by UTC day", func() {
+			mockSQL.ExpectQuery(`SELECT`).WillReturnRows(worklogs())
+
+			report, err := manager.TimeReport(ctx, models.TimeReportQuery{GroupBy: []string{"day"}})
+			Expect(err).To(Succeed())
+			Expect(report.Rows).To(Equal([]models.TimeReportRow{
+				{Day: "2026-01-05", Duration: 2460},
+				{Day: "2026-01-06", Duration: 3600},
+			}))
+		})
+
+		It("filters by time, user and task", func() {
+			from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			to := from.AddDate(0, 1, 0)
+			mockSQL.ExpectQuery(`SELECT task_id, user_id, started_at, duration FROM worklogs `+
+				`WHERE running = 0 AND started_at >= \? AND started_at < \? AND user_id = \? AND task_id = \?`).
+				WithArgs(from, to, "ci", "9").
+				WillReturnRows(sqlmock.NewRows(reportColumns))
+
+			report, err := manager.TimeReport(ctx, models.TimeReportQuery{From: from, To: to, UserID: "ci", TaskID: "9", GroupBy: []string{"user"}})
+			Expect(err).To(Succeed())
+			Expect(report.Rows).To(BeEmpty())
+			Expect(mockSQL.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns an error when the query fails", func() {
+			mockSQL.ExpectQuery(`SELECT`).WillReturnError(errMock)
+
+			_, err := manager.TimeReport(ctx, models.TimeReportQuery{})
+			Expect(err).To(MatchError(errMock))
+		})
+	})
+
+	Describe("with SQLite", func() {
+		var tasks *TaskManager
+
+		BeforeEach(func() {
+			manager.DB = newSQLiteDB()
+			tasks = &TaskManager{DB: manager.DB}
+		})
+
+		It("stops the timers and drops the reminders of a deleted task", func() {
+			dueAt := startedAt
+			task := models.Task{Title: "Ship", Status: models.StatusPending, Assignee: "ci", DueAt: &dueAt}
+			Expect(tasks.Create(ctx, &task)).To(Succeed())
+			_, err := manager.StartTimer(ctx, task.ID, "ci", "")
+			Expect(err).To(Succeed())
+			_, err = manager.DB.ExecContext(ctx, `UPDATE worklogs SET started_at = ?`, time.Now().UTC().Add(-time.Hour))
+			Expect(err).To(Succeed())
+			reminders := &NotificationManager{DB: manager.DB}
+			reminder := models.Reminder{TaskID: task.ID, UserID: "ci", Kind: models.ReminderOverdue, Channel: models.ChannelEmail, DueAt: dueAt}
+			Expect(reminders.ClaimReminder(ctx, &reminder, time.Now())).To(BeTrue())
+			retryAt := time.Now().Add(time.Minute)
+			Expect(reminders.FailReminder(ctx, &reminder, &retryAt)).To(Succeed())
+
+			Expect(tasks.Delete(ctx, task.ID)).To(Succeed())
+
+			worklogs, err := manager.Worklogs(ctx, task.ID)
+			Expect(err).To(MatchError(ErrNotFound))
+			Expect(worklogs).To(BeNil())
+			var duration int64
+			var running bool
+			Expect(manager.DB.QueryRowContext(ctx, `SELECT duration, running FROM worklogs`).Scan(&duration, &running)).To(Succeed())
+			Expect(running).To(BeFalse())
+			Expect(duration).To(BeNumerically("~", 3600, 5))
+			var count int
+			Expect(manager.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM reminders`).Scan(&count)).To(Succeed())
+			Expect(count).To(BeZero())
+
+			other := models.Task{Title: "Next", Status: models.StatusPending}
+			Expect(tasks.Create(ctx, &other)).To(Succeed())
+			_, err = manager.StartTimer(ctx, other.ID, "ci", "")
+			Expect(err).To(Succeed(), "the timer of the deleted task no longer runs")
+		})
+	})
+})
@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"regexp"
+	"strings"
+)
+
+var (
+	stringLiteral  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numericLiteral = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	whitespace     = regexp.MustCompile(`\s+`)
+)
+
+// SanitizeSQL replaces string and numeric literals with placeholders and
+// collapses whitespace, so statements never leak values into traces.
+func SanitizeSQL(query string) string {
+	query = stringLiteral.ReplaceAllString(query, "?")
+	query = numericLiteral.ReplaceAllString(query, "?")
+	return strings.TrimSpace(whitespace.ReplaceAllString(query, " "))
+}
+
+// StartQuery starts a client span for a repository call running query. The
+// returned function ends the span and records err when not nil.
+func StartQuery(ctx context.Context, name, query string) (context.Context, func(err error)) {
+	operation, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	ctx, span := otel.Tracer(InstrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemSqlite,
+			semconv.DBQueryText(SanitizeSQL(query)),
+			attribute.String("db.operation.name", strings.ToUpper(operation)),
+		))
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
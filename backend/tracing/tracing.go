@@ -0,0 +1,88 @@
+// Package tracing configures OpenTelemetry and holds the helpers the HTTP and
+// repository layers use to create spans.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"io"
+	"os"
+)
+
+const (
+	ExporterNone   = ""
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	// InstrumentationName names the tracer used by the backend packages.
+	InstrumentationName = "github.com/saarzur123/task-management/backend"
+)
+
+var (
+	ErrUnknownExporter = errors.New("UnknownExporter")
+)
+
+// Config selects where spans are exported. The OTLP exporter sends them over
+// HTTP to Endpoint ("localhost:4318" when empty, a local collector); the
+// standard OTEL_EXPORTER_OTLP_* variables are honored as well.
+type Config struct {
+	// Writer receives the spans of the stdout exporter, os.Stdout when nil.
+	Writer      io.Writer
+	ServiceName string
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+}
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. The returned function flushes and stops the exporter.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch config.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		options := make([]otlptracehttp.Option, 0)
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	case ExporterStdout:
+		writer := config.Writer
+		if writer == nil {
+			writer = os.Stdout
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(writer))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExporter, config.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(config.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
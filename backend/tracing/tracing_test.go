@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tracing Suite")
+}
+
+var _ = Describe("tracing", func() {
+	DescribeTable("SanitizeSQL",
+		func(query, expected string) {
+			Expect(SanitizeSQL(query)).To(Equal(expected))
+		},
+		Entry("placeholders are kept", "SELECT id FROM tasks WHERE id = ?", "SELECT id FROM tasks WHERE id = ?"),
+		Entry("string literals", "SELECT id FROM tasks WHERE title = 'it''s secret'", "SELECT id FROM tasks WHERE title = ?"),
+		Entry("numeric literals", "SELECT id FROM tasks WHERE id = 42 LIMIT 1.5", "SELECT id FROM tasks WHERE id = ? LIMIT ?"),
+		Entry("identifiers with digits", "SELECT v2 FROM t1", "SELECT v2 FROM t1"),
+		Entry("whitespace", "SELECT id\n\t FROM   tasks ", "SELECT id FROM tasks"),
+	)
+
+	Describe("Setup", func() {
+		It("exports spans with the stdout exporter", func() {
+			var output bytes.Buffer
+			shutdown, err := Setup(context.Background(), Config{ServiceName: "test", Exporter: ExporterStdout, Writer: &output})
+			Expect(err).To(Succeed())
+
+			_, span := otel.Tracer(InstrumentationName).Start(context.Background(), "stdout span")
+			span.End()
+			Expect(shutdown(context.Background())).To(Succeed())
+
+			Expect(output.String()).To(ContainSubstring(`"Name":"stdout span"`))
+			Expect(output.String()).To(ContainSubstring(`"Value":"test"`))
+		})
+
+		It("is a no-op without exporter", func() {
+			shutdown, err := Setup(context.Background(), Config{})
+			Expect(err).To(Succeed())
+			Expect(shutdown(context.Background())).To(Succeed())
+		})
+
+		It("rejects unknown exporters", func() {
+			_, err := Setup(context.Background(), Config{Exporter: "zipkin"})
+			Expect(err).To(MatchError(ErrUnknownExporter))
+		})
+	})
+
+	Describe("StartQuery", func() {
+		var exporter *tracetest.InMemoryExporter
+
+		BeforeEach(func() {
+			exporter = tracetest.NewInMemoryExporter()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+		})
+
+		It("records the sanitized statement and errors", func() {
+			_, end := StartQuery(context.Background(), "TaskManager.Delete", "DELETE FROM tasks WHERE id = 7")
+			end(errors.New("locked"))
+
+			spans := exporter.GetSpans()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Name).To(Equal("TaskManager.Delete"))
+			Expect(spans[0].Attributes).To(ContainElement(attribute.String("db.query.text", "DELETE FROM tasks WHERE id = ?")))
+			Expect(spans[0].Attributes).To(ContainElement(attribute.String("db.operation.name", "DELETE")))
+			Expect(spans[0].Status.Code).To(Equal(codes.Error))
+		})
+	})
+})
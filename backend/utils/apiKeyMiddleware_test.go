@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/graph"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUtils(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Utils Suite")
+}
+
+var (
+	errMock = errors.New("mock error")
+)
+
+var _ = Describe("apiKeyMiddleware", func() {
+	const rawKey = "tm_secret"
+	var (
+		mockTasks *serviceMock.MockTaskRepository
+		mockKeys  *serviceMock.MockAPIKeyRepository
+		mockAudit *serviceMock.MockAuditRepository
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockTasks = serviceMock.NewMockTaskRepository(mockCtrl)
+		mockKeys = serviceMock.NewMockAPIKeyRepository(mockCtrl)
+		mockKeys.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		mockAudit = serviceMock.NewMockAuditRepository(mockCtrl)
+	})
+
+	serve := func(router http.Handler, method, path, key string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, nil)
+		if key != "" {
+			request.Header.Set(apiKeyHeader, key)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	It("lets anonymous requests through when keys aren't required", func() {
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false))
+		mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{}, nil)
+
+		Expect(serve(router, http.MethodGet, "/tasks", "").Code).To(Equal(http.StatusOK))
+	})
+
+	It("rejects anonymous requests when keys are required", func() {
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, true))
+
+		Expect(serve(router, http.MethodGet, "/tasks", "").Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("rejects invalid keys", func() {
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(nil, service.ErrInvalidAPIKey)
+
+		Expect(serve(router, http.MethodGet, "/tasks", rawKey).Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("rejects keys missing the scope of the route", func() {
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksRead}}, nil)
+
+		recorder := serve(router, http.MethodDelete, "/tasks/1", rawKey)
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		Expect(recorder.Body.String()).To(ContainSubstring(models.ScopeTasksWrite))
+	})
+
+	It("lets tasks:read keys post GraphQL queries", func() {
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false), WithGraphQL(&graph.Resolver{Tasks: mockTasks}))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksRead}}, nil)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+		mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{}, nil)
+
+		request := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ tasks { id } }"}`))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set(apiKeyHeader, rawKey)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"data": {"tasks": []}}`))
+	})
+
+	It("lets tasks:read keys manage their notification preferences", func() {
+		mockPreferences := serviceMock.NewMockNotificationPreferencesRepository(gomock.NewController(GinkgoT()))
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false), WithNotifications(mockPreferences, nil))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksRead}}, nil)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+		mockPreferences.EXPECT().SavePreferences(gomock.Any(), gomock.Any()).Return(nil)
+
+		request := httptest.NewRequest(http.MethodPut, "/users/me/notification-preferences", strings.NewReader(`{"channels": ["in_app"]}`))
+		request.Header.Set(apiKeyHeader, rawKey)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"user_id": "bot", "channels": ["in_app"]}`))
+	})
+
+	It("lets tasks:read keys follow tasks", func() {
+		mockWatchers := serviceMock.NewMockWatcherRepository(gomock.NewController(GinkgoT()))
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false), WithWatchers(mockWatchers))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksRead}}, nil)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+		mockWatchers.EXPECT().Watch(gomock.Any(), "1", "bot").Return(nil)
+
+		Expect(serve(router, http.MethodPost, "/tasks/1/watchers", rawKey).Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("requires tasks:write to track time, not to read the worklogs and the time report", func() {
+		mockTracker := serviceMock.NewMockTimeTracker(gomock.NewController(GinkgoT()))
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false), WithTimeTracking(mockTracker))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksRead}}, nil).Times(5)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		mockTracker.EXPECT().Worklogs(gomock.Any(), "1").Return([]models.Worklog{}, nil)
+		mockTracker.EXPECT().TimeReport(gomock.Any(), gomock.Any()).Return(&models.TimeReport{}, nil)
+
+		Expect(serve(router, http.MethodPost, "/tasks/1/timer", rawKey).Code).To(Equal(http.StatusForbidden))
+		Expect(serve(router, http.MethodDelete, "/tasks/1/timer", rawKey).Code).To(Equal(http.StatusForbidden))
+		Expect(serve(router, http.MethodPost, "/tasks/1/worklogs", rawKey).Code).To(Equal(http.StatusForbidden))
+		Expect(serve(router, http.MethodGet, "/tasks/1/worklogs", rawKey).Code).To(Equal(http.StatusOK))
+		Expect(serve(router, http.MethodGet, "/reports/time", rawKey).Code).To(Equal(http.StatusOK))
+	})
+
+	It("lets tasks:write keys track their time", func() {
+		mockTracker := serviceMock.NewMockTimeTracker(gomock.NewController(GinkgoT()))
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false), WithTimeTracking(mockTracker))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksWrite}}, nil)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+		mockTracker.EXPECT().StopTimer(gomock.Any(), "1", "bot").Return(&models.Worklog{ID: "4"}, nil)
+
+		Expect(serve(router, http.MethodDelete, "/tasks/1/timer", rawKey).Code).To(Equal(http.StatusOK))
+	})
+
+	It("requires tasks:write to move tasks on the board", func() {
+		mockBoard := serviceMock.NewMockTaskBoard(gomock.NewController(GinkgoT()))
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false), WithBoard(mockBoard))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksRead}}, nil).Times(2)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+		mockBoard.EXPECT().Board(gomock.Any()).Return(&models.Board{}, nil)
+
+		Expect(serve(router, http.MethodGet, "/board", rawKey).Code).To(Equal(http.StatusOK))
+		Expect(serve(router, http.MethodPost, "/tasks/1/move", rawKey).Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("requires tasks:write to plan sprints", func() {
+		mockSprints := serviceMock.NewMockSprintPlanner(gomock.NewController(GinkgoT()))
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false), WithSprints(mockSprints))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksRead}}, nil).Times(2)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+		mockSprints.EXPECT().SprintProgress(gomock.Any(), "1").Return(&models.SprintProgress{}, nil)
+
+		Expect(serve(router, http.MethodGet, "/sprints/1/progress", rawKey).Code).To(Equal(http.StatusOK))
+		Expect(serve(router, http.MethodPost, "/sprints/1/carry-over", rawKey).Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("lets tasks:read keys read the dashboard statistics", func() {
+		mockStats := serviceMock.NewMockTaskStatsReader(gomock.NewController(GinkgoT()))
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false), WithStats(mockStats))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksRead}}, nil)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+		mockStats.EXPECT().Stats(gomock.Any()).Return(&models.TaskStats{}, nil)
+
+		Expect(serve(router, http.MethodGet, "/stats", rawKey).Code).To(Equal(http.StatusOK))
+	})
+
+	DescribeTable("rejects anonymous requests to the admin routes when keys aren't required",
+		func(method, path string) {
+			router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false))
+
+			Expect(serve(router, method, path, "").Code).To(Equal(http.StatusUnauthorized))
+		},
+		Entry("creating a key", http.MethodPost, "/apikeys"),
+		Entry("listing the keys", http.MethodGet, "/apikeys"),
+		Entry("reading the audit trail", http.MethodGet, "/audit"),
+	)
+
+	DescribeTable("rejects anonymous requests to the webhooks when keys aren't required",
+		func(method, path string) {
+			router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false), WithWebhooks(serviceMock.NewMockWebhookRepository(gomock.NewController(GinkgoT())), mockAudit))
+
+			Expect(serve(router, method, path, "").Code).To(Equal(http.StatusUnauthorized))
+		},
+		Entry("registering a webhook", http.MethodPost, "/webhooks"),
+		Entry("listing the webhooks", http.MethodGet, "/webhooks"),
+		Entry("replaying a delivery", http.MethodPost, "/webhooks/1/deliveries/2/replay"),
+	)
+
+	It("requires the admin scope for key management", func() {
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksWrite}}, nil)
+
+		Expect(serve(router, http.MethodGet, "/apikeys", rawKey).Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("records the usage of a valid key in the audit trail", func() {
+		router := SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false))
+		mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksWrite}}, nil)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry *models.AuditEntry) error {
+			Expect(entry.Actor).To(Equal("apikey:bot"))
+			Expect(entry.Action).To(Equal("DELETE /tasks/{id:[0-9]+}"))
+			Expect(entry.Resource).To(Equal("/tasks/1"))
+			return nil
+		})
+		mockTasks.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+
+		Expect(serve(router, http.MethodDelete, "/tasks/1", rawKey).Code).To(Equal(http.StatusNoContent))
+	})
+})
@@ -0,0 +1,502 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/events"
+	"github.com/saarzur123/task-management/backend/graph"
+	"github.com/saarzur123/task-management/backend/handler"
+	"github.com/saarzur123/task-management/backend/metrics"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/openapi"
+	"github.com/saarzur123/task-management/backend/presence"
+	"github.com/saarzur123/task-management/backend/service"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	specURL = "openapi.json"
+	// adminKey authenticates the calls to adminPaths, which refuse anonymous
+	// requests.
+	adminKey = "tm_admin"
+	// userKey authenticates the calls to userPaths, which refuse anonymous
+	// requests too.
+	userKey = "tm_ci"
+)
+
+var (
+	adminPaths = []string{"/apikeys", "/audit", "/webhooks"}
+	userPaths  = []string{"/users/me", "/tasks/1/watchers"}
+)
+
+// openAPISpec is the subset of the OpenAPI document the contract test reads.
+type openAPISpec struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Responses map[string]openAPIResponse `json:"responses"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	Responses map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIResponse struct {
+	Ref     string                     `json:"$ref"`
+	Content map[string]json.RawMessage `json:"content"`
+}
+
+var (
+	openAPIMethods = map[string]bool{"get": true, "put": true, "post": true, "delete": true, "patch": true}
+	routeVariable  = regexp.MustCompile(`\{([^}:]+)(:[^}]+)?\}`)
+)
+
+// specPath converts a mux path template to the OpenAPI path it documents.
+func specPath(template string) string {
+	return routeVariable.ReplaceAllString(template, "{$1}")
+}
+
+func pointerEscape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+var _ = Describe("OpenAPI contract", func() {
+	var (
+		spec     openAPISpec
+		compiler *jsonschema.Compiler
+		router   *mux.Router
+	)
+
+	// Streamed responses are covered by their own tests.
+	streamed := map[string]bool{
+		"GET /tasks/events":   true,
+		"GET /tasks/presence": true,
+	}
+
+	BeforeEach(func() {
+		Expect(json.Unmarshal(openapi.Spec, &spec)).To(Succeed())
+		document, err := jsonschema.UnmarshalJSON(bytes.NewReader(openapi.Spec))
+		Expect(err).To(Succeed())
+		compiler = jsonschema.NewCompiler()
+		compiler.AssertFormat()
+		Expect(compiler.AddResource(specURL, document)).To(Succeed())
+
+		now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		task := models.Task{ID: "1", Title: "Task 1", Description: "Description", Status: "pending", CreatedAt: now}
+		event := models.TaskEvent{ID: 2, Type: models.EventTaskStatusChanged, TaskID: "1", Task: &task, PreviousStatus: "todo", OccurredAt: now}
+		payload, err := json.Marshal(event)
+		Expect(err).To(Succeed())
+		delivery := models.WebhookDelivery{ID: "3", WebhookID: "1", EventID: 2, EventType: event.Type, Payload: payload,
+			Status: models.WebhookDeliveryFailed, Attempts: 8, LastStatusCode: 500, LastError: "HTTP 500", CreatedAt: now, NextAttemptAt: now}
+
+		mockCtrl := gomock.NewController(GinkgoT())
+		tasks := serviceMock.NewMockTaskRepository(mockCtrl)
+		tasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{task}, nil).AnyTimes()
+		tasks.EXPECT().GetByID(gomock.Any(), "1").Return(&task, nil).AnyTimes()
+		tasks.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, t *models.Task) error {
+			t.ID, t.CreatedAt = "2", now
+			return nil
+		}).AnyTimes()
+		tasks.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, t *models.Task) error {
+			if t.ID != "1" {
+				return service.ErrNotFound
+			}
+			t.CreatedAt = now
+			return nil
+		}).AnyTimes()
+		tasks.EXPECT().Delete(gomock.Any(), "1").Return(nil).AnyTimes()
+		tasks.EXPECT().Delete(gomock.Any(), "9").Return(service.ErrNotFound).AnyTimes()
+
+		pages := serviceMock.NewMockTaskPageReader(mockCtrl)
+		pages.EXPECT().GetPage(gomock.Any(), "", 1).Return([]models.Task{task}, nil).AnyTimes()
+
+		apiKeys := serviceMock.NewMockAPIKeyRepository(mockCtrl)
+		apiKeys.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		apiKeys.EXPECT().GetAll(gomock.Any()).Return([]models.APIKey{
+			{ID: "1", Name: "ci", Prefix: "tm_abcd", Scopes: []string{models.ScopeTasksRead}, CreatedAt: now, LastUsedAt: &now},
+		}, nil).AnyTimes()
+		apiKeys.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key *models.APIKey) error {
+			key.ID, key.Prefix, key.Key, key.CreatedAt = "2", "tm_efgh", "tm_efgh_secret", now
+			return nil
+		}).AnyTimes()
+		apiKeys.EXPECT().Revoke(gomock.Any(), "1").Return(nil).AnyTimes()
+		apiKeys.EXPECT().Revoke(gomock.Any(), "9").Return(service.ErrNotFound).AnyTimes()
+		apiKeys.EXPECT().Authenticate(gomock.Any(), adminKey).Return(&models.APIKey{
+			ID: "1", Name: "admin", Scopes: []string{models.ScopeAPIKeyAdmin},
+		}, nil).AnyTimes()
+		apiKeys.EXPECT().Authenticate(gomock.Any(), userKey).Return(&models.APIKey{
+			ID: "3", Name: "ci", Scopes: []string{models.ScopeTasksRead},
+		}, nil).AnyTimes()
+
+		audit := serviceMock.NewMockAuditRepository(mockCtrl)
+		audit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		audit.EXPECT().GetAll(gomock.Any()).Return([]models.AuditEntry{
+			{ID: "1", Actor: "apikey:ci", Action: "apikey.create", Resource: "apikeys/2", CreatedAt: now},
+		}, nil).AnyTimes()
+
+		webhook := models.Webhook{ID: "1", URL: "https://ci.example.com/hook", EventTypes: []string{models.EventTaskCreated},
+			ConsecutiveFailures: 20, CreatedAt: now, DisabledAt: &now}
+		webhooks := serviceMock.NewMockWebhookRepository(mockCtrl)
+		webhooks.EXPECT().GetAll(gomock.Any()).Return([]models.Webhook{webhook}, nil).AnyTimes()
+		webhooks.EXPECT().GetByID(gomock.Any(), "1").Return(&webhook, nil).AnyTimes()
+		webhooks.EXPECT().GetByID(gomock.Any(), "9").Return(nil, service.ErrNotFound).AnyTimes()
+		webhooks.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, hook *models.Webhook) error {
+			hook.ID, hook.Secret, hook.Enabled, hook.CreatedAt = "2", "whsec_secret", true, now
+			return nil
+		}).AnyTimes()
+		webhooks.EXPECT().Delete(gomock.Any(), "1").Return(nil).AnyTimes()
+		webhooks.EXPECT().Enable(gomock.Any(), "1").Return(nil).AnyTimes()
+		webhooks.EXPECT().Deliveries(gomock.Any(), "1").Return([]models.WebhookDelivery{delivery}, nil).AnyTimes()
+		webhooks.EXPECT().Replay(gomock.Any(), "1", "3").Return(&models.WebhookDelivery{ID: "4", WebhookID: "1", EventID: 2,
+			EventType: event.Type, Payload: payload, Status: models.WebhookDeliveryPending, CreatedAt: now, NextAttemptAt: now}, nil).AnyTimes()
+		webhooks.EXPECT().Replay(gomock.Any(), "1", "9").Return(nil, service.ErrNotFound).AnyTimes()
+
+		outbox := serviceMock.NewMockOutboxReader(mockCtrl)
+		outbox.EXPECT().After(gomock.Any(), uint64(1), gomock.Any()).Return([]models.TaskEvent{event}, nil).AnyTimes()
+		outbox.EXPECT().After(gomock.Any(), uint64(2), gomock.Any()).Return([]models.TaskEvent{}, nil).AnyTimes()
+
+		readiness := serviceMock.NewMockReadinessChecker(mockCtrl)
+		readiness.EXPECT().Ready(gomock.Any()).Return(nil).AnyTimes()
+
+		preferences := serviceMock.NewMockNotificationPreferencesRepository(mockCtrl)
+		preferences.EXPECT().GetPreferences(gomock.Any(), "ci").Return(&models.NotificationPreferences{
+			UserID: "ci", Channels: []string{models.ChannelEmail}, Email: "ci@example.com",
+			QuietStart: "22:00", QuietEnd: "07:00", TimeZone: "Europe/Paris",
+		}, nil).AnyTimes()
+		preferences.EXPECT().SavePreferences(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		inbox := serviceMock.NewMockNotificationInbox(mockCtrl)
+		inbox.EXPECT().Inbox(gomock.Any(), "ci", "", 50, false).Return(&models.Inbox{
+			Notifications: []models.Notification{{ID: "2", UserID: "ci", TaskID: "1", Kind: models.NotificationTaskAssigned,
+				Message: `Task "Write tests" was assigned to you`, Actor: "ci", CreatedAt: now}},
+			Unread: 1,
+		}, nil).AnyTimes()
+		inbox.EXPECT().MarkRead(gomock.Any(), "ci", "2").Return(nil).AnyTimes()
+		inbox.EXPECT().MarkRead(gomock.Any(), "ci", "9").Return(service.ErrNotFound).AnyTimes()
+		inbox.EXPECT().MarkAllRead(gomock.Any(), "ci").Return(nil).AnyTimes()
+
+		watchers := serviceMock.NewMockWatcherRepository(mockCtrl)
+		watchers.EXPECT().Watchers(gomock.Any(), "1").Return([]string{"ci", "qa"}, nil).AnyTimes()
+		watchers.EXPECT().Watchers(gomock.Any(), "9").Return(nil, service.ErrNotFound).AnyTimes()
+		watchers.EXPECT().Watch(gomock.Any(), "1", "ci").Return(nil).AnyTimes()
+		watchers.EXPECT().Unwatch(gomock.Any(), "1", "ci").Return(nil).AnyTimes()
+		watchers.EXPECT().Watching(gomock.Any(), "ci", "", 1).Return([]models.Task{task}, nil).AnyTimes()
+
+		board := serviceMock.NewMockTaskBoard(mockCtrl)
+		board.EXPECT().Board(gomock.Any()).Return(&models.Board{Columns: []models.BoardColumn{
+			{Status: models.StatusPending, Tasks: []models.Task{task}},
+			{Status: models.StatusDone, Tasks: []models.Task{}},
+		}}, nil).AnyTimes()
+		board.EXPECT().Move(gomock.Any(), "1", models.TaskMove{Status: models.StatusDone}).Return(&task, nil).AnyTimes()
+		board.EXPECT().Move(gomock.Any(), "9", gomock.Any()).Return(nil, service.ErrNotFound).AnyTimes()
+		board.EXPECT().Move(gomock.Any(), "1", models.TaskMove{Status: models.StatusDone, After: "7"}).
+			Return(nil, service.ErrInvalidMove).AnyTimes()
+
+		worklog := models.Worklog{ID: "4", TaskID: "1", UserID: "anonymous", StartedAt: now, Duration: 90}
+		tracker := serviceMock.NewMockTimeTracker(mockCtrl)
+		tracker.EXPECT().TaskTime(gomock.Any(), "1").Return(int64(5400), nil).AnyTimes()
+		tracker.EXPECT().StartTimer(gomock.Any(), "1", "anonymous", "").Return(nil, service.ErrNotTrackable).AnyTimes()
+		tracker.EXPECT().StopTimer(gomock.Any(), "1", "anonymous").Return(&worklog, nil).AnyTimes()
+		tracker.EXPECT().StopTimer(gomock.Any(), "9", "anonymous").Return(nil, service.ErrNotFound).AnyTimes()
+		tracker.EXPECT().Worklogs(gomock.Any(), "1").Return([]models.Worklog{worklog}, nil).AnyTimes()
+		tracker.EXPECT().Worklogs(gomock.Any(), "9").Return(nil, service.ErrNotFound).AnyTimes()
+		tracker.EXPECT().TimeReport(gomock.Any(), gomock.Any()).Return(&models.TimeReport{
+			GroupBy: []string{"user", "task"},
+			Rows:    []models.TimeReportRow{{UserID: "ci", TaskID: "1", Duration: 5400}},
+			Total:   5400,
+		}, nil).AnyTimes()
+
+		sprint := models.Sprint{ID: "1", Name: "Sprint 1", Goal: "Ship", StartDate: "2026-01-05", EndDate: "2026-01-16", Capacity: 20}
+		planned := models.Task{ID: "1", Title: "Task 1", Status: "pending", CreatedAt: now, StoryPoints: 3, SprintID: "2"}
+		sprints := serviceMock.NewMockSprintPlanner(mockCtrl)
+		sprints.EXPECT().ListSprints(gomock.Any()).Return([]models.Sprint{sprint}, nil).AnyTimes()
+		sprints.EXPECT().CreateSprint(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, s *models.Sprint) error {
+			s.ID = "2"
+			return nil
+		}).AnyTimes()
+		sprints.EXPECT().GetSprint(gomock.Any(), "1").Return(&sprint, nil).AnyTimes()
+		sprints.EXPECT().GetSprint(gomock.Any(), "9").Return(nil, service.ErrNotFound).AnyTimes()
+		sprints.EXPECT().UpdateSprint(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		sprints.EXPECT().DeleteSprint(gomock.Any(), "1").Return(nil).AnyTimes()
+		sprints.EXPECT().DeleteSprint(gomock.Any(), "9").Return(service.ErrNotFound).AnyTimes()
+		sprints.EXPECT().SprintTasks(gomock.Any(), "1").Return([]models.Task{planned}, nil).AnyTimes()
+		sprints.EXPECT().SprintTasks(gomock.Any(), "9").Return(nil, service.ErrNotFound).AnyTimes()
+		sprints.EXPECT().SprintProgress(gomock.Any(), "1").
+			Return(&models.SprintProgress{SprintID: "1", Capacity: 20, Committed: 13, Completed: 5, Remaining: 8, Tasks: 4, DoneTasks: 1}, nil).AnyTimes()
+		sprints.EXPECT().SprintProgress(gomock.Any(), "9").Return(nil, service.ErrNotFound).AnyTimes()
+		sprints.EXPECT().CarryOver(gomock.Any(), "1", "").
+			Return(&models.CarryOver{From: "1", To: "2", Points: 3, Tasks: []models.Task{planned}}, nil).AnyTimes()
+		sprints.EXPECT().CarryOver(gomock.Any(), "1", "7").Return(nil, service.ErrSprintNotFound).AnyTimes()
+		sprints.EXPECT().CarryOver(gomock.Any(), "2", "").Return(nil, service.ErrNoNextSprint).AnyTimes()
+		sprints.EXPECT().CarryOver(gomock.Any(), "9", "").Return(nil, service.ErrNotFound).AnyTimes()
+		times := models.FlowTimes{
+			LeadTime:    models.Percentiles{Count: 2, P50: 3600, P85: 7200, P95: 7200},
+			CycleTime:   models.Percentiles{Count: 2, P50: 1800, P85: 3600, P95: 3600},
+			Transitions: []models.TransitionTimes{{From: "pending", To: "done", Percentiles: models.Percentiles{Count: 2, P50: 3600, P85: 7200, P95: 7200}}},
+		}
+		stats := serviceMock.NewMockTaskStatsReader(mockCtrl)
+		stats.EXPECT().Stats(gomock.Any()).Return(&models.TaskStats{
+			ByStatus:    map[string]int{"pending": 3, "done": 2},
+			Total:       5,
+			Week:        models.WeekStats{Start: "2026-01-05", Created: 4, Completed: 2},
+			OldestOpen:  []models.Task{task},
+			Assignees:   []models.AssigneeLoad{{Assignee: "ann", Open: 2, Points: 5}, {Open: 1}},
+			GeneratedAt: now,
+		}, nil).AnyTimes()
+		reports := serviceMock.NewMockFlowReporter(mockCtrl)
+		reports.EXPECT().Burndown(gomock.Any(), "1").Return(&models.Burndown{
+			SprintID: "1",
+			Days:     []models.BurndownDay{{Day: "2026-01-05", Committed: 13, Remaining: 8, RemainingTasks: 3, Ideal: 13}},
+			Times:    times,
+		}, nil).AnyTimes()
+		reports.EXPECT().Burndown(gomock.Any(), "9").Return(nil, service.ErrNotFound).AnyTimes()
+		reports.EXPECT().CumulativeFlow(gomock.Any(), gomock.Any(), gomock.Any()).Return(&models.CumulativeFlow{
+			From: "2026-01-05", To: "2026-01-05", Statuses: []string{"pending", "done"},
+			Days:  []models.FlowDay{{Day: "2026-01-05", Counts: map[string]int{"pending": 3, "done": 2}}},
+			Times: times,
+		}, nil).AnyTimes()
+
+		hub := events.NewHub(events.DefaultReplaySize, events.DefaultSubscriberBuffer)
+		router = SetupRoutes(tasks,
+			WithAPIKeys(apiKeys, audit, false),
+			WithMetrics(metrics.NewRegistry()),
+			WithHealth(&handler.HealthHandler{DB: readiness, BuildInfo: models.BuildInfo{GitSHA: "abc", BuildTime: "now", GoVersion: "go1"}}),
+			WithEvents(hub),
+			WithPresence(presence.NewHub(presence.NewMemoryBroker())),
+			WithWebhooks(webhooks, audit),
+			WithEventFeed(&handler.EventFeedHandler{Outbox: outbox}),
+			WithTaskPages(pages),
+			WithNotifications(preferences, inbox),
+			WithWatchers(watchers),
+			WithBoard(board),
+			WithTimeTracking(tracker),
+			WithSprints(sprints),
+			WithFlowReports(reports),
+			WithStats(stats),
+			WithGraphQL(&graph.Resolver{Tasks: tasks, Outbox: outbox, Events: hub}))
+	})
+
+	operation := func(path, method string) (openAPIOperation, bool) {
+		raw, ok := spec.Paths[path][method]
+		if !ok {
+			return openAPIOperation{}, false
+		}
+		var op openAPIOperation
+		Expect(json.Unmarshal(raw, &op)).To(Succeed())
+		return op, true
+	}
+
+	It("documents every route and nothing else", func() {
+		var routes, documented []string
+		err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			template, err := route.GetPathTemplate()
+			if err != nil || template == openapi.DocsPath {
+				// The OPTIONS catch-all and the Swagger UI aren't part of the API.
+				return nil
+			}
+			methods, err := route.GetMethods()
+			Expect(err).To(Succeed())
+			for _, method := range methods {
+				routes = append(routes, method+" "+specPath(template))
+			}
+			return nil
+		})
+		Expect(err).To(Succeed())
+
+		for path, item := range spec.Paths {
+			for method := range item {
+				if openAPIMethods[method] {
+					documented = append(documented, strings.ToUpper(method)+" "+path)
+				}
+			}
+		}
+		Expect(documented).To(ConsistOf(routes))
+	})
+
+	It("answers every operation as documented", func() {
+		type call struct {
+			method, path, body string
+			status             int
+		}
+		calls := []call{
+			{"GET", "/tasks", "", http.StatusOK},
+			{"GET", "/tasks?limit=1", "", http.StatusOK},
+			{"GET", "/tasks?after=x", "", http.StatusBadRequest},
+			{"POST", "/tasks", `{"title":"Task 2","status":"pending"}`, http.StatusCreated},
+			{"POST", "/tasks", `{`, http.StatusBadRequest},
+			{"GET", "/tasks/1", "", http.StatusOK},
+			{"PUT", "/tasks/1", `{"title":"Task 1","status":"done"}`, http.StatusOK},
+			{"PUT", "/tasks/9", `{"title":"Task 9"}`, http.StatusNotFound},
+			{"DELETE", "/tasks/1", "", http.StatusNoContent},
+			{"DELETE", "/tasks/9", "", http.StatusNotFound},
+			{"GET", "/events?after=1&wait=0", "", http.StatusOK},
+			{"GET", "/events?after=2&wait=0", "", http.StatusOK},
+			{"GET", "/events?limit=0", "", http.StatusBadRequest},
+			{"GET", "/graphql?query=" + url.QueryEscape("{ tasks { id title } }"), "", http.StatusOK},
+			{"GET", "/graphql?query=" + url.QueryEscape("{ tasks { owner } }"), "", http.StatusUnprocessableEntity},
+			{"POST", "/graphql", `{"query":"{ events(after: \"1\") { id type previousStatus } }"}`, http.StatusOK},
+			{"POST", "/graphql", `{"query":"mutation { deleteTask(id: \"9\") }"}`, http.StatusOK},
+			{"POST", "/graphql", `{"query":"{ tasks { history { snapshot { history { snapshot { history { id } } } } } } }"}`, http.StatusUnprocessableEntity},
+			{"POST", "/graphql", `{`, http.StatusBadRequest},
+			{"GET", "/apikeys", "", http.StatusOK},
+			{"POST", "/apikeys", `{"name":"bot","scopes":["tasks:read"]}`, http.StatusCreated},
+			{"POST", "/apikeys", `{"name":"bot","scopes":["root"]}`, http.StatusBadRequest},
+			{"DELETE", "/apikeys/1", "", http.StatusNoContent},
+			{"DELETE", "/apikeys/9", "", http.StatusNotFound},
+			{"GET", "/audit", "", http.StatusOK},
+			{"GET", "/webhooks", "", http.StatusOK},
+			{"POST", "/webhooks", `{"url":"https://ci.example.com/hook","event_types":["task.created"]}`, http.StatusCreated},
+			{"POST", "/webhooks", `{"url":"ftp://example.com","event_types":["task.created"]}`, http.StatusBadRequest},
+			{"GET", "/webhooks/1", "", http.StatusOK},
+			{"GET", "/webhooks/9", "", http.StatusNotFound},
+			{"DELETE", "/webhooks/1", "", http.StatusNoContent},
+			{"POST", "/webhooks/1/enable", "", http.StatusNoContent},
+			{"GET", "/webhooks/1/deliveries", "", http.StatusOK},
+			{"POST", "/webhooks/1/deliveries/3/replay", "", http.StatusAccepted},
+			{"POST", "/webhooks/1/deliveries/9/replay", "", http.StatusNotFound},
+			{"GET", "/users/me/notification-preferences", "", http.StatusOK},
+			{"PUT", "/users/me/notification-preferences", `{"channels":["in_app","webhook"],"webhook_url":"https://ci.example.com/notify"}`, http.StatusOK},
+			{"PUT", "/users/me/notification-preferences", `{"channels":["sms"]}`, http.StatusBadRequest},
+			{"GET", "/users/me/notifications", "", http.StatusOK},
+			{"GET", "/users/me/notifications?limit=0", "", http.StatusBadRequest},
+			{"POST", "/users/me/notifications/2/read", "", http.StatusNoContent},
+			{"POST", "/users/me/notifications/9/read", "", http.StatusNotFound},
+			{"POST", "/users/me/notifications/read-all", "", http.StatusNoContent},
+			{"GET", "/tasks/1/watchers", "", http.StatusOK},
+			{"GET", "/tasks/9/watchers", "", http.StatusNotFound},
+			{"POST", "/tasks/1/watchers", "", http.StatusNoContent},
+			{"DELETE", "/tasks/1/watchers", "", http.StatusNoContent},
+			{"GET", "/users/me/watching?limit=1", "", http.StatusOK},
+			{"GET", "/users/me/watching?after=x", "", http.StatusBadRequest},
+			{"GET", "/board", "", http.StatusOK},
+			{"POST", "/tasks/1/move", `{"status":"done"}`, http.StatusOK},
+			{"POST", "/tasks/1/move", `{"after":"7"}`, http.StatusBadRequest},
+			{"POST", "/tasks/9/move", `{"status":"done"}`, http.StatusNotFound},
+			{"POST", "/tasks/1/move", `{"status":"done","after":"7"}`, http.StatusConflict},
+			{"POST", "/tasks/1/timer", "", http.StatusUnauthorized},
+			{"POST", "/tasks/1/timer", `{`, http.StatusBadRequest},
+			{"DELETE", "/tasks/1/timer", "", http.StatusOK},
+			{"DELETE", "/tasks/9/timer", "", http.StatusNotFound},
+			{"GET", "/tasks/1/worklogs", "", http.StatusOK},
+			{"GET", "/tasks/9/worklogs", "", http.StatusNotFound},
+			{"POST", "/tasks/1/worklogs", `{"duration":0}`, http.StatusBadRequest},
+			{"POST", "/tasks/1/worklogs", `{"duration":60}`, http.StatusUnauthorized},
+			{"GET", "/reports/time?from=2026-01-01&group_by=user,task", "", http.StatusOK},
+			{"GET", "/reports/time?format=csv", "", http.StatusOK},
+			{"GET", "/reports/time?group_by=tag", "", http.StatusBadRequest},
+			{"GET", "/sprints", "", http.StatusOK},
+			{"POST", "/sprints", `{"name":"Sprint 2","start_date":"2026-01-19","end_date":"2026-01-30","capacity":20}`, http.StatusCreated},
+			{"POST", "/sprints", `{"name":"Sprint 2","start_date":"2026-01-19"}`, http.StatusBadRequest},
+			{"GET", "/sprints/1", "", http.StatusOK},
+			{"GET", "/sprints/9", "", http.StatusNotFound},
+			{"PUT", "/sprints/1", `{"name":"Sprint 1","start_date":"2026-01-05","end_date":"2026-01-16"}`, http.StatusOK},
+			{"PUT", "/sprints/1", `{"name":""}`, http.StatusBadRequest},
+			{"DELETE", "/sprints/1", "", http.StatusNoContent},
+			{"DELETE", "/sprints/9", "", http.StatusNotFound},
+			{"GET", "/sprints/1/tasks", "", http.StatusOK},
+			{"GET", "/sprints/9/tasks", "", http.StatusNotFound},
+			{"GET", "/sprints/1/progress", "", http.StatusOK},
+			{"GET", "/sprints/9/progress", "", http.StatusNotFound},
+			{"POST", "/sprints/1/carry-over", "", http.StatusOK},
+			{"POST", "/sprints/1/carry-over", `{"to":"7"}`, http.StatusBadRequest},
+			{"POST", "/sprints/2/carry-over", "", http.StatusConflict},
+			{"POST", "/sprints/9/carry-over", "", http.StatusNotFound},
+			{"GET", "/reports/burndown?sprint=1", "", http.StatusOK},
+			{"GET", "/reports/burndown", "", http.StatusBadRequest},
+			{"GET", "/reports/burndown?sprint=9", "", http.StatusNotFound},
+			{"GET", "/reports/cfd?from=2026-01-05&to=2026-01-05", "", http.StatusOK},
+			{"GET", "/reports/cfd?from=2026-01-06&to=2026-01-05", "", http.StatusBadRequest},
+			{"GET", "/stats", "", http.StatusOK},
+			{"GET", "/healthz", "", http.StatusOK},
+			{"GET", "/readyz", "", http.StatusOK},
+			{"GET", "/version", "", http.StatusOK},
+			{"GET", "/metrics", "", http.StatusOK},
+			{"GET", "/openapi.json", "", http.StatusOK},
+		}
+
+		exercised := map[string]bool{}
+		for _, c := range calls {
+			request := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			if c.body != "" {
+				request.Header.Set("Content-Type", "application/json")
+			}
+			for _, prefix := range adminPaths {
+				if strings.HasPrefix(c.path, prefix) {
+					request.Header.Set(apiKeyHeader, adminKey)
+				}
+			}
+			for _, prefix := range userPaths {
+				if strings.HasPrefix(c.path, prefix) {
+					request.Header.Set(apiKeyHeader, userKey)
+				}
+			}
+			var match mux.RouteMatch
+			Expect(router.Match(request, &match)).To(BeTrue(), c.method+" "+c.path)
+			template, err := match.Route.GetPathTemplate()
+			Expect(err).To(Succeed())
+			path := specPath(template)
+			name := c.method + " " + path + " " + c.body
+
+			op, ok := operation(path, strings.ToLower(c.method))
+			Expect(ok).To(BeTrue(), "undocumented operation %s", name)
+			exercised[c.method+" "+path] = true
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(c.status), name)
+
+			status := strconv.Itoa(recorder.Code)
+			response, ok := op.Responses[status]
+			Expect(ok).To(BeTrue(), "undocumented response %s for %s", status, name)
+			if response.Ref != "" {
+				response = spec.Components.Responses[strings.TrimPrefix(response.Ref, "#/components/responses/")]
+			}
+			if len(response.Content) == 0 {
+				Expect(recorder.Body.Len()).To(BeZero(), name)
+				continue
+			}
+
+			mediaType, _, err := mime.ParseMediaType(recorder.Header().Get("Content-Type"))
+			Expect(err).To(Succeed(), name)
+			Expect(response.Content).To(HaveKey(mediaType), name)
+			if mediaType != "application/json" {
+				continue
+			}
+
+			pointer := "#/paths/" + pointerEscape(path) + "/" + strings.ToLower(c.method) +
+				"/responses/" + status + "/content/application~1json/schema"
+			schema, err := compiler.Compile(specURL + pointer)
+			Expect(err).To(Succeed(), name)
+			body, err := jsonschema.UnmarshalJSON(recorder.Body)
+			Expect(err).To(Succeed(), name)
+			Expect(schema.Validate(body)).To(Succeed(), name)
+		}
+
+		var missing []string
+		for path, item := range spec.Paths {
+			for method := range item {
+				name := strings.ToUpper(method) + " " + path
+				if openAPIMethods[method] && !exercised[name] && !streamed[name] {
+					missing = append(missing, name)
+				}
+			}
+		}
+		sort.Strings(missing)
+		Expect(missing).To(BeEmpty(), "operations without a contract check")
+	})
+})
@@ -1,54 +1,161 @@
 package utils
 
 import (
-	"github.com/gorilla/mux"
-	"github.com/saarzur123/task-management/backend/handler"
-	"github.com/saarzur123/task-management/backend/service"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func SetupRoutes(taskRepository service.TaskRepository) *mux.Router {
-	taskHandler := handler.TaskHandler{DB: taskRepository}
+const (
+	headerOrigin        = "Origin"
+	headerVary          = "Vary"
+	headerRequestMethod = "Access-Control-Request-Method"
+	wildcard            = "*"
+)
+
+// CORSConfig describes which cross-origin callers may use the API.
+// An allowed origin is either an exact origin ("https://tasks.example.com"),
+// a wildcard subdomain ("https://*.example.com") or "*" for any origin.
+// Browsers refuse credentials from any origin, so "*" ignores
+// AllowCredentials.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	MaxAge           time.Duration
+	AllowCredentials bool
+}
+
+// DefaultCORSConfig allows the frontend served by the development server.
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins:   []string{"http://localhost:3000"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", apiKeyHeader, headerRequestID, "traceparent", "tracestate"},
+		ExposedHeaders:   []string{headerRequestID, "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
+		MaxAge:           10 * time.Minute,
+		AllowCredentials: true,
+	}
+}
+
+type wildcardOrigin struct {
+	prefix string
+	suffix string
+}
 
-	router := mux.NewRouter()
+// CORS applies a CORSConfig to the responses of a router.
+type CORS struct {
+	exact          map[string]bool
+	wildcards      []wildcardOrigin
+	allowedMethods string
+	allowedHeaders string
+	exposedHeaders string
+	maxAge         string
+	config         CORSConfig
+	allowAll       bool
+}
 
-	router.Use(corsMiddleware)
+func NewCORS(config CORSConfig) *CORS {
+	c := &CORS{
+		config:         config,
+		exact:          make(map[string]bool),
+		allowedMethods: strings.Join(config.AllowedMethods, ", "),
+		allowedHeaders: strings.Join(config.AllowedHeaders, ", "),
+		exposedHeaders: strings.Join(config.ExposedHeaders, ", "),
+	}
+	if config.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+
+	for _, origin := range config.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch {
+		case origin == wildcard:
+			c.allowAll = true
+		case strings.Contains(origin, "://*."):
+			i := strings.Index(origin, wildcard)
+			c.wildcards = append(c.wildcards, wildcardOrigin{prefix: origin[:i], suffix: origin[i+1:]})
+		case origin != "":
+			c.exact[origin] = true
+		}
+	}
+	if c.allowAll {
+		c.config.AllowCredentials = false
+	}
 
-	router.HandleFunc("/tasks", taskHandler.CreateTask).Methods(http.MethodPost)
-	router.HandleFunc("/tasks", taskHandler.GetAllTasks).Methods("GET")
-	router.HandleFunc("/tasks/{id:[0-9]+}", taskHandler.GetTask).Methods("GET")
-	router.HandleFunc("/tasks/{id:[0-9]+}", taskHandler.UpdateTask).Methods("PUT")
-	router.HandleFunc("/tasks/{id:[0-9]+}", taskHandler.DeleteTask).Methods("DELETE")
+	return c
+}
 
-	router.HandleFunc("/tasks", corsHandler).Methods("OPTIONS")
-	router.HandleFunc("/tasks/{id:[0-9]+}", corsHandler).Methods("OPTIONS")
+func (c *CORS) originAllowed(origin string) bool {
+	if c.allowAll {
+		return true
+	}
 
-	return router
+	origin = strings.ToLower(origin)
+	if c.exact[origin] {
+		return true
+	}
+	for _, w := range c.wildcards {
+		if len(origin) > len(w.prefix)+len(w.suffix) &&
+			strings.HasPrefix(origin, w.prefix) && strings.HasSuffix(origin, w.suffix) {
+			return true
+		}
+	}
+	return false
 }
 
-func corsMiddleware(next http.Handler) http.Handler {
+// Middleware reflects the request origin when it is allowed, or answers any
+// origin with "*", and answers preflight requests without calling the next
+// handler.
+func (c *CORS) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		header := w.Header()
+		header.Add(headerVary, headerOrigin)
+
+		origin := r.Header.Get(headerOrigin)
+		isPreflight := r.Method == http.MethodOptions && r.Header.Get(headerRequestMethod) != ""
+		if origin == "" || !c.originAllowed(origin) {
+			if isPreflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
 
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
+		if c.allowAll {
+			header.Set("Access-Control-Allow-Origin", wildcard)
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !isPreflight {
+			if c.exposedHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", c.exposedHeaders)
+			}
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		header.Add(headerVary, headerRequestMethod)
+		header.Add(headerVary, "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Methods", c.allowedMethods)
+		header.Set("Access-Control-Allow-Headers", c.allowedHeaders)
+		if c.maxAge != "" {
+			header.Set("Access-Control-Max-Age", c.maxAge)
+		}
+		w.WriteHeader(http.StatusNoContent)
 	})
 }
 
-func corsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.WriteHeader(http.StatusOK)
-		return
-	}
+// Options answers OPTIONS requests that are not CORS preflights, those are
+// answered by Middleware before reaching it.
+func (c *CORS) Options(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Allow", c.allowedMethods)
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+var _ = Describe("CORS", func() {
+	var (
+		mockTasks *serviceMock.MockTaskRepository
+		router    http.Handler
+		config    = CORSConfig{
+			AllowedOrigins:   []string{"http://localhost:3000", "https://*.example.com"},
+			AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+			AllowedHeaders:   []string{"Content-Type"},
+			ExposedHeaders:   []string{"X-Request-ID"},
+			MaxAge:           time.Hour,
+			AllowCredentials: true,
+		}
+	)
+
+	BeforeEach(func() {
+		mockTasks = serviceMock.NewMockTaskRepository(gomock.NewController(GinkgoT()))
+		router = SetupRoutes(mockTasks, WithCORS(config))
+	})
+
+	request := func(method, path, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			r.Header.Set("Access-Control-Request-Method", http.MethodPut)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, r)
+		return recorder
+	}
+
+	Describe("origin matching", func() {
+		cors := NewCORS(config)
+
+		DescribeTable("originAllowed",
+			func(origin string, allowed bool) {
+				Expect(cors.originAllowed(origin)).To(Equal(allowed))
+			},
+			Entry("exact origin", "http://localhost:3000", true),
+			Entry("different port", "http://localhost:3001", false),
+			Entry("subdomain", "https://app.example.com", true),
+			Entry("nested subdomain", "https://a.b.example.com", true),
+			Entry("apex domain", "https://example.com", false),
+			Entry("other scheme", "http://app.example.com", false),
+			Entry("look-alike domain", "https://app.badexample.com", false),
+		)
+
+		It("allows any origin with *", func() {
+			Expect(NewCORS(CORSConfig{AllowedOrigins: []string{"*"}}).originAllowed("https://any.org")).To(BeTrue())
+		})
+	})
+
+	It("reflects an allowed origin on actual requests", func() {
+		mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{}, nil)
+
+		recorder := request(http.MethodGet, "/tasks", "https://app.example.com")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://app.example.com"))
+		Expect(recorder.Header().Get("Access-Control-Allow-Credentials")).To(Equal("true"))
+		Expect(recorder.Header().Get("Access-Control-Expose-Headers")).To(Equal("X-Request-ID"))
+		Expect(recorder.Header().Values("Vary")).To(ContainElement("Origin"))
+	})
+
+	It("answers any origin with * and without credentials", func() {
+		anyOrigin := config
+		anyOrigin.AllowedOrigins = []string{"*"}
+		router = SetupRoutes(mockTasks, WithCORS(anyOrigin))
+		mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{}, nil)
+
+		recorder := request(http.MethodGet, "/tasks", "https://any.org")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Access-Control-Allow-Origin")).To(Equal("*"))
+		Expect(recorder.Header().Values("Access-Control-Allow-Credentials")).To(BeEmpty())
+
+		recorder = request(http.MethodOptions, "/tasks", "https://any.org")
+		Expect(recorder.Header().Get("Access-Control-Allow-Origin")).To(Equal("*"))
+		Expect(recorder.Header().Values("Access-Control-Allow-Credentials")).To(BeEmpty())
+	})
+
+	It("doesn't add CORS headers for origins outside the allow-list", func() {
+		mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{}, nil)
+
+		recorder := request(http.MethodGet, "/tasks", "https://evil.org")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		Expect(recorder.Header().Values("Vary")).To(ContainElement("Origin"))
+	})
+
+	It("answers preflights on every route", func() {
+		for _, path := range []string{"/tasks", "/tasks/1", "/apikeys"} {
+			recorder := request(http.MethodOptions, path, "http://localhost:3000")
+			Expect(recorder.Code).To(Equal(http.StatusNoContent), path)
+			Expect(recorder.Header().Get("Access-Control-Allow-Origin")).To(Equal("http://localhost:3000"))
+			Expect(recorder.Header().Get("Access-Control-Allow-Methods")).To(Equal("GET, POST"))
+			Expect(recorder.Header().Get("Access-Control-Allow-Headers")).To(Equal("Content-Type"))
+			Expect(recorder.Header().Get("Access-Control-Max-Age")).To(Equal("3600"))
+		}
+	})
+
+	It("answers preflights from disallowed origins without CORS headers", func() {
+		recorder := request(http.MethodOptions, "/tasks", "https://evil.org")
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		Expect(recorder.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		Expect(recorder.Header().Get("Access-Control-Allow-Methods")).To(BeEmpty())
+	})
+})
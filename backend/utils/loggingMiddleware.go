@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gorilla/mux"
+	"github.com/saarzur123/task-management/backend/handler"
+	"github.com/saarzur123/task-management/backend/logging"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	headerRequestID    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+type requestLogContextKey struct{}
+
+// requestLog collects what inner middlewares learn about a request, such as
+// the authenticated principal, for the access log line.
+type requestLog struct {
+	principal string
+}
+
+// setPrincipal records who made the request in its access log line.
+func setPrincipal(r *http.Request, principal string) {
+	if entry, ok := r.Context().Value(requestLogContextKey{}).(*requestLog); ok {
+		entry.principal = principal
+	}
+}
+
+// validRequestID accepts caller supplied IDs made of safe characters only, so
+// they can't be used to forge log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// loggingMiddleware assigns every request an ID, taken from X-Request-ID when
+// the caller sent a valid one, stores a logger carrying it in the request
+// context and writes one line per request once it is served.
+func loggingMiddleware(logger *slog.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			requestID := r.Header.Get(headerRequestID)
+			if !validRequestID(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(headerRequestID, requestID)
+
+			entry := &requestLog{principal: handler.Actor(r)}
+			requestLogger := logger.With(slog.String("request_id", requestID))
+			if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.HasTraceID() {
+				requestLogger = requestLogger.With(slog.String("trace_id", spanContext.TraceID().String()))
+			}
+			ctx := logging.WithLogger(r.Context(), requestLogger)
+			ctx = logging.WithRequestID(ctx, requestID)
+			ctx = context.WithValue(ctx, requestLogContextKey{}, entry)
+
+			recorder := newStatusRecorder(w)
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			level := slog.LevelInfo
+			if recorder.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			requestLogger.LogAttrs(ctx, level, "request",
+				slog.String("method", r.Method),
+				slog.String("route", routeTemplate(r)),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", recorder.status),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.Int("bytes", recorder.bytes),
+				slog.String("principal", entry.principal))
+		})
+	}
+}
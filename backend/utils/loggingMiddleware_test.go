@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/logging"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("loggingMiddleware", func() {
+	var (
+		mockTasks *serviceMock.MockTaskRepository
+		mockKeys  *serviceMock.MockAPIKeyRepository
+		mockAudit *serviceMock.MockAuditRepository
+		output    *bytes.Buffer
+		router    http.Handler
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockTasks = serviceMock.NewMockTaskRepository(mockCtrl)
+		mockKeys = serviceMock.NewMockAPIKeyRepository(mockCtrl)
+		mockKeys.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		mockAudit = serviceMock.NewMockAuditRepository(mockCtrl)
+		output = &bytes.Buffer{}
+		router = SetupRoutes(mockTasks,
+			WithLogger(logging.New(output, slog.LevelInfo)),
+			WithAPIKeys(mockKeys, mockAudit, false))
+	})
+
+	lastLine := func() map[string]any {
+		lines := bytes.Split(bytes.TrimSpace(output.Bytes()), []byte("\n"))
+		var line map[string]any
+		Expect(json.Unmarshal(lines[len(lines)-1], &line)).To(Succeed())
+		return line
+	}
+
+	It("logs one JSON line per request with a generated request ID", func() {
+		mockTasks.EXPECT().GetByID(gomock.Any(), "7").Return(&models.Task{ID: "7"}, nil)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/tasks/7", nil))
+
+		requestID := recorder.Header().Get("X-Request-ID")
+		Expect(requestID).To(HaveLen(32))
+		line := lastLine()
+		Expect(line["msg"]).To(Equal("request"))
+		Expect(line["method"]).To(Equal("GET"))
+		Expect(line["route"]).To(Equal("/tasks/{id:[0-9]+}"))
+		Expect(line["status"]).To(BeEquivalentTo(http.StatusOK))
+		Expect(line["bytes"]).To(BeEquivalentTo(recorder.Body.Len()))
+		Expect(line["request_id"]).To(Equal(requestID))
+		Expect(line["principal"]).To(Equal("anonymous"))
+		Expect(line).To(HaveKey("latency_ms"))
+	})
+
+	It("keeps a valid caller supplied request ID and records the API key", func() {
+		mockKeys.EXPECT().Authenticate(gomock.Any(), "tm_key").Return(&models.APIKey{Name: "ci", Scopes: []string{models.ScopeTasksRead}}, nil)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+		mockTasks.EXPECT().GetAll(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]models.Task, error) {
+			Expect(logging.RequestIDFromContext(ctx)).To(Equal("build-42"))
+			logging.FromContext(ctx).Info("inside repository")
+			return []models.Task{}, nil
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+		request.Header.Set("X-Request-ID", "build-42")
+		request.Header.Set("X-API-Key", "tm_key")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		Expect(recorder.Header().Get("X-Request-ID")).To(Equal("build-42"))
+		Expect(output.String()).To(ContainSubstring(`"msg":"inside repository","request_id":"build-42"`))
+		Expect(lastLine()["principal"]).To(Equal("apikey:ci"))
+	})
+
+	It("replaces request IDs with unsafe characters", func() {
+		mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{}, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+		request.Header.Set("X-Request-ID", "a\nfake line")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		Expect(recorder.Header().Get("X-Request-ID")).To(HaveLen(32))
+	})
+
+	It("logs server errors at error level", func() {
+		mockTasks.EXPECT().GetAll(gomock.Any()).Return(nil, errMock)
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tasks", nil))
+		Expect(lastLine()["level"]).To(Equal("ERROR"))
+	})
+})
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/saarzur123/task-management/backend/metrics"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// httpMetrics are the request metrics labeled by route template, so /tasks/1
+// and /tasks/2 are counted together.
+type httpMetrics struct {
+	requests *metrics.CounterVec
+	duration *metrics.HistogramVec
+	inFlight *metrics.Gauge
+}
+
+func newHTTPMetrics(registry *metrics.Registry) *httpMetrics {
+	return &httpMetrics{
+		requests: registry.NewCounterVec("http_requests_total",
+			"Number of HTTP requests handled.", "method", "route", "code"),
+		duration: registry.NewHistogramVec("http_request_duration_seconds",
+			"Latency of HTTP requests.", metrics.DefBuckets, "method", "route", "code"),
+		inFlight: registry.NewGaugeVec("http_requests_in_flight",
+			"Number of HTTP requests currently being served.").WithLabelValues(),
+	}
+}
+
+func (m *httpMetrics) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		start := time.Now()
+		recorder := newStatusRecorder(w)
+		next.ServeHTTP(recorder, r)
+
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		code := strconv.Itoa(recorder.status)
+		m.requests.WithLabelValues(r.Method, route, code).Inc()
+		m.duration.WithLabelValues(r.Method, route, code).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/metrics"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("metrics", func() {
+	var (
+		mockTasks *serviceMock.MockTaskRepository
+		router    http.Handler
+	)
+
+	BeforeEach(func() {
+		mockTasks = serviceMock.NewMockTaskRepository(gomock.NewController(GinkgoT()))
+		router = SetupRoutes(mockTasks, WithMetrics(metrics.NewRegistry()))
+	})
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		return recorder
+	}
+
+	It("exposes request counts and latencies by route template and status code", func() {
+		mockTasks.EXPECT().GetByID(gomock.Any(), "1").Return(&models.Task{ID: "1"}, nil)
+		mockTasks.EXPECT().GetByID(gomock.Any(), "2").Return(&models.Task{ID: "2"}, nil)
+		mockTasks.EXPECT().Delete(gomock.Any(), "3").Return(service.ErrNotFound)
+
+		serve(http.MethodGet, "/tasks/1")
+		serve(http.MethodGet, "/tasks/2")
+		serve(http.MethodDelete, "/tasks/3")
+
+		scrape := serve(http.MethodGet, "/metrics")
+		Expect(scrape.Code).To(Equal(http.StatusOK))
+		body := scrape.Body.String()
+		Expect(body).To(ContainSubstring(`http_requests_total{method="GET",route="/tasks/{id:[0-9]+}",code="200"} 2`))
+		Expect(body).To(ContainSubstring(`http_requests_total{method="DELETE",route="/tasks/{id:[0-9]+}",code="404"} 1`))
+		Expect(body).To(ContainSubstring(`http_request_duration_seconds_count{method="GET",route="/tasks/{id:[0-9]+}",code="200"} 2`))
+		Expect(body).To(ContainSubstring(`http_request_duration_seconds_bucket{method="GET",route="/tasks/{id:[0-9]+}",code="200",le="+Inf"} 2`))
+		Expect(body).To(ContainSubstring("http_requests_in_flight 1"), "the scrape itself is in flight")
+	})
+
+	It("serves /metrics without an API key even when keys are required", func() {
+		keys := serviceMock.NewMockAPIKeyRepository(gomock.NewController(GinkgoT()))
+		keys.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		audit := serviceMock.NewMockAuditRepository(gomock.NewController(GinkgoT()))
+		router = SetupRoutes(mockTasks, WithMetrics(metrics.NewRegistry()), WithAPIKeys(keys, audit, true))
+
+		Expect(serve(http.MethodGet, "/metrics").Code).To(Equal(http.StatusOK))
+		Expect(serve(http.MethodGet, "/tasks").Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("keeps returning 405 for unsupported methods", func() {
+		Expect(serve(http.MethodPatch, "/tasks").Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -0,0 +1,247 @@
+package utils
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"github.com/saarzur123/task-management/backend/logging"
+	"github.com/saarzur123/task-management/backend/service"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerForwardedFor = "X-Forwarded-For"
+	bucketIdleTimeout  = 10 * time.Minute
+)
+
+// RateLimit is a token bucket allowing bursts of Requests that refills
+// completely every Per.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+func (l RateLimit) refillRate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// RateLimitDecision is the outcome of taking a token for a request.
+type RateLimitDecision struct {
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token is available when not Allowed.
+	RetryAfter time.Duration
+	Remaining  int
+	Allowed    bool
+}
+
+// RateLimitStore keeps the buckets of all clients. The in-memory store is
+// enough for a single instance, a shared store lets replicas share budgets.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitDecision, error)
+}
+
+type bucket struct {
+	updated time.Time
+	tokens  float64
+}
+
+// MemoryRateLimitStore keeps token buckets in process memory.
+type MemoryRateLimitStore struct {
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit RateLimit) (RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	rate := limit.refillRate()
+	capacity := float64(limit.Requests)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	decision := RateLimitDecision{}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = secondsToDuration((capacity - b.tokens) / rate)
+
+	return decision, nil
+}
+
+// sweep drops buckets that have been idle long enough to be full again.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < bucketIdleTimeout {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) > bucketIdleTimeout {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// RateLimitConfig sets separate budgets for read (GET/HEAD) and write routes.
+// X-Forwarded-For is only honored when the direct peer is a trusted proxy.
+type RateLimitConfig struct {
+	Store          RateLimitStore
+	TrustedProxies []netip.Prefix
+	Read           RateLimit
+	Write          RateLimit
+}
+
+// DefaultRateLimitConfig allows 300 reads and 60 writes per minute.
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Store: NewMemoryRateLimitStore(),
+		Read:  RateLimit{Requests: 300, Per: time.Minute},
+		Write: RateLimit{Requests: 60, Per: time.Minute},
+	}
+}
+
+// ParseTrustedProxies parses a comma separated list of IPs and CIDRs.
+func ParseTrustedProxies(value string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func (c RateLimitConfig) trusted(addr netip.Addr) bool {
+	for _, prefix := range c.TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the address of the caller. When the peer is a trusted
+// proxy, X-Forwarded-For is walked from the right and the first address not
+// belonging to a trusted proxy is used.
+func (c RateLimitConfig) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+	peer = peer.Unmap()
+	if !c.trusted(peer) {
+		return peer.String()
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values(headerForwardedFor), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		hop = hop.Unmap()
+		if !c.trusted(hop) {
+			return hop.String()
+		}
+		peer = hop
+	}
+	return peer.String()
+}
+
+// clientKey identifies the budget a request is charged to: its API key when
+// authenticated, its client IP otherwise.
+func (c RateLimitConfig) clientKey(r *http.Request) string {
+	if key, ok := service.APIKeyFromContext(r.Context()); ok {
+		return "apikey:" + key.ID
+	}
+	return "ip:" + c.clientIP(r)
+}
+
+func rateLimitMiddleware(config RateLimitConfig) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			limit, budget := config.Write, "write"
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				limit, budget = config.Read, "read"
+			}
+
+			decision, err := config.Store.Take(r.Context(), budget+":"+config.clientKey(r), limit)
+			if err != nil {
+				// Failing open keeps the API available when a shared store is down.
+				logging.FromContext(r.Context()).ErrorContext(r.Context(), "rate limit store failed", slog.Any("error", err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Policy", strconv.Itoa(limit.Requests)+";w="+strconv.Itoa(int(limit.Per.Seconds())))
+			header.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			header.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+			if !decision.Allowed {
+				header.Set("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
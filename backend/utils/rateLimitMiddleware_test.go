@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+var _ = Describe("rate limiting", func() {
+	var (
+		now   time.Time
+		store *MemoryRateLimitStore
+		limit = RateLimit{Requests: 2, Per: 10 * time.Second}
+	)
+
+	BeforeEach(func() {
+		now = time.Unix(1700000000, 0)
+		store = NewMemoryRateLimitStore()
+		store.now = func() time.Time { return now }
+	})
+
+	Describe("MemoryRateLimitStore", func() {
+		It("allows a burst and refills over time", func() {
+			ctx := context.Background()
+			decision, err := store.Take(ctx, "a", limit)
+			Expect(err).To(Succeed())
+			Expect(decision.Allowed).To(BeTrue())
+			Expect(decision.Remaining).To(Equal(1))
+
+			decision, _ = store.Take(ctx, "a", limit)
+			Expect(decision.Allowed).To(BeTrue())
+			Expect(decision.Remaining).To(Equal(0))
+			Expect(decision.Reset).To(Equal(10 * time.Second))
+
+			decision, _ = store.Take(ctx, "a", limit)
+			Expect(decision.Allowed).To(BeFalse())
+			Expect(decision.RetryAfter).To(Equal(5 * time.Second))
+
+			decision, _ = store.Take(ctx, "b", limit)
+			Expect(decision.Allowed).To(BeTrue(), "keys have separate buckets")
+
+			now = now.Add(5 * time.Second)
+			decision, _ = store.Take(ctx, "a", limit)
+			Expect(decision.Allowed).To(BeTrue())
+		})
+
+		It("drops idle buckets", func() {
+			store.Take(context.Background(), "a", limit)
+			now = now.Add(2 * bucketIdleTimeout)
+			store.Take(context.Background(), "b", limit)
+			Expect(store.buckets).To(HaveLen(1))
+			Expect(store.buckets).To(HaveKey("b"))
+		})
+	})
+
+	Describe("clientIP", func() {
+		proxies, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.1")
+		Expect(err).To(Succeed())
+		config := RateLimitConfig{TrustedProxies: proxies}
+
+		DescribeTable("resolves the client address",
+			func(remoteAddr, forwardedFor, expected string) {
+				r := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+				r.RemoteAddr = remoteAddr
+				if forwardedFor != "" {
+					r.Header.Set(headerForwardedFor, forwardedFor)
+				}
+				Expect(config.clientIP(r)).To(Equal(expected))
+			},
+			Entry("direct client", "203.0.113.7:1234", "", "203.0.113.7"),
+			Entry("untrusted peer spoofing the header", "203.0.113.7:1234", "1.2.3.4", "203.0.113.7"),
+			Entry("trusted proxy", "10.0.0.5:1234", "198.51.100.1", "198.51.100.1"),
+			Entry("chain of trusted proxies", "10.0.0.5:1234", "1.2.3.4, 198.51.100.1, 192.168.1.1", "198.51.100.1"),
+			Entry("trusted proxy without header", "10.0.0.5:1234", "", "10.0.0.5"),
+		)
+
+		It("rejects invalid proxy entries", func() {
+			_, err := ParseTrustedProxies("not-an-ip")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("middleware", func() {
+		var (
+			mockTasks *serviceMock.MockTaskRepository
+			router    http.Handler
+		)
+
+		BeforeEach(func() {
+			mockTasks = serviceMock.NewMockTaskRepository(gomock.NewController(GinkgoT()))
+			router = SetupRoutes(mockTasks, WithRateLimit(RateLimitConfig{
+				Store: store,
+				Read:  RateLimit{Requests: 2, Per: time.Minute},
+				Write: RateLimit{Requests: 1, Per: time.Minute},
+			}))
+		})
+
+		serve := func(method, path string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(method, path, nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, r)
+			return recorder
+		}
+
+		It("sets RateLimit headers and rejects requests over budget", func() {
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{}, nil).Times(2)
+
+			recorder := serve(http.MethodGet, "/tasks")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("RateLimit-Limit")).To(Equal("2"))
+			Expect(recorder.Header().Get("RateLimit-Remaining")).To(Equal("1"))
+			Expect(recorder.Header().Get("RateLimit-Policy")).To(Equal("2;w=60"))
+
+			Expect(serve(http.MethodGet, "/tasks").Code).To(Equal(http.StatusOK))
+
+			recorder = serve(http.MethodGet, "/tasks")
+			Expect(recorder.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(recorder.Header().Get("Retry-After")).To(Equal("30"))
+		})
+
+		It("keeps separate budgets for reads and writes", func() {
+			mockTasks.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+			mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{}, nil)
+
+			Expect(serve(http.MethodDelete, "/tasks/1").Code).To(Equal(http.StatusNoContent))
+			Expect(serve(http.MethodDelete, "/tasks/1").Code).To(Equal(http.StatusTooManyRequests))
+			Expect(serve(http.MethodGet, "/tasks").Code).To(Equal(http.StatusOK))
+		})
+
+		Describe("with API keys", func() {
+			const rawKey = "tm_secret"
+			var (
+				mockKeys  *serviceMock.MockAPIKeyRepository
+				mockAudit *serviceMock.MockAuditRepository
+			)
+
+			BeforeEach(func() {
+				mockCtrl := gomock.NewController(GinkgoT())
+				mockKeys = serviceMock.NewMockAPIKeyRepository(mockCtrl)
+				mockAudit = serviceMock.NewMockAuditRepository(mockCtrl)
+				router = SetupRoutes(mockTasks, WithAPIKeys(mockKeys, mockAudit, false), WithRateLimit(RateLimitConfig{
+					Store: store,
+					Read:  RateLimit{Requests: 1, Per: time.Minute},
+					Write: RateLimit{Requests: 1, Per: time.Minute},
+				}))
+			})
+
+			serveWithKey := func(key string) *httptest.ResponseRecorder {
+				r := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+				r.Header.Set(apiKeyHeader, key)
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, r)
+				return recorder
+			}
+
+			It("records nothing for the requests over budget", func() {
+				key := &models.APIKey{ID: "1", Name: "bot", Scopes: []string{models.ScopeTasksRead}}
+				mockKeys.EXPECT().Authenticate(gomock.Any(), rawKey).Return(key, nil).Times(2)
+				mockKeys.EXPECT().MarkUsed(gomock.Any(), key).Return(nil)
+				mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+				mockTasks.EXPECT().GetAll(gomock.Any()).Return([]models.Task{}, nil)
+
+				Expect(serveWithKey(rawKey).Code).To(Equal(http.StatusOK))
+				Expect(serveWithKey(rawKey).Code).To(Equal(http.StatusTooManyRequests))
+			})
+
+			It("charges invalid keys to the client IP", func() {
+				mockKeys.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(nil, service.ErrInvalidAPIKey).Times(2)
+
+				Expect(serveWithKey("tm_guess1").Code).To(Equal(http.StatusUnauthorized))
+				Expect(serveWithKey("tm_guess2").Code).To(Equal(http.StatusTooManyRequests))
+			})
+		})
+	})
+})
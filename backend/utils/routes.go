@@ -0,0 +1,380 @@
+package utils
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/saarzur123/task-management/backend/events"
+	"github.com/saarzur123/task-management/backend/graph"
+	"github.com/saarzur123/task-management/backend/handler"
+	"github.com/saarzur123/task-management/backend/metrics"
+	"github.com/saarzur123/task-management/backend/openapi"
+	"github.com/saarzur123/task-management/backend/presence"
+	"github.com/saarzur123/task-management/backend/service"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type routerConfig struct {
+	apiKeys        service.APIKeyRepository
+	audit          service.AuditRepository
+	rateLimit      *RateLimitConfig
+	metrics        *metrics.Registry
+	logger         *slog.Logger
+	health         *handler.HealthHandler
+	events         *events.Hub
+	presence       *presence.Hub
+	webhooks       service.WebhookRepository
+	webhookAudit   service.AuditRepository
+	eventFeed      *handler.EventFeedHandler
+	graphql        *graph.Resolver
+	taskPages      service.TaskPageReader
+	notifications  service.NotificationPreferencesRepository
+	inbox          service.NotificationInbox
+	watchers       service.WatcherRepository
+	board          service.TaskBoard
+	timeTracker    service.TimeTracker
+	sprints        service.SprintPlanner
+	flowReports    service.FlowReporter
+	stats          service.TaskStatsReader
+	cors           CORSConfig
+	apiKeyRequired bool
+}
+
+// Option configures the optional parts of the router built by SetupRoutes.
+type Option func(*routerConfig)
+
+// WithAPIKeys registers the API key management routes and the middleware
+// authenticating X-API-Key headers. When required is set, every request has
+// to carry a valid key.
+func WithAPIKeys(apiKeys service.APIKeyRepository, audit service.AuditRepository, required bool) Option {
+	return func(c *routerConfig) {
+		c.apiKeys = apiKeys
+		c.audit = audit
+		c.apiKeyRequired = required
+	}
+}
+
+// WithCORS replaces the default CORS policy, which only allows the local
+// frontend.
+func WithCORS(cors CORSConfig) Option {
+	return func(c *routerConfig) {
+		c.cors = cors
+	}
+}
+
+// WithRateLimit limits the requests each API key or client IP can make.
+func WithRateLimit(rateLimit RateLimitConfig) Option {
+	return func(c *routerConfig) {
+		c.rateLimit = &rateLimit
+	}
+}
+
+// WithMetrics records request metrics in registry and serves it on /metrics.
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(c *routerConfig) {
+		c.metrics = registry
+	}
+}
+
+const (
+	// publicRoutePrefix names the operational routes that skip authentication
+	// and rate limiting.
+	publicRoutePrefix = "public:"
+)
+
+func skipPublicRoutes(middleware mux.MiddlewareFunc) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		wrapped := middleware(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := mux.CurrentRoute(r); route != nil && strings.HasPrefix(route.GetName(), publicRoutePrefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			wrapped.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithLogger writes one structured line per request to logger and makes a
+// logger carrying the request ID available through the request context.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *routerConfig) {
+		c.logger = logger
+	}
+}
+
+// WithHealth serves the liveness, readiness and build-info probes of health.
+func WithHealth(health *handler.HealthHandler) Option {
+	return func(c *routerConfig) {
+		c.health = health
+	}
+}
+
+// WithEvents streams the task changes published to hub on /tasks/events.
+func WithEvents(hub *events.Hub) Option {
+	return func(c *routerConfig) {
+		c.events = hub
+	}
+}
+
+// WithPresence serves the presence WebSocket of hub on /tasks/presence.
+func WithPresence(hub *presence.Hub) Option {
+	return func(c *routerConfig) {
+		c.presence = hub
+	}
+}
+
+// WithWebhooks manages the webhook subscriptions on /webhooks, recording the
+// changes in audit.
+func WithWebhooks(webhooks service.WebhookRepository, audit service.AuditRepository) Option {
+	return func(c *routerConfig) {
+		c.webhooks = webhooks
+		c.webhookAudit = audit
+	}
+}
+
+// WithEventFeed serves the long-polling feed of the task events on /events.
+func WithEventFeed(feed *handler.EventFeedHandler) Option {
+	return func(c *routerConfig) {
+		c.eventFeed = feed
+	}
+}
+
+// WithGraphQL serves the GraphQL API of resolver on /graphql.
+func WithGraphQL(resolver *graph.Resolver) Option {
+	return func(c *routerConfig) {
+		c.graphql = resolver
+	}
+}
+
+// WithTaskPages lets GET /tasks return the tasks a page at a time from
+// pages, when the after or limit query parameters are set.
+func WithTaskPages(pages service.TaskPageReader) Option {
+	return func(c *routerConfig) {
+		c.taskPages = pages
+	}
+}
+
+// WithNotifications lets the callers manage their notification preferences
+// on /users/me/notification-preferences and read their in-app notifications
+// on /users/me/notifications.
+func WithNotifications(preferences service.NotificationPreferencesRepository, inbox service.NotificationInbox) Option {
+	return func(c *routerConfig) {
+		c.notifications = preferences
+		c.inbox = inbox
+	}
+}
+
+// WithWatchers lets the callers follow tasks on /tasks/{id}/watchers and list
+// the ones they follow on /users/me/watching.
+func WithWatchers(watchers service.WatcherRepository) Option {
+	return func(c *routerConfig) {
+		c.watchers = watchers
+	}
+}
+
+// WithBoard serves the Kanban board on /board and moves its cards with
+// POST /tasks/{id}/move.
+func WithBoard(board service.TaskBoard) Option {
+	return func(c *routerConfig) {
+		c.board = board
+	}
+}
+
+// WithTimeTracking lets the callers track their time on tasks with timers
+// and worklogs, adds the time logged to GET /tasks/{id} and serves the
+// time report on /reports/time.
+func WithTimeTracking(tracker service.TimeTracker) Option {
+	return func(c *routerConfig) {
+		c.timeTracker = tracker
+	}
+}
+
+// WithSprints serves the sprints on /sprints, with their tasks, progress and
+// carry-over of unfinished tasks.
+func WithSprints(sprints service.SprintPlanner) Option {
+	return func(c *routerConfig) {
+		c.sprints = sprints
+	}
+}
+
+// WithFlowReports serves the burndown of the sprints on /reports/burndown and
+// the cumulative flow of the tasks on /reports/cfd.
+func WithFlowReports(reports service.FlowReporter) Option {
+	return func(c *routerConfig) {
+		c.flowReports = reports
+	}
+}
+
+// WithStats serves the statistics of the dashboard on /stats.
+func WithStats(stats service.TaskStatsReader) Option {
+	return func(c *routerConfig) {
+		c.stats = stats
+	}
+}
+
+func SetupRoutes(taskRepository service.TaskRepository, options ...Option) *mux.Router {
+	config := routerConfig{cors: DefaultCORSConfig()}
+	for _, option := range options {
+		option(&config)
+	}
+
+	taskHandler := handler.TaskHandler{DB: taskRepository, Pages: config.taskPages, Time: config.timeTracker}
+
+	router := mux.NewRouter()
+
+	router.Use(tracingMiddleware)
+
+	if config.logger != nil {
+		router.Use(loggingMiddleware(config.logger))
+	}
+
+	if config.metrics != nil {
+		router.Use(newHTTPMetrics(config.metrics).middleware)
+	}
+
+	cors := NewCORS(config.cors)
+	router.Use(cors.Middleware)
+
+	// Matches OPTIONS on every path so preflights go through the CORS middleware.
+	router.Methods(http.MethodOptions).HandlerFunc(cors.Options)
+
+	if config.health != nil {
+		router.HandleFunc("/healthz", config.health.Healthz).Methods(http.MethodGet).Name(publicRoutePrefix + "healthz")
+		router.HandleFunc("/readyz", config.health.Readyz).Methods(http.MethodGet).Name(publicRoutePrefix + "readyz")
+		router.HandleFunc("/version", config.health.Version).Methods(http.MethodGet).Name(publicRoutePrefix + "version")
+	}
+
+	if config.metrics != nil {
+		router.Handle("/metrics", config.metrics.Handler()).Methods(http.MethodGet).Name(publicRoutePrefix + "metrics")
+	}
+
+	router.Handle(openapi.SpecPath, openapi.SpecHandler()).Methods(http.MethodGet).Name(publicRoutePrefix + "openapi")
+	router.PathPrefix(openapi.DocsPath).Handler(openapi.DocsHandler()).Methods(http.MethodGet).Name(publicRoutePrefix + "docs")
+
+	// Authentication only reads the key, the rate limit runs before anything
+	// is refused or recorded for it.
+	if config.apiKeys != nil {
+		router.Use(skipPublicRoutes(authenticateMiddleware(config.apiKeys)))
+	}
+
+	if config.rateLimit != nil {
+		router.Use(skipPublicRoutes(rateLimitMiddleware(*config.rateLimit)))
+	}
+
+	if config.apiKeys != nil {
+		router.Use(skipPublicRoutes(authorizeMiddleware(config.apiKeys, config.audit, config.apiKeyRequired)))
+
+		apiKeyHandler := handler.APIKeyHandler{DB: config.apiKeys, Audit: config.audit}
+
+		router.HandleFunc("/apikeys", apiKeyHandler.CreateAPIKey).Methods(http.MethodPost)
+		router.HandleFunc("/apikeys", apiKeyHandler.GetAllAPIKeys).Methods(http.MethodGet)
+		router.HandleFunc("/apikeys/{id:[0-9]+}", apiKeyHandler.RevokeAPIKey).Methods(http.MethodDelete)
+		router.HandleFunc("/audit", apiKeyHandler.GetAuditLog).Methods(http.MethodGet)
+	}
+
+	if config.webhooks != nil {
+		webhookHandler := handler.WebhookHandler{DB: config.webhooks, Audit: config.webhookAudit}
+		router.HandleFunc("/webhooks", webhookHandler.CreateWebhook).Methods(http.MethodPost)
+		router.HandleFunc("/webhooks", webhookHandler.GetAllWebhooks).Methods(http.MethodGet)
+		router.HandleFunc("/webhooks/{id:[0-9]+}", webhookHandler.GetWebhook).Methods(http.MethodGet)
+		router.HandleFunc("/webhooks/{id:[0-9]+}", webhookHandler.DeleteWebhook).Methods(http.MethodDelete)
+		router.HandleFunc("/webhooks/{id:[0-9]+}/enable", webhookHandler.EnableWebhook).Methods(http.MethodPost)
+		router.HandleFunc("/webhooks/{id:[0-9]+}/deliveries", webhookHandler.GetDeliveries).Methods(http.MethodGet)
+		router.HandleFunc("/webhooks/{id:[0-9]+}/deliveries/{deliveryID:[0-9]+}/replay", webhookHandler.ReplayDelivery).Methods(http.MethodPost)
+	}
+
+	if config.events != nil {
+		eventsHandler := handler.EventsHandler{Hub: config.events}
+		router.HandleFunc("/tasks/events", eventsHandler.StreamTaskEvents).Methods(http.MethodGet)
+	}
+
+	if config.eventFeed != nil {
+		router.HandleFunc("/events", config.eventFeed.GetEvents).Methods(http.MethodGet)
+	}
+
+	// WebSocket handshakes are not subject to CORS, check their origin.
+	checkOrigin := func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || cors.originAllowed(origin)
+	}
+
+	if config.presence != nil {
+		presenceHandler := handler.PresenceHandler{
+			Hub: config.presence,
+			Upgrader: websocket.Upgrader{
+				Subprotocols: []string{presence.Subprotocol},
+				CheckOrigin:  checkOrigin,
+			},
+		}
+		router.HandleFunc("/tasks/presence", presenceHandler.Connect).Methods(http.MethodGet)
+	}
+
+	if config.graphql != nil {
+		router.Handle("/graphql", graph.NewHandler(config.graphql, checkOrigin)).Methods(http.MethodGet, http.MethodPost)
+	}
+
+	if config.notifications != nil {
+		notificationHandler := handler.NotificationHandler{Preferences: config.notifications, Inbox: config.inbox}
+		router.HandleFunc("/users/me/notification-preferences", notificationHandler.GetPreferences).Methods(http.MethodGet)
+		router.HandleFunc("/users/me/notification-preferences", notificationHandler.UpdatePreferences).Methods(http.MethodPut)
+		router.HandleFunc("/users/me/notifications", notificationHandler.GetNotifications).Methods(http.MethodGet)
+		router.HandleFunc("/users/me/notifications/read-all", notificationHandler.MarkAllNotificationsRead).Methods(http.MethodPost)
+		router.HandleFunc("/users/me/notifications/{id:[0-9]+}/read", notificationHandler.MarkNotificationRead).Methods(http.MethodPost)
+	}
+
+	if config.watchers != nil {
+		watcherHandler := handler.WatcherHandler{DB: config.watchers}
+		router.HandleFunc("/tasks/{id:[0-9]+}/watchers", watcherHandler.GetWatchers).Methods(http.MethodGet)
+		router.HandleFunc("/tasks/{id:[0-9]+}/watchers", watcherHandler.WatchTask).Methods(http.MethodPost)
+		router.HandleFunc("/tasks/{id:[0-9]+}/watchers", watcherHandler.UnwatchTask).Methods(http.MethodDelete)
+		router.HandleFunc("/users/me/watching", watcherHandler.GetWatching).Methods(http.MethodGet)
+	}
+
+	if config.board != nil {
+		boardHandler := handler.BoardHandler{DB: config.board}
+		router.HandleFunc("/board", boardHandler.GetBoard).Methods(http.MethodGet)
+		router.HandleFunc("/tasks/{id:[0-9]+}/move", boardHandler.MoveTask).Methods(http.MethodPost)
+	}
+
+	if config.timeTracker != nil {
+		worklogHandler := handler.WorklogHandler{DB: config.timeTracker}
+		router.HandleFunc("/tasks/{id:[0-9]+}/timer", worklogHandler.StartTimer).Methods(http.MethodPost)
+		router.HandleFunc("/tasks/{id:[0-9]+}/timer", worklogHandler.StopTimer).Methods(http.MethodDelete)
+		router.HandleFunc("/tasks/{id:[0-9]+}/worklogs", worklogHandler.GetWorklogs).Methods(http.MethodGet)
+		router.HandleFunc("/tasks/{id:[0-9]+}/worklogs", worklogHandler.LogWork).Methods(http.MethodPost)
+		router.HandleFunc("/reports/time", worklogHandler.GetTimeReport).Methods(http.MethodGet)
+	}
+
+	if config.sprints != nil {
+		sprintHandler := handler.SprintHandler{DB: config.sprints}
+		router.HandleFunc("/sprints", sprintHandler.CreateSprint).Methods(http.MethodPost)
+		router.HandleFunc("/sprints", sprintHandler.GetAllSprints).Methods(http.MethodGet)
+		router.HandleFunc("/sprints/{id:[0-9]+}", sprintHandler.GetSprint).Methods(http.MethodGet)
+		router.HandleFunc("/sprints/{id:[0-9]+}", sprintHandler.UpdateSprint).Methods(http.MethodPut)
+		router.HandleFunc("/sprints/{id:[0-9]+}", sprintHandler.DeleteSprint).Methods(http.MethodDelete)
+		router.HandleFunc("/sprints/{id:[0-9]+}/tasks", sprintHandler.GetSprintTasks).Methods(http.MethodGet)
+		router.HandleFunc("/sprints/{id:[0-9]+}/progress", sprintHandler.GetSprintProgress).Methods(http.MethodGet)
+		router.HandleFunc("/sprints/{id:[0-9]+}/carry-over", sprintHandler.CarryOver).Methods(http.MethodPost)
+	}
+
+	if config.flowReports != nil {
+		flowHandler := handler.FlowReportHandler{DB: config.flowReports}
+		router.HandleFunc("/reports/burndown", flowHandler.GetBurndown).Methods(http.MethodGet)
+		router.HandleFunc("/reports/cfd", flowHandler.GetCumulativeFlow).Methods(http.MethodGet)
+	}
+
+	if config.stats != nil {
+		statsHandler := handler.StatsHandler{DB: config.stats}
+		router.HandleFunc("/stats", statsHandler.GetStats).Methods(http.MethodGet)
+	}
+
+	router.HandleFunc("/tasks", taskHandler.CreateTask).Methods(http.MethodPost)
+	router.HandleFunc("/tasks", taskHandler.GetAllTasks).Methods("GET")
+	router.HandleFunc("/tasks/{id:[0-9]+}", taskHandler.GetTask).Methods("GET")
+	router.HandleFunc("/tasks/{id:[0-9]+}", taskHandler.UpdateTask).Methods("PUT")
+	router.HandleFunc("/tasks/{id:[0-9]+}", taskHandler.DeleteTask).Methods("DELETE")
+
+	return router
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"bufio"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/events"
+	"github.com/saarzur123/task-management/backend/handler"
+	"github.com/saarzur123/task-management/backend/metrics"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/openapi"
+	"github.com/saarzur123/task-management/backend/presence"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+var _ = Describe("SetupRoutes", func() {
+	It("serves the probes outside authentication and rate limiting", func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		readiness := serviceMock.NewMockReadinessChecker(mockCtrl)
+		readiness.EXPECT().Ready(gomock.Any()).Return(nil).Times(3)
+		router := SetupRoutes(serviceMock.NewMockTaskRepository(mockCtrl),
+			WithAPIKeys(serviceMock.NewMockAPIKeyRepository(mockCtrl), serviceMock.NewMockAuditRepository(mockCtrl), true),
+			WithRateLimit(RateLimitConfig{
+				Store: NewMemoryRateLimitStore(),
+				Read:  RateLimit{Requests: 1, Per: time.Hour},
+				Write: RateLimit{Requests: 1, Per: time.Hour},
+			}),
+			WithHealth(&handler.HealthHandler{DB: readiness}))
+
+		for i := 0; i < 3; i++ {
+			for _, path := range []string{"/healthz", "/readyz", "/version"} {
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+				Expect(recorder.Code).To(Equal(http.StatusOK), path)
+			}
+		}
+	})
+
+	It("serves the OpenAPI document and Swagger UI without an API key", func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		router := SetupRoutes(serviceMock.NewMockTaskRepository(mockCtrl),
+			WithAPIKeys(serviceMock.NewMockAPIKeyRepository(mockCtrl), serviceMock.NewMockAuditRepository(mockCtrl), true))
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.Bytes()).To(Equal(openapi.Spec))
+
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+		Expect(recorder.Body.String()).To(ContainSubstring("/openapi.json"))
+	})
+
+	It("streams task events through the middlewares", func() {
+		hub := events.NewHub(events.DefaultReplaySize, events.DefaultSubscriberBuffer)
+		server := httptest.NewServer(SetupRoutes(serviceMock.NewMockTaskRepository(gomock.NewController(GinkgoT())),
+			WithMetrics(metrics.NewRegistry()),
+			WithLogger(slog.New(slog.NewJSONHandler(io.Discard, nil))),
+			WithEvents(hub)))
+		defer server.Close()
+
+		response, err := http.Get(server.URL + "/tasks/events")
+		Expect(err).To(Succeed())
+		Expect(response.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+		hub.Publish(models.TaskEvent{Type: models.EventTaskDeleted, TaskID: "1"})
+		reader := bufio.NewReader(response.Body)
+		for {
+			line, err := reader.ReadString('\n')
+			Expect(err).To(Succeed())
+			if strings.HasPrefix(line, "data:") {
+				Expect(line).To(ContainSubstring(`"task_id":"1"`))
+				break
+			}
+		}
+		hub.Close()
+		response.Body.Close()
+	})
+
+	It("serves the event feed to keys with tasks:read", func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockKeys := serviceMock.NewMockAPIKeyRepository(mockCtrl)
+		mockKeys.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		mockAudit := serviceMock.NewMockAuditRepository(mockCtrl)
+		mockOutbox := serviceMock.NewMockOutboxReader(mockCtrl)
+		mockKeys.EXPECT().Authenticate(gomock.Any(), "tm_key").Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksRead}}, nil)
+		mockAudit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+		mockOutbox.EXPECT().After(gomock.Any(), uint64(3), gomock.Any()).Return([]models.TaskEvent{{ID: 4, Type: models.EventTaskDeleted, TaskID: "1"}}, nil)
+		router := SetupRoutes(serviceMock.NewMockTaskRepository(mockCtrl),
+			WithAPIKeys(mockKeys, mockAudit, true),
+			WithEventFeed(&handler.EventFeedHandler{Outbox: mockOutbox}))
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/events?after=3", nil)
+		request.Header.Set("X-API-Key", "tm_key")
+		router.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"next":4`))
+	})
+
+	Describe("presence", func() {
+		var (
+			server   *httptest.Server
+			mockKeys *serviceMock.MockAPIKeyRepository
+			url      string
+		)
+
+		BeforeEach(func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			mockKeys = serviceMock.NewMockAPIKeyRepository(mockCtrl)
+			mockKeys.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			audit := serviceMock.NewMockAuditRepository(mockCtrl)
+			audit.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			hub := presence.NewHub(presence.NewMemoryBroker())
+			server = httptest.NewServer(SetupRoutes(serviceMock.NewMockTaskRepository(mockCtrl),
+				WithAPIKeys(mockKeys, audit, true),
+				WithPresence(hub)))
+			url = "ws" + strings.TrimPrefix(server.URL, "http") + "/tasks/presence"
+			DeferCleanup(hub.Close)
+			DeferCleanup(server.Close)
+		})
+
+		It("authenticates the handshake with the key offered as a subprotocol", func() {
+			mockKeys.EXPECT().Authenticate(gomock.Any(), "tm_key").Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksRead}}, nil)
+
+			dialer := websocket.Dialer{Subprotocols: []string{presence.Subprotocol, "apikey.tm_key"}}
+			conn, response, err := dialer.Dial(url, nil)
+			Expect(err).To(Succeed())
+			defer conn.Close()
+			Expect(response.Header.Get("Sec-WebSocket-Protocol")).To(Equal(presence.Subprotocol))
+
+			var msg presence.Message
+			Expect(conn.ReadJSON(&msg)).To(Succeed())
+			Expect(msg.Viewer.User).To(Equal("apikey:bot"))
+
+			// The key lacks tasks:write.
+			Expect(conn.WriteJSON(presence.Message{Type: presence.MessageSubscribe, TaskID: "1"})).To(Succeed())
+			Expect(conn.ReadJSON(&msg)).To(Succeed())
+			Expect(conn.WriteJSON(presence.Message{Type: presence.MessageUpdate, TaskID: "1", Field: "title"})).To(Succeed())
+			Expect(conn.ReadJSON(&msg)).To(Succeed())
+			Expect(msg.Error).To(Equal("Not allowed to edit tasks"))
+		})
+
+		It("rejects handshakes without a key", func() {
+			_, response, err := websocket.DefaultDialer.Dial(url, nil)
+			Expect(err).To(MatchError(websocket.ErrBadHandshake))
+			Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("rejects origins outside the CORS allow-list", func() {
+			mockKeys.EXPECT().Authenticate(gomock.Any(), "tm_key").Return(&models.APIKey{Name: "bot", Scopes: []string{models.ScopeTasksRead}}, nil)
+
+			_, response, err := websocket.DefaultDialer.Dial(url, http.Header{"X-API-Key": {"tm_key"}, "Origin": {"http://evil.example"}})
+			Expect(err).To(MatchError(websocket.ErrBadHandshake))
+			Expect(response.StatusCode).To(Equal(http.StatusForbidden))
+		})
+	})
+})
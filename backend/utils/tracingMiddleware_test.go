@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("tracingMiddleware", func() {
+	const (
+		traceID    = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpan = "00f067aa0ba902b7"
+	)
+	var (
+		mockTasks *serviceMock.MockTaskRepository
+		exporter  *tracetest.InMemoryExporter
+		router    http.Handler
+	)
+
+	BeforeEach(func() {
+		exporter = tracetest.NewInMemoryExporter()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+		mockTasks = serviceMock.NewMockTaskRepository(gomock.NewController(GinkgoT()))
+		router = SetupRoutes(mockTasks)
+	})
+
+	It("continues the caller's trace in a span named after the route", func() {
+		mockTasks.EXPECT().GetByID(gomock.Any(), "3").DoAndReturn(func(ctx context.Context, id string) (*models.Task, error) {
+			Expect(trace.SpanContextFromContext(ctx).TraceID().String()).To(Equal(traceID))
+			return &models.Task{ID: id}, nil
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/tasks/3", nil)
+		request.Header.Set("traceparent", "00-"+traceID+"-"+parentSpan+"-01")
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name).To(Equal("GET /tasks/{id:[0-9]+}"))
+		Expect(spans[0].SpanKind).To(Equal(trace.SpanKindServer))
+		Expect(spans[0].Parent.SpanID().String()).To(Equal(parentSpan))
+		Expect(spans[0].SpanContext.TraceID().String()).To(Equal(traceID))
+		Expect(spans[0].Attributes).To(ContainElement(attribute.Int("http.response.status_code", http.StatusOK)))
+	})
+
+	It("marks server errors", func() {
+		mockTasks.EXPECT().GetAll(gomock.Any()).Return(nil, errMock)
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tasks", nil))
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status.Code).To(Equal(codes.Error))
+	})
+})
@@ -0,0 +1,60 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+var (
+	ErrForbiddenAddress = errors.New("ForbiddenAddress")
+)
+
+// PublicIP reports whether ip may receive webhooks. Loopback, link-local,
+// private, unspecified and multicast addresses reach the network of the
+// server or its cloud metadata service, so they are refused.
+func PublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsPrivate() && !ip.IsUnspecified() && !ip.IsMulticast()
+}
+
+// PublicHost reports whether host, the host of a webhook URL, may receive
+// webhooks. Only IP addresses and localhost are refused here: the addresses
+// of other names can change after the webhook is created, the client of
+// NewClient checks them when it connects.
+func PublicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return PublicIP(ip)
+	}
+	return true
+}
+
+// dialControl refuses the connections to addresses that aren't public, once
+// the name of the webhook was resolved, redirects included.
+func dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("%w %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// NewClient returns the client of the deliveries, which only connects to
+// public addresses. It ignores the proxy environment variables, the proxy
+// would be checked instead of the webhook.
+func NewClient() *http.Client {
+	dialer := &net.Dialer{Timeout: requestTimeout, Control: dialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: requestTimeout, Transport: transport}
+}
@@ -0,0 +1,221 @@
+// Package webhooks delivers the task events of the outbox to the subscribed
+// webhooks, signing every request.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/saarzur123/task-management/backend/models"
+	"github.com/saarzur123/task-management/backend/service"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	signaturePrefix = "sha256="
+
+	DefaultPollInterval = time.Second
+	DefaultBaseDelay    = 30 * time.Second
+	DefaultMaxDelay     = time.Hour
+	// DefaultMaxAttempts gives up on a delivery after about two hours.
+	DefaultMaxAttempts = 8
+	// DefaultMaxFailures disables a webhook after that many failed attempts
+	// in a row, across its deliveries.
+	DefaultMaxFailures = 20
+	requestTimeout     = 10 * time.Second
+	batchSize          = 100
+	maxResponseBody    = 64 * 1024
+)
+
+var (
+	ErrInvalidSignature = errors.New("InvalidSignature")
+)
+
+// Sign returns the X-Webhook-Signature value of a delivery: the hex encoded
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a received delivery and rejects
+// timestamps further than tolerance from now, which limits replays.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Worker moves new outbox events into the delivery queue and sends the due
+// deliveries, retrying failures with exponential backoff.
+type Worker struct {
+	Outbox service.OutboxReader
+	Queue  service.WebhookQueue
+	Client *http.Client
+	Logger *slog.Logger
+	now    func() time.Time
+
+	PollInterval time.Duration
+	// The delay before retry n is BaseDelay * 2^(n-1), at most MaxDelay.
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	MaxAttempts int
+	MaxFailures int
+}
+
+func NewWorker(outbox service.OutboxReader, queue service.WebhookQueue, logger *slog.Logger) *Worker {
+	return &Worker{
+		Outbox:       outbox,
+		Queue:        queue,
+		Client:       NewClient(),
+		Logger:       logger,
+		now:          time.Now,
+		PollInterval: DefaultPollInterval,
+		BaseDelay:    DefaultBaseDelay,
+		MaxDelay:     DefaultMaxDelay,
+		MaxAttempts:  DefaultMaxAttempts,
+		MaxFailures:  DefaultMaxFailures,
+	}
+}
+
+// Run polls until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Poll(ctx); err != nil && ctx.Err() == nil {
+			w.Logger.ErrorContext(ctx, "failed to deliver webhooks", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll queues the new outbox events and sends the deliveries that are due.
+func (w *Worker) Poll(ctx context.Context) error {
+	if err := w.enqueue(ctx); err != nil {
+		return err
+	}
+
+	due, err := w.Queue.Due(ctx, w.now(), batchSize)
+	if err != nil {
+		return err
+	}
+	for i := range due {
+		if err = w.deliver(ctx, &due[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Worker) enqueue(ctx context.Context) error {
+	cursor, err := w.Queue.Cursor(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		events, err := w.Outbox.After(ctx, cursor, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err = w.Queue.Enqueue(ctx, event); err != nil {
+				return err
+			}
+			cursor = event.ID
+		}
+		if len(events) < batchSize {
+			return nil
+		}
+	}
+}
+
+// deliver sends one delivery and records the outcome. Only storage errors are
+// returned, a failing receiver is handled by retrying.
+func (w *Worker) deliver(ctx context.Context, delivery *models.PendingDelivery) error {
+	now := w.now()
+	statusCode, sendErr := w.send(ctx, delivery, now)
+	if sendErr == nil {
+		return w.Queue.MarkSucceeded(ctx, delivery, statusCode, w.now())
+	}
+
+	var retryAt *time.Time
+	if attempts := delivery.Attempts + 1; attempts < w.MaxAttempts {
+		next := now.Add(w.backoff(attempts))
+		retryAt = &next
+	}
+	disabled, err := w.Queue.MarkFailed(ctx, delivery, statusCode, sendErr.Error(), retryAt, w.MaxFailures, w.now())
+	if err != nil {
+		return err
+	}
+
+	logger := w.Logger.With(slog.String("webhook_id", delivery.WebhookID), slog.String("delivery_id", delivery.ID))
+	logger.WarnContext(ctx, "webhook delivery failed", slog.Any("error", sendErr), slog.Bool("retrying", retryAt != nil))
+	if disabled {
+		logger.WarnContext(ctx, "webhook disabled after repeated failures")
+	}
+	return nil
+}
+
+func (w *Worker) send(ctx context.Context, delivery *models.PendingDelivery, now time.Time) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "task-management-webhooks")
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderDelivery, delivery.ID)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	response, err := w.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseBody))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, errors.New("HTTP " + response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+func (w *Worker) backoff(attempt int) time.Duration {
+	delay := w.BaseDelay
+	for i := 1; i < attempt && delay < w.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, w.MaxDelay)
+}
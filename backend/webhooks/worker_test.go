@@ -0,0 +1,196 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saarzur123/task-management/backend/mocks/serviceMock"
+	"github.com/saarzur123/task-management/backend/models"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var errMock = errors.New("mock error")
+
+func TestWebhooks(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Webhooks Suite")
+}
+
+var _ = Describe("signatures", func() {
+	var (
+		now    = time.Unix(1700000000, 0)
+		body   = []byte(`{"id":1}`)
+		header http.Header
+	)
+
+	BeforeEach(func() {
+		header = http.Header{}
+		header.Set(HeaderTimestamp, "1700000000")
+		header.Set(HeaderSignature, Sign("secret", now.Unix(), body))
+	})
+
+	It("verifies a signed body", func() {
+		Expect(Verify("secret", header, body, time.Minute, now)).To(Succeed())
+	})
+
+	It("rejects another secret or body", func() {
+		Expect(Verify("other", header, body, time.Minute, now)).To(MatchError(ErrInvalidSignature))
+		Expect(Verify("secret", header, []byte(`{"id":2}`), time.Minute, now)).To(MatchError(ErrInvalidSignature))
+	})
+
+	It("rejects stale timestamps", func() {
+		Expect(Verify("secret", header, body, time.Minute, now.Add(2*time.Minute))).To(MatchError(ErrInvalidSignature))
+	})
+})
+
+var _ = Describe("Worker", func() {
+	var (
+		mockOutbox *serviceMock.MockOutboxReader
+		mockQueue  *serviceMock.MockWebhookQueue
+		worker     *Worker
+		receiver   *httptest.Server
+		received   chan *http.Request
+		status     int
+		now        = time.Unix(1700000000, 0)
+		ctx        = context.Background()
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockOutbox = serviceMock.NewMockOutboxReader(mockCtrl)
+		mockQueue = serviceMock.NewMockWebhookQueue(mockCtrl)
+		worker = NewWorker(mockOutbox, mockQueue, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		worker.now = func() time.Time { return now }
+
+		status = http.StatusOK
+		received = make(chan *http.Request, 1)
+		receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			received <- r.Clone(context.Background())
+			w.WriteHeader(status)
+		}))
+		DeferCleanup(receiver.Close)
+		// The receiver listens on loopback, which NewClient refuses.
+		worker.Client = receiver.Client()
+	})
+
+	pending := func(attempts int) models.PendingDelivery {
+		payload, _ := json.Marshal(models.TaskEvent{ID: 7, Type: models.EventTaskDeleted, TaskID: "1"})
+		return models.PendingDelivery{
+			WebhookDelivery: models.WebhookDelivery{ID: "3", WebhookID: "2", EventID: 7, EventType: models.EventTaskDeleted, Payload: payload, Attempts: attempts},
+			URL:             receiver.URL,
+			Secret:          "whsec_secret",
+		}
+	}
+
+	expectNoNewEvents := func() {
+		mockQueue.EXPECT().Cursor(gomock.Any()).Return(uint64(7), nil)
+		mockOutbox.EXPECT().After(gomock.Any(), uint64(7), batchSize).Return(nil, nil)
+	}
+
+	It("queues the outbox events after the cursor", func() {
+		events := []models.TaskEvent{{ID: 8, Type: models.EventTaskCreated}, {ID: 9, Type: models.EventTaskDeleted}}
+		mockQueue.EXPECT().Cursor(gomock.Any()).Return(uint64(7), nil)
+		mockOutbox.EXPECT().After(gomock.Any(), uint64(7), batchSize).Return(events, nil)
+		gomock.InOrder(
+			mockQueue.EXPECT().Enqueue(gomock.Any(), events[0]),
+			mockQueue.EXPECT().Enqueue(gomock.Any(), events[1]),
+		)
+		mockQueue.EXPECT().Due(gomock.Any(), now, batchSize).Return(nil, nil)
+
+		Expect(worker.Poll(ctx)).To(Succeed())
+	})
+
+	It("sends signed deliveries and records the success", func() {
+		delivery := pending(0)
+		expectNoNewEvents()
+		mockQueue.EXPECT().Due(gomock.Any(), now, batchSize).Return([]models.PendingDelivery{delivery}, nil)
+		mockQueue.EXPECT().MarkSucceeded(gomock.Any(), gomock.Any(), http.StatusOK, now)
+
+		Expect(worker.Poll(ctx)).To(Succeed())
+
+		var request *http.Request
+		Eventually(received).Should(Receive(&request))
+		Expect(request.Header.Get(HeaderEvent)).To(Equal(models.EventTaskDeleted))
+		Expect(request.Header.Get(HeaderDelivery)).To(Equal("3"))
+		body, _ := io.ReadAll(request.Body)
+		Expect(body).To(MatchJSON(delivery.Payload))
+		Expect(Verify("whsec_secret", request.Header, body, time.Minute, now)).To(Succeed())
+	})
+
+	It("retries failed deliveries with exponential backoff", func() {
+		status = http.StatusInternalServerError
+		expectNoNewEvents()
+		mockQueue.EXPECT().Due(gomock.Any(), now, batchSize).Return([]models.PendingDelivery{pending(2)}, nil)
+		retryAt := now.Add(4 * DefaultBaseDelay)
+		mockQueue.EXPECT().MarkFailed(gomock.Any(), gomock.Any(), http.StatusInternalServerError, "HTTP 500 Internal Server Error", &retryAt, DefaultMaxFailures, now)
+
+		Expect(worker.Poll(ctx)).To(Succeed())
+	})
+
+	It("gives up after the last attempt", func() {
+		receiver.Close()
+		expectNoNewEvents()
+		mockQueue.EXPECT().Due(gomock.Any(), now, batchSize).Return([]models.PendingDelivery{pending(DefaultMaxAttempts - 1)}, nil)
+		mockQueue.EXPECT().MarkFailed(gomock.Any(), gomock.Any(), 0, gomock.Any(), nil, DefaultMaxFailures, now).Return(true, nil)
+
+		Expect(worker.Poll(ctx)).To(Succeed())
+	})
+
+	It("refuses to connect to addresses that aren't public", func() {
+		worker.Client = NewClient()
+		expectNoNewEvents()
+		mockQueue.EXPECT().Due(gomock.Any(), now, batchSize).Return([]models.PendingDelivery{pending(0)}, nil)
+		retryAt := now.Add(DefaultBaseDelay)
+		mockQueue.EXPECT().MarkFailed(gomock.Any(), gomock.Any(), 0, gomock.Any(), &retryAt, DefaultMaxFailures, now).
+			DoAndReturn(func(_ context.Context, _ *models.PendingDelivery, _ int, message string, _ *time.Time, _ int, _ time.Time) (bool, error) {
+				Expect(message).To(ContainSubstring(ErrForbiddenAddress.Error()))
+				return false, nil
+			})
+
+		Expect(worker.Poll(ctx)).To(Succeed())
+		Expect(received).To(BeEmpty())
+	})
+
+	It("returns storage errors", func() {
+		mockQueue.EXPECT().Cursor(gomock.Any()).Return(uint64(0), errMock)
+
+		Expect(worker.Poll(ctx)).To(MatchError(errMock))
+	})
+
+	DescribeTable("PublicHost",
+		func(host string, public bool) {
+			Expect(PublicHost(host)).To(Equal(public))
+		},
+		Entry("name", "ci.example.com", true),
+		Entry("public IP", "93.184.216.34", true),
+		Entry("localhost", "localhost", false),
+		Entry("localhost subdomain", "app.localhost.", false),
+		Entry("loopback", "127.0.0.1", false),
+		Entry("IPv6 loopback", "::1", false),
+		Entry("IPv4-mapped loopback", "::ffff:127.0.0.1", false),
+		Entry("cloud metadata", "169.254.169.254", false),
+		Entry("private", "10.0.0.1", false),
+		Entry("IPv6 private", "fd00::1", false),
+		Entry("unspecified", "0.0.0.0", false),
+	)
+
+	DescribeTable("backoff",
+		func(attempt int, expected time.Duration) {
+			Expect(worker.backoff(attempt)).To(Equal(expected))
+		},
+		Entry("first retry", 1, DefaultBaseDelay),
+		Entry("doubles", 3, 4*DefaultBaseDelay),
+		Entry("is capped", 10, DefaultMaxDelay),
+	)
+})
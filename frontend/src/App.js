@@ -1,12 +1,34 @@
-import React from 'react';
+import React, {useState} from 'react';
 import './App.css';
+import ToggleButton from '@mui/material/ToggleButton';
+import ToggleButtonGroup from '@mui/material/ToggleButtonGroup';
 import TasksTable from "./components/TasksTable";
+import TaskBoard from "./components/TaskBoard";
+import Dashboard from "./components/Dashboard";
+import NotificationBell from "./components/NotificationBell";
 
 function App() {
+  const [view, setView] = useState("table");
+
   return (
     <div className="tasks-app">
+      <div className="tasks-app-header">
         <h1>Tasks Manager</h1>
-      <TasksTable/>
+        <ToggleButtonGroup
+          size="small"
+          exclusive
+          value={view}
+          onChange={(event, value) => value && setView(value)}
+        >
+          <ToggleButton value="table">Table</ToggleButton>
+          <ToggleButton value="board">Board</ToggleButton>
+          <ToggleButton value="dashboard">Dashboard</ToggleButton>
+        </ToggleButtonGroup>
+        <NotificationBell/>
+      </div>
+      {view === "table" && <TasksTable/>}
+      {view === "board" && <TaskBoard/>}
+      {view === "dashboard" && <Dashboard/>}
     </div>
   );
 }
@@ -0,0 +1,134 @@
+import * as React from 'react';
+import {useCallback, useEffect, useState} from "react";
+import Card from '@mui/material/Card';
+import CardContent from '@mui/material/CardContent';
+import LinearProgress from '@mui/material/LinearProgress';
+import List from '@mui/material/List';
+import ListItem from '@mui/material/ListItem';
+import ListItemText from '@mui/material/ListItemText';
+import Typography from '@mui/material/Typography';
+import {CircularProgress} from "@mui/material";
+
+import './Dashboard.css'
+
+const STATS_URL = "http://localhost:8080/stats";
+
+// age describes how long ago createdAt was, in days once it is a day old.
+export function age(createdAt, now = new Date()) {
+    const hours = Math.floor((now - new Date(createdAt)) / 3600000);
+    if (hours < 1) {
+        return "less than an hour";
+    }
+    if (hours < 24) {
+        return hours === 1 ? "1 hour" : `${hours} hours`;
+    }
+    const days = Math.floor(hours / 24);
+    return days === 1 ? "1 day" : `${days} days`;
+}
+
+export default function Dashboard() {
+    const [stats, setStats] = useState(null);
+    const [loading, setLoading] = useState(true);
+
+    const fetchStats = useCallback(async () => {
+        try {
+            const response = await fetch(STATS_URL);
+            if (!response.ok) {
+                throw new Error(`HTTP error! Status: ${response.status}`);
+            }
+            setStats(await response.json());
+        } catch (err) {
+            alert(err.message);
+        } finally {
+            setLoading(false);
+        }
+    }, []);
+
+    useEffect(() => {
+        fetchStats();
+    }, [fetchStats]);
+
+    // The statistics are cached until a task changes, reload them then.
+    useEffect(() => {
+        if (typeof EventSource === "undefined") {
+            return;
+        }
+        const source = new EventSource("http://localhost:8080/tasks/events");
+        ["task.created", "task.updated", "task.deleted", "resync"].forEach((type) =>
+            source.addEventListener(type, fetchStats)
+        );
+        return () => source.close();
+    }, [fetchStats]);
+
+    if (loading) {
+        return <CircularProgress/>;
+    }
+    if (!stats) {
+        return null;
+    }
+
+    const mostOpen = Math.max(1, ...stats.assignees.map((load) => load.open));
+
+    return (
+        <div className="dashboard">
+            <Card className="dashboard-card" data-testid="by-status">
+                <CardContent>
+                    <Typography variant="h6">Tasks ({stats.total})</Typography>
+                    {Object.entries(stats.by_status).map(([status, count]) => (
+                        <div key={status} className="dashboard-row">
+                            <Typography className="dashboard-status">{status}</Typography>
+                            <Typography>{count}</Typography>
+                        </div>
+                    ))}
+                </CardContent>
+            </Card>
+
+            <Card className="dashboard-card" data-testid="week">
+                <CardContent>
+                    <Typography variant="h6">This week</Typography>
+                    <Typography variant="body2" color="text.secondary">Since {stats.week.start}</Typography>
+                    <div className="dashboard-row">
+                        <Typography>Created</Typography>
+                        <Typography>{stats.week.created}</Typography>
+                    </div>
+                    <div className="dashboard-row">
+                        <Typography>Completed</Typography>
+                        <Typography>{stats.week.completed}</Typography>
+                    </div>
+                </CardContent>
+            </Card>
+
+            <Card className="dashboard-card" data-testid="oldest-open">
+                <CardContent>
+                    <Typography variant="h6">Oldest open tasks</Typography>
+                    {stats.oldest_open.length === 0 ? (
+                        <Typography color="text.secondary">No open tasks</Typography>
+                    ) : (
+                        <List dense>
+                            {stats.oldest_open.map((task) => (
+                                <ListItem key={task.id} disableGutters>
+                                    <ListItemText primary={task.title} secondary={`${task.status}, ${age(task.created_at)} old`}/>
+                                </ListItem>
+                            ))}
+                        </List>
+                    )}
+                </CardContent>
+            </Card>
+
+            <Card className="dashboard-card" data-testid="assignees">
+                <CardContent>
+                    <Typography variant="h6">Open work per assignee</Typography>
+                    {stats.assignees.map((load) => (
+                        <div key={load.assignee} className="dashboard-load">
+                            <div className="dashboard-row">
+                                <Typography>{load.assignee || "Unassigned"}</Typography>
+                                <Typography>{load.open} tasks, {load.points} points</Typography>
+                            </div>
+                            <LinearProgress variant="determinate" value={100 * load.open / mostOpen}/>
+                        </div>
+                    ))}
+                </CardContent>
+            </Card>
+        </div>
+    );
+}
@@ -0,0 +1,65 @@
+import React from "react";
+import {render, screen, waitFor, cleanup, within} from "@testing-library/react";
+import Dashboard, {age} from "./Dashboard";
+
+global.fetch = jest.fn();
+global.alert = jest.fn();
+
+describe("Dashboard", () => {
+    const stats = {
+        by_status: {pending: 3, done: 2},
+        total: 5,
+        week: {start: "2026-01-05", created: 4, completed: 2},
+        oldest_open: [
+            {id: "1", title: "Write tests", status: "pending", created_at: "2026-01-01T09:00:00Z"},
+        ],
+        assignees: [
+            {assignee: "ann", open: 2, points: 5},
+            {assignee: "", open: 1, points: 0},
+        ],
+        generated_at: "2026-01-07T10:00:00Z",
+    };
+
+    const respond = (body) => ({ok: true, status: 200, json: async () => body});
+
+    afterEach(() => {
+        jest.clearAllMocks();
+        cleanup();
+    });
+
+    test("shows the statistics", async () => {
+        fetch.mockResolvedValueOnce(respond(stats));
+        render(<Dashboard/>);
+
+        await waitFor(() => expect(screen.getByText("Tasks (5)")).toBeInTheDocument());
+        expect(fetch).toHaveBeenCalledWith("http://localhost:8080/stats");
+        expect(within(screen.getByTestId("by-status")).getByText("pending")).toBeInTheDocument();
+        expect(within(screen.getByTestId("week")).getByText("Since 2026-01-05")).toBeInTheDocument();
+        expect(within(screen.getByTestId("oldest-open")).getByText("Write tests")).toBeInTheDocument();
+        expect(within(screen.getByTestId("assignees")).getByText("2 tasks, 5 points")).toBeInTheDocument();
+        expect(within(screen.getByTestId("assignees")).getByText("Unassigned")).toBeInTheDocument();
+    });
+
+    test("says when no task is open", async () => {
+        fetch.mockResolvedValueOnce(respond({...stats, oldest_open: [], assignees: []}));
+        render(<Dashboard/>);
+
+        await waitFor(() => expect(screen.getByText("No open tasks")).toBeInTheDocument());
+    });
+
+    test("alerts when the statistics fail", async () => {
+        fetch.mockResolvedValueOnce({ok: false, status: 500});
+        render(<Dashboard/>);
+
+        await waitFor(() => expect(global.alert).toHaveBeenCalledWith("HTTP error! Status: 500"));
+    });
+
+    test("describes the age of a task", () => {
+        const now = new Date("2026-01-07T10:00:00Z");
+        expect(age("2026-01-07T09:30:00Z", now)).toBe("less than an hour");
+        expect(age("2026-01-07T08:30:00Z", now)).toBe("1 hour");
+        expect(age("2026-01-07T05:00:00Z", now)).toBe("5 hours");
+        expect(age("2026-01-06T09:00:00Z", now)).toBe("1 day");
+        expect(age("2026-01-01T09:00:00Z", now)).toBe("6 days");
+    });
+});
@@ -0,0 +1,117 @@
+import * as React from 'react';
+import {useCallback, useEffect, useState} from "react";
+import Badge from '@mui/material/Badge';
+import Button from '@mui/material/Button';
+import IconButton from '@mui/material/IconButton';
+import ListItemText from '@mui/material/ListItemText';
+import Menu from '@mui/material/Menu';
+import MenuItem from '@mui/material/MenuItem';
+import NotificationsIcon from '@mui/icons-material/Notifications';
+
+const NOTIFICATIONS_URL = "http://localhost:8080/users/me/notifications";
+// Reminders don't come with a task event, poll for them.
+const POLL_INTERVAL = 60000;
+
+export default function NotificationBell() {
+    const [notifications, setNotifications] = useState([]);
+    const [unread, setUnread] = useState(0);
+    const [anchorEl, setAnchorEl] = useState(null);
+
+    // Failures are left for the next refresh, the bell isn't worth an alert.
+    const fetchNotifications = useCallback(async () => {
+        try {
+            const response = await fetch(NOTIFICATIONS_URL);
+            if (!response.ok) {
+                return;
+            }
+            const inbox = await response.json();
+            setNotifications(inbox.notifications);
+            setUnread(inbox.unread);
+        } catch (err) {
+        }
+    }, []);
+
+    useEffect(() => {
+        fetchNotifications();
+        const interval = setInterval(fetchNotifications, POLL_INTERVAL);
+        return () => clearInterval(interval);
+    }, [fetchNotifications]);
+
+    // Task changes are what notify the other users, refresh on each of them.
+    useEffect(() => {
+        if (typeof EventSource === "undefined") {
+            return;
+        }
+        const source = new EventSource("http://localhost:8080/tasks/events");
+        ["task.created", "task.updated", "task.deleted", "resync"].forEach((type) =>
+            source.addEventListener(type, fetchNotifications)
+        );
+        return () => source.close();
+    }, [fetchNotifications]);
+
+    const markRead = async (notification) => {
+        if (notification.read_at) {
+            return;
+        }
+        try {
+            const response = await fetch(`${NOTIFICATIONS_URL}/${notification.id}/read`, {method: 'POST'});
+            if (!response.ok) {
+                throw new Error(`HTTP error! Status: ${response.status}`);
+            }
+            const readAt = new Date().toISOString();
+            setNotifications((prev) =>
+                prev.map((n) => (n.id === notification.id ? {...n, read_at: readAt} : n))
+            );
+            setUnread((prev) => Math.max(prev - 1, 0));
+        } catch (err) {
+            alert(err.message);
+        }
+    };
+
+    const markAllRead = async () => {
+        try {
+            const response = await fetch(`${NOTIFICATIONS_URL}/read-all`, {method: 'POST'});
+            if (!response.ok) {
+                throw new Error(`HTTP error! Status: ${response.status}`);
+            }
+            const readAt = new Date().toISOString();
+            setNotifications((prev) => prev.map((n) => ({...n, read_at: n.read_at || readAt})));
+            setUnread(0);
+        } catch (err) {
+            alert(err.message);
+        }
+    };
+
+    return (
+        <>
+            <IconButton aria-label="notifications" onClick={(event) => setAnchorEl(event.currentTarget)}>
+                <Badge badgeContent={unread} color="error">
+                    <NotificationsIcon/>
+                </Badge>
+            </IconButton>
+            <Menu anchorEl={anchorEl} open={Boolean(anchorEl)} onClose={() => setAnchorEl(null)}>
+                {notifications.length === 0 ? (
+                    <MenuItem disabled>No notifications</MenuItem>
+                ) : (
+                    notifications.map((notification) => (
+                        <MenuItem
+                            key={notification.id}
+                            selected={!notification.read_at}
+                            onClick={() => markRead(notification)}
+                        >
+                            <ListItemText
+                                primary={notification.message}
+                                secondary={new Date(notification.created_at).toLocaleString()}
+                            />
+                        </MenuItem>
+                    ))
+                )}
+                {unread > 0 && (
+                    <MenuItem>
+                        <Button size="small" onClick={markAllRead}>Mark all as read</Button>
+                    </MenuItem>
+                )}
+            </Menu>
+        </>
+    );
+}
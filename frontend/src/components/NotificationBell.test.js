@@ -0,0 +1,80 @@
+import React from "react";
+import {render, screen, fireEvent, waitFor, cleanup} from "@testing-library/react";
+import NotificationBell from "./NotificationBell";
+
+global.fetch = jest.fn();
+global.alert = jest.fn();
+
+describe("NotificationBell", () => {
+    const inbox = {
+        notifications: [
+            {id: "2", task_id: "1", kind: "task.status_changed", message: 'Task "Ship" moved from pending to done', created_at: "2026-01-05T09:00:00Z"},
+            {id: "1", task_id: "1", kind: "task.assigned", message: 'Task "Ship" was assigned to you', created_at: "2026-01-05T08:00:00Z", read_at: "2026-01-05T08:30:00Z"},
+        ],
+        unread: 1,
+    };
+
+    const respond = (body) => ({ok: true, json: async () => body});
+
+    afterEach(() => {
+        jest.clearAllMocks();
+        cleanup();
+    });
+
+    test("shows the number of unread notifications", async () => {
+        fetch.mockResolvedValueOnce(respond(inbox));
+        render(<NotificationBell/>);
+
+        await waitFor(() => expect(screen.getByText("1")).toBeInTheDocument());
+        expect(fetch).toHaveBeenCalledWith("http://localhost:8080/users/me/notifications");
+    });
+
+    test("lists the notifications when opened", async () => {
+        fetch.mockResolvedValueOnce(respond(inbox));
+        render(<NotificationBell/>);
+        await waitFor(() => screen.getByText("1"));
+
+        fireEvent.click(screen.getByLabelText("notifications"));
+        expect(screen.getByText('Task "Ship" moved from pending to done')).toBeInTheDocument();
+        expect(screen.getByText('Task "Ship" was assigned to you')).toBeInTheDocument();
+    });
+
+    test("marks an unread notification read", async () => {
+        fetch.mockResolvedValueOnce(respond(inbox));
+        render(<NotificationBell/>);
+        await waitFor(() => screen.getByText("1"));
+
+        fetch.mockResolvedValueOnce({ok: true});
+        fireEvent.click(screen.getByLabelText("notifications"));
+        fireEvent.click(screen.getByText('Task "Ship" moved from pending to done'));
+
+        await waitFor(() => expect(fetch).toHaveBeenCalledWith(
+            "http://localhost:8080/users/me/notifications/2/read", {method: "POST"}));
+        await waitFor(() => expect(screen.queryByText("Mark all as read")).not.toBeInTheDocument());
+    });
+
+    test("marks all the notifications read", async () => {
+        fetch.mockResolvedValueOnce(respond(inbox));
+        render(<NotificationBell/>);
+        await waitFor(() => screen.getByText("1"));
+
+        fetch.mockResolvedValueOnce({ok: true});
+        fireEvent.click(screen.getByLabelText("notifications"));
+        fireEvent.click(screen.getByText("Mark all as read"));
+
+        await waitFor(() => expect(fetch).toHaveBeenCalledWith(
+            "http://localhost:8080/users/me/notifications/read-all", {method: "POST"}));
+    });
+
+    test("alerts when a notification can't be marked read", async () => {
+        fetch.mockResolvedValueOnce(respond(inbox));
+        render(<NotificationBell/>);
+        await waitFor(() => screen.getByText("1"));
+
+        fetch.mockResolvedValueOnce({ok: false, status: 500});
+        fireEvent.click(screen.getByLabelText("notifications"));
+        fireEvent.click(screen.getByText('Task "Ship" moved from pending to done'));
+
+        await waitFor(() => expect(global.alert).toHaveBeenCalledWith("HTTP error! Status: 500"));
+    });
+});
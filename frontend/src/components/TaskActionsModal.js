@@ -1,4 +1,4 @@
-import React, { useState, useEffect } from "react";
+import React, { useState, useEffect, useRef } from "react";
 import { Dialog, DialogActions, DialogContent, DialogTitle, TextField, Button, CircularProgress } from "@mui/material";
 
 export default function TaskActionsModal({ open, onClose, task, onTaskUpdated, onTaskCreated }) {
@@ -7,6 +7,8 @@ export default function TaskActionsModal({ open, onClose, task, onTaskUpdated, o
     const [status, setStatus] = useState("");
     const [loading, setLoading] = useState(false);
     const [error, setError] = useState(null);
+    const [viewers, setViewers] = useState([]);
+    const socket = useRef(null);
 
     // Set initial values in case of update
     useEffect(() => {
@@ -17,6 +19,51 @@ export default function TaskActionsModal({ open, onClose, task, onTaskUpdated, o
         }
     }, [task]);
 
+    // Show who else has the task open and apply their edits as they type.
+    useEffect(() => {
+        if (!open || !task || typeof WebSocket === "undefined") {
+            return;
+        }
+        const ws = new WebSocket("ws://localhost:8080/tasks/presence", ["presence.v1"]);
+        const setters = { title: setTitle, description: setDescription, status: setStatus };
+        let self = null;
+        ws.onopen = () => ws.send(JSON.stringify({ type: "subscribe", task_id: String(task.id) }));
+        ws.onmessage = (event) => {
+            const message = JSON.parse(event.data);
+            switch (message.type) {
+                case "hello":
+                    self = message.viewer.id;
+                    break;
+                case "viewers":
+                    setViewers(message.viewers.filter((viewer) => viewer.id !== self));
+                    break;
+                case "join":
+                    setViewers((prevViewers) => [...prevViewers, message.viewer]);
+                    break;
+                case "leave":
+                    setViewers((prevViewers) => prevViewers.filter((viewer) => viewer.id !== message.viewer.id));
+                    break;
+                case "update":
+                    setters[message.field]?.(message.value);
+                    break;
+                default:
+            }
+        };
+        socket.current = ws;
+        return () => {
+            socket.current = null;
+            setViewers([]);
+            ws.close();
+        };
+    }, [open, task]);
+
+    const editField = (field, setter) => (e) => {
+        setter(e.target.value);
+        if (socket.current?.readyState === 1) {
+            socket.current.send(JSON.stringify({ type: "update", task_id: String(task.id), field, value: e.target.value }));
+        }
+    };
+
     const isUpdateMode = task !== null;
     const handleCreation = () => {
         const task = {
@@ -35,7 +82,9 @@ export default function TaskActionsModal({ open, onClose, task, onTaskUpdated, o
     }
 
     const handleUpdate = () => {
+        // PUT replaces the whole task, keep the fields the form doesn't edit such as the schedule.
         const updatedTask = {
+            ...task,
             title,
             description,
             status,
@@ -103,13 +152,14 @@ export default function TaskActionsModal({ open, onClose, task, onTaskUpdated, o
         <Dialog open={open} onClose={onClose}>
             <DialogTitle>{task ? `Edit ${task.title}` : "Create Task"}</DialogTitle>
             <DialogContent>
+                {viewers.length > 0 && <p className="task-viewers">Also viewing: {viewers.map((viewer) => viewer.user).join(", ")}</p>}
                 <TextField
                     label="Title"
                     variant="outlined"
                     fullWidth
                     value={title}
                     required="true"
-                    onChange={(e) => setTitle(e.target.value)}
+                    onChange={editField("title", setTitle)}
                     margin="normal"
                 />
                 <TextField
@@ -117,7 +167,7 @@ export default function TaskActionsModal({ open, onClose, task, onTaskUpdated, o
                     variant="outlined"
                     fullWidth
                     value={description}
-                    onChange={(e) => setDescription(e.target.value)}
+                    onChange={editField("description", setDescription)}
                     margin="normal"
                 />
                 <TextField
@@ -125,7 +175,7 @@ export default function TaskActionsModal({ open, onClose, task, onTaskUpdated, o
                     variant="outlined"
                     fullWidth
                     value={status}
-                    onChange={(e) => setStatus(e.target.value)}
+                    onChange={editField("status", setStatus)}
                     margin="normal"
                 />
                 {error && <p style={{ color: "red" }}>{error}</p>}
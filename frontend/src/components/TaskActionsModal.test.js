@@ -1,11 +1,29 @@
 import React from "react";
-import { render, screen, fireEvent, waitFor } from "@testing-library/react";
+import { render, screen, fireEvent, waitFor, act } from "@testing-library/react";
 import TaskActionsModal from "./TaskActionsModal";
 
 jest.mock("node-fetch", () => jest.fn());
 global.fetch = jest.fn();
 global.alert = jest.fn()
 
+// Stands in for the presence socket; the last opened one is kept to drive it.
+let socket;
+global.WebSocket = class {
+    constructor(url, protocols) {
+        this.url = url;
+        this.protocols = protocols;
+        this.readyState = 1;
+        this.sent = [];
+        socket = this;
+    }
+
+    send(data) {
+        this.sent.push(JSON.parse(data));
+    }
+
+    close() {}
+};
+
 describe("TaskActionsModal", () => {
     const mockOnClose = jest.fn();
     const mockOnTaskUpdated = jest.fn();
@@ -174,6 +192,35 @@ describe("TaskActionsModal", () => {
         });
     });
 
+    it("keeps the schedule of a recurring task when updating it", async () => {
+        const recurringTask = {
+            id: 2, title: "Standup", description: "", status: "pending",
+            due_at: "2026-01-05T09:00:00Z", recurrence: "FREQ=DAILY",
+        };
+        fetch.mockResolvedValueOnce({
+            ok: true,
+            json: async () => (recurringTask),
+        });
+
+        render(
+            <TaskActionsModal
+                open={true}
+                onClose={mockOnClose}
+                task={recurringTask}
+                onTaskUpdated={mockOnTaskUpdated}
+                onTaskCreated={mockOnTaskCreated}
+            />
+        );
+
+        fireEvent.change(screen.getByLabelText(labelStatus), { target: { value: "done" } });
+        fireEvent.click(screen.getByText("Submit"));
+
+        await waitFor(() => {
+            const body = JSON.parse(fetch.mock.calls.at(-1)[1].body);
+            expect(body).toMatchObject({ status: "done", due_at: "2026-01-05T09:00:00Z", recurrence: "FREQ=DAILY" });
+        });
+    });
+
     it("popping an alert when submission error", async () => {
         fetch.mockResolvedValueOnce({
             ok: false,
@@ -218,4 +265,33 @@ describe("TaskActionsModal", () => {
 
         expect(mockOnClose).toHaveBeenCalled();
     });
+
+    it("shows the other viewers and applies their edits", () => {
+        render(
+            <TaskActionsModal
+                open={true}
+                onClose={mockOnClose}
+                task={baseTask}
+                onTaskUpdated={mockOnTaskUpdated}
+                onTaskCreated={mockOnTaskCreated}
+            />
+        );
+        const receive = (message) => act(() => socket.onmessage({ data: JSON.stringify(message) }));
+
+        act(() => socket.onopen());
+        expect(socket.sent[0]).toEqual({ type: "subscribe", task_id: "1" });
+
+        receive({ type: "hello", viewer: { id: "me", user: "anonymous" } });
+        receive({ type: "viewers", task_id: "1", viewers: [{ id: "me", user: "anonymous" }, { id: "b", user: "apikey:bob" }] });
+        expect(screen.getByText("Also viewing: apikey:bob")).toBeInTheDocument();
+
+        receive({ type: "update", task_id: "1", viewer: { id: "b", user: "apikey:bob" }, field: "title", value: "Bob's title" });
+        expect(screen.getByLabelText(labelTitle)).toHaveValue("Bob's title");
+
+        fireEvent.change(screen.getByLabelText(labelStatus), { target: { value: "Done" } });
+        expect(socket.sent[1]).toEqual({ type: "update", task_id: "1", field: "status", value: "Done" });
+
+        receive({ type: "leave", task_id: "1", viewer: { id: "b", user: "apikey:bob" } });
+        expect(screen.queryByText(/Also viewing/)).not.toBeInTheDocument();
+    });
 });
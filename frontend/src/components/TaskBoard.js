@@ -0,0 +1,163 @@
+import * as React from 'react';
+import {useCallback, useEffect, useState} from "react";
+import Card from '@mui/material/Card';
+import CardContent from '@mui/material/CardContent';
+import Paper from '@mui/material/Paper';
+import Typography from '@mui/material/Typography';
+import {CircularProgress} from "@mui/material";
+
+import './TaskBoard.css'
+
+const BOARD_URL = "http://localhost:8080/board";
+
+// neighbors returns the IDs of the cards around index in tasks, the column
+// the card is dropped in without the card itself.
+export function neighbors(tasks, index) {
+    return {
+        after: index > 0 ? tasks[index - 1].id : "",
+        before: index < tasks.length ? tasks[index].id : "",
+    };
+}
+
+export default function TaskBoard() {
+    const [columns, setColumns] = useState([]);
+    const [loading, setLoading] = useState(true);
+    const [dragged, setDragged] = useState(null);
+
+    const fetchBoard = useCallback(async () => {
+        try {
+            const response = await fetch(BOARD_URL);
+            if (!response.ok) {
+                throw new Error(`HTTP error! Status: ${response.status}`);
+            }
+            const board = await response.json();
+            setColumns(board.columns);
+        } catch (err) {
+            alert(err.message);
+        } finally {
+            setLoading(false);
+        }
+    }, []);
+
+    useEffect(() => {
+        fetchBoard();
+    }, [fetchBoard]);
+
+    // Cards moved by other clients come as task events, reload the board.
+    useEffect(() => {
+        if (typeof EventSource === "undefined") {
+            return;
+        }
+        const source = new EventSource("http://localhost:8080/tasks/events");
+        ["task.created", "task.updated", "task.deleted", "resync"].forEach((type) =>
+            source.addEventListener(type, fetchBoard)
+        );
+        return () => source.close();
+    }, [fetchBoard]);
+
+    // moveTask drops the dragged card at index of the column of status,
+    // index counting the cards of the column but the dragged one.
+    const moveTask = async (status, index) => {
+        const task = dragged;
+        setDragged(null);
+        if (!task) {
+            return;
+        }
+        const column = columns.find((c) => c.status === status);
+        const others = column.tasks.filter((t) => t.id !== task.id);
+        const move = {status, ...neighbors(others, index)};
+
+        // Show the card at its new place right away, the response gives its rank.
+        setColumns((prev) => prev.map((c) => {
+            const tasks = c.tasks.filter((t) => t.id !== task.id);
+            if (c.status === status) {
+                tasks.splice(index, 0, {...task, status});
+            }
+            return {...c, tasks};
+        }));
+
+        try {
+            const response = await fetch(`http://localhost:8080/tasks/${task.id}/move`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify(move),
+            });
+            if (response.status === 409) {
+                // The board changed meanwhile, show it as it is now.
+                await fetchBoard();
+                return;
+            }
+            if (!response.ok) {
+                throw new Error(`HTTP error! Status: ${response.status}`);
+            }
+            const moved = await response.json();
+            setColumns((prev) => prev.map((c) => ({
+                ...c,
+                tasks: c.tasks.map((t) => (t.id === moved.id ? moved : t)),
+            })));
+        } catch (err) {
+            alert(err.message);
+            await fetchBoard();
+        }
+    };
+
+    // dropIndex returns where a card dropped on target goes among the other
+    // cards of its column: above it in the upper half, below it in the lower
+    // half.
+    const dropIndex = (event, tasks, target) => {
+        const index = tasks.filter((t) => t.id !== dragged.id).findIndex((t) => t.id === target.id);
+        const rect = event.currentTarget.getBoundingClientRect();
+        return event.clientY > rect.top + rect.height / 2 ? index + 1 : index;
+    };
+
+    if (loading) {
+        return <CircularProgress/>;
+    }
+
+    return (
+        <div className="task-board">
+            {columns.map((column) => (
+                <Paper
+                    key={column.status}
+                    className="task-board-column"
+                    data-testid={`column-${column.status}`}
+                    onDragOver={(event) => event.preventDefault()}
+                    onDrop={(event) => {
+                        event.preventDefault();
+                        moveTask(column.status, column.tasks.filter((t) => t.id !== dragged?.id).length);
+                    }}
+                >
+                    <Typography variant="h6" className="task-board-column-title">
+                        {column.status} ({column.tasks.length})
+                    </Typography>
+                    {column.tasks.map((task) => (
+                        <Card
+                            key={task.id}
+                            className="task-board-card"
+                            draggable
+                            onDragStart={() => setDragged(task)}
+                            onDragEnd={() => setDragged(null)}
+                            onDragOver={(event) => event.preventDefault()}
+                            onDrop={(event) => {
+                                event.preventDefault();
+                                event.stopPropagation();
+                                if (!dragged || dragged.id === task.id) {
+                                    setDragged(null);
+                                    return;
+                                }
+                                moveTask(column.status, dropIndex(event, column.tasks, task));
+                            }}
+                        >
+                            <CardContent>
+                                <Typography>{task.title}</Typography>
+                                {task.assignee && (
+                                    <Typography variant="body2" color="text.secondary">{task.assignee}</Typography>
+                                )}
+                            </CardContent>
+                        </Card>
+                    ))}
+                </Paper>
+            ))}
+        </div>
+    );
+}
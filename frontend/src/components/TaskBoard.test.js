@@ -0,0 +1,90 @@
+import React from "react";
+import {render, screen, fireEvent, waitFor, cleanup, within} from "@testing-library/react";
+import TaskBoard, {neighbors} from "./TaskBoard";
+
+global.fetch = jest.fn();
+global.alert = jest.fn();
+
+describe("TaskBoard", () => {
+    const board = {
+        columns: [
+            {status: "pending", tasks: [
+                {id: "1", title: "Write tests", status: "pending", rank: "F"},
+                {id: "2", title: "Ship", status: "pending", rank: "V"},
+            ]},
+            {status: "done", tasks: [
+                {id: "3", title: "Plan", status: "done", rank: "V"},
+            ]},
+        ],
+    };
+
+    const respond = (body) => ({ok: true, status: 200, json: async () => body});
+
+    afterEach(() => {
+        jest.clearAllMocks();
+        cleanup();
+    });
+
+    test("shows a column per status", async () => {
+        fetch.mockResolvedValueOnce(respond(board));
+        render(<TaskBoard/>);
+
+        await waitFor(() => expect(screen.getByText("pending (2)")).toBeInTheDocument());
+        expect(within(screen.getByTestId("column-pending")).getByText("Write tests")).toBeInTheDocument();
+        expect(within(screen.getByTestId("column-done")).getByText("Plan")).toBeInTheDocument();
+        expect(fetch).toHaveBeenCalledWith("http://localhost:8080/board");
+    });
+
+    test("moves a card dropped at the bottom of a column", async () => {
+        fetch.mockResolvedValueOnce(respond(board));
+        render(<TaskBoard/>);
+        await waitFor(() => screen.getByText("Write tests"));
+
+        fetch.mockResolvedValueOnce(respond({id: "1", title: "Write tests", status: "done", rank: "k"}));
+        fireEvent.dragStart(screen.getByText("Write tests"));
+        fireEvent.drop(screen.getByTestId("column-done"));
+
+        await waitFor(() => expect(fetch).toHaveBeenCalledWith("http://localhost:8080/tasks/1/move", {
+            method: "POST",
+            headers: {"Content-Type": "application/json"},
+            body: JSON.stringify({status: "done", after: "3", before: ""}),
+        }));
+        expect(within(screen.getByTestId("column-done")).getByText("Write tests")).toBeInTheDocument();
+    });
+
+    test("reloads the board when the neighbors moved meanwhile", async () => {
+        fetch.mockResolvedValueOnce(respond(board));
+        render(<TaskBoard/>);
+        await waitFor(() => screen.getByText("Write tests"));
+
+        fetch.mockResolvedValueOnce({ok: false, status: 409});
+        fetch.mockResolvedValueOnce(respond(board));
+        fireEvent.dragStart(screen.getByText("Write tests"));
+        fireEvent.drop(screen.getByTestId("column-done"));
+
+        await waitFor(() => expect(fetch).toHaveBeenCalledTimes(3));
+        await waitFor(() => expect(within(screen.getByTestId("column-pending")).getByText("Write tests")).toBeInTheDocument());
+        expect(global.alert).not.toHaveBeenCalled();
+    });
+
+    test("alerts when the move fails", async () => {
+        fetch.mockResolvedValueOnce(respond(board));
+        render(<TaskBoard/>);
+        await waitFor(() => screen.getByText("Write tests"));
+
+        fetch.mockResolvedValueOnce({ok: false, status: 500});
+        fetch.mockResolvedValueOnce(respond(board));
+        fireEvent.dragStart(screen.getByText("Ship"));
+        fireEvent.drop(screen.getByTestId("column-done"));
+
+        await waitFor(() => expect(global.alert).toHaveBeenCalledWith("HTTP error! Status: 500"));
+    });
+
+    test("finds the neighbors of a drop position", () => {
+        const tasks = [{id: "1"}, {id: "2"}];
+        expect(neighbors(tasks, 0)).toEqual({after: "", before: "1"});
+        expect(neighbors(tasks, 1)).toEqual({after: "1", before: "2"});
+        expect(neighbors(tasks, 2)).toEqual({after: "2", before: ""});
+        expect(neighbors([], 0)).toEqual({after: "", before: ""});
+    });
+});
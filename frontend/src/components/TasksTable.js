@@ -43,28 +43,56 @@ export default function TasksTable() {
     const [modalOpen, setModalOpen] = useState(false);
 
 
-    useEffect(() => {
-        const fetchTasks = async () => {
-            try {
-                setLoading(true); // Set loading to while fetching data
-                const response = await fetch("http://localhost:8080/tasks");
-
-                if (!response.ok) {
-                    throw new Error(`HTTP error! Status: ${response.status}`);
-                }
-
-                const data = await response.json();
-                setTasks(data);
-            } catch (err) {
-                alert(err.message);
-            } finally {
-                setLoading(false);
+    const fetchTasks = async () => {
+        try {
+            setLoading(true); // Set loading to while fetching data
+            const response = await fetch("http://localhost:8080/tasks");
+
+            if (!response.ok) {
+                throw new Error(`HTTP error! Status: ${response.status}`);
             }
-        };
 
+            const data = await response.json();
+            setTasks(data);
+        } catch (err) {
+            alert(err.message);
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    useEffect(() => {
         fetchTasks();
     }, []);
 
+    // Keep the table in sync with changes made by other clients. EventSource
+    // reconnects by itself and resumes from the last received event.
+    useEffect(() => {
+        if (typeof EventSource === "undefined") {
+            return;
+        }
+        const source = new EventSource("http://localhost:8080/tasks/events");
+        const upsert = (event) => {
+            const { task } = JSON.parse(event.data);
+            setTasks((prevTasks) => {
+                const exists = prevTasks.some((t) => String(t.id) === String(task.id));
+                return exists
+                    ? prevTasks.map((t) => (String(t.id) === String(task.id) ? { ...t, ...task } : t))
+                    : [...prevTasks, task];
+            });
+        };
+        const remove = (event) => {
+            const { task_id } = JSON.parse(event.data);
+            setTasks((prevTasks) => prevTasks.filter((t) => String(t.id) !== String(task_id)));
+        };
+        source.addEventListener("task.created", upsert);
+        source.addEventListener("task.updated", upsert);
+        source.addEventListener("task.deleted", remove);
+        // The server couldn't replay every missed event, reload everything.
+        source.addEventListener("resync", fetchTasks);
+        return () => source.close();
+    }, []);
+
     const editTask = (task) => {
         setSelectedTask(task);
         setModalOpen(true);
@@ -84,7 +112,9 @@ export default function TasksTable() {
     };
 
     const handleTaskCreated = (newTask) => {
-        setTasks((prevTasks) => [...prevTasks, newTask]);
+        setTasks((prevTasks) =>
+            prevTasks.some((task) => String(task.id) === String(newTask.id)) ? prevTasks : [...prevTasks, newTask]
+        );
     };
 
     const addNewTask = () => {
@@ -185,4 +185,30 @@ describe("TasksTable", () => {
         expect(screen.queryByText(task.description)).not.toBeInTheDocument();
         expect(screen.queryByText(task.status)).not.toBeInTheDocument();
     });
+
+    test("applies task changes streamed by the server", async () => {
+        const listeners = {};
+        global.EventSource = jest.fn(() => ({
+            addEventListener: (type, listener) => { listeners[type] = listener; },
+            close: jest.fn(),
+        }));
+        fetch.mockResolvedValueOnce({
+            ok: true,
+            json: async () => mockTasks,
+        });
+
+        render(<TasksTable />);
+        await waitFor(() => screen.getByText("Task 1"));
+
+        act(() => {
+            listeners["task.created"]({ data: JSON.stringify({ task_id: "3", task: { ...mockNewTask, id: "3" } }) });
+            listeners["task.updated"]({ data: JSON.stringify({ task_id: "1", task: { ...baseTask, title: "Renamed Task" } }) });
+            listeners["task.deleted"]({ data: JSON.stringify({ task_id: "2" }) });
+        });
+
+        expect(screen.getByText("New Task")).toBeInTheDocument();
+        expect(screen.getByText("Renamed Task")).toBeInTheDocument();
+        expect(screen.queryByText("Task 2")).not.toBeInTheDocument();
+        delete global.EventSource;
+    });
 });